@@ -10,14 +10,30 @@ import (
 
 	db "github.com/akshay237/backend-with-go/database/sqlc"
 	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
 	"github.com/gin-gonic/gin"
 )
 
 const (
 	UniqueKeyConstraint  = "unique_voilation"
 	ForeignKeyConstraint = "foreign_key_violation"
+	CheckConstraint      = "check_violation"
 )
 
+type accountResponse struct {
+	db.Account
+	FormattedBalance          string `json:"formatted_balance"`
+	FormattedAvailableBalance string `json:"formatted_available_balance"`
+}
+
+func (s *Server) newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		Account:                   account,
+		FormattedBalance:          s.currencies.Format(account.Balance, account.Currency),
+		FormattedAvailableBalance: s.currencies.Format(account.AvailableBalance, account.Currency),
+	}
+}
+
 // Create Account
 type CreateAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
@@ -63,7 +79,7 @@ func (s *Server) CreateAccount(ctx *gin.Context) {
 	}
 
 	// 4. return the account details to the end user
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, s.newAccountResponse(account))
 }
 
 // Get Account
@@ -92,16 +108,16 @@ func (s *Server) GetAccount(ctx *gin.Context) {
 		return
 	}
 
-	// 3. check if the account belong to the right user
+	// 3. check if the account belong to the right user, admins can read every account
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if authPayload.Username != account.Owner {
+	if authPayload.Username != account.Owner && authPayload.Role != util.AdminRole {
 		err := errors.New("account doesn't belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
 	// 3. return the account details
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, s.newAccountResponse(account))
 }
 
 // List Accounts
@@ -137,7 +153,11 @@ func (s *Server) ListAccounts(ctx *gin.Context) {
 	}
 
 	// 4. return the accounts
-	ctx.JSON(http.StatusOK, accounts)
+	response := make([]accountResponse, 0, len(accounts))
+	for _, account := range accounts {
+		response = append(response, s.newAccountResponse(account))
+	}
+	ctx.JSON(http.StatusOK, response)
 }
 
 // Update Account
@@ -146,6 +166,7 @@ type UpdateAccountRequest struct {
 	Balance int64 `json:"balance" binding:"required"`
 }
 
+// UpdateAccount sets the balance of an account, the difference is posted as an adjustment entry
 func (s *Server) UpdateAccount(ctx *gin.Context) {
 
 	// 1. validate the request
@@ -155,26 +176,76 @@ func (s *Server) UpdateAccount(ctx *gin.Context) {
 		return
 	}
 
-	// 2. create args for the update account func
-	args := db.UpdateAccountParams{
+	// 2. create args for the update account balance transaction
+	args := db.UpdateAccountBalanceTxParams{
 		ID:      req.Id,
 		Balance: req.Balance,
 	}
 
-	// 3. call the update account database func
-	account, err := s.store.UpdateAccount(ctx, args)
+	// 3. post the adjustment and update the balance
+	result, err := s.store.UpdateAccountBalanceTx(ctx, args)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			noAccountError := fmt.Errorf("no account exists for id %d", req.Id)
 			ctx.JSON(http.StatusNotFound, errorResponse(noAccountError))
 			return
 		}
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
 	// 4. return the updated account
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, s.newAccountResponse(result.Account))
+}
+
+// Update Overdraft Limit
+type UpdateOverdraftLimitRequest struct {
+	// OverdraftLimit is how far below zero the balance can go, zero turns the overdraft off
+	OverdraftLimit *int64 `json:"overdraft_limit" binding:"required,min=0"`
+}
+
+// UpdateOverdraftLimit sets the overdraft limit of an account, the limit can't be lowered below the overdraft in use
+func (s *Server) UpdateOverdraftLimit(ctx *gin.Context) {
+
+	// 1. validate the request
+	var uri GetAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req UpdateOverdraftLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. update the limit, the balance constraints of the account reject a limit below its overdraft
+	account, err := s.store.UpdateAccountOverdraftLimit(ctx, db.UpdateAccountOverdraftLimitParams{
+		ID:             uri.Id,
+		OverdraftLimit: *req.OverdraftLimit,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			noAccountError := fmt.Errorf("no account exists for id %d", uri.Id)
+			ctx.JSON(http.StatusNotFound, errorResponse(noAccountError))
+			return
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == CheckConstraint {
+			err := errors.New("overdraft limit is lower than the overdraft of the account")
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// 3. return the updated account
+	ctx.JSON(http.StatusOK, s.newAccountResponse(account))
 }
 
 // Delete Account
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/akshay237/backend-with-go/apikey"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type apiKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Username   string     `json:"username"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	AllowedIPs []string   `json:"allowed_ips"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// newAPIKeyResponse leaves out the hash of the key
+func newAPIKeyResponse(apiKey db.ApiKey) apiKeyResponse {
+	response := apiKeyResponse{
+		ID:         apiKey.ID,
+		Username:   apiKey.Username,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		AllowedIPs: apiKey.AllowedIps,
+		LastUsedIP: apiKey.LastUsedIp.String,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+	if apiKey.ExpiresAt.Valid {
+		response.ExpiresAt = &apiKey.ExpiresAt.Time
+	}
+	if apiKey.LastUsedAt.Valid {
+		response.LastUsedAt = &apiKey.LastUsedAt.Time
+	}
+	return response
+}
+
+// apiKeyOwner returns the user whose keys are managed, only admins manage the keys of other users
+// such as the service accounts
+func apiKeyOwner(authPayload *token.Payload, username string) (string, error) {
+	if username == "" || username == authPayload.Username {
+		return authPayload.Username, nil
+	}
+	if authPayload.Role != util.AdminRole {
+		return "", fmt.Errorf("role %q is not allowed to manage the api keys of another user", authPayload.Role)
+	}
+	return username, nil
+}
+
+type createAPIKeyRequest struct {
+	Name       string     `json:"name" binding:"required,max=64"`
+	Username   string     `json:"username" binding:"omitempty,alphanum"`
+	Scopes     []string   `json:"scopes" binding:"max=32,dive,required,max=64"`
+	AllowedIPs []string   `json:"allowed_ips" binding:"max=32,dive,required"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+type createAPIKeyResponse struct {
+	// Key is shown only once, it can't be recovered later
+	Key    string         `json:"key"`
+	APIKey apiKeyResponse `json:"api_key"`
+}
+
+// createAPIKey creates an API key of the authenticated user, admins can create the keys of service accounts.
+// A key created with another key is no wider than that key, see apikey.Service.Create.
+func (s *Server) createAPIKey(ctx *gin.Context) {
+	// 1. check the valid request
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		err := errors.New("expires_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. get the owner of the new key
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	owner, err := apiKeyOwner(authPayload, req.Username)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	// 2.1 the key can't have scopes the caller doesn't have, a caller limited by scopes passes them on
+	scopes, err := token.NarrowScopes(authPayload.Scopes, req.Scopes)
+	if err != nil {
+		if errors.Is(err, token.ErrScopeNotGranted) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 3. create the key, only its hash is stored
+	arg := apikey.CreateParams{
+		Username:   owner,
+		Name:       req.Name,
+		Scopes:     scopes,
+		AllowedIPs: req.AllowedIPs,
+		Caller:     authPayload,
+	}
+	if req.ExpiresAt != nil {
+		arg.ExpiresAt = *req.ExpiresAt
+	}
+
+	key, apiKey, err := s.apiKeys.Create(ctx, arg)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == ForeignKeyConstraint {
+			err := fmt.Errorf("no user exists with username %s", owner)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		if errors.Is(err, apikey.ErrInvalidAllowedIP) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if errors.Is(err, apikey.ErrWiderThanCaller) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createAPIKeyResponse{
+		Key:    key,
+		APIKey: newAPIKeyResponse(apiKey),
+	})
+}
+
+type listAPIKeysRequest struct {
+	Username string `form:"username" binding:"omitempty,alphanum"`
+}
+
+// listAPIKeys returns the keys of the authenticated user which are not revoked
+func (s *Server) listAPIKeys(ctx *gin.Context) {
+	// 1. check the valid request
+	var req listAPIKeysRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	owner, err := apiKeyOwner(authPayload, req.Username)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	// 2. list the keys
+	apiKeys, err := s.apiKeys.List(ctx, owner)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]apiKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		response = append(response, newAPIKeyResponse(apiKey))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type revokeAPIKeyRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// revokeAPIKey revokes a key of the authenticated user, admins can revoke any key
+func (s *Server) revokeAPIKey(ctx *gin.Context) {
+	// 1. check the valid request
+	var req revokeAPIKeyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. revoke the key, a key of another user is reported as not found
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	owner := authPayload.Username
+	if authPayload.Role == util.AdminRole {
+		owner = ""
+	}
+
+	apiKey, err := s.apiKeys.Revoke(ctx, uuid.MustParse(req.ID), owner)
+	if err != nil {
+		if errors.Is(err, apikey.ErrKeyNotFound) {
+			err := fmt.Errorf("no api key exists for id %s", req.ID)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// 3. revoke the tokens delegated with the key
+	err = s.revocations.RevokeAPIKey(ctx, apiKey.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAPIKeyResponse(apiKey))
+}
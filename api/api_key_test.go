@@ -0,0 +1,415 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/apikey"
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func randomAPIKey(username string) db.ApiKey {
+	return db.ApiKey{
+		ID:         uuid.New(),
+		Username:   username,
+		Name:       "batch",
+		Prefix:     apikey.KeyPrefix + util.RandomString(8),
+		KeyHash:    util.RandomString(64),
+		Scopes:     []string{},
+		AllowedIps: []string{},
+		CreatedAt:  time.Now(),
+	}
+}
+
+func TestCreateAPIKeyAPI(t *testing.T) {
+	username := util.RandomOwner()
+
+	testcases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": "batch", "scopes": []string{"transfers:write"}, "allowed_ips": []string{"10.0.0.0/8"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+						require.Equal(t, username, arg.Username)
+						require.Equal(t, []string{"transfers:write"}, arg.Scopes)
+						require.Equal(t, []string{"10.0.0.0/8"}, arg.AllowedIps)
+						require.False(t, arg.ExpiresAt.Valid)
+						return db.ApiKey{ID: arg.ID, Username: arg.Username, Name: arg.Name, Prefix: arg.Prefix,
+							KeyHash: arg.KeyHash, Scopes: arg.Scopes, AllowedIps: arg.AllowedIps, CreatedAt: time.Now()}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response createAPIKeyResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.True(t, strings.HasPrefix(response.Key, response.APIKey.Prefix))
+				require.NotContains(t, recorder.Body.String(), apikey.HashKey(response.Key))
+				require.Equal(t, username, response.APIKey.Username)
+			},
+		},
+		{
+			name: "AdminForServiceAccount",
+			body: gin.H{"name": "batch", "username": "batchjobs", "expires_at": time.Now().Add(time.Hour)},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+						require.Equal(t, "batchjobs", arg.Username)
+						require.True(t, arg.ExpiresAt.Valid)
+						return db.ApiKey{ID: arg.ID, Username: arg.Username, ExpiresAt: arg.ExpiresAt}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			// the caller authenticated with a key which is limited to an address range and expires
+			name: "KeyCallerPassesLimitsOn",
+			body: gin.H{"name": "batch"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				callerKey := randomAPIKey(username)
+				callerKey.AllowedIps = []string{"10.0.0.0/8"}
+				callerKey.ExpiresAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Any()).Times(1).Return(callerKey, nil)
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+						require.Equal(t, callerKey.AllowedIps, arg.AllowedIps)
+						require.Equal(t, callerKey.ExpiresAt, arg.ExpiresAt)
+						return db.ApiKey{ID: arg.ID, Username: arg.Username, AllowedIps: arg.AllowedIps, ExpiresAt: arg.ExpiresAt}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WiderThanKeyCaller",
+			body: gin.H{"name": "batch", "allowed_ips": []string{"0.0.0.0/0"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				callerKey := randomAPIKey(username)
+				callerKey.AllowedIps = []string{"10.0.0.0/8"}
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Any()).Times(1).Return(callerKey, nil)
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownUser",
+			body: gin.H{"name": "batch", "username": "nobody"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ApiKey{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "OtherUserNotAdmin",
+			body: gin.H{"name": "batch", "username": "batchjobs"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAllowedIP",
+			body: gin.H{"name": "batch", "allowed_ips": []string{"10.0.0.0/33"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredAlready",
+			body: gin.H{"name": "batch", "expires_at": time.Now().Add(-time.Hour)},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ScopedCallerPassesScopesOn",
+			body: gin.H{"name": "batch"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addScopedAuthorization(t, request, tokenMaker, username, token.ScopeSecurityWrite, token.ScopeAccountsRead)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+						require.Equal(t, []string{token.ScopeSecurityWrite, token.ScopeAccountsRead}, arg.Scopes)
+						return db.ApiKey{ID: arg.ID, Username: arg.Username, Scopes: arg.Scopes}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ScopeNotGranted",
+			body: gin.H{"name": "batch", "scopes": []string{token.ScopeTransfersWrite}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addScopedAuthorization(t, request, tokenMaker, username, token.ScopeSecurityWrite)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownScope",
+			body: gin.H{"name": "batch", "scopes": []string{"transfers:all"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingName",
+			body: gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			// the caller logged in with a password unless the case authenticates with a key
+			store.EXPECT().GetAPIKey(gomock.Any(), gomock.Any()).AnyTimes().Return(db.ApiKey{}, sql.ErrNoRows)
+			store.EXPECT().GetAccessToken(gomock.Any(), gomock.Any()).AnyTimes().Return(db.AccessToken{}, sql.ErrNoRows)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api_keys", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAPIKeyAPI(t *testing.T) {
+	username := util.RandomOwner()
+	apiKey := randomAPIKey(username)
+
+	testcases := []struct {
+		name          string
+		method        string
+		url           string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ListAPIKeys",
+			method: http.MethodGet,
+			url:    "/api_keys",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAPIKeys(gomock.Any(), gomock.Eq(username)).Times(1).Return([]db.ApiKey{apiKey}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), apiKey.KeyHash)
+
+				var apiKeys []apiKeyResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &apiKeys))
+				require.Len(t, apiKeys, 1)
+				require.Equal(t, apiKey.ID, apiKeys[0].ID)
+				require.Equal(t, apiKey.Prefix, apiKeys[0].Prefix)
+			},
+		},
+		{
+			name:   "ListAPIKeysOfAnotherUser",
+			method: http.MethodGet,
+			url:    "/api_keys?username=batchjobs",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAPIKeys(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeAPIKey",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/api_keys/%s", apiKey.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				revoked := apiKey
+				revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(apiKey, nil)
+				store.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(revoked, nil)
+
+				// the tokens delegated with the key are revoked along with it
+				delegated := db.AccessToken{
+					ID:        uuid.New(),
+					Username:  username,
+					ApiKeyID:  uuid.NullUUID{UUID: apiKey.ID, Valid: true},
+					ExpiresAt: time.Now().Add(time.Minute),
+				}
+				store.EXPECT().ListAPIKeyAccessTokens(gomock.Any(), gomock.Eq(delegated.ApiKeyID)).Times(1).Return([]db.AccessToken{delegated}, nil)
+				store.EXPECT().RevokeToken(gomock.Any(), gomock.Eq(db.RevokeTokenParams{
+					ID:        delegated.ID,
+					Username:  username,
+					ExpiresAt: delegated.ExpiresAt,
+				})).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeAPIKeyOfAnotherUser",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/api_keys/%s", apiKey.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(apiKey, nil)
+				store.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "AdminRevokesAPIKey",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/api_keys/%s", apiKey.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(apiKey, nil)
+				store.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(apiKey, nil)
+				store.EXPECT().ListAPIKeyAccessTokens(gomock.Any(), gomock.Any()).Times(1).Return([]db.AccessToken{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeAPIKeyInvalidID",
+			method: http.MethodDelete,
+			url:    "/api_keys/not-a-uuid",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
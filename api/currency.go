@@ -0,0 +1,12 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listCurrencies returns the currencies accounts can be opened in
+func (s *Server) listCurrencies(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, s.currencies.List())
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akshay237/backend-with-go/currency"
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCurrenciesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/currencies", nil)
+	require.NoError(t, err)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var currencies []currency.Currency
+	err = json.Unmarshal(recorder.Body.Bytes(), &currencies)
+	require.NoError(t, err)
+	require.Equal(t, server.currencies.List(), currencies)
+}
+
+func TestAccountResponseFormattedBalance(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	account := createRandomAccount(util.RandomOwner())
+	account.Balance = 12345
+
+	response := server.newAccountResponse(account)
+	require.Equal(t, "123.45", response.FormattedBalance)
+
+	data, err := json.Marshal(response)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"formatted_balance":"123.45"`)
+	require.Contains(t, string(data), `"balance":12345`)
+}
@@ -0,0 +1,268 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/hold"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/gin-gonic/gin"
+)
+
+type holdResponse struct {
+	db.Hold
+	FormattedAmount         string `json:"formatted_amount"`
+	FormattedCapturedAmount string `json:"formatted_captured_amount"`
+}
+
+// newHoldResponse formats the amounts in the currency of the accounts, holds are not cross currency
+func (s *Server) newHoldResponse(h db.Hold, currency string) holdResponse {
+	return holdResponse{
+		Hold:                    h,
+		FormattedAmount:         s.currencies.Format(h.Amount, currency),
+		FormattedCapturedAmount: s.currencies.Format(h.CapturedAmount, currency),
+	}
+}
+
+// holdErrorResponse writes the response of an error of the hold transactions
+func holdErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrInsufficientFunds):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	case errors.Is(err, db.ErrHoldNotActive):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, db.ErrHoldExpired):
+		ctx.JSON(http.StatusGone, errorResponse(err))
+	case errors.Is(err, db.ErrHoldAmountExceeded):
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	default:
+		var velocityErr *db.VelocityError
+		if errors.As(err, &velocityErr) {
+			ctx.JSON(http.StatusUnprocessableEntity, velocityErrorResponse(velocityErr))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
+
+type authorizeHoldRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	// ExpiresIn is the lifetime of the hold in seconds, it is capped by the configured hold duration
+	ExpiresIn int64 `json:"expires_in" binding:"omitempty,min=1"`
+}
+
+type authorizeHoldResponse struct {
+	Hold        holdResponse    `json:"hold"`
+	FromAccount accountResponse `json:"from_account"`
+}
+
+// authorizeHold reserves funds of the from account of the authenticated user for the to account.
+// Nothing is posted to the ledger until the hold is captured.
+func (s *Server) authorizeHold(ctx *gin.Context) {
+	// 1. check the valid request
+	var req authorizeHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. the from account must belong to the user and both accounts must use the currency of the hold
+	fromAccount, valid := s.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if _, valid = s.validAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	// 3. reserve the funds
+	result, err := s.store.AuthorizeHoldTx(ctx, db.AuthorizeHoldTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		ExpiresAt:     hold.ExpiresAt(s.config, time.Duration(req.ExpiresIn)*time.Second),
+	})
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, authorizeHoldResponse{
+		Hold:        s.newHoldResponse(result.Hold, result.FromAccount.Currency),
+		FromAccount: s.newAccountResponse(result.FromAccount),
+	})
+}
+
+type holdRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getHold returns the hold and its accounts, or writes the error response if one of them doesn't exist
+func (s *Server) getHold(ctx *gin.Context, id int64) (db.Hold, db.Account, db.Account, bool) {
+	h, err := s.store.GetHold(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := fmt.Errorf("no hold exists for id %d", id)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return h, db.Account{}, db.Account{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return h, db.Account{}, db.Account{}, false
+	}
+
+	fromAccount, valid := s.getAccount(ctx, h.FromAccountID)
+	if !valid {
+		return h, fromAccount, db.Account{}, false
+	}
+	toAccount, valid := s.getAccount(ctx, h.ToAccountID)
+	return h, fromAccount, toAccount, valid
+}
+
+// getHoldByID returns a hold to the owner of one of its accounts, admins can read every hold
+func (s *Server) getHoldByID(ctx *gin.Context) {
+	// 1. check the valid request
+	var req holdRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. get the hold and check it belongs to the user
+	h, fromAccount, toAccount, valid := s.getHold(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != fromAccount.Owner && authPayload.Username != toAccount.Owner && authPayload.Role != util.AdminRole {
+		err := errors.New("hold doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newHoldResponse(h, fromAccount.Currency))
+}
+
+type captureHoldRequest struct {
+	// Amount defaults to what is left on the hold
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+	// Final releases what is left on the hold after a partial capture
+	Final bool `json:"final"`
+}
+
+type captureHoldResponse struct {
+	Hold holdResponse `json:"hold"`
+	transferTxResponse
+}
+
+// captureHold posts the captured amount of a hold to the ledger, only the owner of the to account can capture.
+// The body is optional, the whole hold is captured without it.
+func (s *Server) captureHold(ctx *gin.Context) {
+	// 1. check the valid request
+	var uri holdRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req captureHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. the hold is captured by the account it pays
+	_, _, toAccount, valid := s.getHold(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if toAccount.Owner != authPayload.Username {
+		err := errors.New("to account of the hold doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// 3. capture the hold
+	result, err := s.store.CaptureHoldTx(ctx, db.CaptureHoldTxParams{
+		HoldID: uri.ID,
+		Amount: req.Amount,
+		Final:  req.Final,
+	})
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, captureHoldResponse{
+		Hold: s.newHoldResponse(result.Hold, result.FromAccount.Currency),
+		transferTxResponse: s.newTransferTxResponse(db.TransferTxResult{
+			Transfer:    result.Transfer,
+			FromAccount: result.FromAccount,
+			ToAccount:   result.ToAccount,
+			FromEntry:   result.FromEntry,
+			ToEntry:     result.ToEntry,
+		}),
+	})
+}
+
+type voidHoldResponse struct {
+	Hold        holdResponse    `json:"hold"`
+	FromAccount accountResponse `json:"from_account"`
+}
+
+// voidHold cancels a hold and gives what is left on it back to the from account.
+// Only the owner of the to account can void, the payer waits for the hold to expire.
+func (s *Server) voidHold(ctx *gin.Context) {
+	// 1. check the valid request
+	var req holdRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. the hold is voided by the account it pays
+	_, _, toAccount, valid := s.getHold(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if toAccount.Owner != authPayload.Username {
+		err := errors.New("to account of the hold doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// 3. release the funds
+	result, err := s.store.VoidHoldTx(ctx, req.ID)
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, voidHoldResponse{
+		Hold:        s.newHoldResponse(result.Hold, result.FromAccount.Currency),
+		FromAccount: s.newAccountResponse(result.FromAccount),
+	})
+}
@@ -5,7 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/akshay237/backend-with-go/currency"
 	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/mail"
+	"github.com/akshay237/backend-with-go/revocation"
 	"github.com/akshay237/backend-with-go/util"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -21,10 +24,12 @@ func newTestServer(t *testing.T, store db.Store) *Server {
 
 	config := util.Config{
 		TokenSymmetricKey:   util.RandomString(32),
+		TOTPEncryptionKey:   util.RandomString(32),
 		AccessTokenDuration: time.Minute * 5,
+		Mailer:              mail.MailerMemory,
 	}
 
-	server, err := NewServerHandler(config, store)
+	server, err := NewServerHandler(config, store, currency.NewRegistry(store), revocation.NewList(store, config))
 	require.NoError(t, err)
 	return server
 }
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/akshay237/backend-with-go/mfa"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type enrollTOTPResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// enrollTOTP creates a pending TOTP secret for the authenticated user, it is enabled by confirmTOTP
+func (s *Server) enrollTOTP(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	enrollment, err := s.mfa.Enroll(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollTOTPResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+	})
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type confirmTOTPResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTOTP enables the pending TOTP secret with a code from the authenticator app.
+// The recovery codes are returned only once.
+func (s *Server) confirmTOTP(ctx *gin.Context) {
+	// 1. check the valid request
+	var req mfaCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. enable the secret and create the recovery codes
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	codes, err := s.mfa.Confirm(ctx, authPayload.Username, req.Code)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, confirmTOTPResponse{RecoveryCodes: codes})
+}
+
+// disableTOTP removes the second factor of the authenticated user, it needs a TOTP or a recovery code
+func (s *Server) disableTOTP(ctx *gin.Context) {
+	// 1. check the valid request
+	var req mfaCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. disable the second factor
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	err := s.mfa.Disable(ctx, authPayload.Username, req.Code)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type verifyLoginMFARequest struct {
+	MFAChallengeToken string `json:"mfa_challenge_token" binding:"required,uuid"`
+	Code              string `json:"code" binding:"required"`
+	// Scopes limit the tokens of the session as in the password step of the login
+	Scopes []string `json:"scopes" binding:"max=16"`
+}
+
+// verifyLoginMFA is the second step of the login, it exchanges the MFA challenge token
+// and a TOTP or recovery code for the access and refresh tokens.
+func (s *Server) verifyLoginMFA(ctx *gin.Context) {
+	// 1. check the valid request
+	var req verifyLoginMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	scopes, err := token.NarrowScopes(nil, req.Scopes)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. count the attempt for the user of the challenge, the codes are throttled like the passwords
+	challengeID := uuid.MustParse(req.MFAChallengeToken)
+	username, err := s.mfa.ChallengeUsername(ctx, challengeID)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), errorResponse(err))
+		return
+	}
+	err = s.loginGuard.Attempt(ctx, username, clientIP(ctx))
+	if err != nil {
+		abortLoginLocked(ctx, err)
+		return
+	}
+
+	// 2.1 check the code for the challenge
+	_, err = s.mfa.VerifyChallenge(ctx, challengeID, req.Code)
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) || errors.Is(err, mfa.ErrChallengeNotUsable) {
+			s.recordLoginFailure(ctx, username)
+		}
+		ctx.JSON(mfaErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	// 2.2 the login is complete, the failed attempts of the username are forgotten
+	err = s.loginGuard.RecordSuccess(ctx, username, clientIP(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// 3. create the tokens and the session
+	user, err := s.store.GetUser(ctx, username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response, err := s.createLoginSession(ctx, user, scopes)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// mfaErrorStatus returns the HTTP status of an error of the MFA authenticator
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, mfa.ErrAlreadyEnabled):
+		return http.StatusConflict
+	case errors.Is(err, mfa.ErrNotEnabled):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, mfa.ErrInvalidCode), errors.Is(err, mfa.ErrChallengeNotUsable):
+		return http.StatusUnauthorized
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/lockout"
+	"github.com/akshay237/backend-with-go/mfa"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginUserMFAAPI(t *testing.T) {
+	user, password := createRandomUser(t)
+	clientIP := "203.0.113.7"
+	enabledTOTP := db.UserTotp{
+		Username:  user.Username,
+		EnabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	testcases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "WithoutMFA",
+			body: gin.H{"username": user.Username, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}, nil
+					})
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserTotp{}, sql.ErrNoRows)
+				store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().ReleaseLoginAttempt(gomock.Any(), gomock.Eq(db.ReleaseLoginAttemptParams{Kind: lockout.KindIP, Subject: clientIP})).
+					Times(1).Return(nil)
+				store.EXPECT().CreateMFAChallenge(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).Return(randomSession(user.Username), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.False(t, response.MFARequired)
+				require.NotEmpty(t, response.AccessToken)
+			},
+		},
+		{
+			name: "WithMFA",
+			body: gin.H{"username": user.Username, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}, nil
+					})
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabledTOTP, nil)
+				// the username keeps the attempt until the code is checked
+				store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ReleaseLoginAttempt(gomock.Any(), gomock.Eq(db.ReleaseLoginAttemptParams{Kind: lockout.KindIP, Subject: clientIP})).
+					Times(1).Return(nil)
+				store.EXPECT().CreateMFAChallenge(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateMFAChallengeParams) (db.MfaChallenge, error) {
+						require.Equal(t, user.Username, arg.Username)
+						return db.MfaChallenge{ID: arg.ID, Username: arg.Username, ExpiresAt: arg.ExpiresAt}, nil
+					})
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response map[string]any
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, true, response["mfa_required"])
+				require.NotEmpty(t, response["mfa_challenge_token"])
+				require.NotContains(t, response, "access_token")
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/user/login", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.RemoteAddr = clientIP + ":51234"
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyLoginMFAAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+	clientIP := "203.0.113.7"
+	recoveryCode := "abcde-fghij"
+	challenge := db.MfaChallenge{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		Attempts:  1,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	enabledTOTP := db.UserTotp{
+		Username:  user.Username,
+		EnabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	testcases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"mfa_challenge_token": challenge.ID, "code": recoveryCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+				store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}, nil
+					})
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabledTOTP, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Eq(db.UseRecoveryCodeParams{
+					Username: user.Username,
+					CodeHash: mfa.HashRecoveryCode(recoveryCode),
+				})).Times(1).Return(db.RecoveryCode{}, nil)
+				store.EXPECT().ConsumeMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+				store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Eq(db.DeleteLoginThrottleParams{Kind: lockout.KindUsername, Subject: user.Username})).
+					Times(1).Return(int64(1), nil)
+				store.EXPECT().ReleaseLoginAttempt(gomock.Any(), gomock.Eq(db.ReleaseLoginAttemptParams{Kind: lockout.KindIP, Subject: clientIP})).
+					Times(1).Return(nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).Return(randomSession(user.Username), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.NotEmpty(t, response.AccessToken)
+				require.NotEmpty(t, response.RefreshToken)
+				require.Equal(t, user.Username, response.User.Username)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: gin.H{"mfa_challenge_token": challenge.ID, "code": recoveryCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+				store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}, nil
+					})
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(enabledTOTP, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RecoveryCode{}, sql.ErrNoRows)
+				store.EXPECT().ConsumeMFAChallenge(gomock.Any(), gomock.Any()).Times(0)
+				// the wrong code counts as a failed login of the username and the ip
+				store.EXPECT().GetLoginThrottle(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.GetLoginThrottleParams) (db.LoginThrottle, error) {
+						if arg.Kind == lockout.KindUsername {
+							require.Equal(t, user.Username, arg.Subject)
+						}
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}, nil
+					})
+				store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ChallengeNotUsable",
+			body: gin.H{"mfa_challenge_token": challenge.ID, "code": recoveryCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(db.MfaChallenge{}, sql.ErrNoRows)
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Locked",
+			body: gin.H{"mfa_challenge_token": challenge.ID, "code": recoveryCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+				store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+						throttle := db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}
+						if arg.Kind == lockout.KindUsername {
+							throttle.LockedUntil = sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true}
+						}
+						return throttle, nil
+					})
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidChallengeToken",
+			body: gin.H{"mfa_challenge_token": "invalid", "code": recoveryCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/user/login/mfa", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.RemoteAddr = clientIP + ":51234"
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
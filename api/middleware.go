@@ -4,19 +4,42 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"strings"
 
+	"github.com/akshay237/backend-with-go/apikey"
+	"github.com/akshay237/backend-with-go/revocation"
 	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
 	"github.com/gin-gonic/gin"
 )
 
 const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "Bearer"
+	authorizationTypeAPIKey = "ApiKey"
 	authorizationPayloadKey = "authorization_payload"
+	forwardedForHeaderKey   = "X-Forwarded-For"
+	clientIPKey             = "client_ip"
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// clientIPMiddleware stores the address of the client of the request. The forwarded address
+// is only used when the request came from a trusted proxy, see util.ClientIP.
+func clientIPMiddleware(trustedProxies []netip.Prefix) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(clientIPKey, util.ClientIP(ctx.Request.RemoteAddr, ctx.Request.Header.Values(forwardedForHeaderKey), trustedProxies))
+		ctx.Next()
+	}
+}
+
+// clientIP returns the address of the client stored by clientIPMiddleware
+func clientIP(ctx *gin.Context) string {
+	return ctx.GetString(clientIPKey)
+}
+
+// authMiddleware verifies the bearer access token and rejects the tokens on the revocation list.
+// Machine clients send an API key instead, it is checked against the allowlist of the key.
+func authMiddleware(tokenMaker token.Maker, revocations *revocation.List, apiKeys *apikey.Service) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
@@ -32,21 +55,85 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
-		authorizationType := fields[0]
-		if authorizationType != authorizationTypeBearer {
+		var payload *token.Payload
+		var err error
+		switch authorizationType := fields[0]; authorizationType {
+		case authorizationTypeBearer:
+			payload, err = tokenMaker.VerifyToken(fields[1])
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+
+			// a refresh token only renews its session, it can't be used as an access token
+			if err := payload.CheckType(token.TokenTypeAccess); err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+
+			if revocations.IsRevoked(payload.ID) {
+				err := errors.New("access token is revoked")
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+
+			if revocations.IssuedBeforePasswordChange(payload) {
+				err := errors.New("access token was issued before the password changed")
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+		case authorizationTypeAPIKey:
+			payload, err = apiKeys.Authenticate(ctx, fields[1], clientIP(ctx))
+			if err != nil {
+				if errors.Is(err, apikey.ErrIPNotAllowed) {
+					ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+					return
+				}
+				if errors.Is(err, apikey.ErrInvalidKey) {
+					ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+					return
+				}
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+		default:
 			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
 
-		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Next()
+	}
+}
+
+// requireRole allows the request only if the authenticated user has one of the roles.
+// It must run after authMiddleware.
+func requireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		for _, role := range roles {
+			if payload.Role == role {
+				ctx.Next()
+				return
+			}
 		}
 
-		ctx.Set(authorizationPayloadKey, payload)
+		err := fmt.Errorf("role %q is not allowed to access this resource", payload.Role)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+	}
+}
+
+// requireScope allows the request only if the token can be used for the scope, a token without scopes is not limited.
+// It must run after authMiddleware.
+func requireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if !payload.HasScope(scope) {
+			err := fmt.Errorf("token is missing the %q scope", scope)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 		ctx.Next()
 	}
 }
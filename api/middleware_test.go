@@ -1,14 +1,22 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/akshay237/backend-with-go/apikey"
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
 	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,9 +26,10 @@ func addAuthorization(
 	tokenMaker token.Maker,
 	authorizationType string,
 	username string,
+	role string,
 	duration time.Duration,
 ) {
-	token, payload, err := tokenMaker.CreateToken(username, duration)
+	token, payload, err := tokenMaker.CreateToken(username, role, nil, token.TokenTypeAccess, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -28,6 +37,14 @@ func addAuthorization(
 	request.Header.Set(authorizationHeaderKey, authorizationHeader)
 }
 
+// addScopedAuthorization adds a bearer token of a depositor limited to the scopes
+func addScopedAuthorization(t *testing.T, request *http.Request, tokenMaker token.Maker, username string, scopes ...string) {
+	token, _, err := tokenMaker.CreateToken(username, util.DepositorRole, scopes, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, token))
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	testcases := []struct {
 		name          string
@@ -37,7 +54,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "Ok",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", util.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -54,7 +71,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "Unsupported Authorization",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, "unsupported", "user", time.Minute)
+				addAuthorization(t, request, tokenMaker, "unsupported", "user", util.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -63,7 +80,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "Invalid Authorization",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, "", "user", time.Minute)
+				addAuthorization(t, request, tokenMaker, "", "user", util.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -72,7 +89,18 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "ExpiredToken",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", -1*time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", util.DepositorRole, -1*time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RefreshTokenAsBearer",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				refreshToken, _, err := tokenMaker.CreateToken("user", util.DepositorRole, nil, token.TokenTypeRefresh, time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -87,7 +115,7 @@ func TestAuthMiddleware(t *testing.T) {
 			authPath := "/auth"
 			server.Router.GET(
 				authPath,
-				authMiddleware(server.tokenMaker),
+				authMiddleware(server.tokenMaker, server.revocations, server.apiKeys),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -103,3 +131,294 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddlewareRevokedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	authPath := "/auth"
+	server.Router.GET(
+		authPath,
+		authMiddleware(server.tokenMaker, server.revocations, server.apiKeys),
+		func(ctx *gin.Context) {
+			ctx.JSON(http.StatusOK, gin.H{})
+		},
+	)
+
+	accessToken, payload, err := server.tokenMaker.CreateToken("user", util.DepositorRole, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	// 1. the token is accepted before it is revoked
+	request, err := http.NewRequest(http.MethodGet, authPath, nil)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+
+	recorder := httptest.NewRecorder()
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// 2. the same token is rejected right after it is revoked
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Eq(db.RevokeTokenParams{
+		ID:        payload.ID,
+		Username:  payload.Username,
+		ExpiresAt: payload.ExpiredAT,
+	})).Times(1).Return(nil)
+	err = server.revocations.Revoke(context.Background(), payload.ID, payload.Username, payload.ExpiredAT)
+	require.NoError(t, err)
+
+	recorder = httptest.NewRecorder()
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestAuthMiddlewarePasswordChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	authPath := "/auth"
+	server.Router.GET(
+		authPath,
+		authMiddleware(server.tokenMaker, server.revocations, server.apiKeys),
+		func(ctx *gin.Context) {
+			ctx.JSON(http.StatusOK, gin.H{})
+		},
+	)
+
+	accessToken, _, err := server.tokenMaker.CreateToken("user", util.DepositorRole, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodGet, authPath, nil)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+
+	// 1. a password change of another user doesn't touch the token
+	server.revocations.PasswordChanged("other", time.Now().Add(2*time.Second))
+
+	recorder := httptest.NewRecorder()
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// 2. the token is rejected once the password of its user changed after it was issued
+	server.revocations.PasswordChanged("user", time.Now().Add(2*time.Second))
+
+	recorder = httptest.NewRecorder()
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	key, err := apikey.GenerateKey()
+	require.NoError(t, err)
+	apiKey := db.ApiKey{
+		ID:         uuid.New(),
+		Username:   "batch",
+		AllowedIps: []string{"203.0.113.0/24"},
+		CreatedAt:  time.Now(),
+	}
+
+	testcases := []struct {
+		name          string
+		header        string
+		remoteAddr    string
+		forwardedFor  string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			header:     fmt.Sprintf("%s %s", authorizationTypeAPIKey, key),
+			remoteAddr: "203.0.113.7:51234",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Eq(apikey.HashKey(key))).Times(1).
+					Return(db.GetActiveAPIKeyByHashRow{ApiKey: apiKey, Role: util.ServiceRole}, nil)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, apiKey.Username, recorder.Body.String())
+			},
+		},
+		{
+			name:       "IPNotAllowed",
+			header:     fmt.Sprintf("%s %s", authorizationTypeAPIKey, key),
+			remoteAddr: "198.51.100.1:51234",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.GetActiveAPIKeyByHashRow{ApiKey: apiKey, Role: util.ServiceRole}, nil)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:         "ForwardedByProxy",
+			header:       fmt.Sprintf("%s %s", authorizationTypeAPIKey, key),
+			remoteAddr:   "127.0.0.1:51234",
+			forwardedFor: "198.51.100.1, 203.0.113.7",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.GetActiveAPIKeyByHashRow{ApiKey: apiKey, Role: util.ServiceRole}, nil)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			// a client which is not a trusted proxy can't pick its address
+			name:         "ForwardedByClient",
+			header:       fmt.Sprintf("%s %s", authorizationTypeAPIKey, key),
+			remoteAddr:   "198.51.100.1:51234",
+			forwardedFor: "203.0.113.7",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.GetActiveAPIKeyByHashRow{ApiKey: apiKey, Role: util.ServiceRole}, nil)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:       "RevokedKey",
+			header:     fmt.Sprintf("%s %s", authorizationTypeAPIKey, key),
+			remoteAddr: "203.0.113.7:51234",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.GetActiveAPIKeyByHashRow{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "KeyAsBearerToken",
+			header:     fmt.Sprintf("%s %s", authorizationTypeBearer, key),
+			remoteAddr: "203.0.113.7:51234",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+
+			authPath := "/auth"
+			server.Router.GET(
+				authPath,
+				authMiddleware(server.tokenMaker, server.revocations, server.apiKeys),
+				func(ctx *gin.Context) {
+					payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+					ctx.String(http.StatusOK, payload.Username)
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+			request.RemoteAddr = tc.remoteAddr
+			request.Header.Set(authorizationHeaderKey, tc.header)
+			if tc.forwardedFor != "" {
+				request.Header.Set(forwardedForHeaderKey, tc.forwardedFor)
+			}
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequireRoleMiddleware(t *testing.T) {
+	testcases := []struct {
+		name          string
+		role          string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Admin",
+			role: util.AdminRole,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Depositor",
+			role: util.DepositorRole,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+
+			adminPath := "/admin"
+			server.Router.GET(
+				adminPath,
+				authMiddleware(server.tokenMaker, server.revocations, server.apiKeys),
+				requireRole(util.AdminRole),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, adminPath, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", tc.role, time.Minute)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequireScopeMiddleware(t *testing.T) {
+	user, _ := createRandomUser(t)
+	account := createRandomAccount(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	// 1. a read only dashboard token can read the accounts
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d", account.ID), nil)
+	require.NoError(t, err)
+	addScopedAuthorization(t, request, server.tokenMaker, user.Username, token.ScopeAccountsRead)
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// 2. the token can't move money, the transfer is rejected before the handler
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodPost, "/transfers", nil)
+	require.NoError(t, err)
+	addScopedAuthorization(t, request, server.tokenMaker, user.Username, token.ScopeAccountsRead)
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
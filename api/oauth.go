@@ -0,0 +1,366 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/oauth"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type oauthClientResponse struct {
+	ID           string    `json:"id"`
+	Owner        string    `json:"owner"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	GrantTypes   []string  `json:"grant_types"`
+	Confidential bool      `json:"confidential"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// newOAuthClientResponse leaves out the hash of the secret
+func newOAuthClientResponse(client db.OauthClient) oauthClientResponse {
+	return oauthClientResponse{
+		ID:           client.ID,
+		Owner:        client.Owner,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectUris,
+		Scopes:       client.Scopes,
+		GrantTypes:   client.GrantTypes,
+		Confidential: client.SecretHash.Valid,
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+// oauthErrorResponse writes the error in the format of RFC 6749, errors which are not OAuth errors are internal
+func oauthErrorResponse(ctx *gin.Context, err error) {
+	var oauthErr *oauth.Error
+	if !errors.As(err, &oauthErr) {
+		oauthErr = &oauth.Error{Code: oauth.ErrorServerError}
+	}
+	if oauthErr.Code == oauth.ErrorInvalidClient {
+		ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(oauthErr.StatusCode(), oauthErr)
+}
+
+type registerOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=64"`
+	RedirectURIs []string `json:"redirect_uris" binding:"max=16,dive,required,max=512"`
+	Scopes       []string `json:"scopes" binding:"required,min=1,max=16"`
+	GrantTypes   []string `json:"grant_types" binding:"max=3"`
+	Confidential bool     `json:"confidential"`
+}
+
+type registerOAuthClientResponse struct {
+	// ClientSecret is shown only once, public clients have none
+	ClientSecret string              `json:"client_secret,omitempty"`
+	Client       oauthClientResponse `json:"client"`
+}
+
+// registerOAuthClient registers a third party app of the authenticated user
+func (s *Server) registerOAuthClient(ctx *gin.Context) {
+	// 1. check the valid request
+	var req registerOAuthClientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. the client can't get scopes the caller doesn't have
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	scopes, err := token.NarrowScopes(authPayload.Scopes, req.Scopes)
+	if err != nil {
+		if errors.Is(err, token.ErrScopeNotGranted) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 3. register the client, only the hash of the secret is stored
+	secret, client, err := s.oauth.RegisterClient(ctx, oauth.RegisterClientParams{
+		Owner:        authPayload.Username,
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       scopes,
+		GrantTypes:   req.GrantTypes,
+		Confidential: req.Confidential,
+	})
+	if err != nil {
+		if errors.Is(err, oauth.ErrInvalidClientMetadata) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, registerOAuthClientResponse{
+		ClientSecret: secret,
+		Client:       newOAuthClientResponse(client),
+	})
+}
+
+// listOAuthClients returns the clients of the authenticated user which are not revoked
+func (s *Server) listOAuthClients(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	clients, err := s.oauth.ListClients(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]oauthClientResponse, 0, len(clients))
+	for _, client := range clients {
+		response = append(response, newOAuthClientResponse(client))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type revokeOAuthClientRequest struct {
+	ID string `uri:"id" binding:"required,max=64"`
+}
+
+// revokeOAuthClient revokes a client of the authenticated user along with the tokens it holds,
+// admins can revoke any client
+func (s *Server) revokeOAuthClient(ctx *gin.Context) {
+	// 1. check the valid request
+	var req revokeOAuthClientRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. revoke the client, a client of another user is reported as not found
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	owner := authPayload.Username
+	if authPayload.Role == util.AdminRole {
+		owner = ""
+	}
+
+	client, err := s.oauth.RevokeClient(ctx, req.ID, owner)
+	if err != nil {
+		if errors.Is(err, oauth.ErrClientNotFound) {
+			err := fmt.Errorf("no oauth client exists for id %s", req.ID)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newOAuthClientResponse(client))
+}
+
+type oauthAuthorizeRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type" binding:"required"`
+	ClientID            string `form:"client_id" json:"client_id" binding:"required,max=64"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri" binding:"max=512"`
+	Scope               string `form:"scope" json:"scope" binding:"max=512"`
+	State               string `form:"state" json:"state" binding:"max=512"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge" binding:"required"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method" binding:"required"`
+}
+
+func (req oauthAuthorizeRequest) authorizeRequest(authPayload *token.Payload) oauth.AuthorizeRequest {
+	return oauth.AuthorizeRequest{
+		Username:            authPayload.Username,
+		UserScopes:          authPayload.Scopes,
+		ResponseType:        req.ResponseType,
+		ClientID:            req.ClientID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               req.Scope,
+		State:               req.State,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+	}
+}
+
+type oauthAuthorizationResponse struct {
+	ClientID        string   `json:"client_id"`
+	ClientName      string   `json:"client_name"`
+	RedirectURI     string   `json:"redirect_uri"`
+	Scopes          []string `json:"scopes"`
+	ConsentRequired bool     `json:"consent_required"`
+}
+
+// getOAuthAuthorization validates an authorization request for the consent screen of the authenticated user.
+// The errors are shown to the user instead of redirecting to a client which may not be trusted.
+func (s *Server) getOAuthAuthorization(ctx *gin.Context) {
+	// 1. check the valid request
+	var req oauthAuthorizeRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		oauthErrorResponse(ctx, &oauth.Error{Code: oauth.ErrorInvalidRequest, Description: err.Error()})
+		return
+	}
+
+	// 2. check the client, the redirect uri and the scopes
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	authorization, err := s.oauth.CheckAuthorization(ctx, req.authorizeRequest(authPayload))
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, oauthAuthorizationResponse{
+		ClientID:        authorization.Client.ID,
+		ClientName:      authorization.Client.Name,
+		RedirectURI:     authorization.RedirectURI,
+		Scopes:          authorization.Scopes,
+		ConsentRequired: authorization.ConsentRequired,
+	})
+}
+
+type authorizeOAuthClientRequest struct {
+	oauthAuthorizeRequest
+	Approve bool `json:"approve"`
+}
+
+type authorizeOAuthClientResponse struct {
+	// RedirectURI sends the user agent back to the client with the code or the access_denied error
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// authorizeOAuthClient records the decision of the authenticated user on the consent screen
+func (s *Server) authorizeOAuthClient(ctx *gin.Context) {
+	// 1. check the valid request
+	var req authorizeOAuthClientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		oauthErrorResponse(ctx, &oauth.Error{Code: oauth.ErrorInvalidRequest, Description: err.Error()})
+		return
+	}
+
+	// 2. store the consent and create the authorization code
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	redirectURI, err := s.oauth.Authorize(ctx, req.authorizeRequest(authPayload), req.Approve)
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, authorizeOAuthClientResponse{RedirectURI: redirectURI})
+}
+
+// authenticateOAuthClient returns the client of the HTTP basic credentials or of the client_id and client_secret
+// form parameters
+func (s *Server) authenticateOAuthClient(ctx *gin.Context) (db.OauthClient, error) {
+	clientID, secret, ok := ctx.Request.BasicAuth()
+	if !ok {
+		clientID = ctx.PostForm("client_id")
+		secret = ctx.PostForm("client_secret")
+	}
+	return s.oauth.AuthenticateClient(ctx, clientID, secret)
+}
+
+type oauthTokenRequest struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+}
+
+// oauthToken is the token endpoint of the third party apps, for the authorization code with PKCE,
+// refresh token and client credentials grants
+func (s *Server) oauthToken(ctx *gin.Context) {
+	// 1. check the valid request
+	var req oauthTokenRequest
+	if err := ctx.ShouldBindWith(&req, binding.FormPost); err != nil {
+		oauthErrorResponse(ctx, &oauth.Error{Code: oauth.ErrorInvalidRequest, Description: err.Error()})
+		return
+	}
+
+	// 2. authenticate the client
+	client, err := s.authenticateOAuthClient(ctx)
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	// 3. issue the tokens of the grant
+	response, err := s.oauth.Token(ctx, client, oauth.TokenRequest{
+		GrantType:    req.GrantType,
+		Code:         req.Code,
+		RedirectURI:  req.RedirectURI,
+		CodeVerifier: req.CodeVerifier,
+		RefreshToken: req.RefreshToken,
+		Scope:        req.Scope,
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIP:     clientIP(ctx),
+	})
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, response)
+}
+
+type oauthTokenOperationRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// introspectOAuthToken tells a confidential client whether a token issued to it is active
+func (s *Server) introspectOAuthToken(ctx *gin.Context) {
+	// 1. check the valid request
+	var req oauthTokenOperationRequest
+	if err := ctx.ShouldBindWith(&req, binding.FormPost); err != nil {
+		oauthErrorResponse(ctx, &oauth.Error{Code: oauth.ErrorInvalidRequest, Description: err.Error()})
+		return
+	}
+
+	// 2. authenticate the client
+	client, err := s.authenticateOAuthClient(ctx)
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	// 3. introspect the token
+	introspection, err := s.oauth.Introspect(ctx, client, req.Token)
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, introspection)
+}
+
+// revokeOAuthToken revokes a token issued to the client, unknown tokens are ignored as RFC 7009 requires
+func (s *Server) revokeOAuthToken(ctx *gin.Context) {
+	// 1. check the valid request
+	var req oauthTokenOperationRequest
+	if err := ctx.ShouldBindWith(&req, binding.FormPost); err != nil {
+		oauthErrorResponse(ctx, &oauth.Error{Code: oauth.ErrorInvalidRequest, Description: err.Error()})
+		return
+	}
+
+	// 2. authenticate the client
+	client, err := s.authenticateOAuthClient(ctx)
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	// 3. revoke the token
+	err = s.oauth.Revoke(ctx, client, req.Token)
+	if err != nil {
+		oauthErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
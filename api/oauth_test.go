@@ -0,0 +1,360 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/oauth"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// randomOAuthClient returns a confidential client and its secret
+func randomOAuthClient(owner string) (string, db.OauthClient) {
+	secret := oauth.ClientSecretPrefix + util.RandomString(32)
+	sum := sha256.Sum256([]byte(secret))
+
+	return secret, db.OauthClient{
+		ID:           util.RandomString(16),
+		Owner:        owner,
+		Name:         "partner app",
+		SecretHash:   sql.NullString{String: hex.EncodeToString(sum[:]), Valid: true},
+		RedirectUris: []string{"https://partner.example.com/callback"},
+		Scopes:       []string{token.ScopeAccountsRead},
+		GrantTypes:   []string{oauth.GrantAuthorizationCode, oauth.GrantRefreshToken, oauth.GrantClientCredentials},
+		CreatedAt:    time.Now(),
+	}
+}
+
+func TestRegisterOAuthClientAPI(t *testing.T) {
+	username := util.RandomOwner()
+
+	testcases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":          "partner app",
+				"redirect_uris": []string{"https://partner.example.com/callback"},
+				"scopes":        []string{token.ScopeAccountsRead},
+				"confidential":  true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateOAuthClientParams) (db.OauthClient, error) {
+						require.Equal(t, username, arg.Owner)
+						require.True(t, arg.SecretHash.Valid)
+						return db.OauthClient{ID: arg.ID, Owner: arg.Owner, Name: arg.Name, SecretHash: arg.SecretHash,
+							RedirectUris: arg.RedirectUris, Scopes: arg.Scopes, GrantTypes: arg.GrantTypes}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response registerOAuthClientResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.True(t, strings.HasPrefix(response.ClientSecret, oauth.ClientSecretPrefix))
+				require.True(t, response.Client.Confidential)
+				require.Equal(t, username, response.Client.Owner)
+			},
+		},
+		{
+			name: "ScopeNotGranted",
+			body: gin.H{
+				"name":          "partner app",
+				"redirect_uris": []string{"https://partner.example.com/callback"},
+				"scopes":        []string{token.ScopeTransfersWrite},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addScopedAuthorization(t, request, tokenMaker, username, token.ScopeSecurityWrite, token.ScopeAccountsRead)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidRedirectURI",
+			body: gin.H{
+				"name":          "partner app",
+				"redirect_uris": []string{"http://partner.example.com/callback"},
+				"scopes":        []string{token.ScopeAccountsRead},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{"name": "partner app", "scopes": []string{token.ScopeAccountsRead}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/clients", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthorizeOAuthClientAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	username := util.RandomOwner()
+	_, client := randomOAuthClient(util.RandomOwner())
+
+	store.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+	store.EXPECT().GetOAuthConsent(gomock.Any(), gomock.Any()).Times(1).Return(db.OauthConsent{}, sql.ErrNoRows)
+	store.EXPECT().UpsertOAuthConsent(gomock.Any(), gomock.Any()).Times(1).Return(db.OauthConsent{}, nil)
+	store.EXPECT().CreateOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ any, arg db.CreateOAuthAuthorizationCodeParams) (db.OauthAuthorizationCode, error) {
+			require.Equal(t, username, arg.Username)
+			return db.OauthAuthorizationCode{}, nil
+		})
+
+	data, err := json.Marshal(gin.H{
+		"response_type":         "code",
+		"client_id":             client.ID,
+		"state":                 "xyz",
+		"code_challenge":        util.RandomString(43),
+		"code_challenge_method": oauth.CodeChallengeS256,
+		"approve":               true,
+	})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/oauth/authorize", bytes.NewReader(data))
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response authorizeOAuthClientResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	redirect, err := url.Parse(response.RedirectURI)
+	require.NoError(t, err)
+	require.Equal(t, "partner.example.com", redirect.Host)
+	require.Equal(t, "xyz", redirect.Query().Get("state"))
+	require.NotEmpty(t, redirect.Query().Get("code"))
+}
+
+func TestOAuthTokenAPI(t *testing.T) {
+	owner := util.RandomOwner()
+	secret, client := randomOAuthClient(owner)
+
+	testcases := []struct {
+		name          string
+		form          url.Values
+		setupAuth     func(request *http.Request)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ClientCredentialsBasicAuth",
+			form: url.Values{"grant_type": {oauth.GrantClientCredentials}},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ID, secret)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				store.EXPECT().GetUser(gomock.Any(), owner).Times(1).Return(db.User{Username: owner, Role: util.DepositorRole}, nil)
+				store.EXPECT().CreateAccessToken(gomock.Any(), gomock.Any()).Times(1).Return(db.AccessToken{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+				var response oauth.TokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, "Bearer", response.TokenType)
+				require.Equal(t, token.ScopeAccountsRead, response.Scope)
+				require.Empty(t, response.RefreshToken)
+			},
+		},
+		{
+			name: "ClientCredentialsInForm",
+			form: url.Values{
+				"grant_type":    {oauth.GrantClientCredentials},
+				"client_id":     {client.ID},
+				"client_secret": {secret},
+			},
+			setupAuth: func(request *http.Request) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				store.EXPECT().GetUser(gomock.Any(), owner).Times(1).Return(db.User{Username: owner, Role: util.DepositorRole}, nil)
+				store.EXPECT().CreateAccessToken(gomock.Any(), gomock.Any()).Times(1).Return(db.AccessToken{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongSecret",
+			form: url.Values{"grant_type": {oauth.GrantClientCredentials}},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ID, "wrong")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("WWW-Authenticate"))
+
+				var response oauth.Error
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, oauth.ErrorInvalidClient, response.Code)
+			},
+		},
+		{
+			name: "UnsupportedGrantType",
+			form: url.Values{"grant_type": {"password"}},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ID, secret)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var response oauth.Error
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, oauth.ErrorUnsupportedGrantType, response.Code)
+			},
+		},
+		{
+			name: "MissingGrantType",
+			form: url.Values{},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ID, secret)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOAuthClient(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var response oauth.Error
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, oauth.ErrorInvalidRequest, response.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(tc.form.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			tc.setupAuth(request)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRevokeOAuthTokenAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	secret, client := randomOAuthClient(util.RandomOwner())
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(util.RandomOwner(), util.DepositorRole, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	// the token was issued to the client with the client credentials grant
+	store.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+	store.EXPECT().GetSessionByAccessToken(gomock.Any(), uuid.NullUUID{UUID: accessPayload.ID, Valid: true}).Times(1).Return(db.Session{}, sql.ErrNoRows)
+	store.EXPECT().GetAccessToken(gomock.Any(), accessPayload.ID).Times(1).Return(db.AccessToken{
+		ID:        accessPayload.ID,
+		Username:  accessPayload.Username,
+		ClientID:  sql.NullString{String: client.ID, Valid: true},
+		ExpiresAt: accessPayload.ExpiredAT,
+	}, nil)
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+	form := url.Values{"token": {accessToken}}
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/oauth/revoke", strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(client.ID, secret)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.True(t, server.revocations.IsRevoked(accessPayload.ID))
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/fx"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultQuoteLockDuration is used when the quote lock duration is not configured
+const defaultQuoteLockDuration = 30 * time.Second
+
+type createTransferQuoteRequest struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64 `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64 `json:"amount" binding:"required,gt=0"`
+}
+
+// createTransferQuote locks the current exchange rate b/w two accounts for the quote lock duration,
+// so the user sees exactly the amount the to account receives.
+func (s *Server) createTransferQuote(ctx *gin.Context) {
+	// 1. check the valid request
+	var req createTransferQuoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. the from account must belong to the authenticated user
+	fromAccount, valid := s.getAccount(ctx, req.FromAccountID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := s.getAccount(ctx, req.ToAccountID)
+	if !valid {
+		return
+	}
+
+	// 3. get the current rate b/w the account currencies
+	rate, err := s.rateProvider.GetRate(ctx, fromAccount.Currency, toAccount.Currency)
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// 4. convert the amount b/w the minor units of the currencies, a quote which would credit nothing is rejected
+	fromCurrency, fromOK := s.currencies.Lookup(fromAccount.Currency)
+	toCurrency, toOK := s.currencies.Lookup(toAccount.Currency)
+	if !fromOK || !toOK {
+		err := fmt.Errorf("unsupported currency: %s/%s", fromAccount.Currency, toAccount.Currency)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	toAmount, err := rate.Convert(req.Amount, fromCurrency.Exponent, toCurrency.Exponent)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 5. store the quote with the locked rate and the converted amount
+	quote, err := s.store.CreateTransferQuote(ctx, db.CreateTransferQuoteParams{
+		ID:            uuid.New(),
+		Owner:         authPayload.Username,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		FromCurrency:  fromAccount.Currency,
+		ToCurrency:    toAccount.Currency,
+		Rate:          rate.String(),
+		FromAmount:    req.Amount,
+		ToAmount:      toAmount,
+		ExpiresAt:     time.Now().Add(s.quoteLockDuration()),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newTransferQuoteResponse(quote))
+}
+
+type transferQuoteResponse struct {
+	db.TransferQuote
+	FormattedFromAmount string `json:"formatted_from_amount"`
+	FormattedToAmount   string `json:"formatted_to_amount"`
+}
+
+func (s *Server) newTransferQuoteResponse(quote db.TransferQuote) transferQuoteResponse {
+	return transferQuoteResponse{
+		TransferQuote:       quote,
+		FormattedFromAmount: s.currencies.Format(quote.FromAmount, quote.FromCurrency),
+		FormattedToAmount:   s.currencies.Format(quote.ToAmount, quote.ToCurrency),
+	}
+}
+
+// quoteLockDuration returns how long a quote keeps its rate
+func (s *Server) quoteLockDuration() time.Duration {
+	if s.config.QuoteLockDuration > 0 {
+		return s.config.QuoteLockDuration
+	}
+	return defaultQuoteLockDuration
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTransferQuoteAPI(t *testing.T) {
+	amount := int64(100)
+	user1, _ := createRandomUser(t)
+	user2, _ := createRandomUser(t)
+	account1 := createRandomAccount(user1.Username)
+	account2 := createRandomAccount(user2.Username)
+
+	account1.Currency = util.USD
+	account2.Currency = util.INR
+
+	exchangeRate := db.ExchangeRate{
+		BaseCurrency:  util.USD,
+		QuoteCurrency: util.INR,
+		Rate:          "83.5",
+		UpdatedAt:     time.Now(),
+	}
+
+	testcases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account1.ID).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account2.ID).Times(1).Return(account2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), db.GetExchangeRateParams{
+					BaseCurrency:  util.USD,
+					QuoteCurrency: util.INR,
+				}).Times(1).Return(exchangeRate, nil)
+				store.EXPECT().CreateTransferQuote(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateTransferQuoteParams) (db.TransferQuote, error) {
+						require.Equal(t, user1.Username, arg.Owner)
+						require.Equal(t, amount, arg.FromAmount)
+						require.Equal(t, int64(8350), arg.ToAmount)
+						require.Equal(t, "83.5000000000", arg.Rate)
+						require.WithinDuration(t, time.Now().Add(defaultQuoteLockDuration), arg.ExpiresAt, time.Second)
+						return db.TransferQuote{
+							ID:            arg.ID,
+							Owner:         arg.Owner,
+							FromAccountID: arg.FromAccountID,
+							ToAccountID:   arg.ToAccountID,
+							FromCurrency:  arg.FromCurrency,
+							ToCurrency:    arg.ToCurrency,
+							Rate:          arg.Rate,
+							FromAmount:    arg.FromAmount,
+							ToAmount:      arg.ToAmount,
+							ExpiresAt:     arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchQuote(t, recorder.Body, int64(8350))
+			},
+		},
+		{
+			name: "ConvertedAmountTooSmall",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				tinyRate := exchangeRate
+				tinyRate.Rate = "0.001"
+				store.EXPECT().GetAccount(gomock.Any(), account1.ID).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account2.ID).Times(1).Return(account2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(1).Return(tinyRate, nil)
+				store.EXPECT().CreateTransferQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account1.ID).Times(1).Return(account1, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateTransferQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RateNotFound",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account1.ID).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account2.ID).Times(1).Return(account2, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(2).Return(db.ExchangeRate{}, sql.ErrNoRows)
+				store.EXPECT().CreateTransferQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          0,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateTransferQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/transfers/quotes"
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCreateTransferQuoteMinorUnitsAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	// the yen has no minor unit, 1 USD at 150 JPY credits 150 yen and not 15000
+	store.EXPECT().ListEnabledCurrencies(gomock.Any()).Times(1).Return([]db.Currency{
+		{Code: util.USD, NumericCode: 840, Exponent: 2, Symbol: "$", Enabled: true},
+		{Code: "JPY", NumericCode: 392, Exponent: 0, Symbol: "¥", Enabled: true},
+	}, nil)
+	require.NoError(t, server.currencies.Refresh(context.Background()))
+
+	user, _ := createRandomUser(t)
+	account1 := createRandomAccount(user.Username)
+	account2 := createRandomAccount(user.Username)
+	account1.Currency = util.USD
+	account2.Currency = "JPY"
+
+	store.EXPECT().GetAccount(gomock.Any(), account1.ID).Times(1).Return(account1, nil)
+	store.EXPECT().GetAccount(gomock.Any(), account2.ID).Times(1).Return(account2, nil)
+	store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(1).Return(db.ExchangeRate{
+		BaseCurrency:  util.USD,
+		QuoteCurrency: "JPY",
+		Rate:          "150",
+		UpdatedAt:     time.Now(),
+	}, nil)
+	store.EXPECT().CreateTransferQuote(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ any, arg db.CreateTransferQuoteParams) (db.TransferQuote, error) {
+			require.Equal(t, int64(100), arg.FromAmount)
+			require.Equal(t, int64(150), arg.ToAmount)
+			return db.TransferQuote{ID: arg.ID, FromAmount: arg.FromAmount, ToAmount: arg.ToAmount}, nil
+		})
+
+	data, err := json.Marshal(gin.H{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 100})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/transfers/quotes", bytes.NewBuffer(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyMatchQuote(t, recorder.Body, 150)
+}
+
+func requireBodyMatchQuote(t *testing.T, body *bytes.Buffer, toAmount int64) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var quote db.TransferQuote
+	err = json.Unmarshal(data, &quote)
+	require.NoError(t, err)
+	require.Equal(t, toAmount, quote.ToAmount)
+}
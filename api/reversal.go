@@ -0,0 +1,98 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/gin-gonic/gin"
+)
+
+type reverseTransferRequest struct {
+	// Amount is refunded in the currency of the from account of the transfer, it defaults to what is left to refund
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+type reverseTransferResponse struct {
+	Original transferResponse `json:"original"`
+	transferTxResponse
+}
+
+// reverseTransfer refunds a transfer with a compensating transfer from its to account back to its from account.
+// Only the owner of the to account or an admin can refund, the body is optional and the whole transfer is refunded without it.
+func (s *Server) reverseTransfer(ctx *gin.Context) {
+	// 1. check the valid request
+	var uri getTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. the transfer is refunded by the account it paid
+	transfer, err := s.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := s.getAccount(ctx, transfer.ToAccountID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if toAccount.Owner != authPayload.Username && authPayload.Role != util.AdminRole {
+		err := errors.New("to account of the transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// 3. post the refund
+	result, err := s.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: uri.ID,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		case errors.Is(err, db.ErrTransferReversed):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrTransferNotReversible),
+			errors.Is(err, db.ErrRefundExceeded),
+			errors.Is(err, db.ErrRefundTooSmall):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	// 4. the compensating transfer goes the other way, its from account is the to account of the original
+	ctx.JSON(http.StatusOK, reverseTransferResponse{
+		Original: s.newTransferResponse(result.Original, result.ToAccount.Currency, result.FromAccount.Currency),
+		transferTxResponse: s.newTransferTxResponse(db.TransferTxResult{
+			Transfer:    result.Transfer,
+			FromAccount: result.FromAccount,
+			ToAccount:   result.ToAccount,
+			FromEntry:   result.FromEntry,
+			ToEntry:     result.ToEntry,
+		}),
+	})
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/schedule"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/gin-gonic/gin"
+)
+
+type scheduledTransferResponse struct {
+	db.ScheduledTransfer
+	FormattedAmount string `json:"formatted_amount"`
+	// Rule is the recurrence in the RRULE syntax
+	Rule string `json:"rule"`
+}
+
+func (s *Server) newScheduledTransferResponse(scheduled db.ScheduledTransfer) scheduledTransferResponse {
+	return scheduledTransferResponse{
+		ScheduledTransfer: scheduled,
+		FormattedAmount:   s.currencies.Format(scheduled.Amount, scheduled.Currency),
+		Rule:              schedule.RuleOf(scheduled).String(),
+	}
+}
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	Frequency     string `json:"frequency" binding:"required,oneof=once daily weekly monthly"`
+	// Interval is the number of days, weeks or months b/w two transfers, it defaults to 1
+	Interval int32 `json:"interval" binding:"omitempty,min=1"`
+	// DayOfMonth is the day a monthly transfer runs on, it defaults to the day of the start date
+	DayOfMonth int32     `json:"day_of_month" binding:"omitempty,min=1,max=31"`
+	StartAt    time.Time `json:"start_at" binding:"required"`
+	// EndAt and MaxOccurrences are optional and end the recurrence, whichever comes first
+	EndAt          time.Time `json:"end_at"`
+	MaxOccurrences int32     `json:"max_occurrences" binding:"omitempty,min=1"`
+}
+
+// createScheduledTransfer schedules a transfer from an account of the authenticated user, once or on a recurrence
+func (s *Server) createScheduledTransfer(ctx *gin.Context) {
+	// 1. check the valid request
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. the from account must belong to the user and both accounts must use the currency of the transfer
+	fromAccount, valid := s.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if _, valid = s.validAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	// 3. validate the recurrence and create the schedule
+	arg, err := schedule.NewScheduledTransfer(schedule.CreateParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		Rule: schedule.Rule{
+			Frequency:  req.Frequency,
+			Interval:   req.Interval,
+			DayOfMonth: req.DayOfMonth,
+			Until:      req.EndAt,
+			Count:      req.MaxOccurrences,
+		},
+		Start: req.StartAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, err := s.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newScheduledTransferResponse(scheduled))
+}
+
+type scheduledTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getScheduledTransfer returns the schedule if the authenticated user owns it, admins can read every schedule.
+// Otherwise it writes the error response.
+func (s *Server) getScheduledTransfer(ctx *gin.Context, id int64, allowAdmin bool) (db.ScheduledTransfer, bool) {
+	scheduled, err := s.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := fmt.Errorf("no scheduled transfer exists for id %d", id)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return scheduled, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return scheduled, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if scheduled.Owner != authPayload.Username && !(allowAdmin && authPayload.Role == util.AdminRole) {
+		err := errors.New("scheduled transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return scheduled, false
+	}
+	return scheduled, true
+}
+
+// getScheduledTransferByID returns a schedule of the authenticated user
+func (s *Server) getScheduledTransferByID(ctx *gin.Context) {
+	var req scheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, valid := s.getScheduledTransfer(ctx, req.ID, true)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newScheduledTransferResponse(scheduled))
+}
+
+type listScheduledTransfersRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listScheduledTransfers returns the schedules of the authenticated user, including the completed and cancelled ones
+func (s *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	schedules, err := s.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]scheduledTransferResponse, 0, len(schedules))
+	for _, scheduled := range schedules {
+		response = append(response, s.newScheduledTransferResponse(scheduled))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type updateScheduledTransferRequest struct {
+	Amount         int64     `json:"amount" binding:"omitempty,gt=0"`
+	EndAt          time.Time `json:"end_at"`
+	MaxOccurrences int32     `json:"max_occurrences" binding:"omitempty,min=1"`
+	// Status pauses or resumes the schedule
+	Status string `json:"status" binding:"omitempty,oneof=active paused"`
+}
+
+// updateScheduledTransfer changes a schedule of the authenticated user, the omitted fields are left unchanged
+func (s *Server) updateScheduledTransfer(ctx *gin.Context) {
+	// 1. check the valid request
+	var uri scheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. only the owner can change the schedule
+	scheduled, valid := s.getScheduledTransfer(ctx, uri.ID, false)
+	if !valid {
+		return
+	}
+
+	// 3. validate the changes and update the schedule
+	arg, err := schedule.UpdateScheduledTransfer(scheduled, schedule.UpdateParams{
+		Amount:         req.Amount,
+		EndAt:          req.EndAt,
+		MaxOccurrences: req.MaxOccurrences,
+		Status:         req.Status,
+	})
+	if err != nil {
+		if errors.Is(err, schedule.ErrNotEditable) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, err = s.store.UpdateScheduledTransfer(ctx, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(schedule.ErrNotEditable))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newScheduledTransferResponse(scheduled))
+}
+
+// cancelScheduledTransfer stops a schedule of the authenticated user, its runs are kept
+func (s *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var req scheduledTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := s.getScheduledTransfer(ctx, req.ID, false); !valid {
+		return
+	}
+
+	scheduled, err := s.store.CancelScheduledTransfer(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(schedule.ErrNotEditable))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.newScheduledTransferResponse(scheduled))
+}
+
+// listScheduledTransferRuns returns the runs of a schedule with their outcome, the latest first
+func (s *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var uri scheduledTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := s.getScheduledTransfer(ctx, uri.ID, true); !valid {
+		return
+	}
+
+	runs, err := s.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: uri.ID,
+		Limit:               req.PageSize,
+		Offset:              (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
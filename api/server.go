@@ -2,9 +2,19 @@ package api
 
 import (
 	"fmt"
+	"net/netip"
 
+	"github.com/akshay237/backend-with-go/apikey"
+	"github.com/akshay237/backend-with-go/currency"
 	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/fx"
+	"github.com/akshay237/backend-with-go/lockout"
+	"github.com/akshay237/backend-with-go/mail"
+	"github.com/akshay237/backend-with-go/mfa"
+	"github.com/akshay237/backend-with-go/oauth"
+	"github.com/akshay237/backend-with-go/revocation"
 	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/usertoken"
 	"github.com/akshay237/backend-with-go/util"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -13,27 +23,76 @@ import (
 
 // Server Serves HTTP requests for our banking service.
 type Server struct {
-	config     util.Config
-	store      db.Store
-	tokenMaker token.Maker
-	Router     *gin.Engine
+	config       util.Config
+	store        db.Store
+	tokenMaker   token.Maker
+	rateProvider fx.RateProvider
+	currencies   *currency.Registry
+	revocations  *revocation.List
+	mfa          *mfa.Authenticator
+	loginGuard   *lockout.Guard
+	userTokens   *usertoken.Service
+	passwords    *util.PasswordHasher
+	policy       *util.PasswordPolicy
+	apiKeys      *apikey.Service
+	oauth        *oauth.Service
+	Router       *gin.Engine
+	// trustedProxies may forward the address of the client
+	trustedProxies []netip.Prefix
 }
 
 // New Server creates a new HTTP server and setup routing.
-func NewServerHandler(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewPasteoMaker(config.TokenSymmetricKey)
+// The currency registry and the token revocation list are shared with the caller, which keeps them refreshed.
+func NewServerHandler(config util.Config, store db.Store, currencies *currency.Registry, revocations *revocation.List) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %v", err)
 	}
+	rateProvider, err := fx.NewRateProvider(config, store)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %v", err)
+	}
+	authenticator, err := mfa.NewAuthenticator(store, config.TOTPEncryptionKey, config.MFAChallengeDuration)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create MFA authenticator: %v", err)
+	}
+	mailer, err := mail.NewMailer(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mailer: %v", err)
+	}
+	passwords, err := util.NewPasswordHasher(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %v", err)
+	}
+	policy, err := util.NewPasswordPolicy(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password policy: %v", err)
+	}
+	trustedProxies, err := util.ParseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse trusted proxies: %v", err)
+	}
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:       config,
+		store:        store,
+		tokenMaker:   tokenMaker,
+		rateProvider: rateProvider,
+		currencies:   currencies,
+		revocations:  revocations,
+		mfa:          authenticator,
+		loginGuard:   lockout.NewGuard(store, config),
+		userTokens:   usertoken.NewService(store, mailer, config),
+		passwords:    passwords,
+		policy:       policy,
+		apiKeys:      apikey.NewService(store),
+		oauth:        oauth.NewService(store, tokenMaker, revocations, config),
+
+		trustedProxies: trustedProxies,
 	}
 
 	// add the validator middleware
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("currency", newCurrencyValidator(currencies))
 	}
 
 	server.setupRouter()
@@ -44,23 +103,92 @@ func (server *Server) setupRouter() {
 	// routes
 	router := gin.Default()
 
+	// the client address is read by clientIP, gin doesn't trust any forwarded address
+	router.ForwardedByClientIP = false
+	router.Use(clientIPMiddleware(server.trustedProxies))
+
 	// user apis
 	router.POST("/users", server.CreateUser)
 	router.POST("/user/login", server.loginUser)
+	router.POST("/user/login/mfa", server.verifyLoginMFA)
 	router.POST("/token/renew_access", server.renewAccessToken)
+	router.POST("/user/logout", server.logoutUser)
+	router.POST("/user/email/verify", server.verifyEmail)
+	router.POST("/user/password/reset_request", server.requestPasswordReset)
+	router.POST("/user/password/reset", server.resetPassword)
+
+	// currency apis
+	router.GET("/currencies", server.listCurrencies)
+
+	// oauth endpoints of the third party apps, which authenticate with the client credentials
+	router.POST("/oauth/token", server.oauthToken)
+	router.POST("/oauth/introspect", server.introspectOAuthToken)
+	router.POST("/oauth/revoke", server.revokeOAuthToken)
+
+	// add the middlewares to all other routes, each route requires the scope of what it does
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.revocations, server.apiKeys))
+	adminRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.revocations, server.apiKeys), requireRole(util.AdminRole))
+
+	// admin only apis
+	adminRoutes.GET("/users/:username", requireScope(token.ScopeUsersRead), server.GetUser)
+	adminRoutes.DELETE("/users/:username/lockout", requireScope(token.ScopeUsersWrite), server.unlockUser)
+
+	// delegated token api, the new token can only have fewer rights than the caller
+	authRoutes.POST("/token/delegate", server.delegateAccessToken)
 
-	// add the middlewares to all other routes
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	// email verification api
+	authRoutes.POST("/user/email/verification", requireScope(token.ScopeSecurityWrite), server.requestEmailVerification)
+
+	// two factor authentication apis
+	authRoutes.POST("/user/mfa/totp", requireScope(token.ScopeSecurityWrite), server.enrollTOTP)
+	authRoutes.POST("/user/mfa/totp/confirm", requireScope(token.ScopeSecurityWrite), server.confirmTOTP)
+	authRoutes.POST("/user/mfa/totp/disable", requireScope(token.ScopeSecurityWrite), server.disableTOTP)
+
+	// session apis
+	authRoutes.GET("/sessions", requireScope(token.ScopeSecurityRead), server.listSessions)
+	authRoutes.DELETE("/sessions", requireScope(token.ScopeSecurityWrite), server.revokeAllSessions)
+	authRoutes.DELETE("/sessions/:id", requireScope(token.ScopeSecurityWrite), server.revokeSession)
+
+	// api key apis
+	authRoutes.POST("/api_keys", requireScope(token.ScopeSecurityWrite), server.createAPIKey)
+	authRoutes.GET("/api_keys", requireScope(token.ScopeSecurityRead), server.listAPIKeys)
+	authRoutes.DELETE("/api_keys/:id", requireScope(token.ScopeSecurityWrite), server.revokeAPIKey)
+
+	// oauth client and consent apis
+	authRoutes.POST("/oauth/clients", requireScope(token.ScopeSecurityWrite), server.registerOAuthClient)
+	authRoutes.GET("/oauth/clients", requireScope(token.ScopeSecurityRead), server.listOAuthClients)
+	authRoutes.DELETE("/oauth/clients/:id", requireScope(token.ScopeSecurityWrite), server.revokeOAuthClient)
+	authRoutes.GET("/oauth/authorize", requireScope(token.ScopeSecurityRead), server.getOAuthAuthorization)
+	authRoutes.POST("/oauth/authorize", requireScope(token.ScopeSecurityWrite), server.authorizeOAuthClient)
 
 	// account apis
-	authRoutes.POST("/accounts", server.CreateAccount)
-	authRoutes.GET("/accounts/:id", server.GetAccount)
-	authRoutes.GET("/accounts", server.ListAccounts)
-	authRoutes.PUT("/accounts", server.UpdateAccount)
-	authRoutes.DELETE("/accounts/:id", server.DeleteAccount)
+	authRoutes.POST("/accounts", requireScope(token.ScopeAccountsWrite), server.CreateAccount)
+	authRoutes.GET("/accounts/:id", requireScope(token.ScopeAccountsRead), server.GetAccount)
+	authRoutes.GET("/accounts", requireScope(token.ScopeAccountsRead), server.ListAccounts)
+	authRoutes.GET("/accounts/:id/statement", requireScope(token.ScopeAccountsRead), server.GetAccountStatement)
+	adminRoutes.PUT("/accounts", requireScope(token.ScopeAccountsWrite), server.UpdateAccount)
+	adminRoutes.PUT("/accounts/:id/overdraft_limit", requireScope(token.ScopeAccountsWrite), server.UpdateOverdraftLimit)
+	adminRoutes.DELETE("/accounts/:id", requireScope(token.ScopeAccountsWrite), server.DeleteAccount)
 
 	// transfer api
-	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.POST("/transfers", requireScope(token.ScopeTransfersWrite), server.createTransfer)
+	authRoutes.POST("/transfers/quotes", requireScope(token.ScopeTransfersRead), server.createTransferQuote)
+	authRoutes.GET("/transfers/:id", requireScope(token.ScopeTransfersRead), server.getTransfer)
+	authRoutes.POST("/transfers/:id/reversals", requireScope(token.ScopeTransfersWrite), server.reverseTransfer)
+
+	// hold apis, funds are reserved first and posted when the hold is captured
+	authRoutes.POST("/holds", requireScope(token.ScopeTransfersWrite), server.authorizeHold)
+	authRoutes.GET("/holds/:id", requireScope(token.ScopeTransfersRead), server.getHoldByID)
+	authRoutes.POST("/holds/:id/capture", requireScope(token.ScopeTransfersWrite), server.captureHold)
+	authRoutes.POST("/holds/:id/void", requireScope(token.ScopeTransfersWrite), server.voidHold)
+
+	// scheduled transfer apis, the scheduler runs them in the background
+	authRoutes.POST("/scheduled_transfers", requireScope(token.ScopeTransfersWrite), server.createScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers", requireScope(token.ScopeTransfersRead), server.listScheduledTransfers)
+	authRoutes.GET("/scheduled_transfers/:id", requireScope(token.ScopeTransfersRead), server.getScheduledTransferByID)
+	authRoutes.PATCH("/scheduled_transfers/:id", requireScope(token.ScopeTransfersWrite), server.updateScheduledTransfer)
+	authRoutes.DELETE("/scheduled_transfers/:id", requireScope(token.ScopeTransfersWrite), server.cancelScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers/:id/runs", requireScope(token.ScopeTransfersRead), server.listScheduledTransferRuns)
 
 	server.Router = router
 }
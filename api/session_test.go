@@ -0,0 +1,251 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func randomSession(username string) db.Session {
+	id := uuid.New()
+	return db.Session{
+		ID:                   id,
+		Username:             username,
+		RefreshToken:         util.RandomString(32),
+		UserAgent:            "curl/8.0",
+		ClientIp:             "10.0.0.1",
+		ExpiresAt:            time.Now().Add(time.Hour),
+		CreatedAt:            time.Now(),
+		FamilyID:             id,
+		AccessTokenID:        uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		AccessTokenExpiresAt: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+	}
+}
+
+func TestSessionAPI(t *testing.T) {
+	username := util.RandomOwner()
+	session := randomSession(username)
+
+	testcases := []struct {
+		name          string
+		method        string
+		url           string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ListSessions",
+			method: http.MethodGet,
+			url:    "/sessions",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListActiveSessions(gomock.Any(), gomock.Eq(username)).Times(1).Return([]db.Session{session}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), session.RefreshToken)
+
+				var sessions []sessionResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &sessions))
+				require.Len(t, sessions, 1)
+				require.Equal(t, session.ID, sessions[0].ID)
+				require.Equal(t, session.UserAgent, sessions[0].UserAgent)
+				require.Equal(t, session.ClientIp, sessions[0].ClientIp)
+			},
+		},
+		{
+			name:   "ListSessionsNoAuthorization",
+			method: http.MethodGet,
+			url:    "/sessions",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListActiveSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeSession",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/sessions/%s", session.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.BlockSessionParams{ID: session.ID, Username: username}
+				blocked := session
+				blocked.IsBlocked = true
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(arg)).Times(1).Return(blocked, nil)
+				store.EXPECT().RevokeToken(gomock.Any(), gomock.Eq(db.RevokeTokenParams{
+					ID:        session.AccessTokenID.UUID,
+					Username:  username,
+					ExpiresAt: session.AccessTokenExpiresAt.Time,
+				})).Times(1).Return(nil)
+				store.EXPECT().ListSessionAccessTokens(gomock.Any(), gomock.Eq([]uuid.UUID{session.ID})).Times(1).Return([]db.AccessToken{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeSessionOfAnotherUser",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/sessions/%s", session.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeSessionInvalidID",
+			method: http.MethodDelete,
+			url:    "/sessions/123",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeAllSessions",
+			method: http.MethodDelete,
+			url:    "/sessions",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				sessions := []db.Session{randomSession(username), randomSession(username), randomSession(username)}
+				store.EXPECT().BlockUserSessions(gomock.Any(), gomock.Eq(username)).Times(1).Return(sessions, nil)
+				store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(3).Return(nil)
+				store.EXPECT().ListSessionAccessTokens(gomock.Any(), gomock.Any()).Times(1).Return([]db.AccessToken{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"revoked_sessions": 3}`, recorder.Body.String())
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestLogoutUserAPI(t *testing.T) {
+	username := util.RandomOwner()
+
+	testcases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore, session db.Session)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).Times(1).Return([]db.Session{session}, nil)
+				store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().ListSessionAccessTokens(gomock.Any(), gomock.Eq([]uuid.UUID{session.ID})).Times(1).Return([]db.AccessToken{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "MismatchedToken",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.RefreshToken = util.RandomString(32)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+				store.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+
+			refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(username, util.DepositorRole, nil, token.TokenTypeRefresh, time.Hour)
+			require.NoError(t, err)
+
+			session := randomSession(username)
+			session.ID = refreshPayload.ID
+			session.FamilyID = refreshPayload.ID
+			session.RefreshToken = refreshToken
+			tc.buildStubs(store, session)
+
+			data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/user/logout", bytes.NewBuffer(data))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/akshay237/backend-with-go/currency"
+	"github.com/akshay237/backend-with-go/statement"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/gin-gonic/gin"
+)
+
+type getAccountStatementRequest struct {
+	From string `form:"from" binding:"required"`
+	To   string `form:"to" binding:"required"`
+	// Format defaults to json, the other formats are downloaded as a file
+	Format string `form:"format" binding:"omitempty,oneof=json csv ofx pdf"`
+}
+
+type statementLineResponse struct {
+	EntryID                 int64     `json:"entry_id"`
+	TransferID              *int64    `json:"transfer_id,omitempty"`
+	ReversalOf              *int64    `json:"reversal_of,omitempty"`
+	CounterpartyAccountID   *int64    `json:"counterparty_account_id,omitempty"`
+	CounterpartyOwner       string    `json:"counterparty_owner,omitempty"`
+	Amount                  int64     `json:"amount"`
+	RunningBalance          int64     `json:"running_balance"`
+	FormattedAmount         string    `json:"formatted_amount"`
+	FormattedRunningBalance string    `json:"formatted_running_balance"`
+	CreatedAt               time.Time `json:"created_at"`
+}
+
+type statementResponse struct {
+	Account                 accountResponse         `json:"account"`
+	From                    string                  `json:"from"`
+	To                      string                  `json:"to"`
+	OpeningBalance          int64                   `json:"opening_balance"`
+	ClosingBalance          int64                   `json:"closing_balance"`
+	FormattedOpeningBalance string                  `json:"formatted_opening_balance"`
+	FormattedClosingBalance string                  `json:"formatted_closing_balance"`
+	Lines                   []statementLineResponse `json:"lines"`
+}
+
+func (s *Server) newStatementResponse(st statement.Statement) statementResponse {
+	rsp := statementResponse{
+		Account:                 s.newAccountResponse(st.Account),
+		From:                    st.From.Format(statement.DateLayout),
+		To:                      st.To.Format(statement.DateLayout),
+		OpeningBalance:          st.OpeningBalance,
+		ClosingBalance:          st.ClosingBalance,
+		FormattedOpeningBalance: st.Format(st.OpeningBalance),
+		FormattedClosingBalance: st.Format(st.ClosingBalance),
+		Lines:                   make([]statementLineResponse, 0, len(st.Lines)),
+	}
+	for _, line := range st.Lines {
+		lineRsp := statementLineResponse{
+			EntryID:                 line.ID,
+			CounterpartyOwner:       line.CounterpartyOwner.String,
+			Amount:                  line.Amount,
+			RunningBalance:          line.RunningBalance,
+			FormattedAmount:         st.Format(line.Amount),
+			FormattedRunningBalance: st.Format(line.RunningBalance),
+			CreatedAt:               line.CreatedAt,
+		}
+		if line.TransferID.Valid {
+			lineRsp.TransferID = &line.TransferID.Int64
+		}
+		if line.ReversalOf.Valid {
+			lineRsp.ReversalOf = &line.ReversalOf.Int64
+		}
+		if line.CounterpartyAccountID.Valid {
+			lineRsp.CounterpartyAccountID = &line.CounterpartyAccountID.Int64
+		}
+		rsp.Lines = append(rsp.Lines, lineRsp)
+	}
+	return rsp
+}
+
+// GetAccountStatement returns the entries of an account over a period with the running balance after each of them.
+// The statement is checked like GetAccount, only the owner and admins can read it.
+func (s *Server) GetAccountStatement(ctx *gin.Context) {
+	// 1. check the valid request
+	var uri GetAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getAccountStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	from, to, err := statement.ParsePeriod(req.From, req.To)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. get the account and check it belongs to the user
+	account, valid := s.getAccount(ctx, uri.Id)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != account.Owner && authPayload.Role != util.AdminRole {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// 3. build the statement, a currency which is not enabled anymore is formatted without decimal places
+	cur, ok := s.currencies.Lookup(account.Currency)
+	if !ok {
+		cur = currency.Currency{Code: account.Currency}
+	}
+
+	st, err := statement.New(ctx, s.store, account, cur, from, to)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if req.Format == "" || req.Format == statement.FormatJSON {
+		ctx.JSON(http.StatusOK, s.newStatementResponse(st))
+		return
+	}
+
+	// 4. export the statement as a file
+	var document bytes.Buffer
+	if err := st.Export(&document, req.Format); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", st.Filename(req.Format)))
+	ctx.Data(http.StatusOK, statement.ContentType(req.Format), document.Bytes())
+}
@@ -4,10 +4,15 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/revocation"
+	"github.com/akshay237/backend-with-go/token"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type renewAccessTokenRequest struct {
@@ -15,10 +20,15 @@ type renewAccessTokenRequest struct {
 }
 
 type renewAccessTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	SessionID             uuid.UUID `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
+// renewAccessToken exchanges a refresh token for a new access token and a new refresh token.
+// The refresh token can be used only once, its session is replaced by a new one in the same token family.
 func (s *Server) renewAccessToken(ctx *gin.Context) {
 	// 1. check the valid request
 	var req renewAccessTokenRequest
@@ -29,6 +39,9 @@ func (s *Server) renewAccessToken(ctx *gin.Context) {
 
 	// 2. verify the token
 	refreshPaylaod, err := s.tokenMaker.VerifyToken(req.RefreshToken)
+	if err == nil {
+		err = refreshPaylaod.CheckType(token.TokenTypeRefresh)
+	}
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -45,21 +58,14 @@ func (s *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
-	// 3.1 check if the session is blocked
-	if session.IsBlocked {
-		err := fmt.Errorf("blocked session")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
-	}
-
-	// 3.2 check if session is for the same user
+	// 3.1 check if session is for the same user
 	if session.Username != refreshPaylaod.Username {
 		err := fmt.Errorf("incorrect session user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
-	// 3.3 check if session refresh and the requested refresh token is same or not
+	// 3.2 check if session refresh and the requested refresh token is same or not
 	if session.RefreshToken != req.RefreshToken {
 		err := fmt.Errorf("mismatched session token")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
@@ -73,17 +79,144 @@ func (s *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
-	// 4. create a access token for the user
-	accessToken, accessPayload, err := s.tokenMaker.CreateToken(refreshPaylaod.Username, s.config.AccessTokenDuration)
+	// 3.4 the sessions of the oauth clients are renewed at the token endpoint with the client credentials
+	if session.ClientID.Valid {
+		err := fmt.Errorf("session belongs to an oauth client, renew it at /oauth/token")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// 4. create a access token and the refresh token replacing the used one,
+	// the role is read again so a changed role is applied at the next renewal, the scopes of the session are kept
+	user, err := s.store.GetUser(ctx, session.Username)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	// 5. return newly created response token
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, refreshPaylaod.Scopes, token.TokenTypeAccess, s.config.AccessTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, refreshPaylaod.Scopes, token.TokenTypeRefresh, s.config.RefreshTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// 5. rotate the session, a blocked or already rotated session can't be renewed
+	result, err := s.store.RotateSessionTx(ctx, db.RotateSessionTxParams{
+		SessionID:            session.ID,
+		NewSessionID:         refreshPayload.ID,
+		NewRefreshToken:      refreshToken,
+		ExpiresAt:            refreshPayload.ExpiredAT,
+		UserAgent:            ctx.Request.UserAgent(),
+		ClientIp:             clientIP(ctx),
+		AccessTokenID:        accessPayload.ID,
+		AccessTokenExpiresAt: accessPayload.ExpiredAT,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrRefreshTokenReused):
+			log.Printf("security event: %s for user %s, session %s from %s", db.SecurityEventTokenReuse, session.Username, session.ID, clientIP(ctx))
+			if err := s.revocations.RevokeSessions(ctx, result.BlockedSessions...); err != nil {
+				log.Println("failed to revoke the access tokens of the blocked sessions:", err)
+			}
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrSessionBlocked):
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// 6. return newly created tokens
 	response := renewAccessTokenResponse{
+		SessionID:             result.NewSession.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAT,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAT,
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type delegateAccessTokenRequest struct {
+	Scopes []string `json:"scopes" binding:"required,min=1,max=16"`
+	// ExpiresIn is the lifetime of the token in seconds, it is capped by the lifetime of an access token
+	ExpiresIn int64 `json:"expires_in" binding:"omitempty,min=1"`
+}
+
+type delegateAccessTokenResponse struct {
+	AccessToken          string    `json:"access_token"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	Scopes               []string  `json:"scopes"`
+}
+
+// delegateAccessToken creates a short lived access token with fewer scopes than the caller, for example
+// for a read only dashboard. The token is bound to the session or api key of the caller, so it is revoked
+// along with it, and doesn't outlive the token it is created with.
+func (s *Server) delegateAccessToken(ctx *gin.Context) {
+	// 1. check the valid request
+	var req delegateAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. the scopes must be granted to the caller
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	scopes, err := token.NarrowScopes(authPayload.Scopes, req.Scopes)
+	if err != nil {
+		if errors.Is(err, token.ErrScopeNotGranted) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 3. create the token
+	duration := delegatedTokenDuration(authPayload, time.Duration(req.ExpiresIn)*time.Second, s.config.AccessTokenDuration)
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(authPayload.Username, authPayload.Role, scopes, token.TokenTypeAccess, duration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// 4. bind the token to the session or api key of the caller, so it is revoked along with it
+	err = s.revocations.Delegate(ctx, authPayload, accessPayload)
+	if err != nil {
+		if errors.Is(err, revocation.ErrUnboundToken) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, delegateAccessTokenResponse{
 		AccessToken:          accessToken,
 		AccessTokenExpiresAt: accessPayload.ExpiredAT,
+		Scopes:               accessPayload.Scopes,
+	})
+}
+
+// delegatedTokenDuration returns the lifetime of a delegated token. It is at most the access token duration
+// and ends with the token of the caller, an api key without expiry doesn't limit it.
+func delegatedTokenDuration(authPayload *token.Payload, requested time.Duration, maxDuration time.Duration) time.Duration {
+	duration := maxDuration
+	if requested > 0 && requested < duration {
+		duration = requested
 	}
-	ctx.JSON(http.StatusOK, response)
+	if !authPayload.ExpiredAT.IsZero() {
+		if remaining := time.Until(authPayload.ExpiredAT); remaining < duration {
+			duration = remaining
+		}
+	}
+	return duration
 }
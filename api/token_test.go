@@ -0,0 +1,416 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	username := util.RandomOwner()
+
+	testcases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore, session db.Session)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(db.User{Username: username, Role: util.DepositorRole}, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.RotateSessionTxParams) (db.RotateSessionTxResult, error) {
+						require.Equal(t, session.ID, arg.SessionID)
+						require.NotEqual(t, session.ID, arg.NewSessionID)
+						require.NotEqual(t, session.RefreshToken, arg.NewRefreshToken)
+						require.Equal(t, "203.0.113.7", arg.ClientIp)
+						newSession := session
+						newSession.ID = arg.NewSessionID
+						newSession.RefreshToken = arg.NewRefreshToken
+						return db.RotateSessionTxResult{OldSession: session, NewSession: newSession}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response renewAccessTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.NotEmpty(t, response.AccessToken)
+				require.NotEmpty(t, response.RefreshToken)
+				require.NotEqual(t, session.RefreshToken, response.RefreshToken)
+				require.NotEqual(t, session.ID, response.SessionID)
+			},
+		},
+		{
+			name: "RefreshTokenReused",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(db.User{Username: username, Role: util.DepositorRole}, nil)
+				result := db.RotateSessionTxResult{BlockedSessions: []db.Session{randomSession(username)}}
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(1).Return(result, db.ErrRefreshTokenReused)
+				store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().ListSessionAccessTokens(gomock.Any(), gomock.Any()).Times(1).Return([]db.AccessToken{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(db.User{Username: username, Role: util.DepositorRole}, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(1).Return(db.RotateSessionTxResult{}, db.ErrSessionBlocked)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredSession",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.ExpiresAt = time.Now().Add(-time.Minute)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "OAuthClientSession",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.ClientID = sql.NullString{String: util.RandomString(16), Valid: true}
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+
+			refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(username, util.DepositorRole, nil, token.TokenTypeRefresh, time.Hour)
+			require.NoError(t, err)
+
+			session := randomSession(username)
+			session.ID = refreshPayload.ID
+			session.FamilyID = refreshPayload.ID
+			session.RefreshToken = refreshToken
+			tc.buildStubs(store, session)
+
+			data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/token/renew_access", bytes.NewBuffer(data))
+			require.NoError(t, err)
+			request.RemoteAddr = "203.0.113.7:51234"
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, session)
+		})
+	}
+}
+
+func TestRenewAccessTokenKeepsScopesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	username := util.RandomOwner()
+	scopes := []string{token.ScopeAccountsRead}
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(username, util.DepositorRole, scopes, token.TokenTypeRefresh, time.Hour)
+	require.NoError(t, err)
+
+	session := randomSession(username)
+	session.ID = refreshPayload.ID
+	session.RefreshToken = refreshToken
+
+	store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(db.User{Username: username, Role: util.DepositorRole}, nil)
+	store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ any, arg db.RotateSessionTxParams) (db.RotateSessionTxResult, error) {
+			newSession := session
+			newSession.ID = arg.NewSessionID
+			return db.RotateSessionTxResult{OldSession: session, NewSession: newSession}, nil
+		})
+
+	data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/token/renew_access", bytes.NewBuffer(data))
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// the new access token keeps the scopes of the session
+	var response renewAccessTokenResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	payload, err := server.tokenMaker.VerifyToken(response.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, scopes, payload.Scopes)
+}
+
+func TestDelegateAccessTokenAPI(t *testing.T) {
+	// the caller is logged in with a session, the delegated token is bound to it
+	bindToSession := func(store *mockdb.MockStore, caller *token.Payload) {
+		session := randomSession(caller.Username)
+		store.EXPECT().GetAccessToken(gomock.Any(), gomock.Eq(caller.ID)).Times(1).Return(db.AccessToken{}, sql.ErrNoRows)
+		store.EXPECT().GetSessionByAccessToken(gomock.Any(), gomock.Eq(uuid.NullUUID{UUID: caller.ID, Valid: true})).Times(1).Return(session, nil)
+		store.EXPECT().CreateAccessToken(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ any, arg db.CreateAccessTokenParams) (db.AccessToken, error) {
+				require.Equal(t, caller.Username, arg.Username)
+				require.Equal(t, uuid.NullUUID{UUID: session.ID, Valid: true}, arg.SessionID)
+				require.False(t, arg.ApiKeyID.Valid)
+				return recordedAccessToken(arg), nil
+			})
+	}
+
+	testcases := []struct {
+		name          string
+		scopes        []string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore, caller *token.Payload)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker)
+	}{
+		{
+			name:       "OK",
+			body:       gin.H{"scopes": []string{token.ScopeAccountsRead}, "expires_in": 60},
+			buildStubs: bindToSession,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response delegateAccessTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, []string{token.ScopeAccountsRead}, response.Scopes)
+				require.WithinDuration(t, time.Now().Add(time.Minute), response.AccessTokenExpiresAt, time.Second)
+
+				payload, err := tokenMaker.VerifyToken(response.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, response.Scopes, payload.Scopes)
+			},
+		},
+		{
+			name:       "CappedByCallerToken",
+			scopes:     []string{token.ScopeAccountsRead, token.ScopeTransfersRead},
+			body:       gin.H{"scopes": []string{token.ScopeTransfersRead}, "expires_in": 3600},
+			buildStubs: bindToSession,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response delegateAccessTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.WithinDuration(t, time.Now().Add(time.Minute), response.AccessTokenExpiresAt, time.Second)
+			},
+		},
+		{
+			name: "FromDelegatedToken",
+			body: gin.H{"scopes": []string{token.ScopeAccountsRead}},
+			buildStubs: func(store *mockdb.MockStore, caller *token.Payload) {
+				parent := db.AccessToken{
+					ID:        caller.ID,
+					Username:  caller.Username,
+					ApiKeyID:  uuid.NullUUID{UUID: uuid.New(), Valid: true},
+					ExpiresAt: caller.ExpiredAT,
+				}
+				store.EXPECT().GetAccessToken(gomock.Any(), gomock.Eq(caller.ID)).Times(1).Return(parent, nil)
+				store.EXPECT().CreateAccessToken(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAccessTokenParams) (db.AccessToken, error) {
+						require.Equal(t, parent.ApiKeyID, arg.ApiKeyID)
+						require.False(t, arg.SessionID.Valid)
+						return recordedAccessToken(arg), nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NoSession",
+			body: gin.H{"scopes": []string{token.ScopeAccountsRead}},
+			buildStubs: func(store *mockdb.MockStore, caller *token.Payload) {
+				store.EXPECT().GetAccessToken(gomock.Any(), gomock.Any()).Times(1).Return(db.AccessToken{}, sql.ErrNoRows)
+				store.EXPECT().GetSessionByAccessToken(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(caller.ID)).Times(1).Return(db.ApiKey{}, sql.ErrNoRows)
+				store.EXPECT().CreateAccessToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "ScopeNotGranted",
+			scopes: []string{token.ScopeAccountsRead},
+			body:   gin.H{"scopes": []string{token.ScopeTransfersWrite}},
+			buildStubs: func(store *mockdb.MockStore, caller *token.Payload) {
+				store.EXPECT().CreateAccessToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownScope",
+			body: gin.H{"scopes": []string{"everything"}},
+			buildStubs: func(store *mockdb.MockStore, caller *token.Payload) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoScopes",
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore, caller *token.Payload) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/token/delegate", bytes.NewBuffer(data))
+			require.NoError(t, err)
+
+			// the token of the caller expires in a minute
+			accessToken, caller, err := server.tokenMaker.CreateToken(util.RandomOwner(), util.DepositorRole, tc.scopes, token.TokenTypeAccess, time.Minute)
+			require.NoError(t, err)
+			request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+accessToken)
+			tc.buildStubs(store, caller)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.tokenMaker)
+		})
+	}
+}
+
+// recordedAccessToken returns the row the store creates for the delegated token
+func recordedAccessToken(arg db.CreateAccessTokenParams) db.AccessToken {
+	return db.AccessToken{
+		ID:        arg.ID,
+		Username:  arg.Username,
+		SessionID: arg.SessionID,
+		ApiKeyID:  arg.ApiKeyID,
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestDelegatedTokenRevokedWithSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	// 1. the caller logged in with a session
+	accessToken, caller, err := server.tokenMaker.CreateToken(util.RandomOwner(), util.DepositorRole, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	session := randomSession(caller.Username)
+	session.AccessTokenID = uuid.NullUUID{UUID: caller.ID, Valid: true}
+	session.AccessTokenExpiresAt = sql.NullTime{Time: caller.ExpiredAT, Valid: true}
+
+	// 2. the delegated token is recorded under the session
+	var delegated db.AccessToken
+	store.EXPECT().GetAccessToken(gomock.Any(), gomock.Eq(caller.ID)).Times(1).Return(db.AccessToken{}, sql.ErrNoRows)
+	store.EXPECT().GetSessionByAccessToken(gomock.Any(), gomock.Eq(session.AccessTokenID)).Times(1).Return(session, nil)
+	store.EXPECT().CreateAccessToken(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ any, arg db.CreateAccessTokenParams) (db.AccessToken, error) {
+			delegated = recordedAccessToken(arg)
+			return delegated, nil
+		})
+
+	data, err := json.Marshal(gin.H{"scopes": []string{token.ScopeSecurityRead}})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/token/delegate", bytes.NewBuffer(data))
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+accessToken)
+
+	recorder := httptest.NewRecorder()
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response delegateAccessTokenResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Equal(t, session.ID, delegated.SessionID.UUID)
+
+	listSessions := func() *httptest.ResponseRecorder {
+		request, err := http.NewRequest(http.MethodGet, "/sessions", nil)
+		require.NoError(t, err)
+		request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+response.AccessToken)
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// 3. the delegated token works while the session is active
+	store.EXPECT().ListActiveSessions(gomock.Any(), gomock.Eq(caller.Username)).Times(1).Return([]db.Session{session}, nil)
+	require.Equal(t, http.StatusOK, listSessions().Code)
+
+	// 4. revoking the session revokes the delegated token along with the token of the session
+	blocked := session
+	blocked.IsBlocked = true
+	store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(db.BlockSessionParams{ID: session.ID, Username: caller.Username})).Times(1).Return(blocked, nil)
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Eq(db.RevokeTokenParams{
+		ID:        caller.ID,
+		Username:  caller.Username,
+		ExpiresAt: caller.ExpiredAT,
+	})).Times(1).Return(nil)
+	store.EXPECT().ListSessionAccessTokens(gomock.Any(), gomock.Eq([]uuid.UUID{session.ID})).Times(1).Return([]db.AccessToken{delegated}, nil)
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Eq(db.RevokeTokenParams{
+		ID:        delegated.ID,
+		Username:  delegated.Username,
+		ExpiresAt: delegated.ExpiresAt,
+	})).Times(1).Return(nil)
+
+	request, err = http.NewRequest(http.MethodDelete, fmt.Sprintf("/sessions/%s", session.ID), nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, caller.Username, util.DepositorRole, time.Minute)
+	recorder = httptest.NewRecorder()
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// 5. the delegated token is rejected
+	require.Equal(t, http.StatusUnauthorized, listSessions().Code)
+}
@@ -5,10 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	db "github.com/akshay237/backend-with-go/database/sqlc"
 	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
 )
 
 type transferRequest struct {
@@ -16,6 +25,8 @@ type transferRequest struct {
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
 	Amount        int64  `json:"amount" binding:"required,gt=0"`
 	Currency      string `json:"currency" binding:"required,currency"`
+	// QuoteID is required when the to account uses a different currency
+	QuoteID string `json:"quote_id" binding:"omitempty,uuid"`
 }
 
 func (s *Server) createTransfer(ctx *gin.Context) {
@@ -28,12 +39,25 @@ func (s *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
+	// 1.1 optional idempotency key sent by the clients which retry the request, the reserved keys are used by the servers
+	idempotencyKey := ctx.GetHeader(idempotencyKeyHeader)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		err := fmt.Errorf("%s header must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if strings.HasPrefix(idempotencyKey, db.ReservedIdempotencyKeyPrefix) {
+		err := fmt.Errorf("%s header must not start with %q", idempotencyKeyHeader, db.ReservedIdempotencyKeyPrefix)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	fromAccount, valid := s.validAccount(ctx, req.FromAccountID, req.Currency)
 	if !valid {
 		return
 	}
 
-	// 1.1 Add the authorization middleware to check from account  is his acc only
+	// 1.2 Add the authorization middleware to check from account  is his acc only
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	if fromAccount.Owner != authPayload.Username {
 		err := errors.New("from account doesn't belong to the authenticated user")
@@ -41,31 +65,91 @@ func (s *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	_, valid = s.validAccount(ctx, req.ToAccountID, req.Currency)
+	// 1.3 a cross currency transfer uses the quote which locked its exchange rate
+	var quoteID uuid.NullUUID
+	if req.QuoteID == "" {
+		_, valid = s.validAccount(ctx, req.ToAccountID, req.Currency)
+	} else {
+		quoteID = uuid.NullUUID{UUID: uuid.MustParse(req.QuoteID), Valid: true}
+		_, valid = s.getAccount(ctx, req.ToAccountID)
+	}
 	if !valid {
 		return
 	}
 
 	// 2. if the request is valid create the transfer req
 	createTransferReq := db.TransferTxParams{
-		FromAccountId: req.FromAccountID,
-		ToAccountId:   req.ToAccountID,
-		Amount:        req.Amount,
+		FromAccountId:  req.FromAccountID,
+		ToAccountId:    req.ToAccountID,
+		Amount:         req.Amount,
+		IdempotencyKey: idempotencyKey,
+		QuoteID:        quoteID,
 	}
 
 	// 3. calls the store account func of database to create an account
 	result, err := s.store.TransferTx(ctx, createTransferReq)
 	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrIdempotencyKeyConflict):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrQuoteNotUsable):
+			ctx.JSON(http.StatusGone, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrQuoteMismatch):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		var velocityErr *db.VelocityError
+		if errors.As(err, &velocityErr) {
+			ctx.JSON(http.StatusUnprocessableEntity, velocityErrorResponse(velocityErr))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
+	if result.Replayed {
+		ctx.Header(idempotentReplayedHeader, "true")
+	}
+
 	// 4. return the account details to the end user
-	ctx.JSON(http.StatusOK, result)
+	ctx.JSON(http.StatusOK, s.newTransferTxResponse(result))
+}
+
+// velocityErrorResponse adds the reason and the remaining allowance to the error, so clients can tell the limits apart
+func velocityErrorResponse(err *db.VelocityError) gin.H {
+	return gin.H{
+		"error":     err.Error(),
+		"reason":    err.Reason,
+		"currency":  err.Currency,
+		"limit":     err.Limit,
+		"remaining": err.Remaining,
+	}
 }
 
 func (s *Server) validAccount(ctx *gin.Context, accountId int64, currency string) (db.Account, bool) {
 
+	account, valid := s.getAccount(ctx, accountId)
+	if !valid {
+		return account, false
+	}
+
+	if account.Currency != currency {
+		err := fmt.Errorf("account [%d] currency mismatch is %s and %s", accountId, account.Currency, currency)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+func (s *Server) getAccount(ctx *gin.Context, accountId int64) (db.Account, bool) {
+
 	account, err := s.store.GetAccount(ctx, accountId)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -76,11 +160,117 @@ func (s *Server) validAccount(ctx *gin.Context, accountId int64, currency string
 		return account, false
 	}
 
-	if account.Currency != currency {
-		err := fmt.Errorf("account [%d] currency mismatch is %s and %s", accountId, account.Currency, currency)
+	return account, true
+}
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getTransferResponse struct {
+	transferResponse
+	// Reversals are the refunds of the transfer, oldest first
+	Reversals []transferResponse `json:"reversals"`
+}
+
+// getTransfer returns a transfer with its status and refunds to the owner of one of its accounts, admins can read every transfer
+func (s *Server) getTransfer(ctx *gin.Context) {
+	// 1. check the valid request
+	var req getTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return account, false
+		return
 	}
 
-	return account, true
+	// 2. get the transfer and check it belongs to the user
+	transfer, err := s.store.GetTransfer(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := s.getAccount(ctx, transfer.FromAccountID)
+	if !valid {
+		return
+	}
+	toAccount, valid := s.getAccount(ctx, transfer.ToAccountID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != fromAccount.Owner && authPayload.Username != toAccount.Owner && authPayload.Role != util.AdminRole {
+		err := errors.New("transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// 3. the refunds go the other way, from the to account back to the from account
+	reversals, err := s.store.ListTransferReversals(ctx, sql.NullInt64{Int64: transfer.ID, Valid: true})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := getTransferResponse{
+		transferResponse: s.newTransferResponse(transfer, fromAccount.Currency, toAccount.Currency),
+		Reversals:        make([]transferResponse, 0, len(reversals)),
+	}
+	for _, reversal := range reversals {
+		response.Reversals = append(response.Reversals, s.newTransferResponse(reversal, toAccount.Currency, fromAccount.Currency))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type transferResponse struct {
+	db.Transfer
+	FormattedAmount         string `json:"formatted_amount"`
+	FormattedToAmount       string `json:"formatted_to_amount"`
+	FormattedReversedAmount string `json:"formatted_reversed_amount"`
+}
+
+// newTransferResponse formats the debited and refunded amounts in the from currency and the credited amount in the to currency
+func (s *Server) newTransferResponse(transfer db.Transfer, fromCurrency string, toCurrency string) transferResponse {
+	return transferResponse{
+		Transfer:                transfer,
+		FormattedAmount:         s.currencies.Format(transfer.Amount, fromCurrency),
+		FormattedToAmount:       s.currencies.Format(transfer.ToAmount, toCurrency),
+		FormattedReversedAmount: s.currencies.Format(transfer.ReversedAmount, fromCurrency),
+	}
+}
+
+type entryResponse struct {
+	db.Entry
+	FormattedAmount string `json:"formatted_amount"`
+}
+
+type transferTxResponse struct {
+	Transfer    transferResponse `json:"transfer"`
+	FromAccount accountResponse  `json:"from_account"`
+	ToAccount   accountResponse  `json:"to_account"`
+	FromEntry   entryResponse    `json:"from_entry"`
+	ToEntry     entryResponse    `json:"to_entry"`
+}
+
+// newTransferTxResponse formats every amount of the transfer in the currency of the account it belongs to
+func (s *Server) newTransferTxResponse(result db.TransferTxResult) transferTxResponse {
+	fromCurrency := result.FromAccount.Currency
+	toCurrency := result.ToAccount.Currency
+	return transferTxResponse{
+		Transfer:    s.newTransferResponse(result.Transfer, fromCurrency, toCurrency),
+		FromAccount: s.newAccountResponse(result.FromAccount),
+		ToAccount:   s.newAccountResponse(result.ToAccount),
+		FromEntry: entryResponse{
+			Entry:           result.FromEntry,
+			FormattedAmount: s.currencies.Format(result.FromEntry.Amount, fromCurrency),
+		},
+		ToEntry: entryResponse{
+			Entry:           result.ToEntry,
+			FormattedAmount: s.currencies.Format(result.ToEntry.Amount, toCurrency),
+		},
+	}
 }
@@ -5,10 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/lockout"
+	"github.com/akshay237/backend-with-go/token"
 	"github.com/akshay237/backend-with-go/util"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -16,13 +20,14 @@ import (
 )
 
 var (
-	ErrWrongPassword = errors.New("please provide valid password for login, password is incorrect")
+	ErrWrongPassword       = errors.New("please provide valid password for login, password is incorrect")
+	ErrServiceAccountLogin = errors.New("service accounts can't log in with a password, use an api key")
 )
 
 // Create User
 type CreateUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 	FullName string `json:"full_name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 }
@@ -31,6 +36,8 @@ type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	Role              string    `json:"role"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -40,6 +47,8 @@ func newUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -55,8 +64,14 @@ func (s *Server) CreateUser(ctx *gin.Context) {
 		return
 	}
 
-	// 2. hash the password before saving it to database
-	hashedPassword, err := util.HashPassword(req.Password)
+	// 2. check the password policy and hash the password before saving it to database
+	err := s.policy.Validate(req.Username, req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := s.passwords.HashPassword(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -85,6 +100,12 @@ func (s *Server) CreateUser(ctx *gin.Context) {
 		return
 	}
 
+	// 4. mail the email verification token, the user can ask for a new one if it doesn't arrive
+	err = s.userTokens.SendEmailVerification(ctx, user)
+	if err != nil {
+		log.Printf("failed to send the email verification to %s: %v", user.Username, err)
+	}
+
 	// 5. create user response
 	response := newUserResponse(user)
 
@@ -92,9 +113,9 @@ func (s *Server) CreateUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
-// GetUser API
+// GetUser API, only admins can look up users
 type GetUserRequest struct {
-	Username string `json:"username" binding:"required,alphanum"`
+	Username string `uri:"username" binding:"required,alphanum"`
 }
 
 func (s *Server) GetUser(ctx *gin.Context) {
@@ -103,8 +124,8 @@ func (s *Server) GetUser(ctx *gin.Context) {
 	var req GetUserRequest
 
 	// 2. check for the request
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadGateway, errorResponse(err))
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
@@ -125,10 +146,45 @@ func (s *Server) GetUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// UnlockUser API, admins remove the login lockout of a user
+type unlockUserRequest struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type unlockUserResponse struct {
+	Username string `json:"username"`
+	Unlocked bool   `json:"unlocked"`
+}
+
+func (s *Server) unlockUser(ctx *gin.Context) {
+
+	// 1. check the valid request
+	var req unlockUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. forget the failed logins of the user, unlocked is false if there were none
+	unlocked, err := s.loginGuard.Unlock(ctx, req.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// 3. return the response
+	ctx.JSON(http.StatusOK, unlockUserResponse{
+		Username: req.Username,
+		Unlocked: unlocked,
+	})
+}
+
 // Login User
 type loginUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum"`
 	Password string `json:"password" binding:"required,min=6"`
+	// Scopes limit the tokens of the session, the tokens are not limited without them
+	Scopes []string `json:"scopes" binding:"max=16"`
 }
 
 type loginUserResponse struct {
@@ -138,6 +194,14 @@ type loginUserResponse struct {
 	RefreshToken          string       `json:"refresh_token"`
 	RefreshTokenExpiresAt time.Time    `json:"refresh_token_expires_at"`
 	User                  userResponse `json:"user"`
+	MFARequired           bool         `json:"mfa_required"`
+}
+
+// mfaChallengeResponse is returned by the password step of a login which needs a second factor
+type mfaChallengeResponse struct {
+	MFARequired           bool      `json:"mfa_required"`
+	MFAChallengeToken     string    `json:"mfa_challenge_token"`
+	MFAChallengeExpiresAt time.Time `json:"mfa_challenge_expires_at"`
 }
 
 func (s *Server) loginUser(ctx *gin.Context) {
@@ -148,11 +212,24 @@ func (s *Server) loginUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	scopes, err := token.NarrowScopes(nil, req.Scopes)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
-	// 2. get the user from the db
+	// 2. count the attempt and reject the login while the username or the client is locked out, before the password is checked
+	err = s.loginGuard.Attempt(ctx, req.Username, clientIP(ctx))
+	if err != nil {
+		abortLoginLocked(ctx, err)
+		return
+	}
+
+	// 3. get the user from the db, unknown usernames count as failed logins too
 	user, err := s.store.GetUser(ctx, req.Username)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
+			s.recordLoginFailure(ctx, req.Username)
 			ctx.JSON(http.StatusBadRequest, errorResponse(err))
 			return
 		}
@@ -160,51 +237,148 @@ func (s *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
-	// 3. check if the password of the user is same or not
-	err = util.CheckPassword(req.Password, user.HashedPassword)
+	// 3.1 check if the password of the user is same or not
+	outdated, err := s.passwords.CheckPassword(req.Password, user.HashedPassword)
 	if err != nil {
 		log.Printf("Check password err: %v", err)
+		s.recordLoginFailure(ctx, req.Username)
 		ctx.JSON(http.StatusUnauthorized, errorResponse(ErrWrongPassword))
 		return
 	}
 
-	// 4. create a access token for the user
-	accessToken, accessPayload, err := s.tokenMaker.CreateToken(req.Username, s.config.AccessTokenDuration)
+	// 3.2 a hash of older settings is replaced now that the password is known
+	if outdated {
+		s.rehashPassword(ctx, user, req.Password)
+	}
+
+	// 3.3 service accounts authenticate with api keys only
+	if user.Role == util.ServiceRole {
+		ctx.JSON(http.StatusForbidden, errorResponse(ErrServiceAccountLogin))
+		return
+	}
+
+	// 4. users with two factor authentication get a challenge for the second step instead of the tokens,
+	// the failed attempts of the username are kept until the code is checked
+	enabled, err := s.mfa.IsEnabled(ctx, user.Username)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	if enabled {
+		err = s.loginGuard.RecordFirstFactor(ctx, clientIP(ctx))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
 
-	// 4.1 create a refresh token
-	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(req.Username, s.config.RefreshTokenDuration)
+		challenge, err := s.mfa.CreateChallenge(ctx, user.Username)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusOK, mfaChallengeResponse{
+			MFARequired:           true,
+			MFAChallengeToken:     challenge.ID.String(),
+			MFAChallengeExpiresAt: challenge.ExpiresAt,
+		})
+		return
+	}
+
+	// 5. the login is complete, the failed attempts of the username are forgotten
+	err = s.loginGuard.RecordSuccess(ctx, user.Username, clientIP(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	// 4.2 create the session and store to DB
-	session, err := s.store.CreateSession(ctx, db.CreateSessionParams{
-		ID:           refreshPayload.ID,
-		Username:     user.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    ctx.Request.UserAgent(),
-		ClientIp:     ctx.Request.RemoteAddr,
-		IsBlocked:    false,
-		ExpiresAt:    refreshPayload.ExpiredAT,
+	// 6. create the tokens and the session
+	response, err := s.createLoginSession(ctx, user, scopes)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// rehashPassword stores the password hashed with the current settings, the login doesn't depend on it so errors are only logged.
+// The hash is replaced only if it didn't change since it was checked, so a concurrent password reset wins.
+func (s *Server) rehashPassword(ctx *gin.Context, user db.User, password string) {
+	hashedPassword, err := s.passwords.HashPassword(password)
+	if err != nil {
+		log.Printf("failed to rehash the password of %s: %v", user.Username, err)
+		return
+	}
+
+	_, err = s.store.RehashUserPassword(ctx, db.RehashUserPasswordParams{
+		Username:          user.Username,
+		OldHashedPassword: user.HashedPassword,
+		NewHashedPassword: hashedPassword,
 	})
 	if err != nil {
+		log.Printf("failed to store the rehashed password of %s: %v", user.Username, err)
+	}
+}
+
+// recordLoginFailure counts a failed login, the login response doesn't depend on it so errors are only logged
+func (s *Server) recordLoginFailure(ctx *gin.Context, username string) {
+	err := s.loginGuard.RecordFailure(ctx, username, clientIP(ctx))
+	if err != nil {
+		log.Printf("failed to record the failed login of %s: %v", username, err)
+	}
+}
+
+// abortLoginLocked responds to a login which is locked out with the time the client has to wait
+func abortLoginLocked(ctx *gin.Context, err error) {
+	var locked *lockout.LockedError
+	if !errors.As(err, &locked) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	// 5. send the response to user with
-	response := loginUserResponse{
+	retryAfter := int(math.Ceil(locked.RetryAfter.Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+	ctx.JSON(http.StatusTooManyRequests, errorResponse(err))
+}
+
+// createLoginSession creates the access and refresh tokens of a logged in user and stores the session.
+// Both tokens carry the scopes, so the renewed access tokens keep them.
+func (s *Server) createLoginSession(ctx *gin.Context, user db.User, scopes []string) (loginUserResponse, error) {
+
+	// 1. create a access token for the user
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, scopes, token.TokenTypeAccess, s.config.AccessTokenDuration)
+	if err != nil {
+		return loginUserResponse{}, err
+	}
+
+	// 2. create a refresh token
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, scopes, token.TokenTypeRefresh, s.config.RefreshTokenDuration)
+	if err != nil {
+		return loginUserResponse{}, err
+	}
+
+	// 3. create the session and store to DB
+	session, err := s.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:                   refreshPayload.ID,
+		Username:             user.Username,
+		RefreshToken:         refreshToken,
+		UserAgent:            ctx.Request.UserAgent(),
+		ClientIp:             clientIP(ctx),
+		IsBlocked:            false,
+		ExpiresAt:            refreshPayload.ExpiredAT,
+		FamilyID:             refreshPayload.ID,
+		AccessTokenID:        uuid.NullUUID{UUID: accessPayload.ID, Valid: true},
+		AccessTokenExpiresAt: sql.NullTime{Time: accessPayload.ExpiredAT, Valid: true},
+	})
+	if err != nil {
+		return loginUserResponse{}, err
+	}
+
+	return loginUserResponse{
 		Session_Id:            session.ID,
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  accessPayload.ExpiredAT,
 		RefreshToken:          refreshToken,
 		RefreshTokenExpiresAt: refreshPayload.ExpiredAT,
 		User:                  newUserResponse(user),
-	}
-	ctx.JSON(http.StatusOK, response)
+	}, nil
 }
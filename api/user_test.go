@@ -9,14 +9,19 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	mockdb "github.com/akshay237/backend-with-go/database/mock"
 	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/lockout"
+	"github.com/akshay237/backend-with-go/token"
 	"github.com/akshay237/backend-with-go/util"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type eqCreateUserParamMatcher struct {
@@ -49,7 +54,7 @@ func EqCreateUserParams(arg db.CreateUSerParams, password string) gomock.Matcher
 }
 
 func createRandomUser(t *testing.T) (db.User, string) {
-	password := util.RandomString(6)
+	password := util.RandomString(10)
 	hashedPassword, err := util.HashPassword(password)
 	require.NoError(t, err)
 
@@ -86,6 +91,12 @@ func TestCreateUser(t *testing.T) {
 					Email:          user.Email,
 				}
 				store.EXPECT().CreateUSer(gomock.Any(), EqCreateUserParams(args, password)).Times(1).Return(user, nil)
+				store.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateUserTokenParams) (db.UserToken, error) {
+						require.Equal(t, db.UserTokenEmailVerification, arg.Purpose)
+						require.Equal(t, user.Email, arg.Email)
+						return db.UserToken{TokenHash: arg.TokenHash, Username: arg.Username}, nil
+					})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -184,3 +195,315 @@ func TestCreateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginUserLockoutAPI(t *testing.T) {
+	user, password := createRandomUser(t)
+	clientIP := "203.0.113.7"
+
+	testcases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Locked",
+			body: gin.H{"username": user.Username, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+						throttle := db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}
+						if arg.Kind == lockout.KindUsername {
+							require.Equal(t, user.Username, arg.Subject)
+							throttle.FailedAttempts = 10
+							throttle.LockedUntil = sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true}
+						} else {
+							require.Equal(t, clientIP, arg.Subject)
+						}
+						return throttle, nil
+					})
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "60", recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{"username": user.Username, "password": "wrong-password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}, nil
+					})
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetLoginThrottle(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.GetLoginThrottleParams) (db.LoginThrottle, error) {
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}, nil
+					})
+				store.EXPECT().LockLoginThrottle(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "WrongPasswordLocksUsername",
+			body: gin.H{"username": user.Username, "password": "wrong-password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}, nil
+					})
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetLoginThrottle(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.GetLoginThrottleParams) (db.LoginThrottle, error) {
+						attempts := int32(1)
+						if arg.Kind == lockout.KindUsername {
+							attempts = lockout.DefaultMaxAttempts
+						}
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: attempts}, nil
+					})
+				store.EXPECT().LockLoginThrottle(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.LockLoginThrottleParams) (db.LoginThrottle, error) {
+						require.Equal(t, lockout.KindUsername, arg.Kind)
+						require.WithinDuration(t, time.Now().Add(lockout.DefaultLockoutDuration), arg.LockedUntil.Time, time.Second)
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, LockedUntil: arg.LockedUntil}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			body: gin.H{"username": user.Username, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}, nil
+					})
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetLoginThrottle(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ any, arg db.GetLoginThrottleParams) (db.LoginThrottle, error) {
+						return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/user/login", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.RemoteAddr = clientIP + ":51234"
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLoginUserRehashAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user, password := createRandomUser(t)
+
+	// the user still has a bcrypt hash from before argon2id was the default
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	user.HashedPassword = string(bcryptHash)
+
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginThrottle{FailedAttempts: 1}, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	store.EXPECT().RehashUserPassword(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ any, arg db.RehashUserPasswordParams) (int64, error) {
+			require.Equal(t, user.Username, arg.Username)
+			require.Equal(t, user.HashedPassword, arg.OldHashedPassword)
+			require.NoError(t, util.CheckPassword(password, arg.NewHashedPassword))
+			require.True(t, strings.HasPrefix(arg.NewHashedPassword, "$argon2id$"))
+			// a concurrent password change wins, the login still succeeds
+			return 0, nil
+		})
+	store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserTotp{}, sql.ErrNoRows)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).Return(randomSession(user.Username), nil)
+
+	data, err := json.Marshal(gin.H{"username": user.Username, "password": password})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/user/login", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestLoginServiceAccountAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user, password := createRandomUser(t)
+	user.Role = util.ServiceRole
+
+	// service accounts authenticate with api keys only, even with the right password
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginThrottle{FailedAttempts: 1}, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+
+	data, err := json.Marshal(gin.H{"username": user.Username, "password": password})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/user/login", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestLoginUserScopesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user, password := createRandomUser(t)
+
+	// 1. unknown scopes are rejected before the password is checked
+	data, err := json.Marshal(gin.H{"username": user.Username, "password": password, "scopes": []string{"accounts:*"}})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/user/login", bytes.NewReader(data))
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// 2. the tokens of the session carry the requested scopes
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginThrottle{FailedAttempts: 1}, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserTotp{}, sql.ErrNoRows)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).Return(randomSession(user.Username), nil)
+
+	data, err = json.Marshal(gin.H{"username": user.Username, "password": password, "scopes": []string{token.ScopeAccountsRead}})
+	require.NoError(t, err)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodPost, "/user/login", bytes.NewReader(data))
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response loginUserResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	payload, err := server.tokenMaker.VerifyToken(response.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, []string{token.ScopeAccountsRead}, payload.Scopes)
+}
+
+func TestUnlockUserAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+
+	testcases := []struct {
+		name          string
+		username      string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Eq(db.DeleteLoginThrottleParams{
+					Kind:    lockout.KindUsername,
+					Subject: user.Username,
+				})).Times(1).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response unlockUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, user.Username, response.Username)
+				require.True(t, response.Unlocked)
+			},
+		},
+		{
+			name:     "Forbidden",
+			username: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "admin", util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%s/lockout", tc.username)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/usertoken"
+	"github.com/gin-gonic/gin"
+)
+
+// requestEmailVerification mails a new verification token to the email address of the authenticated user
+func (s *Server) requestEmailVerification(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// 1. get the current email address of the user
+	user, err := s.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// 2. mail the token
+	err = s.userTokens.SendEmailVerification(ctx, user)
+	if err != nil {
+		ctx.JSON(userTokenErrorStatus(err), errorResponse(err))
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// verifyEmail marks the email address of a user as verified with the mailed token
+func (s *Server) verifyEmail(ctx *gin.Context) {
+	// 1. check the valid request
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. redeem the token
+	user, err := s.userTokens.VerifyEmail(ctx, req.Token)
+	if err != nil {
+		ctx.JSON(userTokenErrorStatus(err), errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type requestPasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// requestPasswordReset mails a password reset token. The response is the same for unknown addresses,
+// so it doesn't tell who has an account.
+func (s *Server) requestPasswordReset(ctx *gin.Context) {
+	// 1. check the valid request
+	var req requestPasswordResetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. mail the token
+	err := s.userTokens.SendPasswordReset(ctx, req.Email)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+type resetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// resetPassword sets a new password with the mailed token. All the sessions of the user are blocked
+// and the tokens issued before the reset are rejected, whether or not they belong to a session.
+func (s *Server) resetPassword(ctx *gin.Context) {
+	// 1. check the valid request
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2. check the new password against the policy of the user the token belongs to
+	userToken, err := s.userTokens.LookupPasswordReset(ctx, req.Token)
+	if err != nil {
+		ctx.JSON(userTokenErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	err = s.policy.Validate(userToken.Username, req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// 2.1 hash the new password
+	hashedPassword, err := s.passwords.HashPassword(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// 3. redeem the token, the password is changed and the sessions are blocked and revoked in one transaction
+	result, err := s.userTokens.ResetPassword(ctx, req.Token, hashedPassword)
+	if err != nil {
+		ctx.JSON(userTokenErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	// 4. reject the tokens issued before the reset right away, the other processes do after their next refresh
+	s.revocations.PasswordChanged(result.User.Username, result.User.PasswordChangedAt)
+
+	// 5. the owner of the email proved who they are, a login lockout is lifted
+	_, err = s.loginGuard.Unlock(ctx, result.User.Username)
+	if err != nil {
+		log.Printf("failed to unlock the user %s after the password reset: %v", result.User.Username, err)
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(result.User))
+}
+
+// userTokenErrorStatus returns the HTTP status of an error of the user token service
+func userTokenErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usertoken.ErrInvalidToken):
+		return http.StatusUnauthorized
+	case errors.Is(err, usertoken.ErrAlreadyVerified):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/lockout"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/usertoken"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetPasswordAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+	session := randomSession(user.Username)
+	resetToken := "reset-token"
+	userToken := db.UserToken{
+		TokenHash: usertoken.HashToken(resetToken),
+		Username:  user.Username,
+		Purpose:   db.UserTokenPasswordReset,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	testcases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"token": resetToken, "new_password": "new-secret"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUsableUserToken(gomock.Any(), gomock.Eq(db.GetUsableUserTokenParams{
+					TokenHash: userToken.TokenHash,
+					Purpose:   db.UserTokenPasswordReset,
+				})).Times(1).Return(userToken, nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.ResetPasswordTxParams) (db.ResetPasswordTxResult, error) {
+						require.Equal(t, usertoken.HashToken(resetToken), arg.TokenHash)
+						updated := user
+						updated.HashedPassword = arg.HashedPassword
+						updated.PasswordChangedAt = time.Now()
+						return db.ResetPasswordTxResult{User: updated, BlockedSessions: []db.Session{session}}, nil
+					})
+				store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Eq(db.DeleteLoginThrottleParams{
+					Kind:    lockout.KindUsername,
+					Subject: user.Username,
+				})).Times(1).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				// a token issued before the reset is rejected, with or without a session
+				issued := &token.Payload{Username: user.Username, IssuedAt: time.Now().Add(-time.Minute)}
+				require.True(t, server.revocations.IssuedBeforePasswordChange(issued))
+
+				var response userResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, user.Username, response.Username)
+				require.False(t, response.PasswordChangedAt.IsZero())
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: gin.H{"token": resetToken, "new_password": "new-secret"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUsableUserToken(gomock.Any(), gomock.Any()).Times(1).Return(db.UserToken{}, sql.ErrNoRows)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ShortPassword",
+			body: gin.H{"token": resetToken, "new_password": "123"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUsableUserToken(gomock.Any(), gomock.Any()).Times(1).Return(userToken, nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/user/password/reset", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server)
+		})
+	}
+}
+
+func TestRequestPasswordResetAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	// an unknown email gets the same response as a known one
+	store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq("unknown@example.com")).Times(1).Return(db.User{}, sql.ErrNoRows)
+	store.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).Times(0)
+
+	data, err := json.Marshal(gin.H{"email": "unknown@example.com"})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/user/password/reset_request", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+}
+
+func TestVerifyEmailAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user, _ := createRandomUser(t)
+	user.IsEmailVerified = true
+
+	store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Eq(usertoken.HashToken("verify-token"))).Times(1).Return(user, nil)
+
+	data, err := json.Marshal(gin.H{"token": "verify-token"})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/user/email/verify", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response userResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.True(t, response.IsEmailVerified)
+}
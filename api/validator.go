@@ -1,13 +1,16 @@
 package api
 
 import (
-	util "github.com/akshay237/backend-with-go/util"
+	"github.com/akshay237/backend-with-go/currency"
 	"github.com/go-playground/validator/v10"
 )
 
-var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
-	if curr, isok := fl.Field().Interface().(string); isok {
-		return util.IsSupportedCurrency(curr)
+// newCurrencyValidator validates the currency against the enabled currencies of the registry
+func newCurrencyValidator(currencies *currency.Registry) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		if curr, isok := fl.Field().Interface().(string); isok {
+			return currencies.IsSupported(curr)
+		}
+		return false
 	}
-	return false
 }
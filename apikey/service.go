@@ -0,0 +1,340 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/google/uuid"
+)
+
+// KeyPrefix starts every API key, so leaked keys are easy to spot in logs and by secret scanners
+const KeyPrefix = "sbk_"
+
+// displayPrefixLength is how much of a key is stored in the clear to tell the keys apart
+const displayPrefixLength = len(KeyPrefix) + 8
+
+// lastUsedInterval is how often the last use of a key is written, a busy key doesn't update its row on every request
+const lastUsedInterval = time.Minute
+
+var (
+	ErrInvalidKey       = errors.New("api key is invalid, expired or revoked")
+	ErrIPNotAllowed     = errors.New("api key is not allowed from this address")
+	ErrKeyNotFound      = errors.New("api key not found")
+	ErrInvalidAllowedIP = errors.New("invalid allowed ip")
+	ErrWiderThanCaller  = errors.New("api key can't be allowed from more addresses or for longer than the api key of the caller")
+)
+
+// Store is the part of the database store used for the API keys
+type Store interface {
+	CreateAPIKey(ctx context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error)
+	GetAPIKey(ctx context.Context, id uuid.UUID) (db.ApiKey, error)
+	GetAccessToken(ctx context.Context, id uuid.UUID) (db.AccessToken, error)
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (db.GetActiveAPIKeyByHashRow, error)
+	ListAPIKeys(ctx context.Context, username string) ([]db.ApiKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (db.ApiKey, error)
+	TouchAPIKey(ctx context.Context, arg db.TouchAPIKeyParams) error
+}
+
+// Service issues the API keys of the machine clients and authenticates the requests made with them.
+// A key is shown once when it is created, only its hash is stored.
+type Service struct {
+	store Store
+}
+
+// NewService creates the API key service
+func NewService(store Store) *Service {
+	return &Service{store: store}
+}
+
+// CreateParams describes a new API key
+type CreateParams struct {
+	Username string
+	Name     string
+	Scopes   []string
+	// AllowedIPs are addresses or CIDR ranges, the key is accepted from any address if it is empty
+	AllowedIPs []string
+	// ExpiresAt is zero for a key which doesn't expire
+	ExpiresAt time.Time
+	// Caller creates the key, a caller which authenticated with an api key can't create a wider key
+	Caller *token.Payload
+}
+
+// Create stores a new key of the user and returns the key, it can't be recovered later.
+// A key created with another api key, or with a token delegated from one, gets the allowlist and the expiry
+// of that key unless it asks for narrower ones, it fails with ErrWiderThanCaller if it asks for wider ones.
+func (s *Service) Create(ctx context.Context, arg CreateParams) (string, db.ApiKey, error) {
+	prefixes := make([]netip.Prefix, 0, len(arg.AllowedIPs))
+	for _, allowed := range arg.AllowedIPs {
+		prefix, err := parseAllowedIP(allowed)
+		if err != nil {
+			return "", db.ApiKey{}, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	if arg.Caller != nil {
+		callerKey, ok, err := s.callerKey(ctx, arg.Caller)
+		if err != nil {
+			return "", db.ApiKey{}, err
+		}
+		if ok {
+			prefixes, arg.ExpiresAt, err = narrowToKey(prefixes, arg.ExpiresAt, callerKey)
+			if err != nil {
+				return "", db.ApiKey{}, err
+			}
+		}
+	}
+
+	allowedIPs := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		allowedIPs = append(allowedIPs, prefix.String())
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", db.ApiKey{}, err
+	}
+	key, err := GenerateKey()
+	if err != nil {
+		return "", db.ApiKey{}, err
+	}
+
+	apiKey, err := s.store.CreateAPIKey(ctx, db.CreateAPIKeyParams{
+		ID:         id,
+		Username:   arg.Username,
+		Name:       arg.Name,
+		Prefix:     key[:displayPrefixLength],
+		KeyHash:    HashKey(key),
+		Scopes:     append([]string{}, arg.Scopes...),
+		AllowedIps: allowedIPs,
+		ExpiresAt:  sql.NullTime{Time: arg.ExpiresAt, Valid: !arg.ExpiresAt.IsZero()},
+	})
+	if err != nil {
+		return "", db.ApiKey{}, err
+	}
+	return key, apiKey, nil
+}
+
+// Authenticate checks the key sent from the client address and returns a payload standing in for an access token.
+// The payload carries the current role of the key owner, the scopes and the id of the key.
+func (s *Service) Authenticate(ctx context.Context, key string, clientIP string) (*token.Payload, error) {
+	if !strings.HasPrefix(key, KeyPrefix) {
+		return nil, ErrInvalidKey
+	}
+
+	row, err := s.store.GetActiveAPIKeyByHash(ctx, HashKey(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidKey
+		}
+		return nil, err
+	}
+	apiKey := row.ApiKey
+
+	addr, ok := parseClientIP(clientIP)
+	if !isAllowed(apiKey.AllowedIps, addr, ok) {
+		return nil, ErrIPNotAllowed
+	}
+
+	// the request doesn't depend on the last use, so errors are only logged
+	err = s.store.TouchAPIKey(ctx, db.TouchAPIKeyParams{
+		ID:         apiKey.ID,
+		LastUsedIp: sql.NullString{String: addr.String(), Valid: ok},
+		UsedBefore: sql.NullTime{Time: time.Now().Add(-lastUsedInterval), Valid: true},
+	})
+	if err != nil {
+		log.Printf("failed to record the use of the api key %s: %v", apiKey.ID, err)
+	}
+
+	return &token.Payload{
+		ID:        apiKey.ID,
+		Username:  apiKey.Username,
+		Role:      row.Role,
+		Scopes:    apiKey.Scopes,
+		Type:      token.TokenTypeAccess,
+		IssuedAt:  apiKey.CreatedAt,
+		ExpiredAT: apiKey.ExpiresAt.Time,
+	}, nil
+}
+
+// List returns the keys of the user which are not revoked
+func (s *Service) List(ctx context.Context, username string) ([]db.ApiKey, error) {
+	return s.store.ListAPIKeys(ctx, username)
+}
+
+// Revoke revokes a key of the owner, the key stops working right away.
+// An empty owner revokes the key of any user, a key of another user is reported as not found.
+func (s *Service) Revoke(ctx context.Context, id uuid.UUID, owner string) (db.ApiKey, error) {
+	apiKey, err := s.store.GetAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.ApiKey{}, ErrKeyNotFound
+		}
+		return db.ApiKey{}, err
+	}
+	if owner != "" && apiKey.Username != owner {
+		return db.ApiKey{}, ErrKeyNotFound
+	}
+
+	apiKey, err = s.store.RevokeAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.ApiKey{}, ErrKeyNotFound
+		}
+		return db.ApiKey{}, err
+	}
+	return apiKey, nil
+}
+
+// GenerateKey returns a new random API key
+func GenerateKey() (string, error) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// HashKey returns the hash stored for the key. The keys are random, so a fast hash is enough.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// callerKey returns the api key the caller authenticated with, directly or with a token delegated from it
+func (s *Service) callerKey(ctx context.Context, caller *token.Payload) (db.ApiKey, bool, error) {
+	// the payload of an api key carries the id of the key
+	apiKey, err := s.store.GetAPIKey(ctx, caller.ID)
+	if err == nil {
+		return apiKey, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return db.ApiKey{}, false, err
+	}
+
+	accessToken, err := s.store.GetAccessToken(ctx, caller.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.ApiKey{}, false, nil
+		}
+		return db.ApiKey{}, false, err
+	}
+	if !accessToken.ApiKeyID.Valid {
+		return db.ApiKey{}, false, nil
+	}
+
+	apiKey, err = s.store.GetAPIKey(ctx, accessToken.ApiKeyID.UUID)
+	if err != nil {
+		return db.ApiKey{}, false, err
+	}
+	return apiKey, true, nil
+}
+
+// narrowToKey limits the allowlist and the expiry of a new key to the ones of the parent key,
+// the new key gets the ones of the parent key if it doesn't ask for its own
+func narrowToKey(prefixes []netip.Prefix, expiresAt time.Time, parent db.ApiKey) ([]netip.Prefix, time.Time, error) {
+	if len(parent.AllowedIps) > 0 {
+		parentPrefixes := make([]netip.Prefix, 0, len(parent.AllowedIps))
+		for _, allowed := range parent.AllowedIps {
+			prefix, err := parseAllowedIP(allowed)
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+			parentPrefixes = append(parentPrefixes, prefix)
+		}
+
+		if len(prefixes) == 0 {
+			prefixes = parentPrefixes
+		}
+		for _, prefix := range prefixes {
+			if !containsPrefix(parentPrefixes, prefix) {
+				return nil, time.Time{}, fmt.Errorf("%w: %s is not allowed", ErrWiderThanCaller, prefix)
+			}
+		}
+	}
+
+	if parent.ExpiresAt.Valid {
+		if expiresAt.IsZero() {
+			expiresAt = parent.ExpiresAt.Time
+		}
+		if expiresAt.After(parent.ExpiresAt.Time) {
+			return nil, time.Time{}, fmt.Errorf("%w: it expires at %s", ErrWiderThanCaller, parent.ExpiresAt.Time.Format(time.RFC3339))
+		}
+	}
+	return prefixes, expiresAt, nil
+}
+
+// containsPrefix reports whether the range is inside one of the ranges
+func containsPrefix(prefixes []netip.Prefix, prefix netip.Prefix) bool {
+	for _, p := range prefixes {
+		if p.Bits() <= prefix.Bits() && p.Contains(prefix.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAllowedIP reads an address or a CIDR range, an address is a range of itself
+func parseAllowedIP(allowed string) (netip.Prefix, error) {
+	if strings.Contains(allowed, "/") {
+		prefix, err := netip.ParsePrefix(allowed)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("%w %q: %v", ErrInvalidAllowedIP, allowed, err)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(allowed)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%w %q: %v", ErrInvalidAllowedIP, allowed, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// parseClientIP returns the client address without the port. The address must be the one the
+// servers trust, see util.ClientIP, a forwarded list sent by the client is not an address.
+func parseClientIP(clientIP string) (netip.Addr, bool) {
+	ip := strings.TrimSpace(clientIP)
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// isAllowed reports whether the client address is in the allowlist, an unknown address is only allowed without a list
+func isAllowed(allowedIPs []string, addr netip.Addr, ok bool) bool {
+	if len(allowedIPs) == 0 {
+		return true
+	}
+	if !ok {
+		return false
+	}
+
+	for _, allowed := range allowedIPs {
+		prefix, err := netip.ParsePrefix(allowed)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,285 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func randomAPIKey(username string, allowedIPs ...string) db.ApiKey {
+	return db.ApiKey{
+		ID:         uuid.New(),
+		Username:   username,
+		Name:       util.RandomOwner(),
+		Prefix:     KeyPrefix + util.RandomString(8),
+		Scopes:     []string{token.ScopeAccountsRead},
+		AllowedIps: allowedIPs,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func TestCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service := NewService(store)
+	username := util.RandomOwner()
+	expiresAt := time.Now().Add(time.Hour)
+
+	// 1. only the hash of the key is stored, the allowlist is normalized
+	var stored db.CreateAPIKeyParams
+	store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+			stored = arg
+			return db.ApiKey{ID: arg.ID, Username: arg.Username, Prefix: arg.Prefix, KeyHash: arg.KeyHash}, nil
+		})
+
+	key, apiKey, err := service.Create(context.Background(), CreateParams{
+		Username:   username,
+		Name:       "batch",
+		Scopes:     []string{"transfers:write"},
+		AllowedIPs: []string{"203.0.113.7", "10.1.2.3/8", "::ffff:198.51.100.1"},
+		ExpiresAt:  expiresAt,
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, KeyPrefix))
+	require.Equal(t, HashKey(key), stored.KeyHash)
+	require.NotContains(t, stored.KeyHash, key)
+	require.True(t, strings.HasPrefix(key, stored.Prefix))
+	require.Len(t, stored.Prefix, displayPrefixLength)
+	require.Equal(t, []string{"transfers:write"}, stored.Scopes)
+	require.Equal(t, []string{"203.0.113.7/32", "10.0.0.0/8", "198.51.100.1/32"}, stored.AllowedIps)
+	require.True(t, stored.ExpiresAt.Valid)
+	require.Equal(t, username, apiKey.Username)
+
+	// 2. an invalid allowlist entry is rejected before anything is stored
+	_, _, err = service.Create(context.Background(), CreateParams{
+		Username:   username,
+		Name:       "batch",
+		AllowedIPs: []string{"not-an-ip"},
+	})
+	require.ErrorIs(t, err, ErrInvalidAllowedIP)
+
+	// 3. a key without expiry
+	store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+			require.False(t, arg.ExpiresAt.Valid)
+			require.Empty(t, arg.AllowedIps)
+			return db.ApiKey{}, nil
+		})
+	_, _, err = service.Create(context.Background(), CreateParams{Username: username, Name: "batch"})
+	require.NoError(t, err)
+}
+
+func TestAuthenticate(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	username := util.RandomOwner()
+
+	testcases := []struct {
+		name       string
+		key        string
+		clientIP   string
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, err error)
+	}{
+		{
+			name:     "OK",
+			key:      key,
+			clientIP: "203.0.113.7:51234",
+			buildStubs: func(store *mockdb.MockStore) {
+				apiKey := randomAPIKey(username, "203.0.113.0/24")
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Eq(HashKey(key))).Times(1).
+					Return(db.GetActiveAPIKeyByHashRow{ApiKey: apiKey, Role: util.ServiceRole}, nil)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TouchAPIKeyParams) error {
+						require.Equal(t, apiKey.ID, arg.ID)
+						require.Equal(t, "203.0.113.7", arg.LastUsedIp.String)
+						require.WithinDuration(t, time.Now().Add(-lastUsedInterval), arg.UsedBefore.Time, time.Second)
+						return nil
+					})
+			},
+			check: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "TouchFails",
+			key:      key,
+			clientIP: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.GetActiveAPIKeyByHashRow{ApiKey: randomAPIKey(username), Role: util.ServiceRole}, nil)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+			},
+			check: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "WrongPrefix",
+			key:      "not-a-key",
+			clientIP: "203.0.113.7",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidKey)
+			},
+		},
+		{
+			name:     "RevokedOrExpired",
+			key:      key,
+			clientIP: "203.0.113.7",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.GetActiveAPIKeyByHashRow{}, sql.ErrNoRows)
+			},
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidKey)
+			},
+		},
+		{
+			name:     "IPNotAllowed",
+			key:      key,
+			clientIP: "198.51.100.1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.GetActiveAPIKeyByHashRow{ApiKey: randomAPIKey(username, "203.0.113.0/24"), Role: util.ServiceRole}, nil)
+				store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrIPNotAllowed)
+			},
+		},
+		{
+			name:     "UnknownIPWithAllowlist",
+			key:      key,
+			clientIP: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.GetActiveAPIKeyByHashRow{ApiKey: randomAPIKey(username, "203.0.113.0/24"), Role: util.ServiceRole}, nil)
+			},
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrIPNotAllowed)
+			},
+		},
+		{
+			name:     "InternalError",
+			key:      key,
+			clientIP: "203.0.113.7",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.GetActiveAPIKeyByHashRow{}, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, err error) {
+				require.True(t, errors.Is(err, sql.ErrConnDone))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			payload, err := NewService(store).Authenticate(context.Background(), tc.key, tc.clientIP)
+			tc.check(t, err)
+			if err == nil {
+				require.Equal(t, username, payload.Username)
+				require.Equal(t, util.ServiceRole, payload.Role)
+				require.Equal(t, []string{token.ScopeAccountsRead}, payload.Scopes)
+			}
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service := NewService(store)
+	apiKey := randomAPIKey(util.RandomOwner())
+
+	// 1. a key of another user is not found
+	store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(3).Return(apiKey, nil)
+	_, err := service.Revoke(context.Background(), apiKey.ID, "other")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	// 2. the owner revokes the key, an empty owner revokes any key
+	revoked := apiKey
+	revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	store.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(revoked, nil)
+	result, err := service.Revoke(context.Background(), apiKey.ID, apiKey.Username)
+	require.NoError(t, err)
+	require.True(t, result.RevokedAt.Valid)
+
+	// 3. a key revoked already is not found
+	store.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(db.ApiKey{}, sql.ErrNoRows)
+	_, err = service.Revoke(context.Background(), apiKey.ID, "")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	// 4. an unknown key
+	store.EXPECT().GetAPIKey(gomock.Any(), gomock.Any()).Times(1).Return(db.ApiKey{}, sql.ErrNoRows)
+	_, err = service.Revoke(context.Background(), uuid.New(), "")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestNarrowToKey(t *testing.T) {
+	parent := randomAPIKey(util.RandomOwner(), "10.0.0.0/8", "203.0.113.7/32")
+	parent.ExpiresAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+
+	parse := func(allowed ...string) []netip.Prefix {
+		prefixes := make([]netip.Prefix, 0, len(allowed))
+		for _, a := range allowed {
+			prefix, err := parseAllowedIP(a)
+			require.NoError(t, err)
+			prefixes = append(prefixes, prefix)
+		}
+		return prefixes
+	}
+
+	// 1. a key which asks for nothing gets the limits of the parent key
+	prefixes, expiresAt, err := narrowToKey(nil, time.Time{}, parent)
+	require.NoError(t, err)
+	require.Equal(t, parse("10.0.0.0/8", "203.0.113.7"), prefixes)
+	require.Equal(t, parent.ExpiresAt.Time, expiresAt)
+
+	// 2. narrower limits are kept
+	earlier := time.Now().Add(time.Minute)
+	prefixes, expiresAt, err = narrowToKey(parse("10.1.0.0/16", "203.0.113.7"), earlier, parent)
+	require.NoError(t, err)
+	require.Equal(t, parse("10.1.0.0/16", "203.0.113.7"), prefixes)
+	require.Equal(t, earlier, expiresAt)
+
+	// 3. wider limits are refused
+	_, _, err = narrowToKey(parse("0.0.0.0/0"), time.Time{}, parent)
+	require.ErrorIs(t, err, ErrWiderThanCaller)
+	_, _, err = narrowToKey(parse("203.0.113.0/24"), time.Time{}, parent)
+	require.ErrorIs(t, err, ErrWiderThanCaller)
+	_, _, err = narrowToKey(nil, time.Now().Add(2*time.Hour), parent)
+	require.ErrorIs(t, err, ErrWiderThanCaller)
+
+	// 4. a parent key without limits doesn't limit the new key
+	prefixes, expiresAt, err = narrowToKey(nil, time.Time{}, randomAPIKey(parent.Username))
+	require.NoError(t, err)
+	require.Empty(t, prefixes)
+	require.True(t, expiresAt.IsZero())
+}
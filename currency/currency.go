@@ -0,0 +1,57 @@
+package currency
+
+import (
+	"strconv"
+	"strings"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/util"
+)
+
+// Currency is an ISO 4217 currency whose amounts are stored as integers in its minor unit.
+type Currency struct {
+	Code        string `json:"code"`
+	NumericCode int32  `json:"numeric_code"`
+	Exponent    int16  `json:"exponent"`
+	Symbol      string `json:"symbol"`
+}
+
+// defaultCurrencies are served until the registry is loaded from the database
+var defaultCurrencies = []Currency{
+	{Code: util.EUR, NumericCode: 978, Exponent: 2, Symbol: "€"},
+	{Code: util.INR, NumericCode: 356, Exponent: 2, Symbol: "₹"},
+	{Code: util.USD, NumericCode: 840, Exponent: 2, Symbol: "$"},
+}
+
+func fromDB(currency db.Currency) Currency {
+	return Currency{
+		Code:        currency.Code,
+		NumericCode: currency.NumericCode,
+		Exponent:    currency.Exponent,
+		Symbol:      currency.Symbol,
+	}
+}
+
+// Format formats an amount in minor units as a decimal string, e.g. 12345 USD as "123.45"
+func (c Currency) Format(amount int64) string {
+	return FormatAmount(amount, c.Exponent)
+}
+
+// FormatAmount formats an amount in minor units as a decimal string with exponent decimal places
+func FormatAmount(amount int64, exponent int16) string {
+	digits := strconv.FormatInt(amount, 10)
+	if exponent <= 0 {
+		return digits
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	scale := int(exponent)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
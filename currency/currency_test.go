@@ -0,0 +1,26 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAmount(t *testing.T) {
+	testcases := []struct {
+		amount   int64
+		exponent int16
+		want     string
+	}{
+		{amount: 12345, exponent: 2, want: "123.45"},
+		{amount: 5, exponent: 2, want: "0.05"},
+		{amount: 0, exponent: 2, want: "0.00"},
+		{amount: -250, exponent: 2, want: "-2.50"},
+		{amount: -1, exponent: 3, want: "-0.001"},
+		{amount: 1500, exponent: 0, want: "1500"},
+	}
+
+	for _, tc := range testcases {
+		require.Equal(t, tc.want, FormatAmount(tc.amount, tc.exponent))
+	}
+}
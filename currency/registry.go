@@ -0,0 +1,113 @@
+package currency
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+)
+
+// Store is the part of the database store used to load the enabled currencies.
+type Store interface {
+	ListEnabledCurrencies(ctx context.Context) ([]db.Currency, error)
+}
+
+// Registry caches the enabled currencies of the currencies table.
+// It starts with the built in currencies and is reloaded by Refresh, so a currency enabled
+// in the database is accepted by the running servers without a redeploy.
+type Registry struct {
+	store Store
+
+	mu         sync.RWMutex
+	currencies map[string]Currency
+}
+
+// NewRegistry creates a registry which serves the built in currencies until it is refreshed
+func NewRegistry(store Store) *Registry {
+	registry := &Registry{
+		store:      store,
+		currencies: make(map[string]Currency, len(defaultCurrencies)),
+	}
+	for _, currency := range defaultCurrencies {
+		registry.currencies[currency.Code] = currency
+	}
+	return registry
+}
+
+// Refresh reloads the enabled currencies from the database
+func (r *Registry) Refresh(ctx context.Context) error {
+	rows, err := r.store.ListEnabledCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	currencies := make(map[string]Currency, len(rows))
+	for _, row := range rows {
+		currencies[row.Code] = fromDB(row)
+	}
+
+	r.mu.Lock()
+	r.currencies = currencies
+	r.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the registry every interval until the context is done
+func (r *Registry) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Println("failed to refresh the currency registry:", err)
+			}
+		}
+	}
+}
+
+// Lookup returns the currency if it is enabled
+func (r *Registry) Lookup(code string) (Currency, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	currency, ok := r.currencies[code]
+	return currency, ok
+}
+
+// IsSupported returns true if the currency is enabled
+func (r *Registry) IsSupported(code string) bool {
+	_, ok := r.Lookup(code)
+	return ok
+}
+
+// List returns the enabled currencies ordered by code
+func (r *Registry) List() []Currency {
+	r.mu.RLock()
+	currencies := make([]Currency, 0, len(r.currencies))
+	for _, currency := range r.currencies {
+		currencies = append(currencies, currency)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].Code < currencies[j].Code
+	})
+	return currencies
+}
+
+// Format formats an amount of the currency as a decimal string.
+// An amount of an unknown currency is formatted without decimal places.
+func (r *Registry) Format(amount int64, code string) string {
+	currency, ok := r.Lookup(code)
+	if !ok {
+		return FormatAmount(amount, 0)
+	}
+	return currency.Format(amount)
+}
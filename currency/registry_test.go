@@ -0,0 +1,45 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	registry := NewRegistry(store)
+
+	// the built in currencies are served before the first refresh
+	require.True(t, registry.IsSupported(util.USD))
+	require.False(t, registry.IsSupported("JPY"))
+
+	store.EXPECT().ListEnabledCurrencies(gomock.Any()).Times(1).Return([]db.Currency{
+		{Code: util.USD, NumericCode: 840, Exponent: 2, Symbol: "$", Enabled: true},
+		{Code: "JPY", NumericCode: 392, Exponent: 0, Symbol: "¥", Enabled: true},
+	}, nil)
+	require.NoError(t, registry.Refresh(context.Background()))
+
+	require.True(t, registry.IsSupported("JPY"))
+	require.False(t, registry.IsSupported(util.EUR))
+	require.Equal(t, "1500", registry.Format(1500, "JPY"))
+	require.Equal(t, "15.00", registry.Format(1500, util.USD))
+
+	currencies := registry.List()
+	require.Len(t, currencies, 2)
+	require.Equal(t, "JPY", currencies[0].Code)
+
+	// a failed refresh keeps the currencies loaded before
+	store.EXPECT().ListEnabledCurrencies(gomock.Any()).Times(1).Return(nil, errors.New("connection refused"))
+	require.Error(t, registry.Refresh(context.Background()))
+	require.True(t, registry.IsSupported("JPY"))
+}
@@ -0,0 +1,12 @@
+CREATE TABLE "idempotency_keys" (
+  "owner" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response" jsonb NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("owner", "key")
+);
+
+COMMENT ON COLUMN "idempotency_keys"."response" IS 'serialized TransferTxResult of the first request';
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
@@ -0,0 +1,3 @@
+UPDATE "entries" SET "amount" = -"amount" WHERE "transfer_id" IS NOT NULL AND "amount" < 0;
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "transfer_id";
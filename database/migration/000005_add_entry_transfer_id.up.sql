@@ -0,0 +1,32 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer which posted this leg, null for non transfer entries';
+
+-- link the legs of existing transfers, both legs were written in the same transaction as the
+-- transfer so they share its created_at, and the debit leg was written first. Each transfer takes
+-- its first candidate entry on the from account as the debit leg and its next one on the to account
+-- as the credit leg, so the two legs of a transfer to the same account are told apart.
+-- The debit leg was stored positive, so flip its sign.
+WITH "legs" AS (
+  SELECT e."id" AS "entry_id",
+         t."id" AS "transfer_id",
+         e."account_id" = t."from_account_id" AS "on_from",
+         e."account_id" = t."to_account_id" AS "on_to",
+         ROW_NUMBER() OVER (PARTITION BY t."id" ORDER BY e."id") AS "leg"
+  FROM "transfers" t
+  JOIN "entries" e
+    ON e."transfer_id" IS NULL
+   AND e."amount" = t."amount"
+   AND e."created_at" = t."created_at"
+   AND e."account_id" IN (t."from_account_id", t."to_account_id")
+)
+UPDATE "entries" e
+SET "transfer_id" = l."transfer_id",
+    "amount" = CASE WHEN l."leg" = 1 THEN -e."amount" ELSE e."amount" END
+FROM "legs" l
+WHERE e."id" = l."entry_id"
+  AND ((l."leg" = 1 AND l."on_from") OR (l."leg" = 2 AND l."on_to"));
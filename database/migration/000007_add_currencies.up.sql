@@ -0,0 +1,19 @@
+CREATE TABLE "currencies" (
+  "code" varchar(3) PRIMARY KEY,
+  "numeric_code" int UNIQUE NOT NULL,
+  "exponent" smallint NOT NULL CHECK ("exponent" BETWEEN 0 AND 4),
+  "symbol" varchar NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 alphabetic code';
+
+COMMENT ON COLUMN "currencies"."exponent" IS 'number of decimal places of the minor unit';
+
+INSERT INTO "currencies" ("code", "numeric_code", "exponent", "symbol") VALUES
+  ('USD', 840, 2, '$'),
+  ('EUR', 978, 2, '€'),
+  ('INR', 356, 2, '₹');
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
@@ -0,0 +1,31 @@
+ALTER TABLE "sessions" ADD COLUMN "family_id" uuid;
+
+UPDATE "sessions" SET "family_id" = "id";
+
+ALTER TABLE "sessions" ALTER COLUMN "family_id" SET NOT NULL;
+
+ALTER TABLE "sessions" ADD COLUMN "parent_id" uuid;
+
+ALTER TABLE "sessions" ADD COLUMN "rotated_at" timestamptz;
+
+ALTER TABLE "sessions" ADD FOREIGN KEY ("parent_id") REFERENCES "sessions" ("id");
+
+CREATE INDEX ON "sessions" ("family_id");
+
+COMMENT ON COLUMN "sessions"."family_id" IS 'id of the login session all rotated sessions descend from';
+
+COMMENT ON COLUMN "sessions"."rotated_at" IS 'set when the refresh token was exchanged for a new session';
+
+CREATE TABLE "security_events" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "session_id" uuid,
+  "user_agent" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "security_events" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE INDEX ON "security_events" ("username");
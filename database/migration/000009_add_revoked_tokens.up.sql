@@ -0,0 +1,16 @@
+CREATE TABLE "revoked_tokens" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "revoked_tokens" ("expires_at");
+
+COMMENT ON COLUMN "revoked_tokens"."id" IS 'id of the revoked access token';
+
+ALTER TABLE "sessions" ADD COLUMN "access_token_id" uuid;
+
+ALTER TABLE "sessions" ADD COLUMN "access_token_expires_at" timestamptz;
+
+COMMENT ON COLUMN "sessions"."access_token_id" IS 'access token issued along with the refresh token of the session';
@@ -0,0 +1,34 @@
+CREATE TABLE "user_totps" (
+  "username" varchar PRIMARY KEY REFERENCES "users" ("username") ON DELETE CASCADE,
+  "encrypted_secret" bytea NOT NULL,
+  "enabled_at" timestamptz,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "user_totps"."enabled_at" IS 'null until the enrollment is confirmed with a valid code';
+
+COMMENT ON COLUMN "user_totps"."last_used_step" IS 'time step of the last accepted code, a code can be used only once';
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username") ON DELETE CASCADE,
+  "code_hash" varchar NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "recovery_codes" ("username", "code_hash");
+
+CREATE TABLE "mfa_challenges" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username") ON DELETE CASCADE,
+  "attempts" int NOT NULL DEFAULT 0,
+  "expires_at" timestamptz NOT NULL,
+  "consumed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "mfa_challenges" ("expires_at");
+
+COMMENT ON TABLE "mfa_challenges" IS 'password step of a login which still needs a second factor';
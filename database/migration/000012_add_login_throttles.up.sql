@@ -0,0 +1,16 @@
+CREATE TABLE "login_throttles" (
+  "kind" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "failed_attempts" int NOT NULL DEFAULT 0,
+  "last_failed_at" timestamptz NOT NULL DEFAULT (now()),
+  "locked_until" timestamptz,
+  PRIMARY KEY ("kind", "subject")
+);
+
+ALTER TABLE "login_throttles" ADD CONSTRAINT "login_throttles_kind_check" CHECK ("kind" IN ('username', 'ip'));
+
+CREATE INDEX ON "login_throttles" ("last_failed_at");
+
+COMMENT ON TABLE "login_throttles" IS 'failed login attempts per username and per client ip, shared by all the replicas';
+
+COMMENT ON COLUMN "login_throttles"."locked_until" IS 'no login is tried for the subject before this time';
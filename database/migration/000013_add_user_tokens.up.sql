@@ -0,0 +1,19 @@
+ALTER TABLE "users" ADD COLUMN "is_email_verified" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE "user_tokens" (
+  "token_hash" varchar PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username") ON DELETE CASCADE,
+  "purpose" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "user_tokens" ADD CONSTRAINT "user_tokens_purpose_check" CHECK ("purpose" IN ('email_verification', 'password_reset'));
+
+CREATE INDEX ON "user_tokens" ("username", "purpose");
+
+COMMENT ON TABLE "user_tokens" IS 'single use tokens mailed to the users, only the hash of a token is stored';
+
+COMMENT ON COLUMN "user_tokens"."email" IS 'address the token was sent to, a verification token verifies only this address';
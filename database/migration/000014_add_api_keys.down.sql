@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "api_keys";
+
+UPDATE "users" SET "role" = 'depositor' WHERE "role" = 'service';
+
+ALTER TABLE "users" DROP CONSTRAINT "users_role_check";
+
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('depositor', 'admin'));
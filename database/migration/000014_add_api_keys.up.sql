@@ -0,0 +1,26 @@
+ALTER TABLE "users" DROP CONSTRAINT "users_role_check";
+
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('depositor', 'admin', 'service'));
+
+CREATE TABLE "api_keys" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username") ON DELETE CASCADE,
+  "name" varchar NOT NULL,
+  "prefix" varchar NOT NULL,
+  "key_hash" varchar UNIQUE NOT NULL,
+  "scopes" varchar[] NOT NULL DEFAULT '{}',
+  "allowed_ips" varchar[] NOT NULL DEFAULT '{}',
+  "expires_at" timestamptz,
+  "last_used_at" timestamptz,
+  "last_used_ip" varchar,
+  "revoked_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "api_keys" ("username");
+
+COMMENT ON TABLE "api_keys" IS 'long lived keys of machine clients, only the hash of a key is stored';
+
+COMMENT ON COLUMN "api_keys"."prefix" IS 'start of the key shown in the listings so the owner can tell the keys apart';
+
+COMMENT ON COLUMN "api_keys"."allowed_ips" IS 'addresses or CIDR ranges the key is accepted from, empty allows any address';
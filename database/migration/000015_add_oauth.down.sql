@@ -0,0 +1,9 @@
+DELETE FROM "sessions" WHERE "client_id" IS NOT NULL;
+
+ALTER TABLE "sessions" DROP COLUMN IF EXISTS "client_id";
+
+DROP TABLE IF EXISTS "oauth_authorization_codes";
+
+DROP TABLE IF EXISTS "oauth_consents";
+
+DROP TABLE IF EXISTS "oauth_clients";
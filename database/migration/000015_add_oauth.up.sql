@@ -0,0 +1,49 @@
+CREATE TABLE "oauth_clients" (
+  "id" varchar PRIMARY KEY,
+  "owner" varchar NOT NULL REFERENCES "users" ("username") ON DELETE CASCADE,
+  "name" varchar NOT NULL,
+  "secret_hash" varchar,
+  "redirect_uris" varchar[] NOT NULL DEFAULT '{}',
+  "scopes" varchar[] NOT NULL DEFAULT '{}',
+  "grant_types" varchar[] NOT NULL DEFAULT '{}',
+  "revoked_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "oauth_consents" (
+  "username" varchar NOT NULL REFERENCES "users" ("username") ON DELETE CASCADE,
+  "client_id" varchar NOT NULL REFERENCES "oauth_clients" ("id") ON DELETE CASCADE,
+  "scopes" varchar[] NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("username", "client_id")
+);
+
+CREATE TABLE "oauth_authorization_codes" (
+  "code_hash" varchar PRIMARY KEY,
+  "client_id" varchar NOT NULL REFERENCES "oauth_clients" ("id") ON DELETE CASCADE,
+  "username" varchar NOT NULL REFERENCES "users" ("username") ON DELETE CASCADE,
+  "redirect_uri" varchar NOT NULL,
+  "scopes" varchar[] NOT NULL DEFAULT '{}',
+  "code_challenge" varchar NOT NULL,
+  "code_challenge_method" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "sessions" ADD COLUMN "client_id" varchar REFERENCES "oauth_clients" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "oauth_clients" ("owner");
+
+CREATE INDEX ON "sessions" ("client_id");
+
+COMMENT ON TABLE "oauth_clients" IS 'third party apps allowed to get tokens of the users who consent';
+
+COMMENT ON COLUMN "oauth_clients"."secret_hash" IS 'hash of the secret of a confidential client, public clients have no secret and must use PKCE';
+
+COMMENT ON TABLE "oauth_consents" IS 'scopes a user granted to a client';
+
+COMMENT ON TABLE "oauth_authorization_codes" IS 'single use codes of the authorization code grant, only the hash of a code is stored';
+
+COMMENT ON COLUMN "sessions"."client_id" IS 'oauth client the refresh token was issued to, empty for the sessions of a login';
@@ -0,0 +1,5 @@
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "hold_id";
+
+DROP TABLE IF EXISTS "holds";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "available_balance";
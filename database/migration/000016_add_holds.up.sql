@@ -0,0 +1,35 @@
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint;
+
+UPDATE "accounts" SET "available_balance" = "balance";
+
+ALTER TABLE "accounts" ALTER COLUMN "available_balance" SET NOT NULL;
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance less the amount reserved by the active holds, it is what the owner can spend';
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "to_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "amount" bigint NOT NULL CHECK ("amount" > 0),
+  "captured_amount" bigint NOT NULL DEFAULT 0 CHECK ("captured_amount" >= 0 AND "captured_amount" <= "amount"),
+  "status" varchar NOT NULL DEFAULT 'authorized' CHECK ("status" IN ('authorized', 'partially_captured', 'captured', 'voided', 'expired')),
+  "expires_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "holds" ("from_account_id");
+
+CREATE INDEX ON "holds" ("to_account_id");
+
+CREATE INDEX ON "holds" ("expires_at") WHERE "status" IN ('authorized', 'partially_captured');
+
+COMMENT ON TABLE "holds" IS 'funds reserved on the from account, the ledger entries are posted only when the hold is captured';
+
+COMMENT ON COLUMN "holds"."captured_amount" IS 'sum of the captures, the rest is released when the hold is voided, expires or the last capture is final';
+
+ALTER TABLE "transfers" ADD COLUMN "hold_id" bigint REFERENCES "holds" ("id");
+
+CREATE INDEX ON "transfers" ("hold_id");
+
+COMMENT ON COLUMN "transfers"."hold_id" IS 'hold captured by this transfer, null for direct transfers';
@@ -0,0 +1,5 @@
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reversal_of";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reversed_amount";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status";
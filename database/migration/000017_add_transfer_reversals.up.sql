@@ -0,0 +1,13 @@
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'posted' CHECK ("status" IN ('posted', 'partially_reversed', 'reversed'));
+
+ALTER TABLE "transfers" ADD COLUMN "reversed_amount" bigint NOT NULL DEFAULT 0 CHECK ("reversed_amount" >= 0 AND "reversed_amount" <= "amount");
+
+ALTER TABLE "transfers" ADD COLUMN "reversal_of" bigint REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "transfers" ("reversal_of");
+
+COMMENT ON COLUMN "transfers"."status" IS 'posted until it is refunded, then partially_reversed or reversed once the whole amount is refunded';
+
+COMMENT ON COLUMN "transfers"."reversed_amount" IS 'sum of the refunds in the from account currency, it can never exceed the amount';
+
+COMMENT ON COLUMN "transfers"."reversal_of" IS 'transfer refunded by this compensating transfer, null for the other transfers';
@@ -0,0 +1,53 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL REFERENCES "users" ("username"),
+  "from_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "to_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "amount" bigint NOT NULL CHECK ("amount" > 0),
+  "currency" varchar NOT NULL,
+  "frequency" varchar NOT NULL CHECK ("frequency" IN ('once', 'daily', 'weekly', 'monthly')),
+  "interval" int NOT NULL DEFAULT 1 CHECK ("interval" > 0),
+  "day_of_month" int CHECK ("day_of_month" BETWEEN 1 AND 31),
+  "start_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "max_occurrences" int CHECK ("max_occurrences" > 0),
+  "occurrences" int NOT NULL DEFAULT 0,
+  "occurrence_at" timestamptz NOT NULL,
+  "next_run_at" timestamptz NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "claimed_until" timestamptz,
+  "status" varchar NOT NULL DEFAULT 'active' CHECK ("status" IN ('active', 'paused', 'completed', 'cancelled')),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("next_run_at") WHERE "status" = 'active';
+
+COMMENT ON TABLE "scheduled_transfers" IS 'transfers run by the scheduler once or on a recurrence, like rent every month';
+
+COMMENT ON COLUMN "scheduled_transfers"."day_of_month" IS 'day a monthly transfer runs on, the last day of the shorter months';
+
+COMMENT ON COLUMN "scheduled_transfers"."occurrences" IS 'occurrences which were run or given up after their last retry';
+
+COMMENT ON COLUMN "scheduled_transfers"."occurrence_at" IS 'occurrence which is run next, it stays the same while the occurrence is retried';
+
+COMMENT ON COLUMN "scheduled_transfers"."next_run_at" IS 'when the scheduler runs the occurrence, later than the occurrence when it is retried';
+
+COMMENT ON COLUMN "scheduled_transfers"."claimed_until" IS 'a scheduler is running the occurrence, the others skip it until then';
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL REFERENCES "scheduled_transfers" ("id"),
+  "occurrence_at" timestamptz NOT NULL,
+  "attempt" int NOT NULL,
+  "status" varchar NOT NULL CHECK ("status" IN ('succeeded', 'retrying', 'failed')),
+  "transfer_id" bigint REFERENCES "transfers" ("id"),
+  "error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."status" IS 'retrying when the occurrence is run again later, failed when it was given up';
@@ -0,0 +1,11 @@
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_available_balance_check";
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_balance_check";
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_legacy_overdraft_check";
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_overdraft_limit_check";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "legacy_overdraft";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "overdraft_limit";
@@ -0,0 +1,17 @@
+ALTER TABLE "accounts" ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD COLUMN "legacy_overdraft" bigint NOT NULL DEFAULT 0;
+
+UPDATE "accounts" SET "legacy_overdraft" = -LEAST("balance", "available_balance") WHERE LEAST("balance", "available_balance") < 0;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_overdraft_limit_check" CHECK ("overdraft_limit" >= 0);
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_legacy_overdraft_check" CHECK ("legacy_overdraft" >= 0);
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_balance_check" CHECK ("balance" >= -GREATEST("overdraft_limit", "legacy_overdraft"));
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_available_balance_check" CHECK ("available_balance" >= -GREATEST("overdraft_limit", "legacy_overdraft"));
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far below zero the balances can go';
+
+COMMENT ON COLUMN "accounts"."legacy_overdraft" IS 'the overdraft of the accounts already overdrawn before the limits were added, it only shrinks as the account is credited';
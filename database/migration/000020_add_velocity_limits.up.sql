@@ -0,0 +1,32 @@
+ALTER TABLE "users" ADD COLUMN "tier" varchar NOT NULL DEFAULT 'standard';
+
+COMMENT ON COLUMN "users"."tier" IS 'velocity limits the transfers of the user are checked against';
+
+CREATE TABLE "velocity_limits" (
+  "tier" varchar NOT NULL,
+  "currency" varchar(3) NOT NULL REFERENCES "currencies" ("code"),
+  "max_amount" bigint CHECK ("max_amount" > 0),
+  "max_daily_amount" bigint CHECK ("max_daily_amount" > 0),
+  "max_hourly_count" int CHECK ("max_hourly_count" > 0),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("tier", "currency")
+);
+
+COMMENT ON TABLE "velocity_limits" IS 'caps on the outgoing transfers of an account by the tier of its owner and its currency, a missing row or null cap is no limit';
+
+COMMENT ON COLUMN "velocity_limits"."max_amount" IS 'largest single transfer in the minor unit of the currency';
+
+COMMENT ON COLUMN "velocity_limits"."max_daily_amount" IS 'total of the transfers over the last 24 hours in the minor unit of the currency';
+
+COMMENT ON COLUMN "velocity_limits"."max_hourly_count" IS 'number of transfers over the last hour';
+
+INSERT INTO "velocity_limits" ("tier", "currency", "max_amount", "max_daily_amount", "max_hourly_count") VALUES
+  ('standard', 'USD', 500000, 1000000, 20),
+  ('standard', 'EUR', 500000, 1000000, 20),
+  ('standard', 'INR', 50000000, 100000000, 20),
+  ('premium', 'USD', 2500000, 5000000, 100),
+  ('premium', 'EUR', 2500000, 5000000, 100),
+  ('premium', 'INR', 250000000, 500000000, 100);
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "users_password_changed_at_idx";
@@ -0,0 +1 @@
+CREATE INDEX ON "users" ("password_changed_at");
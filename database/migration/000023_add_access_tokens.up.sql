@@ -0,0 +1,21 @@
+CREATE TABLE "access_tokens" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL REFERENCES "users" ("username") ON DELETE CASCADE,
+  "session_id" uuid REFERENCES "sessions" ("id") ON DELETE CASCADE,
+  "api_key_id" uuid REFERENCES "api_keys" ("id") ON DELETE CASCADE,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK (num_nonnulls("session_id", "api_key_id") = 1)
+);
+
+CREATE INDEX ON "access_tokens" ("session_id");
+
+CREATE INDEX ON "access_tokens" ("api_key_id");
+
+CREATE INDEX ON "access_tokens" ("expires_at");
+
+COMMENT ON TABLE "access_tokens" IS 'access tokens issued outside of a login, bound to the session or api key they were created under so revoking it revokes them';
+
+COMMENT ON COLUMN "access_tokens"."session_id" IS 'session of the token a delegated token was created with';
+
+COMMENT ON COLUMN "access_tokens"."api_key_id" IS 'api key a delegated token was created with';
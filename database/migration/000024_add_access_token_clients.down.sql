@@ -0,0 +1,7 @@
+DELETE FROM "access_tokens" WHERE "client_id" IS NOT NULL;
+
+ALTER TABLE "access_tokens" DROP CONSTRAINT "access_tokens_check";
+
+ALTER TABLE "access_tokens" ADD CONSTRAINT "access_tokens_check" CHECK (num_nonnulls("session_id", "api_key_id") = 1);
+
+ALTER TABLE "access_tokens" DROP COLUMN IF EXISTS "client_id";
@@ -0,0 +1,11 @@
+ALTER TABLE "access_tokens" ADD COLUMN "client_id" varchar REFERENCES "oauth_clients" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "access_tokens" DROP CONSTRAINT "access_tokens_check";
+
+ALTER TABLE "access_tokens" ADD CONSTRAINT "access_tokens_check" CHECK (num_nonnulls("session_id", "api_key_id", "client_id") = 1);
+
+CREATE INDEX ON "access_tokens" ("client_id");
+
+COMMENT ON TABLE "access_tokens" IS 'access tokens issued outside of a login, bound to the session, api key or oauth client they were issued under so revoking it revokes them';
+
+COMMENT ON COLUMN "access_tokens"."client_id" IS 'oauth client a token without a refresh token was issued to';
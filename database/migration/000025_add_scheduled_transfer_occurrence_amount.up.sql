@@ -0,0 +1,3 @@
+ALTER TABLE "scheduled_transfers" ADD COLUMN "occurrence_amount" bigint CHECK ("occurrence_amount" > 0);
+
+COMMENT ON COLUMN "scheduled_transfers"."occurrence_amount" IS 'amount of the occurrence, frozen when it is first claimed so its retries transfer the same amount';
@@ -0,0 +1 @@
+COMMENT ON TABLE "velocity_limits" IS 'caps on the outgoing transfers of an account by the tier of its owner and its currency, a missing row or null cap is no limit';
@@ -0,0 +1,34 @@
+-- name: CreateAccessToken :one
+INSERT INTO access_tokens (
+    id,
+    username,
+    session_id,
+    api_key_id,
+    client_id,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetAccessToken :one
+SELECT * FROM access_tokens
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSessionAccessTokens :many
+SELECT * FROM access_tokens
+WHERE session_id = ANY(sqlc.arg(session_ids)::uuid[])
+  AND expires_at > now();
+
+-- name: ListAPIKeyAccessTokens :many
+SELECT * FROM access_tokens
+WHERE api_key_id = $1
+  AND expires_at > now();
+
+-- name: ListClientAccessTokens :many
+SELECT * FROM access_tokens
+WHERE client_id = $1
+  AND expires_at > now();
+
+-- name: DeleteExpiredAccessTokens :execrows
+DELETE FROM access_tokens
+WHERE expires_at <= now();
\ No newline at end of file
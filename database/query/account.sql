@@ -2,9 +2,10 @@
 INSERT INTO accounts (
     owner,
     balance,
+    available_balance,
     currency
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $2, $3
 ) RETURNING *;
 
 -- name: GetAccount :one
@@ -25,15 +26,31 @@ order by id
 LIMIT $2
 OFFSET $3;
 
--- name: UpdateAccount :one
+-- name: AddAccountBalance :one
 UPDATE accounts
-SET balance=$2
-where id=$1
+SET balance = balance + sqlc.arg(amount),
+    available_balance = available_balance + sqlc.arg(amount),
+    legacy_overdraft = LEAST(legacy_overdraft, GREATEST(-LEAST(balance, available_balance) - sqlc.arg(amount), 0))
+where id = sqlc.arg(id)
 RETURNING *;
 
--- name: AddAccountBalance :one
+-- name: AddAccountAvailableBalance :one
+UPDATE accounts
+SET available_balance = available_balance + sqlc.arg(amount),
+    legacy_overdraft = LEAST(legacy_overdraft, GREATEST(-LEAST(balance, available_balance + sqlc.arg(amount)), 0))
+where id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CaptureAccountBalance :one
+UPDATE accounts
+SET balance = balance - sqlc.arg(amount),
+    legacy_overdraft = LEAST(legacy_overdraft, GREATEST(-LEAST(balance - sqlc.arg(amount), available_balance), 0))
+where id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAccountOverdraftLimit :one
 UPDATE accounts
-SET balance = balance + sqlc.arg(amount)
+SET overdraft_limit = sqlc.arg(overdraft_limit)
 where id = sqlc.arg(id)
 RETURNING *;
 
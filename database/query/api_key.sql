@@ -0,0 +1,45 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    id,
+    username,
+    name,
+    prefix,
+    key_hash,
+    scopes,
+    allowed_ips,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetAPIKey :one
+SELECT * FROM api_keys
+WHERE id = $1 LIMIT 1;
+
+-- name: GetActiveAPIKeyByHash :one
+SELECT sqlc.embed(api_keys), users.role FROM api_keys
+JOIN users ON users.username = api_keys.username
+WHERE api_keys.key_hash = $1
+  AND api_keys.revoked_at IS NULL
+  AND (api_keys.expires_at IS NULL OR api_keys.expires_at > now())
+LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+WHERE username = $1
+  AND revoked_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1
+  AND revoked_at IS NULL
+RETURNING *;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = now(),
+    last_used_ip = sqlc.arg(last_used_ip)
+WHERE id = sqlc.arg(id)
+  AND (last_used_at IS NULL OR last_used_at < sqlc.arg(used_before));
\ No newline at end of file
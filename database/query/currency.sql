@@ -0,0 +1,30 @@
+-- name: CreateCurrency :one
+INSERT INTO currencies (
+    code,
+    numeric_code,
+    exponent,
+    symbol,
+    enabled
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetCurrency :one
+SELECT * FROM currencies
+WHERE code = $1
+LIMIT 1;
+
+-- name: ListCurrencies :many
+SELECT * FROM currencies
+ORDER BY code;
+
+-- name: ListEnabledCurrencies :many
+SELECT * FROM currencies
+WHERE enabled = true
+ORDER BY code;
+
+-- name: SetCurrencyEnabled :one
+UPDATE currencies
+SET enabled = $2
+WHERE code = $1
+RETURNING *;
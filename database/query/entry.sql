@@ -1,9 +1,10 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
-    amount
+    amount,
+    transfer_id
 ) values (
-    $1, $2
+    $1, $2, $3
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -16,4 +17,35 @@ SELECT * FROM entries
 where account_id = $1
 order by id
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- name: ListTransferEntries :many
+SELECT * FROM entries
+where transfer_id = $1
+order by id;
+
+-- name: GetAccountBalanceAt :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS balance
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at < sqlc.arg(at);
+
+-- name: ListStatementEntries :many
+SELECT
+    entries.id,
+    entries.amount,
+    entries.transfer_id,
+    entries.created_at,
+    counterparty.id AS counterparty_account_id,
+    counterparty.owner AS counterparty_owner,
+    transfers.reversal_of
+FROM entries
+LEFT JOIN transfers ON transfers.id = entries.transfer_id
+LEFT JOIN accounts counterparty ON counterparty.id = CASE
+    WHEN transfers.from_account_id = entries.account_id THEN transfers.to_account_id
+    ELSE transfers.from_account_id
+END
+WHERE entries.account_id = sqlc.arg(account_id)
+  AND entries.created_at >= sqlc.arg(from_time)
+  AND entries.created_at < sqlc.arg(to_time)
+ORDER BY entries.created_at, entries.id;
\ No newline at end of file
@@ -0,0 +1,34 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+    from_account_id,
+    to_account_id,
+    amount,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: GetNextExpiredHoldForUpdate :one
+SELECT * FROM holds
+WHERE status IN ('authorized', 'partially_captured')
+  AND expires_at <= now()
+ORDER BY expires_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateHold :one
+UPDATE holds
+SET status = $2,
+    captured_amount = $3,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    owner,
+    key,
+    request_hash
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (owner, key) DO NOTHING
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE owner = $1 AND key = $2
+LIMIT 1;
+
+-- name: UpdateIdempotencyKeyResponse :exec
+UPDATE idempotency_keys
+SET response = $3
+WHERE owner = $1 AND key = $2;
@@ -0,0 +1,63 @@
+-- name: ListAccountBalanceDrifts :many
+SELECT
+    a.id,
+    a.owner,
+    a.currency,
+    a.balance,
+    CAST(COALESCE(SUM(e.amount), 0) AS bigint) AS entries_sum
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListUnbalancedTransfers :many
+SELECT
+    t.id,
+    t.from_account_id,
+    t.to_account_id,
+    t.amount,
+    t.to_amount,
+    COUNT(e.id) AS legs,
+    CAST(COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id AND e.amount < 0), 0) AS bigint) AS debited,
+    CAST(COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id AND e.amount > 0), 0) AS bigint) AS credited
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING COUNT(e.id) <> 2
+    OR COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id AND e.amount < 0), 0) <> -t.amount
+    OR COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id AND e.amount > 0), 0) <> t.to_amount
+ORDER BY t.id;
+
+-- name: ListAvailableBalanceDrifts :many
+SELECT
+    a.id,
+    a.owner,
+    a.currency,
+    a.balance,
+    a.available_balance,
+    CAST(COALESCE(SUM(h.amount - h.captured_amount), 0) AS bigint) AS held_amount
+FROM accounts a
+LEFT JOIN holds h ON h.from_account_id = a.id AND h.status IN ('authorized', 'partially_captured')
+GROUP BY a.id
+HAVING a.available_balance <> a.balance - COALESCE(SUM(h.amount - h.captured_amount), 0)
+ORDER BY a.id;
+
+-- name: ListReversalDrifts :many
+SELECT
+    t.id,
+    t.amount,
+    t.status,
+    t.reversed_amount,
+    CAST(COALESCE(SUM(r.to_amount), 0) AS bigint) AS refunded
+FROM transfers t
+LEFT JOIN transfers r ON r.reversal_of = t.id
+WHERE t.reversal_of IS NULL
+GROUP BY t.id
+HAVING t.reversed_amount <> COALESCE(SUM(r.to_amount), 0)
+    OR t.status <> CASE
+        WHEN t.reversed_amount = 0 THEN 'posted'
+        WHEN t.reversed_amount < t.amount THEN 'partially_reversed'
+        ELSE 'reversed'
+    END
+ORDER BY t.id;
\ No newline at end of file
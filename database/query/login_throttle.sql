@@ -0,0 +1,44 @@
+-- name: GetLoginThrottle :one
+SELECT * FROM login_throttles
+WHERE kind = $1 AND subject = $2 LIMIT 1;
+
+-- name: RecordLoginAttempt :one
+INSERT INTO login_throttles (
+    kind,
+    subject,
+    failed_attempts,
+    last_failed_at
+) VALUES (
+    sqlc.arg(kind), sqlc.arg(subject), 1, now()
+) ON CONFLICT (kind, subject) DO UPDATE
+SET failed_attempts = CASE
+        WHEN login_throttles.locked_until > now() THEN login_throttles.failed_attempts
+        WHEN login_throttles.last_failed_at < sqlc.arg(reset_before) THEN 1
+        ELSE login_throttles.failed_attempts + 1
+    END,
+    last_failed_at = CASE
+        WHEN login_throttles.locked_until > now() THEN login_throttles.last_failed_at
+        ELSE now()
+    END
+RETURNING *;
+
+-- name: ReleaseLoginAttempt :exec
+UPDATE login_throttles
+SET failed_attempts = GREATEST(failed_attempts - 1, 0)
+WHERE kind = $1 AND subject = $2;
+
+-- name: LockLoginThrottle :one
+UPDATE login_throttles
+SET locked_until = sqlc.arg(locked_until)
+WHERE kind = sqlc.arg(kind) AND subject = sqlc.arg(subject)
+  AND (locked_until IS NULL OR locked_until < sqlc.arg(locked_until))
+RETURNING *;
+
+-- name: DeleteLoginThrottle :execrows
+DELETE FROM login_throttles
+WHERE kind = $1 AND subject = $2;
+
+-- name: DeleteStaleLoginThrottles :execrows
+DELETE FROM login_throttles
+WHERE last_failed_at < sqlc.arg(reset_before)
+  AND (locked_until IS NULL OR locked_until < now());
\ No newline at end of file
@@ -0,0 +1,75 @@
+-- name: UpsertUserTOTP :one
+INSERT INTO user_totps (
+    username,
+    encrypted_secret
+) VALUES (
+    $1, $2
+) ON CONFLICT (username) DO UPDATE
+SET encrypted_secret = EXCLUDED.encrypted_secret,
+    last_used_step = 0,
+    created_at = now()
+WHERE user_totps.enabled_at IS NULL
+RETURNING *;
+
+-- name: GetUserTOTP :one
+SELECT * FROM user_totps
+WHERE username = $1 LIMIT 1;
+
+-- name: EnableUserTOTP :one
+UPDATE user_totps
+SET enabled_at = now()
+WHERE username = $1 AND enabled_at IS NULL
+RETURNING *;
+
+-- name: UseTOTPStep :one
+UPDATE user_totps
+SET last_used_step = sqlc.arg(step)
+WHERE username = sqlc.arg(username) AND last_used_step < sqlc.arg(step)
+RETURNING *;
+
+-- name: DeleteUserTOTP :exec
+DELETE FROM user_totps
+WHERE username = $1;
+
+-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+    username,
+    code_hash
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = now()
+WHERE username = $1 AND code_hash = $2 AND used_at IS NULL
+RETURNING *;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE username = $1;
+
+-- name: CreateMFAChallenge :one
+INSERT INTO mfa_challenges (
+    id,
+    username,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetMFAChallenge :one
+SELECT * FROM mfa_challenges
+WHERE id = $1 LIMIT 1;
+
+-- name: AttemptMFAChallenge :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: ConsumeMFAChallenge :one
+UPDATE mfa_challenges
+SET consumed_at = now()
+WHERE id = $1 AND consumed_at IS NULL
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,67 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (
+    id,
+    owner,
+    name,
+    secret_hash,
+    redirect_uris,
+    scopes,
+    grant_types
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetOAuthClient :one
+SELECT * FROM oauth_clients
+WHERE id = $1 LIMIT 1;
+
+-- name: ListOAuthClients :many
+SELECT * FROM oauth_clients
+WHERE owner = $1
+  AND revoked_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: RevokeOAuthClient :one
+UPDATE oauth_clients
+SET revoked_at = now()
+WHERE id = $1
+  AND revoked_at IS NULL
+RETURNING *;
+
+-- name: GetOAuthConsent :one
+SELECT * FROM oauth_consents
+WHERE username = $1 AND client_id = $2 LIMIT 1;
+
+-- name: UpsertOAuthConsent :one
+INSERT INTO oauth_consents (
+    username,
+    client_id,
+    scopes
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (username, client_id) DO UPDATE
+SET scopes = EXCLUDED.scopes,
+    updated_at = now()
+RETURNING *;
+
+-- name: CreateOAuthAuthorizationCode :one
+INSERT INTO oauth_authorization_codes (
+    code_hash,
+    client_id,
+    username,
+    redirect_uri,
+    scopes,
+    code_challenge,
+    code_challenge_method,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: UseOAuthAuthorizationCode :one
+UPDATE oauth_authorization_codes
+SET used_at = now()
+WHERE code_hash = $1
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
\ No newline at end of file
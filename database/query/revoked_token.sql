@@ -0,0 +1,17 @@
+-- name: RevokeToken :exec
+INSERT INTO revoked_tokens (
+    id,
+    username,
+    expires_at
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: ListRevokedTokens :many
+SELECT * FROM revoked_tokens
+WHERE expires_at > now();
+
+-- name: DeleteExpiredRevokedTokens :execrows
+DELETE FROM revoked_tokens
+WHERE expires_at <= now();
\ No newline at end of file
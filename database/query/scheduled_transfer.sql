@@ -0,0 +1,93 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+    owner,
+    from_account_id,
+    to_account_id,
+    amount,
+    currency,
+    frequency,
+    interval,
+    day_of_month,
+    start_at,
+    end_at,
+    max_occurrences,
+    occurrence_at,
+    next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET amount = $2,
+    end_at = $3,
+    max_occurrences = $4,
+    status = $5,
+    updated_at = now()
+WHERE id = $1 AND status IN ('active', 'paused')
+RETURNING *;
+
+-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers
+SET status = 'cancelled',
+    updated_at = now()
+WHERE id = $1 AND status IN ('active', 'paused')
+RETURNING *;
+
+-- name: ClaimDueScheduledTransfers :many
+UPDATE scheduled_transfers
+SET claimed_until = sqlc.arg(claimed_until)::timestamptz,
+    occurrence_amount = COALESCE(occurrence_amount, amount)
+WHERE id IN (
+    SELECT id FROM scheduled_transfers
+    WHERE status = 'active'
+      AND next_run_at <= now()
+      AND (claimed_until IS NULL OR claimed_until < now())
+    ORDER BY next_run_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: AdvanceScheduledTransfer :one
+UPDATE scheduled_transfers
+SET occurrences = sqlc.arg(occurrences),
+    occurrence_amount = CASE WHEN occurrence_at = sqlc.arg(occurrence_at) THEN occurrence_amount END,
+    occurrence_at = sqlc.arg(occurrence_at),
+    next_run_at = sqlc.arg(next_run_at),
+    attempts = sqlc.arg(attempts),
+    status = CASE WHEN status = 'active' THEN sqlc.arg(status)::varchar ELSE status END,
+    claimed_until = NULL,
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+    scheduled_transfer_id,
+    occurrence_at,
+    attempt,
+    status,
+    transfer_id,
+    error
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
\ No newline at end of file
@@ -2,9 +2,14 @@
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    to_amount,
+    exchange_rate,
+    quote_id,
+    hold_id,
+    reversal_of
 ) values (
-    $1, $2, $3
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetTransfer :one
@@ -12,6 +17,24 @@ SELECT * FROM transfers
 where id = $1
 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+where id = $1
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListTransferReversals :many
+SELECT * FROM transfers
+where reversal_of = $1
+ORDER BY id;
+
+-- name: UpdateTransferReversal :one
+UPDATE transfers
+SET reversed_amount = $2,
+    status = $3
+where id = $1
+RETURNING *;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
 where   
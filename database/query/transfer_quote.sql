@@ -0,0 +1,26 @@
+-- name: CreateTransferQuote :one
+INSERT INTO transfer_quotes (
+    id,
+    owner,
+    from_account_id,
+    to_account_id,
+    from_currency,
+    to_currency,
+    rate,
+    from_amount,
+    to_amount,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: GetTransferQuote :one
+SELECT * FROM transfer_quotes
+WHERE id = $1
+LIMIT 1;
+
+-- name: UseTransferQuote :one
+UPDATE transfer_quotes
+SET used_at = now()
+WHERE id = $1 AND used_at IS NULL AND expires_at > now()
+RETURNING *;
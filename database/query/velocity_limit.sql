@@ -0,0 +1,40 @@
+-- name: GetAccountVelocityLimit :one
+SELECT velocity_limits.* FROM velocity_limits
+JOIN users ON users.tier = velocity_limits.tier
+JOIN accounts ON accounts.owner = users.username AND accounts.currency = velocity_limits.currency
+WHERE accounts.id = $1
+LIMIT 1;
+
+-- name: UpsertVelocityLimit :one
+INSERT INTO velocity_limits (
+    tier,
+    currency,
+    max_amount,
+    max_daily_amount,
+    max_hourly_count
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (tier, currency) DO UPDATE
+SET max_amount = EXCLUDED.max_amount,
+    max_daily_amount = EXCLUDED.max_daily_amount,
+    max_hourly_count = EXCLUDED.max_hourly_count,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListVelocityLimits :many
+SELECT * FROM velocity_limits
+ORDER BY tier, currency;
+
+-- name: DeleteVelocityLimit :one
+DELETE FROM velocity_limits
+WHERE tier = $1 AND currency = $2
+RETURNING *;
+
+-- name: GetTransferVelocity :one
+SELECT
+    COALESCE(SUM(amount), 0)::bigint AS daily_amount,
+    COUNT(*) FILTER (WHERE created_at > now() - interval '1 hour') AS hourly_count
+FROM transfers
+WHERE from_account_id = $1
+  AND reversal_of IS NULL
+  AND created_at > now() - interval '1 day';
\ No newline at end of file
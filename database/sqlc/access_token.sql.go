@@ -0,0 +1,203 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: access_token.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createAccessToken = `-- name: CreateAccessToken :one
+INSERT INTO access_tokens (
+    id,
+    username,
+    session_id,
+    api_key_id,
+    client_id,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, username, session_id, api_key_id, expires_at, created_at, client_id
+`
+
+type CreateAccessTokenParams struct {
+	ID        uuid.UUID      `json:"id"`
+	Username  string         `json:"username"`
+	SessionID uuid.NullUUID  `json:"session_id"`
+	ApiKeyID  uuid.NullUUID  `json:"api_key_id"`
+	ClientID  sql.NullString `json:"client_id"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+func (q *Queries) CreateAccessToken(ctx context.Context, arg CreateAccessTokenParams) (AccessToken, error) {
+	row := q.queryRow(ctx, q.createAccessTokenStmt, createAccessToken,
+		arg.ID,
+		arg.Username,
+		arg.SessionID,
+		arg.ApiKeyID,
+		arg.ClientID,
+		arg.ExpiresAt,
+	)
+	var i AccessToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.SessionID,
+		&i.ApiKeyID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.ClientID,
+	)
+	return i, err
+}
+
+const deleteExpiredAccessTokens = `-- name: DeleteExpiredAccessTokens :execrows
+DELETE FROM access_tokens
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredAccessTokens(ctx context.Context) (int64, error) {
+	result, err := q.exec(ctx, q.deleteExpiredAccessTokensStmt, deleteExpiredAccessTokens)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getAccessToken = `-- name: GetAccessToken :one
+SELECT id, username, session_id, api_key_id, expires_at, created_at, client_id FROM access_tokens
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccessToken(ctx context.Context, id uuid.UUID) (AccessToken, error) {
+	row := q.queryRow(ctx, q.getAccessTokenStmt, getAccessToken, id)
+	var i AccessToken
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.SessionID,
+		&i.ApiKeyID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.ClientID,
+	)
+	return i, err
+}
+
+const listAPIKeyAccessTokens = `-- name: ListAPIKeyAccessTokens :many
+SELECT id, username, session_id, api_key_id, expires_at, created_at, client_id FROM access_tokens
+WHERE api_key_id = $1
+  AND expires_at > now()
+`
+
+func (q *Queries) ListAPIKeyAccessTokens(ctx context.Context, apiKeyID uuid.NullUUID) ([]AccessToken, error) {
+	rows, err := q.query(ctx, q.listAPIKeyAccessTokensStmt, listAPIKeyAccessTokens, apiKeyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccessToken{}
+	for rows.Next() {
+		var i AccessToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.SessionID,
+			&i.ApiKeyID,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.ClientID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listClientAccessTokens = `-- name: ListClientAccessTokens :many
+SELECT id, username, session_id, api_key_id, expires_at, created_at, client_id FROM access_tokens
+WHERE client_id = $1
+  AND expires_at > now()
+`
+
+func (q *Queries) ListClientAccessTokens(ctx context.Context, clientID sql.NullString) ([]AccessToken, error) {
+	rows, err := q.query(ctx, q.listClientAccessTokensStmt, listClientAccessTokens, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccessToken{}
+	for rows.Next() {
+		var i AccessToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.SessionID,
+			&i.ApiKeyID,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.ClientID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionAccessTokens = `-- name: ListSessionAccessTokens :many
+SELECT id, username, session_id, api_key_id, expires_at, created_at, client_id FROM access_tokens
+WHERE session_id = ANY($1::uuid[])
+  AND expires_at > now()
+`
+
+func (q *Queries) ListSessionAccessTokens(ctx context.Context, sessionIds []uuid.UUID) ([]AccessToken, error) {
+	rows, err := q.query(ctx, q.listSessionAccessTokensStmt, listSessionAccessTokens, pq.Array(sessionIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccessToken{}
+	for rows.Next() {
+		var i AccessToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.SessionID,
+			&i.ApiKeyID,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.ClientID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
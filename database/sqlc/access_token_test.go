@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionAccessTokens(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username)
+	apiKey := createRandomAPIKey(t, user.Username, sql.NullTime{})
+
+	delegated, err := testQueries.CreateAccessToken(context.Background(), CreateAccessTokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		SessionID: uuid.NullUUID{UUID: session.ID, Valid: true},
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	expired, err := testQueries.CreateAccessToken(context.Background(), CreateAccessTokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		SessionID: uuid.NullUUID{UUID: session.ID, Valid: true},
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	withKey, err := testQueries.CreateAccessToken(context.Background(), CreateAccessTokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ApiKeyID:  uuid.NullUUID{UUID: apiKey.ID, Valid: true},
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	// 1. a token is bound to exactly one session or api key
+	_, err = testQueries.CreateAccessToken(context.Background(), CreateAccessTokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.Error(t, err)
+
+	// 2. only the unexpired tokens are listed
+	tokens, err := testQueries.ListSessionAccessTokens(context.Background(), []uuid.UUID{session.ID})
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, delegated.ID, tokens[0].ID)
+
+	tokens, err = testQueries.ListAPIKeyAccessTokens(context.Background(), uuid.NullUUID{UUID: apiKey.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, withKey.ID, tokens[0].ID)
+
+	// 3. a session is found by its own access token, not by a delegated one
+	_, err = testQueries.GetSessionByAccessToken(context.Background(), uuid.NullUUID{UUID: delegated.ID, Valid: true})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	deleted, err := testQueries.DeleteExpiredAccessTokens(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	_, err = testQueries.GetAccessToken(context.Background(), expired.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestClientAccessTokens(t *testing.T) {
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, user.Username)
+	clientID := sql.NullString{String: client.ID, Valid: true}
+
+	issued, err := testQueries.CreateAccessToken(context.Background(), CreateAccessTokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ClientID:  clientID,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	tokens, err := testQueries.ListClientAccessTokens(context.Background(), clientID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, issued.ID, tokens[0].ID)
+
+	// a token is bound to the client only, not to a session as well
+	session := createRandomSession(t, user.Username)
+	_, err = testQueries.CreateAccessToken(context.Background(), CreateAccessTokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		SessionID: uuid.NullUUID{UUID: session.ID, Valid: true},
+		ClientID:  clientID,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.Error(t, err)
+}
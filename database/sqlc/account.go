@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+)
+
+// UpdateAccountBalanceTxParams contains the balance an admin sets on the account
+type UpdateAccountBalanceTxParams struct {
+	ID      int64 `json:"id"`
+	Balance int64 `json:"balance"`
+}
+
+// UpdateAccountBalanceTxResult is the updated account and the adjustment entry, the entry is empty if the balance didn't change
+type UpdateAccountBalanceTxResult struct {
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// UpdateAccountBalanceTx sets the balance of the account by posting an adjustment entry for the difference,
+// so the balance stays equal to the sum of the entries. It fails with ErrInsufficientFunds if the balance
+// would go past the overdraft limit of the account.
+func (s *SQLStore) UpdateAccountBalanceTx(ctx context.Context, arg UpdateAccountBalanceTxParams) (UpdateAccountBalanceTxResult, error) {
+	var result UpdateAccountBalanceTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		// 1. lock the account so a concurrent transfer can't change the balance the difference is computed from
+		account, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		amount := arg.Balance - account.Balance
+		if amount == 0 {
+			result.Account = account
+			return nil
+		}
+
+		// 2. post the difference as an entry which is not part of a transfer
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: arg.ID,
+			Amount:    amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			Amount: amount,
+			ID:     arg.ID,
+		})
+		return fundsError(err)
+	})
+
+	return result, err
+}
@@ -9,11 +9,42 @@ import (
 	"context"
 )
 
+const addAccountAvailableBalance = `-- name: AddAccountAvailableBalance :one
+UPDATE accounts
+SET available_balance = available_balance + $1,
+    legacy_overdraft = LEAST(legacy_overdraft, GREATEST(-LEAST(balance, available_balance + $1), 0))
+where id = $2
+RETURNING id, owner, balance, currency, created_at, available_balance, overdraft_limit, legacy_overdraft
+`
+
+type AddAccountAvailableBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error) {
+	row := q.queryRow(ctx, q.addAccountAvailableBalanceStmt, addAccountAvailableBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.LegacyOverdraft,
+	)
+	return i, err
+}
+
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
-SET balance = balance + $1
+SET balance = balance + $1,
+    available_balance = available_balance + $1,
+    legacy_overdraft = LEAST(legacy_overdraft, GREATEST(-LEAST(balance, available_balance) - $1, 0))
 where id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, available_balance, overdraft_limit, legacy_overdraft
 `
 
 type AddAccountBalanceParams struct {
@@ -30,6 +61,38 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.LegacyOverdraft,
+	)
+	return i, err
+}
+
+const captureAccountBalance = `-- name: CaptureAccountBalance :one
+UPDATE accounts
+SET balance = balance - $1,
+    legacy_overdraft = LEAST(legacy_overdraft, GREATEST(-LEAST(balance - $1, available_balance), 0))
+where id = $2
+RETURNING id, owner, balance, currency, created_at, available_balance, overdraft_limit, legacy_overdraft
+`
+
+type CaptureAccountBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) CaptureAccountBalance(ctx context.Context, arg CaptureAccountBalanceParams) (Account, error) {
+	row := q.queryRow(ctx, q.captureAccountBalanceStmt, captureAccountBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.LegacyOverdraft,
 	)
 	return i, err
 }
@@ -38,10 +101,11 @@ const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
     owner,
     balance,
+    available_balance,
     currency
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+    $1, $2, $2, $3
+) RETURNING id, owner, balance, currency, created_at, available_balance, overdraft_limit, legacy_overdraft
 `
 
 type CreateAccountParams struct {
@@ -59,6 +123,9 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.LegacyOverdraft,
 	)
 	return i, err
 }
@@ -74,7 +141,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, available_balance, overdraft_limit, legacy_overdraft FROM accounts
 where id=$1
 LIMIT 1
 `
@@ -88,12 +155,15 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.LegacyOverdraft,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, available_balance, overdraft_limit, legacy_overdraft FROM accounts
 where id = $1
 LIMIT 1
 FOR NO KEY UPDATE
@@ -108,12 +178,15 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.LegacyOverdraft,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, available_balance, overdraft_limit, legacy_overdraft FROM accounts
 where owner = $1
 order by id
 LIMIT $2
@@ -141,6 +214,9 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.AvailableBalance,
+			&i.OverdraftLimit,
+			&i.LegacyOverdraft,
 		); err != nil {
 			return nil, err
 		}
@@ -155,20 +231,20 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
-const updateAccount = `-- name: UpdateAccount :one
+const updateAccountOverdraftLimit = `-- name: UpdateAccountOverdraftLimit :one
 UPDATE accounts
-SET balance=$2
-where id=$1
-RETURNING id, owner, balance, currency, created_at
+SET overdraft_limit = $1
+where id = $2
+RETURNING id, owner, balance, currency, created_at, available_balance, overdraft_limit, legacy_overdraft
 `
 
-type UpdateAccountParams struct {
-	ID      int64 `json:"id"`
-	Balance int64 `json:"balance"`
+type UpdateAccountOverdraftLimitParams struct {
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	ID             int64 `json:"id"`
 }
 
-func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.queryRow(ctx, q.updateAccountStmt, updateAccount, arg.ID, arg.Balance)
+func (q *Queries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	row := q.queryRow(ctx, q.updateAccountOverdraftLimitStmt, updateAccountOverdraftLimit, arg.OverdraftLimit, arg.ID)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -176,6 +252,9 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.OverdraftLimit,
+		&i.LegacyOverdraft,
 	)
 	return i, err
 }
@@ -59,24 +59,39 @@ func TestGetAccount(t *testing.T) {
 }
 
 func TestUpdateAccount(t *testing.T) {
+	store := NewStore(testDB)
 
 	// 1. Create an account
-	account1 := createRandomAccount(t)
+	account1 := createFundedAccount(t, 100)
 
 	// 2. Update the balance of the created account
-	args := UpdateAccountParams{
+	args := UpdateAccountBalanceTxParams{
 		ID:      account1.ID,
-		Balance: util.RandomBalance(),
+		Balance: 250,
 	}
-	account2, err := testQueries.UpdateAccount(context.Background(), args)
+	result, err := store.UpdateAccountBalanceTx(context.Background(), args)
 
 	// 3. check error for not nil and other properties
 	require.NoError(t, err)
+	account2 := result.Account
 	require.NotEmpty(t, account2)
 	require.Equal(t, account1.ID, account2.ID)
 	require.Equal(t, args.Balance, account2.Balance)
+	require.Equal(t, args.Balance, account2.AvailableBalance)
 	require.Equal(t, account1.Currency, account2.Currency)
 	require.Equal(t, account1.Owner, account2.Owner)
+
+	// 4. the difference is posted as an entry, so the balance is still the sum of the entries
+	require.Equal(t, int64(150), result.Entry.Amount)
+	require.False(t, result.Entry.TransferID.Valid)
+
+	report, err := store.VerifyLedger(context.Background())
+	require.NoError(t, err)
+	require.NotContains(t, driftAccountIDs(report), account1.ID)
+
+	// 5. the balance can't go past the overdraft limit
+	_, err = store.UpdateAccountBalanceTx(context.Background(), UpdateAccountBalanceTxParams{ID: account1.ID, Balance: -1})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
 }
 
 func TestDeleteAccount(t *testing.T) {
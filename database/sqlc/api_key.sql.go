@@ -0,0 +1,219 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: api_key.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    id,
+    username,
+    name,
+    prefix,
+    key_hash,
+    scopes,
+    allowed_ips,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, username, name, prefix, key_hash, scopes, allowed_ips, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+`
+
+type CreateAPIKeyParams struct {
+	ID         uuid.UUID    `json:"id"`
+	Username   string       `json:"username"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    string       `json:"key_hash"`
+	Scopes     []string     `json:"scopes"`
+	AllowedIps []string     `json:"allowed_ips"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	row := q.queryRow(ctx, q.createAPIKeyStmt, createAPIKey,
+		arg.ID,
+		arg.Username,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		pq.Array(arg.Scopes),
+		pq.Array(arg.AllowedIps),
+		arg.ExpiresAt,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.AllowedIps),
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAPIKey = `-- name: GetAPIKey :one
+SELECT id, username, name, prefix, key_hash, scopes, allowed_ips, expires_at, last_used_at, last_used_ip, revoked_at, created_at FROM api_keys
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAPIKey(ctx context.Context, id uuid.UUID) (ApiKey, error) {
+	row := q.queryRow(ctx, q.getAPIKeyStmt, getAPIKey, id)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.AllowedIps),
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getActiveAPIKeyByHash = `-- name: GetActiveAPIKeyByHash :one
+SELECT api_keys.id, api_keys.username, api_keys.name, api_keys.prefix, api_keys.key_hash, api_keys.scopes, api_keys.allowed_ips, api_keys.expires_at, api_keys.last_used_at, api_keys.last_used_ip, api_keys.revoked_at, api_keys.created_at, users.role FROM api_keys
+JOIN users ON users.username = api_keys.username
+WHERE api_keys.key_hash = $1
+  AND api_keys.revoked_at IS NULL
+  AND (api_keys.expires_at IS NULL OR api_keys.expires_at > now())
+LIMIT 1
+`
+
+type GetActiveAPIKeyByHashRow struct {
+	ApiKey ApiKey `json:"api_key"`
+	Role   string `json:"role"`
+}
+
+func (q *Queries) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (GetActiveAPIKeyByHashRow, error) {
+	row := q.queryRow(ctx, q.getActiveAPIKeyByHashStmt, getActiveAPIKeyByHash, keyHash)
+	var i GetActiveAPIKeyByHashRow
+	err := row.Scan(
+		&i.ApiKey.ID,
+		&i.ApiKey.Username,
+		&i.ApiKey.Name,
+		&i.ApiKey.Prefix,
+		&i.ApiKey.KeyHash,
+		pq.Array(&i.ApiKey.Scopes),
+		pq.Array(&i.ApiKey.AllowedIps),
+		&i.ApiKey.ExpiresAt,
+		&i.ApiKey.LastUsedAt,
+		&i.ApiKey.LastUsedIp,
+		&i.ApiKey.RevokedAt,
+		&i.ApiKey.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const listAPIKeys = `-- name: ListAPIKeys :many
+SELECT id, username, name, prefix, key_hash, scopes, allowed_ips, expires_at, last_used_at, last_used_ip, revoked_at, created_at FROM api_keys
+WHERE username = $1
+  AND revoked_at IS NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListAPIKeys(ctx context.Context, username string) ([]ApiKey, error) {
+	rows, err := q.query(ctx, q.listAPIKeysStmt, listAPIKeys, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApiKey{}
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			pq.Array(&i.Scopes),
+			pq.Array(&i.AllowedIps),
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.LastUsedIp,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1
+  AND revoked_at IS NULL
+RETURNING id, username, name, prefix, key_hash, scopes, allowed_ips, expires_at, last_used_at, last_used_ip, revoked_at, created_at
+`
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, id uuid.UUID) (ApiKey, error) {
+	row := q.queryRow(ctx, q.revokeAPIKeyStmt, revokeAPIKey, id)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.AllowedIps),
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const touchAPIKey = `-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = now(),
+    last_used_ip = $1
+WHERE id = $2
+  AND (last_used_at IS NULL OR last_used_at < $3)
+`
+
+type TouchAPIKeyParams struct {
+	LastUsedIp sql.NullString `json:"last_used_ip"`
+	ID         uuid.UUID      `json:"id"`
+	UsedBefore sql.NullTime   `json:"used_before"`
+}
+
+func (q *Queries) TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error {
+	_, err := q.exec(ctx, q.touchAPIKeyStmt, touchAPIKey, arg.LastUsedIp, arg.ID, arg.UsedBefore)
+	return err
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAPIKey(t *testing.T, username string, expiresAt sql.NullTime) ApiKey {
+	arg := CreateAPIKeyParams{
+		ID:         uuid.New(),
+		Username:   username,
+		Name:       util.RandomOwner(),
+		Prefix:     "sbk_" + util.RandomString(8),
+		KeyHash:    util.RandomString(64),
+		Scopes:     []string{"accounts:read"},
+		AllowedIps: []string{"10.0.0.0/8"},
+		ExpiresAt:  expiresAt,
+	}
+
+	apiKey, err := testQueries.CreateAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Scopes, apiKey.Scopes)
+	require.Equal(t, arg.AllowedIps, apiKey.AllowedIps)
+	require.False(t, apiKey.LastUsedAt.Valid)
+	require.False(t, apiKey.RevokedAt.Valid)
+	return apiKey
+}
+
+func TestGetActiveAPIKeyByHash(t *testing.T) {
+	user := createRandomUser(t)
+	apiKey := createRandomAPIKey(t, user.Username, sql.NullTime{})
+	expired := createRandomAPIKey(t, user.Username, sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true})
+
+	// 1. the key comes with the role of its owner
+	row, err := testQueries.GetActiveAPIKeyByHash(context.Background(), apiKey.KeyHash)
+	require.NoError(t, err)
+	require.Equal(t, apiKey.ID, row.ApiKey.ID)
+	require.Equal(t, user.Role, row.Role)
+
+	// 2. expired and revoked keys are not active
+	_, err = testQueries.GetActiveAPIKeyByHash(context.Background(), expired.KeyHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	revoked, err := testQueries.RevokeAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+
+	_, err = testQueries.GetActiveAPIKeyByHash(context.Background(), apiKey.KeyHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.RevokeAPIKey(context.Background(), apiKey.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// 3. the listing leaves out the revoked keys
+	apiKeys, err := testQueries.ListAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, apiKeys, 1)
+	require.Equal(t, expired.ID, apiKeys[0].ID)
+}
+
+func TestTouchAPIKey(t *testing.T) {
+	user := createRandomUser(t)
+	apiKey := createRandomAPIKey(t, user.Username, sql.NullTime{})
+
+	arg := TouchAPIKeyParams{
+		ID:         apiKey.ID,
+		LastUsedIp: sql.NullString{String: "10.1.2.3", Valid: true},
+		UsedBefore: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+	}
+
+	// 1. the first use is recorded
+	err := testQueries.TouchAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	touched, err := testQueries.GetAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.True(t, touched.LastUsedAt.Valid)
+	require.Equal(t, "10.1.2.3", touched.LastUsedIp.String)
+
+	// 2. a use within the interval is not written again
+	arg.LastUsedIp.String = "10.3.2.1"
+	err = testQueries.TouchAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	touched, err = testQueries.GetAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.Equal(t, "10.1.2.3", touched.LastUsedIp.String)
+}
+
+func TestServiceRole(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	user2, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Username: user1.Username,
+		Role:     util.ServiceRole,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.ServiceRole, user2.Role)
+}
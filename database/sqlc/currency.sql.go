@@ -0,0 +1,167 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: currency.sql
+
+package database
+
+import (
+	"context"
+)
+
+const createCurrency = `-- name: CreateCurrency :one
+INSERT INTO currencies (
+    code,
+    numeric_code,
+    exponent,
+    symbol,
+    enabled
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING code, numeric_code, exponent, symbol, enabled, created_at
+`
+
+type CreateCurrencyParams struct {
+	Code        string `json:"code"`
+	NumericCode int32  `json:"numeric_code"`
+	Exponent    int16  `json:"exponent"`
+	Symbol      string `json:"symbol"`
+	Enabled     bool   `json:"enabled"`
+}
+
+func (q *Queries) CreateCurrency(ctx context.Context, arg CreateCurrencyParams) (Currency, error) {
+	row := q.queryRow(ctx, q.createCurrencyStmt, createCurrency,
+		arg.Code,
+		arg.NumericCode,
+		arg.Exponent,
+		arg.Symbol,
+		arg.Enabled,
+	)
+	var i Currency
+	err := row.Scan(
+		&i.Code,
+		&i.NumericCode,
+		&i.Exponent,
+		&i.Symbol,
+		&i.Enabled,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCurrency = `-- name: GetCurrency :one
+SELECT code, numeric_code, exponent, symbol, enabled, created_at FROM currencies
+WHERE code = $1
+LIMIT 1
+`
+
+func (q *Queries) GetCurrency(ctx context.Context, code string) (Currency, error) {
+	row := q.queryRow(ctx, q.getCurrencyStmt, getCurrency, code)
+	var i Currency
+	err := row.Scan(
+		&i.Code,
+		&i.NumericCode,
+		&i.Exponent,
+		&i.Symbol,
+		&i.Enabled,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listCurrencies = `-- name: ListCurrencies :many
+SELECT code, numeric_code, exponent, symbol, enabled, created_at FROM currencies
+ORDER BY code
+`
+
+func (q *Queries) ListCurrencies(ctx context.Context) ([]Currency, error) {
+	rows, err := q.query(ctx, q.listCurrenciesStmt, listCurrencies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Currency{}
+	for rows.Next() {
+		var i Currency
+		if err := rows.Scan(
+			&i.Code,
+			&i.NumericCode,
+			&i.Exponent,
+			&i.Symbol,
+			&i.Enabled,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEnabledCurrencies = `-- name: ListEnabledCurrencies :many
+SELECT code, numeric_code, exponent, symbol, enabled, created_at FROM currencies
+WHERE enabled = true
+ORDER BY code
+`
+
+func (q *Queries) ListEnabledCurrencies(ctx context.Context) ([]Currency, error) {
+	rows, err := q.query(ctx, q.listEnabledCurrenciesStmt, listEnabledCurrencies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Currency{}
+	for rows.Next() {
+		var i Currency
+		if err := rows.Scan(
+			&i.Code,
+			&i.NumericCode,
+			&i.Exponent,
+			&i.Symbol,
+			&i.Enabled,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setCurrencyEnabled = `-- name: SetCurrencyEnabled :one
+UPDATE currencies
+SET enabled = $2
+WHERE code = $1
+RETURNING code, numeric_code, exponent, symbol, enabled, created_at
+`
+
+type SetCurrencyEnabledParams struct {
+	Code    string `json:"code"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (q *Queries) SetCurrencyEnabled(ctx context.Context, arg SetCurrencyEnabledParams) (Currency, error) {
+	row := q.queryRow(ctx, q.setCurrencyEnabledStmt, setCurrencyEnabled, arg.Code, arg.Enabled)
+	var i Currency
+	err := row.Scan(
+		&i.Code,
+		&i.NumericCode,
+		&i.Exponent,
+		&i.Symbol,
+		&i.Enabled,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomCurrency(t *testing.T) Currency {
+	args := CreateCurrencyParams{
+		Code:        strings.ToUpper(util.RandomString(3)),
+		NumericCode: int32(util.RandomInt(1000, 999999)),
+		Exponent:    int16(util.RandomInt(0, 3)),
+		Symbol:      util.RandomString(1),
+		Enabled:     true,
+	}
+
+	currency, err := testQueries.CreateCurrency(context.Background(), args)
+	require.NoError(t, err)
+	require.Equal(t, args.Code, currency.Code)
+	require.Equal(t, args.NumericCode, currency.NumericCode)
+	require.Equal(t, args.Exponent, currency.Exponent)
+	require.Equal(t, args.Symbol, currency.Symbol)
+	require.True(t, currency.Enabled)
+	require.NotZero(t, currency.CreatedAt)
+
+	return currency
+}
+
+func TestSetCurrencyEnabled(t *testing.T) {
+	currency := createRandomCurrency(t)
+
+	disabled, err := testQueries.SetCurrencyEnabled(context.Background(), SetCurrencyEnabledParams{
+		Code:    currency.Code,
+		Enabled: false,
+	})
+	require.NoError(t, err)
+	require.False(t, disabled.Enabled)
+
+	enabled, err := testQueries.ListEnabledCurrencies(context.Background())
+	require.NoError(t, err)
+	for _, c := range enabled {
+		require.NotEqual(t, currency.Code, c.Code)
+	}
+
+	// the built in currencies are seeded by the migration
+	builtIn, err := testQueries.GetCurrency(context.Background(), util.USD)
+	require.NoError(t, err)
+	require.Equal(t, int16(2), builtIn.Exponent)
+}
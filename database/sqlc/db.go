@@ -24,77 +24,510 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.addAccountAvailableBalanceStmt, err = db.PrepareContext(ctx, addAccountAvailableBalance); err != nil {
+		return nil, fmt.Errorf("error preparing query AddAccountAvailableBalance: %w", err)
+	}
 	if q.addAccountBalanceStmt, err = db.PrepareContext(ctx, addAccountBalance); err != nil {
 		return nil, fmt.Errorf("error preparing query AddAccountBalance: %w", err)
 	}
+	if q.advanceScheduledTransferStmt, err = db.PrepareContext(ctx, advanceScheduledTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query AdvanceScheduledTransfer: %w", err)
+	}
+	if q.attemptMFAChallengeStmt, err = db.PrepareContext(ctx, attemptMFAChallenge); err != nil {
+		return nil, fmt.Errorf("error preparing query AttemptMFAChallenge: %w", err)
+	}
+	if q.blockClientSessionsStmt, err = db.PrepareContext(ctx, blockClientSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query BlockClientSessions: %w", err)
+	}
+	if q.blockSessionStmt, err = db.PrepareContext(ctx, blockSession); err != nil {
+		return nil, fmt.Errorf("error preparing query BlockSession: %w", err)
+	}
+	if q.blockSessionFamilyStmt, err = db.PrepareContext(ctx, blockSessionFamily); err != nil {
+		return nil, fmt.Errorf("error preparing query BlockSessionFamily: %w", err)
+	}
+	if q.blockUserSessionsStmt, err = db.PrepareContext(ctx, blockUserSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query BlockUserSessions: %w", err)
+	}
+	if q.cancelScheduledTransferStmt, err = db.PrepareContext(ctx, cancelScheduledTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query CancelScheduledTransfer: %w", err)
+	}
+	if q.captureAccountBalanceStmt, err = db.PrepareContext(ctx, captureAccountBalance); err != nil {
+		return nil, fmt.Errorf("error preparing query CaptureAccountBalance: %w", err)
+	}
+	if q.claimDueScheduledTransfersStmt, err = db.PrepareContext(ctx, claimDueScheduledTransfers); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimDueScheduledTransfers: %w", err)
+	}
+	if q.consumeMFAChallengeStmt, err = db.PrepareContext(ctx, consumeMFAChallenge); err != nil {
+		return nil, fmt.Errorf("error preparing query ConsumeMFAChallenge: %w", err)
+	}
+	if q.createAPIKeyStmt, err = db.PrepareContext(ctx, createAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAPIKey: %w", err)
+	}
+	if q.createAccessTokenStmt, err = db.PrepareContext(ctx, createAccessToken); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAccessToken: %w", err)
+	}
 	if q.createAccountStmt, err = db.PrepareContext(ctx, createAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateAccount: %w", err)
 	}
+	if q.createCurrencyStmt, err = db.PrepareContext(ctx, createCurrency); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateCurrency: %w", err)
+	}
 	if q.createEntryStmt, err = db.PrepareContext(ctx, createEntry); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateEntry: %w", err)
 	}
+	if q.createHoldStmt, err = db.PrepareContext(ctx, createHold); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateHold: %w", err)
+	}
+	if q.createIdempotencyKeyStmt, err = db.PrepareContext(ctx, createIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateIdempotencyKey: %w", err)
+	}
+	if q.createMFAChallengeStmt, err = db.PrepareContext(ctx, createMFAChallenge); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateMFAChallenge: %w", err)
+	}
+	if q.createOAuthAuthorizationCodeStmt, err = db.PrepareContext(ctx, createOAuthAuthorizationCode); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateOAuthAuthorizationCode: %w", err)
+	}
+	if q.createOAuthClientStmt, err = db.PrepareContext(ctx, createOAuthClient); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateOAuthClient: %w", err)
+	}
+	if q.createRecoveryCodeStmt, err = db.PrepareContext(ctx, createRecoveryCode); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateRecoveryCode: %w", err)
+	}
+	if q.createScheduledTransferStmt, err = db.PrepareContext(ctx, createScheduledTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateScheduledTransfer: %w", err)
+	}
+	if q.createScheduledTransferRunStmt, err = db.PrepareContext(ctx, createScheduledTransferRun); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateScheduledTransferRun: %w", err)
+	}
+	if q.createSecurityEventStmt, err = db.PrepareContext(ctx, createSecurityEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateSecurityEvent: %w", err)
+	}
 	if q.createSessionStmt, err = db.PrepareContext(ctx, createSession); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateSession: %w", err)
 	}
 	if q.createTransferStmt, err = db.PrepareContext(ctx, createTransfer); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateTransfer: %w", err)
 	}
+	if q.createTransferQuoteStmt, err = db.PrepareContext(ctx, createTransferQuote); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTransferQuote: %w", err)
+	}
 	if q.createUSerStmt, err = db.PrepareContext(ctx, createUSer); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateUSer: %w", err)
 	}
+	if q.createUserTokenStmt, err = db.PrepareContext(ctx, createUserToken); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateUserToken: %w", err)
+	}
 	if q.deleteAccountStmt, err = db.PrepareContext(ctx, deleteAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteAccount: %w", err)
 	}
+	if q.deleteExpiredAccessTokensStmt, err = db.PrepareContext(ctx, deleteExpiredAccessTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredAccessTokens: %w", err)
+	}
+	if q.deleteExpiredRevokedTokensStmt, err = db.PrepareContext(ctx, deleteExpiredRevokedTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredRevokedTokens: %w", err)
+	}
+	if q.deleteLoginThrottleStmt, err = db.PrepareContext(ctx, deleteLoginThrottle); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteLoginThrottle: %w", err)
+	}
+	if q.deleteRecoveryCodesStmt, err = db.PrepareContext(ctx, deleteRecoveryCodes); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteRecoveryCodes: %w", err)
+	}
+	if q.deleteStaleLoginThrottlesStmt, err = db.PrepareContext(ctx, deleteStaleLoginThrottles); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteStaleLoginThrottles: %w", err)
+	}
+	if q.deleteUserTOTPStmt, err = db.PrepareContext(ctx, deleteUserTOTP); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUserTOTP: %w", err)
+	}
+	if q.deleteVelocityLimitStmt, err = db.PrepareContext(ctx, deleteVelocityLimit); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteVelocityLimit: %w", err)
+	}
+	if q.enableUserTOTPStmt, err = db.PrepareContext(ctx, enableUserTOTP); err != nil {
+		return nil, fmt.Errorf("error preparing query EnableUserTOTP: %w", err)
+	}
+	if q.getAPIKeyStmt, err = db.PrepareContext(ctx, getAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAPIKey: %w", err)
+	}
+	if q.getAccessTokenStmt, err = db.PrepareContext(ctx, getAccessToken); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAccessToken: %w", err)
+	}
 	if q.getAccountStmt, err = db.PrepareContext(ctx, getAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAccount: %w", err)
 	}
+	if q.getAccountBalanceAtStmt, err = db.PrepareContext(ctx, getAccountBalanceAt); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAccountBalanceAt: %w", err)
+	}
 	if q.getAccountForUpdateStmt, err = db.PrepareContext(ctx, getAccountForUpdate); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAccountForUpdate: %w", err)
 	}
+	if q.getAccountVelocityLimitStmt, err = db.PrepareContext(ctx, getAccountVelocityLimit); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAccountVelocityLimit: %w", err)
+	}
+	if q.getActiveAPIKeyByHashStmt, err = db.PrepareContext(ctx, getActiveAPIKeyByHash); err != nil {
+		return nil, fmt.Errorf("error preparing query GetActiveAPIKeyByHash: %w", err)
+	}
+	if q.getCurrencyStmt, err = db.PrepareContext(ctx, getCurrency); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCurrency: %w", err)
+	}
 	if q.getEntryStmt, err = db.PrepareContext(ctx, getEntry); err != nil {
 		return nil, fmt.Errorf("error preparing query GetEntry: %w", err)
 	}
+	if q.getExchangeRateStmt, err = db.PrepareContext(ctx, getExchangeRate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetExchangeRate: %w", err)
+	}
+	if q.getHoldStmt, err = db.PrepareContext(ctx, getHold); err != nil {
+		return nil, fmt.Errorf("error preparing query GetHold: %w", err)
+	}
+	if q.getHoldForUpdateStmt, err = db.PrepareContext(ctx, getHoldForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetHoldForUpdate: %w", err)
+	}
+	if q.getIdempotencyKeyStmt, err = db.PrepareContext(ctx, getIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetIdempotencyKey: %w", err)
+	}
+	if q.getLoginThrottleStmt, err = db.PrepareContext(ctx, getLoginThrottle); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLoginThrottle: %w", err)
+	}
+	if q.getMFAChallengeStmt, err = db.PrepareContext(ctx, getMFAChallenge); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMFAChallenge: %w", err)
+	}
+	if q.getNextExpiredHoldForUpdateStmt, err = db.PrepareContext(ctx, getNextExpiredHoldForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNextExpiredHoldForUpdate: %w", err)
+	}
+	if q.getOAuthClientStmt, err = db.PrepareContext(ctx, getOAuthClient); err != nil {
+		return nil, fmt.Errorf("error preparing query GetOAuthClient: %w", err)
+	}
+	if q.getOAuthConsentStmt, err = db.PrepareContext(ctx, getOAuthConsent); err != nil {
+		return nil, fmt.Errorf("error preparing query GetOAuthConsent: %w", err)
+	}
+	if q.getScheduledTransferStmt, err = db.PrepareContext(ctx, getScheduledTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query GetScheduledTransfer: %w", err)
+	}
 	if q.getSessionStmt, err = db.PrepareContext(ctx, getSession); err != nil {
 		return nil, fmt.Errorf("error preparing query GetSession: %w", err)
 	}
+	if q.getSessionByAccessTokenStmt, err = db.PrepareContext(ctx, getSessionByAccessToken); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSessionByAccessToken: %w", err)
+	}
+	if q.getSessionForUpdateStmt, err = db.PrepareContext(ctx, getSessionForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSessionForUpdate: %w", err)
+	}
 	if q.getTransferStmt, err = db.PrepareContext(ctx, getTransfer); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTransfer: %w", err)
 	}
+	if q.getTransferForUpdateStmt, err = db.PrepareContext(ctx, getTransferForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransferForUpdate: %w", err)
+	}
+	if q.getTransferQuoteStmt, err = db.PrepareContext(ctx, getTransferQuote); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransferQuote: %w", err)
+	}
+	if q.getTransferVelocityStmt, err = db.PrepareContext(ctx, getTransferVelocity); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransferVelocity: %w", err)
+	}
+	if q.getUsableUserTokenStmt, err = db.PrepareContext(ctx, getUsableUserToken); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUsableUserToken: %w", err)
+	}
 	if q.getUserStmt, err = db.PrepareContext(ctx, getUser); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUser: %w", err)
 	}
+	if q.getUserByEmailStmt, err = db.PrepareContext(ctx, getUserByEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserByEmail: %w", err)
+	}
+	if q.getUserTOTPStmt, err = db.PrepareContext(ctx, getUserTOTP); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserTOTP: %w", err)
+	}
+	if q.invalidateUserTokensStmt, err = db.PrepareContext(ctx, invalidateUserTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query InvalidateUserTokens: %w", err)
+	}
+	if q.listAPIKeyAccessTokensStmt, err = db.PrepareContext(ctx, listAPIKeyAccessTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAPIKeyAccessTokens: %w", err)
+	}
+	if q.listAPIKeysStmt, err = db.PrepareContext(ctx, listAPIKeys); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAPIKeys: %w", err)
+	}
+	if q.listAccountBalanceDriftsStmt, err = db.PrepareContext(ctx, listAccountBalanceDrifts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAccountBalanceDrifts: %w", err)
+	}
 	if q.listAccountsStmt, err = db.PrepareContext(ctx, listAccounts); err != nil {
 		return nil, fmt.Errorf("error preparing query ListAccounts: %w", err)
 	}
+	if q.listActiveSessionsStmt, err = db.PrepareContext(ctx, listActiveSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListActiveSessions: %w", err)
+	}
+	if q.listAvailableBalanceDriftsStmt, err = db.PrepareContext(ctx, listAvailableBalanceDrifts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAvailableBalanceDrifts: %w", err)
+	}
+	if q.listClientAccessTokensStmt, err = db.PrepareContext(ctx, listClientAccessTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query ListClientAccessTokens: %w", err)
+	}
+	if q.listCurrenciesStmt, err = db.PrepareContext(ctx, listCurrencies); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCurrencies: %w", err)
+	}
+	if q.listEnabledCurrenciesStmt, err = db.PrepareContext(ctx, listEnabledCurrencies); err != nil {
+		return nil, fmt.Errorf("error preparing query ListEnabledCurrencies: %w", err)
+	}
 	if q.listEntriesStmt, err = db.PrepareContext(ctx, listEntries); err != nil {
 		return nil, fmt.Errorf("error preparing query ListEntries: %w", err)
 	}
+	if q.listOAuthClientsStmt, err = db.PrepareContext(ctx, listOAuthClients); err != nil {
+		return nil, fmt.Errorf("error preparing query ListOAuthClients: %w", err)
+	}
+	if q.listPasswordChangesStmt, err = db.PrepareContext(ctx, listPasswordChanges); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPasswordChanges: %w", err)
+	}
+	if q.listReversalDriftsStmt, err = db.PrepareContext(ctx, listReversalDrifts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListReversalDrifts: %w", err)
+	}
+	if q.listRevokedTokensStmt, err = db.PrepareContext(ctx, listRevokedTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query ListRevokedTokens: %w", err)
+	}
+	if q.listScheduledTransferRunsStmt, err = db.PrepareContext(ctx, listScheduledTransferRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query ListScheduledTransferRuns: %w", err)
+	}
+	if q.listScheduledTransfersStmt, err = db.PrepareContext(ctx, listScheduledTransfers); err != nil {
+		return nil, fmt.Errorf("error preparing query ListScheduledTransfers: %w", err)
+	}
+	if q.listSecurityEventsStmt, err = db.PrepareContext(ctx, listSecurityEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSecurityEvents: %w", err)
+	}
+	if q.listSessionAccessTokensStmt, err = db.PrepareContext(ctx, listSessionAccessTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSessionAccessTokens: %w", err)
+	}
+	if q.listStatementEntriesStmt, err = db.PrepareContext(ctx, listStatementEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListStatementEntries: %w", err)
+	}
+	if q.listTransferEntriesStmt, err = db.PrepareContext(ctx, listTransferEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTransferEntries: %w", err)
+	}
+	if q.listTransferReversalsStmt, err = db.PrepareContext(ctx, listTransferReversals); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTransferReversals: %w", err)
+	}
 	if q.listTransfersStmt, err = db.PrepareContext(ctx, listTransfers); err != nil {
 		return nil, fmt.Errorf("error preparing query ListTransfers: %w", err)
 	}
-	if q.updateAccountStmt, err = db.PrepareContext(ctx, updateAccount); err != nil {
-		return nil, fmt.Errorf("error preparing query UpdateAccount: %w", err)
+	if q.listUnbalancedTransfersStmt, err = db.PrepareContext(ctx, listUnbalancedTransfers); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUnbalancedTransfers: %w", err)
+	}
+	if q.listVelocityLimitsStmt, err = db.PrepareContext(ctx, listVelocityLimits); err != nil {
+		return nil, fmt.Errorf("error preparing query ListVelocityLimits: %w", err)
+	}
+	if q.lockLoginThrottleStmt, err = db.PrepareContext(ctx, lockLoginThrottle); err != nil {
+		return nil, fmt.Errorf("error preparing query LockLoginThrottle: %w", err)
+	}
+	if q.recordLoginAttemptStmt, err = db.PrepareContext(ctx, recordLoginAttempt); err != nil {
+		return nil, fmt.Errorf("error preparing query RecordLoginAttempt: %w", err)
+	}
+	if q.rehashUserPasswordStmt, err = db.PrepareContext(ctx, rehashUserPassword); err != nil {
+		return nil, fmt.Errorf("error preparing query RehashUserPassword: %w", err)
+	}
+	if q.releaseLoginAttemptStmt, err = db.PrepareContext(ctx, releaseLoginAttempt); err != nil {
+		return nil, fmt.Errorf("error preparing query ReleaseLoginAttempt: %w", err)
+	}
+	if q.revokeAPIKeyStmt, err = db.PrepareContext(ctx, revokeAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeAPIKey: %w", err)
+	}
+	if q.revokeOAuthClientStmt, err = db.PrepareContext(ctx, revokeOAuthClient); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeOAuthClient: %w", err)
+	}
+	if q.revokeTokenStmt, err = db.PrepareContext(ctx, revokeToken); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeToken: %w", err)
+	}
+	if q.rotateSessionStmt, err = db.PrepareContext(ctx, rotateSession); err != nil {
+		return nil, fmt.Errorf("error preparing query RotateSession: %w", err)
+	}
+	if q.setCurrencyEnabledStmt, err = db.PrepareContext(ctx, setCurrencyEnabled); err != nil {
+		return nil, fmt.Errorf("error preparing query SetCurrencyEnabled: %w", err)
+	}
+	if q.touchAPIKeyStmt, err = db.PrepareContext(ctx, touchAPIKey); err != nil {
+		return nil, fmt.Errorf("error preparing query TouchAPIKey: %w", err)
+	}
+	if q.updateAccountOverdraftLimitStmt, err = db.PrepareContext(ctx, updateAccountOverdraftLimit); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateAccountOverdraftLimit: %w", err)
+	}
+	if q.updateHoldStmt, err = db.PrepareContext(ctx, updateHold); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateHold: %w", err)
+	}
+	if q.updateIdempotencyKeyResponseStmt, err = db.PrepareContext(ctx, updateIdempotencyKeyResponse); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateIdempotencyKeyResponse: %w", err)
+	}
+	if q.updateScheduledTransferStmt, err = db.PrepareContext(ctx, updateScheduledTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateScheduledTransfer: %w", err)
+	}
+	if q.updateTransferReversalStmt, err = db.PrepareContext(ctx, updateTransferReversal); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateTransferReversal: %w", err)
+	}
+	if q.updateUserPasswordStmt, err = db.PrepareContext(ctx, updateUserPassword); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserPassword: %w", err)
+	}
+	if q.updateUserRoleStmt, err = db.PrepareContext(ctx, updateUserRole); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserRole: %w", err)
+	}
+	if q.updateUserTierStmt, err = db.PrepareContext(ctx, updateUserTier); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserTier: %w", err)
+	}
+	if q.upsertExchangeRateStmt, err = db.PrepareContext(ctx, upsertExchangeRate); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertExchangeRate: %w", err)
+	}
+	if q.upsertOAuthConsentStmt, err = db.PrepareContext(ctx, upsertOAuthConsent); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertOAuthConsent: %w", err)
+	}
+	if q.upsertUserTOTPStmt, err = db.PrepareContext(ctx, upsertUserTOTP); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertUserTOTP: %w", err)
+	}
+	if q.upsertVelocityLimitStmt, err = db.PrepareContext(ctx, upsertVelocityLimit); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertVelocityLimit: %w", err)
+	}
+	if q.useOAuthAuthorizationCodeStmt, err = db.PrepareContext(ctx, useOAuthAuthorizationCode); err != nil {
+		return nil, fmt.Errorf("error preparing query UseOAuthAuthorizationCode: %w", err)
+	}
+	if q.useRecoveryCodeStmt, err = db.PrepareContext(ctx, useRecoveryCode); err != nil {
+		return nil, fmt.Errorf("error preparing query UseRecoveryCode: %w", err)
+	}
+	if q.useTOTPStepStmt, err = db.PrepareContext(ctx, useTOTPStep); err != nil {
+		return nil, fmt.Errorf("error preparing query UseTOTPStep: %w", err)
+	}
+	if q.useTransferQuoteStmt, err = db.PrepareContext(ctx, useTransferQuote); err != nil {
+		return nil, fmt.Errorf("error preparing query UseTransferQuote: %w", err)
+	}
+	if q.useUserTokenStmt, err = db.PrepareContext(ctx, useUserToken); err != nil {
+		return nil, fmt.Errorf("error preparing query UseUserToken: %w", err)
+	}
+	if q.verifyUserEmailStmt, err = db.PrepareContext(ctx, verifyUserEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query VerifyUserEmail: %w", err)
 	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.addAccountAvailableBalanceStmt != nil {
+		if cerr := q.addAccountAvailableBalanceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addAccountAvailableBalanceStmt: %w", cerr)
+		}
+	}
 	if q.addAccountBalanceStmt != nil {
 		if cerr := q.addAccountBalanceStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing addAccountBalanceStmt: %w", cerr)
 		}
 	}
+	if q.advanceScheduledTransferStmt != nil {
+		if cerr := q.advanceScheduledTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing advanceScheduledTransferStmt: %w", cerr)
+		}
+	}
+	if q.attemptMFAChallengeStmt != nil {
+		if cerr := q.attemptMFAChallengeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing attemptMFAChallengeStmt: %w", cerr)
+		}
+	}
+	if q.blockClientSessionsStmt != nil {
+		if cerr := q.blockClientSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing blockClientSessionsStmt: %w", cerr)
+		}
+	}
+	if q.blockSessionStmt != nil {
+		if cerr := q.blockSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing blockSessionStmt: %w", cerr)
+		}
+	}
+	if q.blockSessionFamilyStmt != nil {
+		if cerr := q.blockSessionFamilyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing blockSessionFamilyStmt: %w", cerr)
+		}
+	}
+	if q.blockUserSessionsStmt != nil {
+		if cerr := q.blockUserSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing blockUserSessionsStmt: %w", cerr)
+		}
+	}
+	if q.cancelScheduledTransferStmt != nil {
+		if cerr := q.cancelScheduledTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing cancelScheduledTransferStmt: %w", cerr)
+		}
+	}
+	if q.captureAccountBalanceStmt != nil {
+		if cerr := q.captureAccountBalanceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing captureAccountBalanceStmt: %w", cerr)
+		}
+	}
+	if q.claimDueScheduledTransfersStmt != nil {
+		if cerr := q.claimDueScheduledTransfersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimDueScheduledTransfersStmt: %w", cerr)
+		}
+	}
+	if q.consumeMFAChallengeStmt != nil {
+		if cerr := q.consumeMFAChallengeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing consumeMFAChallengeStmt: %w", cerr)
+		}
+	}
+	if q.createAPIKeyStmt != nil {
+		if cerr := q.createAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.createAccessTokenStmt != nil {
+		if cerr := q.createAccessTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAccessTokenStmt: %w", cerr)
+		}
+	}
 	if q.createAccountStmt != nil {
 		if cerr := q.createAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createAccountStmt: %w", cerr)
 		}
 	}
+	if q.createCurrencyStmt != nil {
+		if cerr := q.createCurrencyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCurrencyStmt: %w", cerr)
+		}
+	}
 	if q.createEntryStmt != nil {
 		if cerr := q.createEntryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createEntryStmt: %w", cerr)
 		}
 	}
+	if q.createHoldStmt != nil {
+		if cerr := q.createHoldStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createHoldStmt: %w", cerr)
+		}
+	}
+	if q.createIdempotencyKeyStmt != nil {
+		if cerr := q.createIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.createMFAChallengeStmt != nil {
+		if cerr := q.createMFAChallengeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createMFAChallengeStmt: %w", cerr)
+		}
+	}
+	if q.createOAuthAuthorizationCodeStmt != nil {
+		if cerr := q.createOAuthAuthorizationCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createOAuthAuthorizationCodeStmt: %w", cerr)
+		}
+	}
+	if q.createOAuthClientStmt != nil {
+		if cerr := q.createOAuthClientStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createOAuthClientStmt: %w", cerr)
+		}
+	}
+	if q.createRecoveryCodeStmt != nil {
+		if cerr := q.createRecoveryCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createRecoveryCodeStmt: %w", cerr)
+		}
+	}
+	if q.createScheduledTransferStmt != nil {
+		if cerr := q.createScheduledTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createScheduledTransferStmt: %w", cerr)
+		}
+	}
+	if q.createScheduledTransferRunStmt != nil {
+		if cerr := q.createScheduledTransferRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createScheduledTransferRunStmt: %w", cerr)
+		}
+	}
+	if q.createSecurityEventStmt != nil {
+		if cerr := q.createSecurityEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createSecurityEventStmt: %w", cerr)
+		}
+	}
 	if q.createSessionStmt != nil {
 		if cerr := q.createSessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createSessionStmt: %w", cerr)
@@ -105,64 +538,479 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createTransferStmt: %w", cerr)
 		}
 	}
+	if q.createTransferQuoteStmt != nil {
+		if cerr := q.createTransferQuoteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTransferQuoteStmt: %w", cerr)
+		}
+	}
 	if q.createUSerStmt != nil {
 		if cerr := q.createUSerStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createUSerStmt: %w", cerr)
 		}
 	}
+	if q.createUserTokenStmt != nil {
+		if cerr := q.createUserTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createUserTokenStmt: %w", cerr)
+		}
+	}
 	if q.deleteAccountStmt != nil {
 		if cerr := q.deleteAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteAccountStmt: %w", cerr)
 		}
 	}
+	if q.deleteExpiredAccessTokensStmt != nil {
+		if cerr := q.deleteExpiredAccessTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredAccessTokensStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredRevokedTokensStmt != nil {
+		if cerr := q.deleteExpiredRevokedTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredRevokedTokensStmt: %w", cerr)
+		}
+	}
+	if q.deleteLoginThrottleStmt != nil {
+		if cerr := q.deleteLoginThrottleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteLoginThrottleStmt: %w", cerr)
+		}
+	}
+	if q.deleteRecoveryCodesStmt != nil {
+		if cerr := q.deleteRecoveryCodesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteRecoveryCodesStmt: %w", cerr)
+		}
+	}
+	if q.deleteStaleLoginThrottlesStmt != nil {
+		if cerr := q.deleteStaleLoginThrottlesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteStaleLoginThrottlesStmt: %w", cerr)
+		}
+	}
+	if q.deleteUserTOTPStmt != nil {
+		if cerr := q.deleteUserTOTPStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserTOTPStmt: %w", cerr)
+		}
+	}
+	if q.deleteVelocityLimitStmt != nil {
+		if cerr := q.deleteVelocityLimitStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteVelocityLimitStmt: %w", cerr)
+		}
+	}
+	if q.enableUserTOTPStmt != nil {
+		if cerr := q.enableUserTOTPStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing enableUserTOTPStmt: %w", cerr)
+		}
+	}
+	if q.getAPIKeyStmt != nil {
+		if cerr := q.getAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.getAccessTokenStmt != nil {
+		if cerr := q.getAccessTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAccessTokenStmt: %w", cerr)
+		}
+	}
 	if q.getAccountStmt != nil {
 		if cerr := q.getAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAccountStmt: %w", cerr)
 		}
 	}
+	if q.getAccountBalanceAtStmt != nil {
+		if cerr := q.getAccountBalanceAtStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAccountBalanceAtStmt: %w", cerr)
+		}
+	}
 	if q.getAccountForUpdateStmt != nil {
 		if cerr := q.getAccountForUpdateStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAccountForUpdateStmt: %w", cerr)
 		}
 	}
+	if q.getAccountVelocityLimitStmt != nil {
+		if cerr := q.getAccountVelocityLimitStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAccountVelocityLimitStmt: %w", cerr)
+		}
+	}
+	if q.getActiveAPIKeyByHashStmt != nil {
+		if cerr := q.getActiveAPIKeyByHashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getActiveAPIKeyByHashStmt: %w", cerr)
+		}
+	}
+	if q.getCurrencyStmt != nil {
+		if cerr := q.getCurrencyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCurrencyStmt: %w", cerr)
+		}
+	}
 	if q.getEntryStmt != nil {
 		if cerr := q.getEntryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getEntryStmt: %w", cerr)
 		}
 	}
+	if q.getExchangeRateStmt != nil {
+		if cerr := q.getExchangeRateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getExchangeRateStmt: %w", cerr)
+		}
+	}
+	if q.getHoldStmt != nil {
+		if cerr := q.getHoldStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getHoldStmt: %w", cerr)
+		}
+	}
+	if q.getHoldForUpdateStmt != nil {
+		if cerr := q.getHoldForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getHoldForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getIdempotencyKeyStmt != nil {
+		if cerr := q.getIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.getLoginThrottleStmt != nil {
+		if cerr := q.getLoginThrottleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLoginThrottleStmt: %w", cerr)
+		}
+	}
+	if q.getMFAChallengeStmt != nil {
+		if cerr := q.getMFAChallengeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMFAChallengeStmt: %w", cerr)
+		}
+	}
+	if q.getNextExpiredHoldForUpdateStmt != nil {
+		if cerr := q.getNextExpiredHoldForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNextExpiredHoldForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getOAuthClientStmt != nil {
+		if cerr := q.getOAuthClientStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getOAuthClientStmt: %w", cerr)
+		}
+	}
+	if q.getOAuthConsentStmt != nil {
+		if cerr := q.getOAuthConsentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getOAuthConsentStmt: %w", cerr)
+		}
+	}
+	if q.getScheduledTransferStmt != nil {
+		if cerr := q.getScheduledTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getScheduledTransferStmt: %w", cerr)
+		}
+	}
 	if q.getSessionStmt != nil {
 		if cerr := q.getSessionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getSessionStmt: %w", cerr)
 		}
 	}
+	if q.getSessionByAccessTokenStmt != nil {
+		if cerr := q.getSessionByAccessTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSessionByAccessTokenStmt: %w", cerr)
+		}
+	}
+	if q.getSessionForUpdateStmt != nil {
+		if cerr := q.getSessionForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSessionForUpdateStmt: %w", cerr)
+		}
+	}
 	if q.getTransferStmt != nil {
 		if cerr := q.getTransferStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getTransferStmt: %w", cerr)
 		}
 	}
+	if q.getTransferForUpdateStmt != nil {
+		if cerr := q.getTransferForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransferForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getTransferQuoteStmt != nil {
+		if cerr := q.getTransferQuoteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransferQuoteStmt: %w", cerr)
+		}
+	}
+	if q.getTransferVelocityStmt != nil {
+		if cerr := q.getTransferVelocityStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransferVelocityStmt: %w", cerr)
+		}
+	}
+	if q.getUsableUserTokenStmt != nil {
+		if cerr := q.getUsableUserTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUsableUserTokenStmt: %w", cerr)
+		}
+	}
 	if q.getUserStmt != nil {
 		if cerr := q.getUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserStmt: %w", cerr)
 		}
 	}
+	if q.getUserByEmailStmt != nil {
+		if cerr := q.getUserByEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserByEmailStmt: %w", cerr)
+		}
+	}
+	if q.getUserTOTPStmt != nil {
+		if cerr := q.getUserTOTPStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserTOTPStmt: %w", cerr)
+		}
+	}
+	if q.invalidateUserTokensStmt != nil {
+		if cerr := q.invalidateUserTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing invalidateUserTokensStmt: %w", cerr)
+		}
+	}
+	if q.listAPIKeyAccessTokensStmt != nil {
+		if cerr := q.listAPIKeyAccessTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAPIKeyAccessTokensStmt: %w", cerr)
+		}
+	}
+	if q.listAPIKeysStmt != nil {
+		if cerr := q.listAPIKeysStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAPIKeysStmt: %w", cerr)
+		}
+	}
+	if q.listAccountBalanceDriftsStmt != nil {
+		if cerr := q.listAccountBalanceDriftsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAccountBalanceDriftsStmt: %w", cerr)
+		}
+	}
 	if q.listAccountsStmt != nil {
 		if cerr := q.listAccountsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listAccountsStmt: %w", cerr)
 		}
 	}
+	if q.listActiveSessionsStmt != nil {
+		if cerr := q.listActiveSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listActiveSessionsStmt: %w", cerr)
+		}
+	}
+	if q.listAvailableBalanceDriftsStmt != nil {
+		if cerr := q.listAvailableBalanceDriftsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAvailableBalanceDriftsStmt: %w", cerr)
+		}
+	}
+	if q.listClientAccessTokensStmt != nil {
+		if cerr := q.listClientAccessTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listClientAccessTokensStmt: %w", cerr)
+		}
+	}
+	if q.listCurrenciesStmt != nil {
+		if cerr := q.listCurrenciesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCurrenciesStmt: %w", cerr)
+		}
+	}
+	if q.listEnabledCurrenciesStmt != nil {
+		if cerr := q.listEnabledCurrenciesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listEnabledCurrenciesStmt: %w", cerr)
+		}
+	}
 	if q.listEntriesStmt != nil {
 		if cerr := q.listEntriesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listEntriesStmt: %w", cerr)
 		}
 	}
+	if q.listOAuthClientsStmt != nil {
+		if cerr := q.listOAuthClientsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listOAuthClientsStmt: %w", cerr)
+		}
+	}
+	if q.listPasswordChangesStmt != nil {
+		if cerr := q.listPasswordChangesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPasswordChangesStmt: %w", cerr)
+		}
+	}
+	if q.listReversalDriftsStmt != nil {
+		if cerr := q.listReversalDriftsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listReversalDriftsStmt: %w", cerr)
+		}
+	}
+	if q.listRevokedTokensStmt != nil {
+		if cerr := q.listRevokedTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listRevokedTokensStmt: %w", cerr)
+		}
+	}
+	if q.listScheduledTransferRunsStmt != nil {
+		if cerr := q.listScheduledTransferRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listScheduledTransferRunsStmt: %w", cerr)
+		}
+	}
+	if q.listScheduledTransfersStmt != nil {
+		if cerr := q.listScheduledTransfersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listScheduledTransfersStmt: %w", cerr)
+		}
+	}
+	if q.listSecurityEventsStmt != nil {
+		if cerr := q.listSecurityEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSecurityEventsStmt: %w", cerr)
+		}
+	}
+	if q.listSessionAccessTokensStmt != nil {
+		if cerr := q.listSessionAccessTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSessionAccessTokensStmt: %w", cerr)
+		}
+	}
+	if q.listStatementEntriesStmt != nil {
+		if cerr := q.listStatementEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listStatementEntriesStmt: %w", cerr)
+		}
+	}
+	if q.listTransferEntriesStmt != nil {
+		if cerr := q.listTransferEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTransferEntriesStmt: %w", cerr)
+		}
+	}
+	if q.listTransferReversalsStmt != nil {
+		if cerr := q.listTransferReversalsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTransferReversalsStmt: %w", cerr)
+		}
+	}
 	if q.listTransfersStmt != nil {
 		if cerr := q.listTransfersStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listTransfersStmt: %w", cerr)
 		}
 	}
-	if q.updateAccountStmt != nil {
-		if cerr := q.updateAccountStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing updateAccountStmt: %w", cerr)
+	if q.listUnbalancedTransfersStmt != nil {
+		if cerr := q.listUnbalancedTransfersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUnbalancedTransfersStmt: %w", cerr)
+		}
+	}
+	if q.listVelocityLimitsStmt != nil {
+		if cerr := q.listVelocityLimitsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listVelocityLimitsStmt: %w", cerr)
+		}
+	}
+	if q.lockLoginThrottleStmt != nil {
+		if cerr := q.lockLoginThrottleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing lockLoginThrottleStmt: %w", cerr)
+		}
+	}
+	if q.recordLoginAttemptStmt != nil {
+		if cerr := q.recordLoginAttemptStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing recordLoginAttemptStmt: %w", cerr)
+		}
+	}
+	if q.rehashUserPasswordStmt != nil {
+		if cerr := q.rehashUserPasswordStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing rehashUserPasswordStmt: %w", cerr)
+		}
+	}
+	if q.releaseLoginAttemptStmt != nil {
+		if cerr := q.releaseLoginAttemptStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing releaseLoginAttemptStmt: %w", cerr)
+		}
+	}
+	if q.revokeAPIKeyStmt != nil {
+		if cerr := q.revokeAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.revokeOAuthClientStmt != nil {
+		if cerr := q.revokeOAuthClientStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeOAuthClientStmt: %w", cerr)
+		}
+	}
+	if q.revokeTokenStmt != nil {
+		if cerr := q.revokeTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeTokenStmt: %w", cerr)
+		}
+	}
+	if q.rotateSessionStmt != nil {
+		if cerr := q.rotateSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing rotateSessionStmt: %w", cerr)
+		}
+	}
+	if q.setCurrencyEnabledStmt != nil {
+		if cerr := q.setCurrencyEnabledStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setCurrencyEnabledStmt: %w", cerr)
+		}
+	}
+	if q.touchAPIKeyStmt != nil {
+		if cerr := q.touchAPIKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing touchAPIKeyStmt: %w", cerr)
+		}
+	}
+	if q.updateAccountOverdraftLimitStmt != nil {
+		if cerr := q.updateAccountOverdraftLimitStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateAccountOverdraftLimitStmt: %w", cerr)
+		}
+	}
+	if q.updateHoldStmt != nil {
+		if cerr := q.updateHoldStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateHoldStmt: %w", cerr)
+		}
+	}
+	if q.updateIdempotencyKeyResponseStmt != nil {
+		if cerr := q.updateIdempotencyKeyResponseStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateIdempotencyKeyResponseStmt: %w", cerr)
+		}
+	}
+	if q.updateScheduledTransferStmt != nil {
+		if cerr := q.updateScheduledTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateScheduledTransferStmt: %w", cerr)
+		}
+	}
+	if q.updateTransferReversalStmt != nil {
+		if cerr := q.updateTransferReversalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateTransferReversalStmt: %w", cerr)
+		}
+	}
+	if q.updateUserPasswordStmt != nil {
+		if cerr := q.updateUserPasswordStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserPasswordStmt: %w", cerr)
+		}
+	}
+	if q.updateUserRoleStmt != nil {
+		if cerr := q.updateUserRoleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserRoleStmt: %w", cerr)
+		}
+	}
+	if q.updateUserTierStmt != nil {
+		if cerr := q.updateUserTierStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserTierStmt: %w", cerr)
+		}
+	}
+	if q.upsertExchangeRateStmt != nil {
+		if cerr := q.upsertExchangeRateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertExchangeRateStmt: %w", cerr)
+		}
+	}
+	if q.upsertOAuthConsentStmt != nil {
+		if cerr := q.upsertOAuthConsentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertOAuthConsentStmt: %w", cerr)
+		}
+	}
+	if q.upsertUserTOTPStmt != nil {
+		if cerr := q.upsertUserTOTPStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertUserTOTPStmt: %w", cerr)
+		}
+	}
+	if q.upsertVelocityLimitStmt != nil {
+		if cerr := q.upsertVelocityLimitStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertVelocityLimitStmt: %w", cerr)
+		}
+	}
+	if q.useOAuthAuthorizationCodeStmt != nil {
+		if cerr := q.useOAuthAuthorizationCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing useOAuthAuthorizationCodeStmt: %w", cerr)
+		}
+	}
+	if q.useRecoveryCodeStmt != nil {
+		if cerr := q.useRecoveryCodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing useRecoveryCodeStmt: %w", cerr)
+		}
+	}
+	if q.useTOTPStepStmt != nil {
+		if cerr := q.useTOTPStepStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing useTOTPStepStmt: %w", cerr)
+		}
+	}
+	if q.useTransferQuoteStmt != nil {
+		if cerr := q.useTransferQuoteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing useTransferQuoteStmt: %w", cerr)
+		}
+	}
+	if q.useUserTokenStmt != nil {
+		if cerr := q.useUserTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing useUserTokenStmt: %w", cerr)
+		}
+	}
+	if q.verifyUserEmailStmt != nil {
+		if cerr := q.verifyUserEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing verifyUserEmailStmt: %w", cerr)
 		}
 	}
 	return err
@@ -202,47 +1050,259 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                      DBTX
-	tx                      *sql.Tx
-	addAccountBalanceStmt   *sql.Stmt
-	createAccountStmt       *sql.Stmt
-	createEntryStmt         *sql.Stmt
-	createSessionStmt       *sql.Stmt
-	createTransferStmt      *sql.Stmt
-	createUSerStmt          *sql.Stmt
-	deleteAccountStmt       *sql.Stmt
-	getAccountStmt          *sql.Stmt
-	getAccountForUpdateStmt *sql.Stmt
-	getEntryStmt            *sql.Stmt
-	getSessionStmt          *sql.Stmt
-	getTransferStmt         *sql.Stmt
-	getUserStmt             *sql.Stmt
-	listAccountsStmt        *sql.Stmt
-	listEntriesStmt         *sql.Stmt
-	listTransfersStmt       *sql.Stmt
-	updateAccountStmt       *sql.Stmt
+	db                               DBTX
+	tx                               *sql.Tx
+	addAccountAvailableBalanceStmt   *sql.Stmt
+	addAccountBalanceStmt            *sql.Stmt
+	advanceScheduledTransferStmt     *sql.Stmt
+	attemptMFAChallengeStmt          *sql.Stmt
+	blockClientSessionsStmt          *sql.Stmt
+	blockSessionStmt                 *sql.Stmt
+	blockSessionFamilyStmt           *sql.Stmt
+	blockUserSessionsStmt            *sql.Stmt
+	cancelScheduledTransferStmt      *sql.Stmt
+	captureAccountBalanceStmt        *sql.Stmt
+	claimDueScheduledTransfersStmt   *sql.Stmt
+	consumeMFAChallengeStmt          *sql.Stmt
+	createAPIKeyStmt                 *sql.Stmt
+	createAccessTokenStmt            *sql.Stmt
+	createAccountStmt                *sql.Stmt
+	createCurrencyStmt               *sql.Stmt
+	createEntryStmt                  *sql.Stmt
+	createHoldStmt                   *sql.Stmt
+	createIdempotencyKeyStmt         *sql.Stmt
+	createMFAChallengeStmt           *sql.Stmt
+	createOAuthAuthorizationCodeStmt *sql.Stmt
+	createOAuthClientStmt            *sql.Stmt
+	createRecoveryCodeStmt           *sql.Stmt
+	createScheduledTransferStmt      *sql.Stmt
+	createScheduledTransferRunStmt   *sql.Stmt
+	createSecurityEventStmt          *sql.Stmt
+	createSessionStmt                *sql.Stmt
+	createTransferStmt               *sql.Stmt
+	createTransferQuoteStmt          *sql.Stmt
+	createUSerStmt                   *sql.Stmt
+	createUserTokenStmt              *sql.Stmt
+	deleteAccountStmt                *sql.Stmt
+	deleteExpiredAccessTokensStmt    *sql.Stmt
+	deleteExpiredRevokedTokensStmt   *sql.Stmt
+	deleteLoginThrottleStmt          *sql.Stmt
+	deleteRecoveryCodesStmt          *sql.Stmt
+	deleteStaleLoginThrottlesStmt    *sql.Stmt
+	deleteUserTOTPStmt               *sql.Stmt
+	deleteVelocityLimitStmt          *sql.Stmt
+	enableUserTOTPStmt               *sql.Stmt
+	getAPIKeyStmt                    *sql.Stmt
+	getAccessTokenStmt               *sql.Stmt
+	getAccountStmt                   *sql.Stmt
+	getAccountBalanceAtStmt          *sql.Stmt
+	getAccountForUpdateStmt          *sql.Stmt
+	getAccountVelocityLimitStmt      *sql.Stmt
+	getActiveAPIKeyByHashStmt        *sql.Stmt
+	getCurrencyStmt                  *sql.Stmt
+	getEntryStmt                     *sql.Stmt
+	getExchangeRateStmt              *sql.Stmt
+	getHoldStmt                      *sql.Stmt
+	getHoldForUpdateStmt             *sql.Stmt
+	getIdempotencyKeyStmt            *sql.Stmt
+	getLoginThrottleStmt             *sql.Stmt
+	getMFAChallengeStmt              *sql.Stmt
+	getNextExpiredHoldForUpdateStmt  *sql.Stmt
+	getOAuthClientStmt               *sql.Stmt
+	getOAuthConsentStmt              *sql.Stmt
+	getScheduledTransferStmt         *sql.Stmt
+	getSessionStmt                   *sql.Stmt
+	getSessionByAccessTokenStmt      *sql.Stmt
+	getSessionForUpdateStmt          *sql.Stmt
+	getTransferStmt                  *sql.Stmt
+	getTransferForUpdateStmt         *sql.Stmt
+	getTransferQuoteStmt             *sql.Stmt
+	getTransferVelocityStmt          *sql.Stmt
+	getUsableUserTokenStmt           *sql.Stmt
+	getUserStmt                      *sql.Stmt
+	getUserByEmailStmt               *sql.Stmt
+	getUserTOTPStmt                  *sql.Stmt
+	invalidateUserTokensStmt         *sql.Stmt
+	listAPIKeyAccessTokensStmt       *sql.Stmt
+	listAPIKeysStmt                  *sql.Stmt
+	listAccountBalanceDriftsStmt     *sql.Stmt
+	listAccountsStmt                 *sql.Stmt
+	listActiveSessionsStmt           *sql.Stmt
+	listAvailableBalanceDriftsStmt   *sql.Stmt
+	listClientAccessTokensStmt       *sql.Stmt
+	listCurrenciesStmt               *sql.Stmt
+	listEnabledCurrenciesStmt        *sql.Stmt
+	listEntriesStmt                  *sql.Stmt
+	listOAuthClientsStmt             *sql.Stmt
+	listPasswordChangesStmt          *sql.Stmt
+	listReversalDriftsStmt           *sql.Stmt
+	listRevokedTokensStmt            *sql.Stmt
+	listScheduledTransferRunsStmt    *sql.Stmt
+	listScheduledTransfersStmt       *sql.Stmt
+	listSecurityEventsStmt           *sql.Stmt
+	listSessionAccessTokensStmt      *sql.Stmt
+	listStatementEntriesStmt         *sql.Stmt
+	listTransferEntriesStmt          *sql.Stmt
+	listTransferReversalsStmt        *sql.Stmt
+	listTransfersStmt                *sql.Stmt
+	listUnbalancedTransfersStmt      *sql.Stmt
+	listVelocityLimitsStmt           *sql.Stmt
+	lockLoginThrottleStmt            *sql.Stmt
+	recordLoginAttemptStmt           *sql.Stmt
+	rehashUserPasswordStmt           *sql.Stmt
+	releaseLoginAttemptStmt          *sql.Stmt
+	revokeAPIKeyStmt                 *sql.Stmt
+	revokeOAuthClientStmt            *sql.Stmt
+	revokeTokenStmt                  *sql.Stmt
+	rotateSessionStmt                *sql.Stmt
+	setCurrencyEnabledStmt           *sql.Stmt
+	touchAPIKeyStmt                  *sql.Stmt
+	updateAccountOverdraftLimitStmt  *sql.Stmt
+	updateHoldStmt                   *sql.Stmt
+	updateIdempotencyKeyResponseStmt *sql.Stmt
+	updateScheduledTransferStmt      *sql.Stmt
+	updateTransferReversalStmt       *sql.Stmt
+	updateUserPasswordStmt           *sql.Stmt
+	updateUserRoleStmt               *sql.Stmt
+	updateUserTierStmt               *sql.Stmt
+	upsertExchangeRateStmt           *sql.Stmt
+	upsertOAuthConsentStmt           *sql.Stmt
+	upsertUserTOTPStmt               *sql.Stmt
+	upsertVelocityLimitStmt          *sql.Stmt
+	useOAuthAuthorizationCodeStmt    *sql.Stmt
+	useRecoveryCodeStmt              *sql.Stmt
+	useTOTPStepStmt                  *sql.Stmt
+	useTransferQuoteStmt             *sql.Stmt
+	useUserTokenStmt                 *sql.Stmt
+	verifyUserEmailStmt              *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                      tx,
-		tx:                      tx,
-		addAccountBalanceStmt:   q.addAccountBalanceStmt,
-		createAccountStmt:       q.createAccountStmt,
-		createEntryStmt:         q.createEntryStmt,
-		createSessionStmt:       q.createSessionStmt,
-		createTransferStmt:      q.createTransferStmt,
-		createUSerStmt:          q.createUSerStmt,
-		deleteAccountStmt:       q.deleteAccountStmt,
-		getAccountStmt:          q.getAccountStmt,
-		getAccountForUpdateStmt: q.getAccountForUpdateStmt,
-		getEntryStmt:            q.getEntryStmt,
-		getSessionStmt:          q.getSessionStmt,
-		getTransferStmt:         q.getTransferStmt,
-		getUserStmt:             q.getUserStmt,
-		listAccountsStmt:        q.listAccountsStmt,
-		listEntriesStmt:         q.listEntriesStmt,
-		listTransfersStmt:       q.listTransfersStmt,
-		updateAccountStmt:       q.updateAccountStmt,
+		db:                               tx,
+		tx:                               tx,
+		addAccountAvailableBalanceStmt:   q.addAccountAvailableBalanceStmt,
+		addAccountBalanceStmt:            q.addAccountBalanceStmt,
+		advanceScheduledTransferStmt:     q.advanceScheduledTransferStmt,
+		attemptMFAChallengeStmt:          q.attemptMFAChallengeStmt,
+		blockClientSessionsStmt:          q.blockClientSessionsStmt,
+		blockSessionStmt:                 q.blockSessionStmt,
+		blockSessionFamilyStmt:           q.blockSessionFamilyStmt,
+		blockUserSessionsStmt:            q.blockUserSessionsStmt,
+		cancelScheduledTransferStmt:      q.cancelScheduledTransferStmt,
+		captureAccountBalanceStmt:        q.captureAccountBalanceStmt,
+		claimDueScheduledTransfersStmt:   q.claimDueScheduledTransfersStmt,
+		consumeMFAChallengeStmt:          q.consumeMFAChallengeStmt,
+		createAPIKeyStmt:                 q.createAPIKeyStmt,
+		createAccessTokenStmt:            q.createAccessTokenStmt,
+		createAccountStmt:                q.createAccountStmt,
+		createCurrencyStmt:               q.createCurrencyStmt,
+		createEntryStmt:                  q.createEntryStmt,
+		createHoldStmt:                   q.createHoldStmt,
+		createIdempotencyKeyStmt:         q.createIdempotencyKeyStmt,
+		createMFAChallengeStmt:           q.createMFAChallengeStmt,
+		createOAuthAuthorizationCodeStmt: q.createOAuthAuthorizationCodeStmt,
+		createOAuthClientStmt:            q.createOAuthClientStmt,
+		createRecoveryCodeStmt:           q.createRecoveryCodeStmt,
+		createScheduledTransferStmt:      q.createScheduledTransferStmt,
+		createScheduledTransferRunStmt:   q.createScheduledTransferRunStmt,
+		createSecurityEventStmt:          q.createSecurityEventStmt,
+		createSessionStmt:                q.createSessionStmt,
+		createTransferStmt:               q.createTransferStmt,
+		createTransferQuoteStmt:          q.createTransferQuoteStmt,
+		createUSerStmt:                   q.createUSerStmt,
+		createUserTokenStmt:              q.createUserTokenStmt,
+		deleteAccountStmt:                q.deleteAccountStmt,
+		deleteExpiredAccessTokensStmt:    q.deleteExpiredAccessTokensStmt,
+		deleteExpiredRevokedTokensStmt:   q.deleteExpiredRevokedTokensStmt,
+		deleteLoginThrottleStmt:          q.deleteLoginThrottleStmt,
+		deleteRecoveryCodesStmt:          q.deleteRecoveryCodesStmt,
+		deleteStaleLoginThrottlesStmt:    q.deleteStaleLoginThrottlesStmt,
+		deleteUserTOTPStmt:               q.deleteUserTOTPStmt,
+		deleteVelocityLimitStmt:          q.deleteVelocityLimitStmt,
+		enableUserTOTPStmt:               q.enableUserTOTPStmt,
+		getAPIKeyStmt:                    q.getAPIKeyStmt,
+		getAccessTokenStmt:               q.getAccessTokenStmt,
+		getAccountStmt:                   q.getAccountStmt,
+		getAccountBalanceAtStmt:          q.getAccountBalanceAtStmt,
+		getAccountForUpdateStmt:          q.getAccountForUpdateStmt,
+		getAccountVelocityLimitStmt:      q.getAccountVelocityLimitStmt,
+		getActiveAPIKeyByHashStmt:        q.getActiveAPIKeyByHashStmt,
+		getCurrencyStmt:                  q.getCurrencyStmt,
+		getEntryStmt:                     q.getEntryStmt,
+		getExchangeRateStmt:              q.getExchangeRateStmt,
+		getHoldStmt:                      q.getHoldStmt,
+		getHoldForUpdateStmt:             q.getHoldForUpdateStmt,
+		getIdempotencyKeyStmt:            q.getIdempotencyKeyStmt,
+		getLoginThrottleStmt:             q.getLoginThrottleStmt,
+		getMFAChallengeStmt:              q.getMFAChallengeStmt,
+		getNextExpiredHoldForUpdateStmt:  q.getNextExpiredHoldForUpdateStmt,
+		getOAuthClientStmt:               q.getOAuthClientStmt,
+		getOAuthConsentStmt:              q.getOAuthConsentStmt,
+		getScheduledTransferStmt:         q.getScheduledTransferStmt,
+		getSessionStmt:                   q.getSessionStmt,
+		getSessionByAccessTokenStmt:      q.getSessionByAccessTokenStmt,
+		getSessionForUpdateStmt:          q.getSessionForUpdateStmt,
+		getTransferStmt:                  q.getTransferStmt,
+		getTransferForUpdateStmt:         q.getTransferForUpdateStmt,
+		getTransferQuoteStmt:             q.getTransferQuoteStmt,
+		getTransferVelocityStmt:          q.getTransferVelocityStmt,
+		getUsableUserTokenStmt:           q.getUsableUserTokenStmt,
+		getUserStmt:                      q.getUserStmt,
+		getUserByEmailStmt:               q.getUserByEmailStmt,
+		getUserTOTPStmt:                  q.getUserTOTPStmt,
+		invalidateUserTokensStmt:         q.invalidateUserTokensStmt,
+		listAPIKeyAccessTokensStmt:       q.listAPIKeyAccessTokensStmt,
+		listAPIKeysStmt:                  q.listAPIKeysStmt,
+		listAccountBalanceDriftsStmt:     q.listAccountBalanceDriftsStmt,
+		listAccountsStmt:                 q.listAccountsStmt,
+		listActiveSessionsStmt:           q.listActiveSessionsStmt,
+		listAvailableBalanceDriftsStmt:   q.listAvailableBalanceDriftsStmt,
+		listClientAccessTokensStmt:       q.listClientAccessTokensStmt,
+		listCurrenciesStmt:               q.listCurrenciesStmt,
+		listEnabledCurrenciesStmt:        q.listEnabledCurrenciesStmt,
+		listEntriesStmt:                  q.listEntriesStmt,
+		listOAuthClientsStmt:             q.listOAuthClientsStmt,
+		listPasswordChangesStmt:          q.listPasswordChangesStmt,
+		listReversalDriftsStmt:           q.listReversalDriftsStmt,
+		listRevokedTokensStmt:            q.listRevokedTokensStmt,
+		listScheduledTransferRunsStmt:    q.listScheduledTransferRunsStmt,
+		listScheduledTransfersStmt:       q.listScheduledTransfersStmt,
+		listSecurityEventsStmt:           q.listSecurityEventsStmt,
+		listSessionAccessTokensStmt:      q.listSessionAccessTokensStmt,
+		listStatementEntriesStmt:         q.listStatementEntriesStmt,
+		listTransferEntriesStmt:          q.listTransferEntriesStmt,
+		listTransferReversalsStmt:        q.listTransferReversalsStmt,
+		listTransfersStmt:                q.listTransfersStmt,
+		listUnbalancedTransfersStmt:      q.listUnbalancedTransfersStmt,
+		listVelocityLimitsStmt:           q.listVelocityLimitsStmt,
+		lockLoginThrottleStmt:            q.lockLoginThrottleStmt,
+		recordLoginAttemptStmt:           q.recordLoginAttemptStmt,
+		rehashUserPasswordStmt:           q.rehashUserPasswordStmt,
+		releaseLoginAttemptStmt:          q.releaseLoginAttemptStmt,
+		revokeAPIKeyStmt:                 q.revokeAPIKeyStmt,
+		revokeOAuthClientStmt:            q.revokeOAuthClientStmt,
+		revokeTokenStmt:                  q.revokeTokenStmt,
+		rotateSessionStmt:                q.rotateSessionStmt,
+		setCurrencyEnabledStmt:           q.setCurrencyEnabledStmt,
+		touchAPIKeyStmt:                  q.touchAPIKeyStmt,
+		updateAccountOverdraftLimitStmt:  q.updateAccountOverdraftLimitStmt,
+		updateHoldStmt:                   q.updateHoldStmt,
+		updateIdempotencyKeyResponseStmt: q.updateIdempotencyKeyResponseStmt,
+		updateScheduledTransferStmt:      q.updateScheduledTransferStmt,
+		updateTransferReversalStmt:       q.updateTransferReversalStmt,
+		updateUserPasswordStmt:           q.updateUserPasswordStmt,
+		updateUserRoleStmt:               q.updateUserRoleStmt,
+		updateUserTierStmt:               q.updateUserTierStmt,
+		upsertExchangeRateStmt:           q.upsertExchangeRateStmt,
+		upsertOAuthConsentStmt:           q.upsertOAuthConsentStmt,
+		upsertUserTOTPStmt:               q.upsertUserTOTPStmt,
+		upsertVelocityLimitStmt:          q.upsertVelocityLimitStmt,
+		useOAuthAuthorizationCodeStmt:    q.useOAuthAuthorizationCodeStmt,
+		useRecoveryCodeStmt:              q.useRecoveryCodeStmt,
+		useTOTPStepStmt:                  q.useTOTPStepStmt,
+		useTransferQuoteStmt:             q.useTransferQuoteStmt,
+		useUserTokenStmt:                 q.useUserTokenStmt,
+		verifyUserEmailStmt:              q.verifyUserEmailStmt,
 	}
 }
@@ -0,0 +1,64 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: exchange_rate.sql
+
+package database
+
+import (
+	"context"
+)
+
+const getExchangeRate = `-- name: GetExchangeRate :one
+SELECT base_currency, quote_currency, rate, updated_at FROM exchange_rates
+WHERE base_currency = $1 AND quote_currency = $2
+LIMIT 1
+`
+
+type GetExchangeRateParams struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+}
+
+func (q *Queries) GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error) {
+	row := q.queryRow(ctx, q.getExchangeRateStmt, getExchangeRate, arg.BaseCurrency, arg.QuoteCurrency)
+	var i ExchangeRate
+	err := row.Scan(
+		&i.BaseCurrency,
+		&i.QuoteCurrency,
+		&i.Rate,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertExchangeRate = `-- name: UpsertExchangeRate :one
+INSERT INTO exchange_rates (
+    base_currency,
+    quote_currency,
+    rate
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (base_currency, quote_currency) DO UPDATE
+SET rate = EXCLUDED.rate, updated_at = now()
+RETURNING base_currency, quote_currency, rate, updated_at
+`
+
+type UpsertExchangeRateParams struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+}
+
+func (q *Queries) UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error) {
+	row := q.queryRow(ctx, q.upsertExchangeRateStmt, upsertExchangeRate, arg.BaseCurrency, arg.QuoteCurrency, arg.Rate)
+	var i ExchangeRate
+	err := row.Scan(
+		&i.BaseCurrency,
+		&i.QuoteCurrency,
+		&i.Rate,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,283 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrHoldNotActive      = errors.New("hold is already captured, voided or expired")
+	ErrHoldExpired        = errors.New("hold is expired")
+	ErrHoldAmountExceeded = errors.New("capture exceeds the amount left on the hold")
+)
+
+// Statuses of a hold, only the authorized and partially captured holds reserve funds
+const (
+	HoldStatusAuthorized        = "authorized"
+	HoldStatusPartiallyCaptured = "partially_captured"
+	HoldStatusCaptured          = "captured"
+	HoldStatusVoided            = "voided"
+	HoldStatusExpired           = "expired"
+)
+
+// IsActive reports whether the hold still reserves funds
+func (h Hold) IsActive() bool {
+	return h.Status == HoldStatusAuthorized || h.Status == HoldStatusPartiallyCaptured
+}
+
+// Remaining returns the amount of the hold which is not captured yet
+func (h Hold) Remaining() int64 {
+	return h.Amount - h.CapturedAmount
+}
+
+// AuthorizeHoldTxParams contains the funds to reserve on the from account
+type AuthorizeHoldTxParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// AuthorizeHoldTxResult is the new hold and the from account with its reduced available balance
+type AuthorizeHoldTxResult struct {
+	Hold        Hold    `json:"hold"`
+	FromAccount Account `json:"from_account"`
+}
+
+// AuthorizeHoldTx reserves the amount on the from account. The balance is not changed and no entry is posted,
+// only the available balance is reduced. It fails with ErrInsufficientFunds if the available balance would go
+// past the overdraft limit of the account.
+func (s *SQLStore) AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (AuthorizeHoldTxResult, error) {
+	var result AuthorizeHoldTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		// 1. reserve the funds, the update locks the account so concurrent holds can't reserve the same funds
+		result.FromAccount, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			Amount: -arg.Amount,
+			ID:     arg.FromAccountID,
+		})
+		if err != nil {
+			return fundsError(err)
+		}
+
+		// 2. create the hold
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			ExpiresAt:     arg.ExpiresAt,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// CaptureHoldTxParams contains the hold to capture
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Amount defaults to the amount left on the hold, a smaller amount is a partial capture
+	Amount int64 `json:"amount"`
+	// Final releases what is left on the hold after this capture, otherwise it can be captured again
+	Final bool `json:"final"`
+}
+
+// CaptureHoldTxResult is the captured hold and the transfer which posted the captured amount
+type CaptureHoldTxResult struct {
+	Hold        Hold     `json:"hold"`
+	Transfer    Transfer `json:"transfer"`
+	FromAccount Account  `json:"from_account"`
+	ToAccount   Account  `json:"to_account"`
+	FromEntry   Entry    `json:"from_entry"`
+	ToEntry     Entry    `json:"to_entry"`
+}
+
+// CaptureHoldTx moves the captured amount of an active hold to the to account with a transfer and its entries.
+// The captured amount already left the available balance of the from account when it was held.
+// The capture is checked against the velocity limits of the from account like a transfer, a rejected
+// capture fails with a VelocityError and leaves the hold as it was.
+func (s *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		// 1. lock the hold so it can't be captured, voided or expired concurrently
+		hold, err := lockActiveHold(ctx, q, arg.HoldID)
+		if err != nil {
+			return err
+		}
+		if !time.Now().Before(hold.ExpiresAt) {
+			return ErrHoldExpired
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Remaining()
+		}
+		if amount < 0 || amount > hold.Remaining() {
+			return ErrHoldAmountExceeded
+		}
+
+		// 2. post the transfer of the captured amount
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: hold.FromAccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+			ToAmount:      amount,
+			ExchangeRate:  "1",
+			HoldID:        sql.NullInt64{Int64: hold.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  hold.FromAccountID,
+			Amount:     -amount,
+			TransferID: transferID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  hold.ToAccountID,
+			Amount:     amount,
+			TransferID: transferID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// 3. the final capture releases what is left on the hold
+		captured := hold.CapturedAmount + amount
+		release := int64(0)
+		status := HoldStatusPartiallyCaptured
+		if arg.Final || captured == hold.Amount {
+			release = hold.Amount - captured
+			status = HoldStatusCaptured
+		}
+
+		// 4. update the accounts, the lowest id first as in TransferTx
+		if hold.FromAccountID < hold.ToAccountID {
+			result.FromAccount, err = captureFromAccount(ctx, q, hold.FromAccountID, amount, release)
+			if err != nil {
+				return err
+			}
+			result.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{Amount: amount, ID: hold.ToAccountID})
+		} else {
+			result.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{Amount: amount, ID: hold.ToAccountID})
+			if err != nil {
+				return err
+			}
+			result.FromAccount, err = captureFromAccount(ctx, q, hold.FromAccountID, amount, release)
+		}
+		if err != nil {
+			return err
+		}
+
+		// 5. check the velocity limits of the from account on the captured amount, which is posted as a transfer
+		if err = checkVelocity(ctx, q, result.FromAccount, amount); err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+			ID:             hold.ID,
+			Status:         status,
+			CapturedAmount: captured,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// captureFromAccount debits the captured amount from the balance of the from account
+// and gives the released amount back to its available balance
+func captureFromAccount(ctx context.Context, q *Queries, accountID int64, amount int64, release int64) (Account, error) {
+	account, err := q.CaptureAccountBalance(ctx, CaptureAccountBalanceParams{Amount: amount, ID: accountID})
+	if err != nil || release == 0 {
+		return account, err
+	}
+	return q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{Amount: release, ID: accountID})
+}
+
+// ReleaseHoldTxResult is the voided or expired hold and the from account the funds were released to
+type ReleaseHoldTxResult struct {
+	Hold        Hold    `json:"hold"`
+	FromAccount Account `json:"from_account"`
+}
+
+// VoidHoldTx cancels an active hold, what is left on it goes back to the available balance of the from account
+func (s *SQLStore) VoidHoldTx(ctx context.Context, holdID int64) (ReleaseHoldTxResult, error) {
+	var result ReleaseHoldTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		hold, err := lockActiveHold(ctx, q, holdID)
+		if err != nil {
+			return err
+		}
+
+		result, err = releaseHold(ctx, q, hold, HoldStatusVoided)
+		return err
+	})
+
+	return result, err
+}
+
+// ExpireNextHoldTx expires the active hold which expired first and releases what is left on it.
+// Holds locked by a capture or another sweeper are skipped, sql.ErrNoRows is returned when no hold is left to expire.
+// Each hold is expired in its own transaction, so the sweeper never locks more than one account at a time.
+func (s *SQLStore) ExpireNextHoldTx(ctx context.Context) (ReleaseHoldTxResult, error) {
+	var result ReleaseHoldTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetNextExpiredHoldForUpdate(ctx)
+		if err != nil {
+			return err
+		}
+
+		result, err = releaseHold(ctx, q, hold, HoldStatusExpired)
+		return err
+	})
+
+	return result, err
+}
+
+// lockActiveHold locks the hold, it fails with ErrHoldNotActive if the hold doesn't reserve funds anymore
+func lockActiveHold(ctx context.Context, q *Queries, holdID int64) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return hold, err
+	}
+	if !hold.IsActive() {
+		return hold, ErrHoldNotActive
+	}
+	return hold, nil
+}
+
+// releaseHold gives what is left on the locked hold back to the available balance and sets its final status
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string) (ReleaseHoldTxResult, error) {
+	var result ReleaseHoldTxResult
+	var err error
+
+	result.FromAccount, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+		Amount: hold.Remaining(),
+		ID:     hold.FromAccountID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+		ID:             hold.ID,
+		Status:         status,
+		CapturedAmount: hold.CapturedAmount,
+	})
+	return result, err
+}
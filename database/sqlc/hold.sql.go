@@ -0,0 +1,154 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: hold.sql
+
+package database
+
+import (
+	"context"
+	"time"
+)
+
+const createHold = `-- name: CreateHold :one
+INSERT INTO holds (
+    from_account_id,
+    to_account_id,
+    amount,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, from_account_id, to_account_id, amount, captured_amount, status, expires_at, updated_at, created_at
+`
+
+type CreateHoldParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error) {
+	row := q.queryRow(ctx, q.createHoldStmt, createHold,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ExpiresAt,
+	)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getHold = `-- name: GetHold :one
+SELECT id, from_account_id, to_account_id, amount, captured_amount, status, expires_at, updated_at, created_at FROM holds
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetHold(ctx context.Context, id int64) (Hold, error) {
+	row := q.queryRow(ctx, q.getHoldStmt, getHold, id)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getHoldForUpdate = `-- name: GetHoldForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, captured_amount, status, expires_at, updated_at, created_at FROM holds
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetHoldForUpdate(ctx context.Context, id int64) (Hold, error) {
+	row := q.queryRow(ctx, q.getHoldForUpdateStmt, getHoldForUpdate, id)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getNextExpiredHoldForUpdate = `-- name: GetNextExpiredHoldForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, captured_amount, status, expires_at, updated_at, created_at FROM holds
+WHERE status IN ('authorized', 'partially_captured')
+  AND expires_at <= now()
+ORDER BY expires_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) GetNextExpiredHoldForUpdate(ctx context.Context) (Hold, error) {
+	row := q.queryRow(ctx, q.getNextExpiredHoldForUpdateStmt, getNextExpiredHoldForUpdate)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateHold = `-- name: UpdateHold :one
+UPDATE holds
+SET status = $2,
+    captured_amount = $3,
+    updated_at = now()
+WHERE id = $1
+RETURNING id, from_account_id, to_account_id, amount, captured_amount, status, expires_at, updated_at, created_at
+`
+
+type UpdateHoldParams struct {
+	ID             int64  `json:"id"`
+	Status         string `json:"status"`
+	CapturedAmount int64  `json:"captured_amount"`
+}
+
+func (q *Queries) UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error) {
+	row := q.queryRow(ctx, q.updateHoldStmt, updateHold, arg.ID, arg.Status, arg.CapturedAmount)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func authorizeRandomHold(t *testing.T, store Store, from, to Account, amount int64, expiresAt time.Time) Hold {
+	result, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		ExpiresAt:     expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusAuthorized, result.Hold.Status)
+	require.Zero(t, result.Hold.CapturedAmount)
+	require.Equal(t, from.Balance, result.FromAccount.Balance)
+	require.Equal(t, from.AvailableBalance-amount, result.FromAccount.AvailableBalance)
+	return result.Hold
+}
+
+func TestAuthorizeHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
+
+	hold := authorizeRandomHold(t, store, account1, account2, 60, time.Now().Add(time.Hour))
+	require.True(t, hold.IsActive())
+
+	// 1. the held funds can't be held again
+	_, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(40), account.AvailableBalance)
+}
+
+func TestCaptureHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
+
+	hold := authorizeRandomHold(t, store, account1, account2, 60, time.Now().Add(time.Hour))
+
+	// 1. a partial capture posts the captured amount and keeps the rest on hold
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 20})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusPartiallyCaptured, result.Hold.Status)
+	require.Equal(t, int64(20), result.Hold.CapturedAmount)
+	require.Equal(t, sql.NullInt64{Int64: hold.ID, Valid: true}, result.Transfer.HoldID)
+	require.Equal(t, int64(-20), result.FromEntry.Amount)
+	require.Equal(t, int64(20), result.ToEntry.Amount)
+	require.Equal(t, int64(80), result.FromAccount.Balance)
+	require.Equal(t, int64(40), result.FromAccount.AvailableBalance)
+	require.Equal(t, int64(120), result.ToAccount.Balance)
+	require.Equal(t, int64(120), result.ToAccount.AvailableBalance)
+
+	// 2. more than what is left can't be captured
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 50})
+	require.ErrorIs(t, err, ErrHoldAmountExceeded)
+
+	// 3. the final capture releases what is left
+	result, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 10, Final: true})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusCaptured, result.Hold.Status)
+	require.Equal(t, int64(30), result.Hold.CapturedAmount)
+	require.Equal(t, int64(70), result.FromAccount.Balance)
+	require.Equal(t, int64(70), result.FromAccount.AvailableBalance)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	report, err := store.VerifyLedger(context.Background())
+	require.NoError(t, err)
+	require.NotContains(t, driftAccountIDs(report), account1.ID)
+	require.NotContains(t, driftAccountIDs(report), account2.ID)
+	for _, drift := range report.AvailableBalanceDrifts {
+		require.NotEqual(t, account1.ID, drift.ID)
+	}
+}
+
+func TestVoidHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
+
+	hold := authorizeRandomHold(t, store, account1, account2, 60, time.Now().Add(time.Hour))
+
+	result, err := store.VoidHoldTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusVoided, result.Hold.Status)
+	require.Equal(t, int64(100), result.FromAccount.Balance)
+	require.Equal(t, int64(100), result.FromAccount.AvailableBalance)
+
+	// 1. a voided hold can't be voided or captured
+	_, err = store.VoidHoldTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestExpireNextHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
+
+	hold := authorizeRandomHold(t, store, account1, account2, 60, time.Now().Add(-time.Second))
+
+	// 1. an expired hold can't be captured
+	_, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	// 2. the sweep releases every expired hold, including this one
+	for {
+		_, err := store.ExpireNextHoldTx(context.Background())
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	expired, err := testQueries.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusExpired, expired.Status)
+
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), account.AvailableBalance)
+}
@@ -0,0 +1,83 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: idempotency_key.sql
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+)
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    owner,
+    key,
+    request_hash
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (owner, key) DO NOTHING
+RETURNING owner, key, request_hash, response, created_at
+`
+
+type CreateIdempotencyKeyParams struct {
+	Owner       string `json:"owner"`
+	Key         string `json:"key"`
+	RequestHash string `json:"request_hash"`
+}
+
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.queryRow(ctx, q.createIdempotencyKeyStmt, createIdempotencyKey, arg.Owner, arg.Key, arg.RequestHash)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Owner,
+		&i.Key,
+		&i.RequestHash,
+		&i.Response,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT owner, key, request_hash, response, created_at FROM idempotency_keys
+WHERE owner = $1 AND key = $2
+LIMIT 1
+`
+
+type GetIdempotencyKeyParams struct {
+	Owner string `json:"owner"`
+	Key   string `json:"key"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.queryRow(ctx, q.getIdempotencyKeyStmt, getIdempotencyKey, arg.Owner, arg.Key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Owner,
+		&i.Key,
+		&i.RequestHash,
+		&i.Response,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateIdempotencyKeyResponse = `-- name: UpdateIdempotencyKeyResponse :exec
+UPDATE idempotency_keys
+SET response = $3
+WHERE owner = $1 AND key = $2
+`
+
+type UpdateIdempotencyKeyResponseParams struct {
+	Owner    string          `json:"owner"`
+	Key      string          `json:"key"`
+	Response json.RawMessage `json:"response"`
+}
+
+func (q *Queries) UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) error {
+	_, err := q.exec(ctx, q.updateIdempotencyKeyResponseStmt, updateIdempotencyKeyResponse, arg.Owner, arg.Key, arg.Response)
+	return err
+}
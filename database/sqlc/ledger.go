@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// LedgerReport lists every violation of the double-entry invariants found in the ledger.
+type LedgerReport struct {
+	// AccountDrifts are the accounts whose balance differs from the sum of their entries
+	AccountDrifts []ListAccountBalanceDriftsRow `json:"account_drifts"`
+	// UnbalancedTransfers are the transfers whose legs are missing or do not match the transfer amounts.
+	// The legs of a same currency transfer net to zero, a cross currency transfer nets to zero at its exchange rate.
+	// The debit is the negative leg of the from account and the credit the positive leg of the to account,
+	// so the legs of a transfer to the same account are told apart.
+	UnbalancedTransfers []ListUnbalancedTransfersRow `json:"unbalanced_transfers"`
+	// AvailableBalanceDrifts are the accounts whose available balance differs from the balance less the active holds
+	AvailableBalanceDrifts []ListAvailableBalanceDriftsRow `json:"available_balance_drifts"`
+	// ReversalDrifts are the transfers whose reversed amount or status doesn't match the reversals linked to them
+	ReversalDrifts []ListReversalDriftsRow `json:"reversal_drifts"`
+}
+
+// IsBalanced returns true if no violation was found
+func (r LedgerReport) IsBalanced() bool {
+	return len(r.AccountDrifts) == 0 && len(r.UnbalancedTransfers) == 0 && len(r.AvailableBalanceDrifts) == 0 &&
+		len(r.ReversalDrifts) == 0
+}
+
+// VerifyLedger scans accounts, entries and transfers and reports every violation of the ledger invariants.
+// The checks run on the same snapshot so concurrent transfers cannot show up as false drifts.
+func (s *SQLStore) VerifyLedger(ctx context.Context) (LedgerReport, error) {
+	var report LedgerReport
+
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := s.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		var err error
+
+		// 1. every account balance must be equal to the sum of its entries
+		report.AccountDrifts, err = q.ListAccountBalanceDrifts(ctx)
+		if err != nil {
+			return err
+		}
+
+		// 2. every transfer must debit its amount and credit its converted amount
+		report.UnbalancedTransfers, err = q.ListUnbalancedTransfers(ctx)
+		if err != nil {
+			return err
+		}
+
+		// 3. every available balance must be the balance less what the active holds reserve
+		report.AvailableBalanceDrifts, err = q.ListAvailableBalanceDrifts(ctx)
+		if err != nil {
+			return err
+		}
+
+		// 4. every reversed amount must be the sum of the refunds linked to the transfer
+		report.ReversalDrifts, err = q.ListReversalDrifts(ctx)
+		return err
+	})
+
+	return report, err
+}
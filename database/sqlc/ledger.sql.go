@@ -0,0 +1,234 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: ledger.sql
+
+package database
+
+import (
+	"context"
+)
+
+const listAccountBalanceDrifts = `-- name: ListAccountBalanceDrifts :many
+SELECT
+    a.id,
+    a.owner,
+    a.currency,
+    a.balance,
+    CAST(COALESCE(SUM(e.amount), 0) AS bigint) AS entries_sum
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id
+`
+
+type ListAccountBalanceDriftsRow struct {
+	ID         int64  `json:"id"`
+	Owner      string `json:"owner"`
+	Currency   string `json:"currency"`
+	Balance    int64  `json:"balance"`
+	EntriesSum int64  `json:"entries_sum"`
+}
+
+func (q *Queries) ListAccountBalanceDrifts(ctx context.Context) ([]ListAccountBalanceDriftsRow, error) {
+	rows, err := q.query(ctx, q.listAccountBalanceDriftsStmt, listAccountBalanceDrifts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountBalanceDriftsRow{}
+	for rows.Next() {
+		var i ListAccountBalanceDriftsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Currency,
+			&i.Balance,
+			&i.EntriesSum,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAvailableBalanceDrifts = `-- name: ListAvailableBalanceDrifts :many
+SELECT
+    a.id,
+    a.owner,
+    a.currency,
+    a.balance,
+    a.available_balance,
+    CAST(COALESCE(SUM(h.amount - h.captured_amount), 0) AS bigint) AS held_amount
+FROM accounts a
+LEFT JOIN holds h ON h.from_account_id = a.id AND h.status IN ('authorized', 'partially_captured')
+GROUP BY a.id
+HAVING a.available_balance <> a.balance - COALESCE(SUM(h.amount - h.captured_amount), 0)
+ORDER BY a.id
+`
+
+type ListAvailableBalanceDriftsRow struct {
+	ID               int64  `json:"id"`
+	Owner            string `json:"owner"`
+	Currency         string `json:"currency"`
+	Balance          int64  `json:"balance"`
+	AvailableBalance int64  `json:"available_balance"`
+	HeldAmount       int64  `json:"held_amount"`
+}
+
+func (q *Queries) ListAvailableBalanceDrifts(ctx context.Context) ([]ListAvailableBalanceDriftsRow, error) {
+	rows, err := q.query(ctx, q.listAvailableBalanceDriftsStmt, listAvailableBalanceDrifts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAvailableBalanceDriftsRow{}
+	for rows.Next() {
+		var i ListAvailableBalanceDriftsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Currency,
+			&i.Balance,
+			&i.AvailableBalance,
+			&i.HeldAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReversalDrifts = `-- name: ListReversalDrifts :many
+SELECT
+    t.id,
+    t.amount,
+    t.status,
+    t.reversed_amount,
+    CAST(COALESCE(SUM(r.to_amount), 0) AS bigint) AS refunded
+FROM transfers t
+LEFT JOIN transfers r ON r.reversal_of = t.id
+WHERE t.reversal_of IS NULL
+GROUP BY t.id
+HAVING t.reversed_amount <> COALESCE(SUM(r.to_amount), 0)
+    OR t.status <> CASE
+        WHEN t.reversed_amount = 0 THEN 'posted'
+        WHEN t.reversed_amount < t.amount THEN 'partially_reversed'
+        ELSE 'reversed'
+    END
+ORDER BY t.id
+`
+
+type ListReversalDriftsRow struct {
+	ID             int64  `json:"id"`
+	Amount         int64  `json:"amount"`
+	Status         string `json:"status"`
+	ReversedAmount int64  `json:"reversed_amount"`
+	Refunded       int64  `json:"refunded"`
+}
+
+func (q *Queries) ListReversalDrifts(ctx context.Context) ([]ListReversalDriftsRow, error) {
+	rows, err := q.query(ctx, q.listReversalDriftsStmt, listReversalDrifts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListReversalDriftsRow{}
+	for rows.Next() {
+		var i ListReversalDriftsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.Status,
+			&i.ReversedAmount,
+			&i.Refunded,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnbalancedTransfers = `-- name: ListUnbalancedTransfers :many
+SELECT
+    t.id,
+    t.from_account_id,
+    t.to_account_id,
+    t.amount,
+    t.to_amount,
+    COUNT(e.id) AS legs,
+    CAST(COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id AND e.amount < 0), 0) AS bigint) AS debited,
+    CAST(COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id AND e.amount > 0), 0) AS bigint) AS credited
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING COUNT(e.id) <> 2
+    OR COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id AND e.amount < 0), 0) <> -t.amount
+    OR COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id AND e.amount > 0), 0) <> t.to_amount
+ORDER BY t.id
+`
+
+type ListUnbalancedTransfersRow struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+	ToAmount      int64 `json:"to_amount"`
+	Legs          int64 `json:"legs"`
+	Debited       int64 `json:"debited"`
+	Credited      int64 `json:"credited"`
+}
+
+func (q *Queries) ListUnbalancedTransfers(ctx context.Context) ([]ListUnbalancedTransfersRow, error) {
+	rows, err := q.query(ctx, q.listUnbalancedTransfersStmt, listUnbalancedTransfers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUnbalancedTransfersRow{}
+	for rows.Next() {
+		var i ListUnbalancedTransfersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.ToAmount,
+			&i.Legs,
+			&i.Debited,
+			&i.Credited,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
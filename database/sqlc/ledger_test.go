@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/stretchr/testify/require"
+)
+
+// createFundedAccount creates an account whose balance is backed by an opening entry
+func createFundedAccount(t *testing.T, balance int64) Account {
+	user := createRandomUser(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  0,
+		Currency: util.RandomCurrency(),
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID: account.ID,
+		Amount:    balance,
+	})
+	require.NoError(t, err)
+
+	account, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: balance,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestVerifyLedger(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// the legs of a transfer to the same account are told apart by their sign
+	self, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account2.ID,
+		ToAccountId:   account2.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+
+	// 1. the transfers keep both accounts and their legs balanced
+	report, err := store.VerifyLedger(context.Background())
+	require.NoError(t, err)
+	require.NotContains(t, driftAccountIDs(report), account1.ID)
+	require.NotContains(t, driftAccountIDs(report), account2.ID)
+	for _, transfer := range report.UnbalancedTransfers {
+		require.NotEqual(t, result.Transfer.ID, transfer.ID)
+		require.NotEqual(t, self.Transfer.ID, transfer.ID)
+	}
+
+	// 2. a balance change without an entry is reported as drift
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account1.ID,
+		Amount: 5,
+	})
+	require.NoError(t, err)
+
+	report, err = store.VerifyLedger(context.Background())
+	require.NoError(t, err)
+	require.False(t, report.IsBalanced())
+	require.Contains(t, driftAccountIDs(report), account1.ID)
+	require.NotContains(t, driftAccountIDs(report), account2.ID)
+}
+
+func driftAccountIDs(report LedgerReport) []int64 {
+	ids := make([]int64, 0, len(report.AccountDrifts))
+	for _, drift := range report.AccountDrifts {
+		ids = append(ids, drift.ID)
+	}
+	return ids
+}
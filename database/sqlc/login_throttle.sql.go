@@ -0,0 +1,150 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: login_throttle.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const deleteLoginThrottle = `-- name: DeleteLoginThrottle :execrows
+DELETE FROM login_throttles
+WHERE kind = $1 AND subject = $2
+`
+
+type DeleteLoginThrottleParams struct {
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) DeleteLoginThrottle(ctx context.Context, arg DeleteLoginThrottleParams) (int64, error) {
+	result, err := q.exec(ctx, q.deleteLoginThrottleStmt, deleteLoginThrottle, arg.Kind, arg.Subject)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteStaleLoginThrottles = `-- name: DeleteStaleLoginThrottles :execrows
+DELETE FROM login_throttles
+WHERE last_failed_at < $1
+  AND (locked_until IS NULL OR locked_until < now())
+`
+
+func (q *Queries) DeleteStaleLoginThrottles(ctx context.Context, resetBefore time.Time) (int64, error) {
+	result, err := q.exec(ctx, q.deleteStaleLoginThrottlesStmt, deleteStaleLoginThrottles, resetBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getLoginThrottle = `-- name: GetLoginThrottle :one
+SELECT kind, subject, failed_attempts, last_failed_at, locked_until FROM login_throttles
+WHERE kind = $1 AND subject = $2 LIMIT 1
+`
+
+type GetLoginThrottleParams struct {
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) GetLoginThrottle(ctx context.Context, arg GetLoginThrottleParams) (LoginThrottle, error) {
+	row := q.queryRow(ctx, q.getLoginThrottleStmt, getLoginThrottle, arg.Kind, arg.Subject)
+	var i LoginThrottle
+	err := row.Scan(
+		&i.Kind,
+		&i.Subject,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const lockLoginThrottle = `-- name: LockLoginThrottle :one
+UPDATE login_throttles
+SET locked_until = $1
+WHERE kind = $2 AND subject = $3
+  AND (locked_until IS NULL OR locked_until < $1)
+RETURNING kind, subject, failed_attempts, last_failed_at, locked_until
+`
+
+type LockLoginThrottleParams struct {
+	LockedUntil sql.NullTime `json:"locked_until"`
+	Kind        string       `json:"kind"`
+	Subject     string       `json:"subject"`
+}
+
+func (q *Queries) LockLoginThrottle(ctx context.Context, arg LockLoginThrottleParams) (LoginThrottle, error) {
+	row := q.queryRow(ctx, q.lockLoginThrottleStmt, lockLoginThrottle, arg.LockedUntil, arg.Kind, arg.Subject)
+	var i LoginThrottle
+	err := row.Scan(
+		&i.Kind,
+		&i.Subject,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const recordLoginAttempt = `-- name: RecordLoginAttempt :one
+INSERT INTO login_throttles (
+    kind,
+    subject,
+    failed_attempts,
+    last_failed_at
+) VALUES (
+    $1, $2, 1, now()
+) ON CONFLICT (kind, subject) DO UPDATE
+SET failed_attempts = CASE
+        WHEN login_throttles.locked_until > now() THEN login_throttles.failed_attempts
+        WHEN login_throttles.last_failed_at < $3 THEN 1
+        ELSE login_throttles.failed_attempts + 1
+    END,
+    last_failed_at = CASE
+        WHEN login_throttles.locked_until > now() THEN login_throttles.last_failed_at
+        ELSE now()
+    END
+RETURNING kind, subject, failed_attempts, last_failed_at, locked_until
+`
+
+type RecordLoginAttemptParams struct {
+	Kind        string    `json:"kind"`
+	Subject     string    `json:"subject"`
+	ResetBefore time.Time `json:"reset_before"`
+}
+
+func (q *Queries) RecordLoginAttempt(ctx context.Context, arg RecordLoginAttemptParams) (LoginThrottle, error) {
+	row := q.queryRow(ctx, q.recordLoginAttemptStmt, recordLoginAttempt, arg.Kind, arg.Subject, arg.ResetBefore)
+	var i LoginThrottle
+	err := row.Scan(
+		&i.Kind,
+		&i.Subject,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const releaseLoginAttempt = `-- name: ReleaseLoginAttempt :exec
+UPDATE login_throttles
+SET failed_attempts = GREATEST(failed_attempts - 1, 0)
+WHERE kind = $1 AND subject = $2
+`
+
+type ReleaseLoginAttemptParams struct {
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) ReleaseLoginAttempt(ctx context.Context, arg ReleaseLoginAttemptParams) error {
+	_, err := q.exec(ctx, q.releaseLoginAttemptStmt, releaseLoginAttempt, arg.Kind, arg.Subject)
+	return err
+}
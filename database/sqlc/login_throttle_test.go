@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordLoginAttempt(t *testing.T) {
+	arg := RecordLoginAttemptParams{
+		Kind:        "username",
+		Subject:     util.RandomOwner(),
+		ResetBefore: time.Now().Add(-time.Hour),
+	}
+
+	// 1. the attempts are counted per kind and subject
+	throttle, err := testQueries.RecordLoginAttempt(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), throttle.FailedAttempts)
+	require.False(t, throttle.LockedUntil.Valid)
+
+	throttle, err = testQueries.RecordLoginAttempt(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), throttle.FailedAttempts)
+
+	// 2. attempts before the reset time start the count again
+	arg.ResetBefore = time.Now().Add(time.Minute)
+	throttle, err = testQueries.RecordLoginAttempt(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), throttle.FailedAttempts)
+
+	// 3. a lock is only extended, never shortened
+	lockedUntil := time.Now().Add(time.Hour)
+	throttle, err = testQueries.LockLoginThrottle(context.Background(), LockLoginThrottleParams{
+		Kind:        arg.Kind,
+		Subject:     arg.Subject,
+		LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true},
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, lockedUntil, throttle.LockedUntil.Time, time.Second)
+
+	_, err = testQueries.LockLoginThrottle(context.Background(), LockLoginThrottleParams{
+		Kind:        arg.Kind,
+		Subject:     arg.Subject,
+		LockedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// 3.1 the attempts while locked are not counted, a released attempt is given back
+	throttle, err = testQueries.RecordLoginAttempt(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), throttle.FailedAttempts)
+
+	err = testQueries.ReleaseLoginAttempt(context.Background(), ReleaseLoginAttemptParams{
+		Kind:    arg.Kind,
+		Subject: arg.Subject,
+	})
+	require.NoError(t, err)
+
+	throttle, err = testQueries.GetLoginThrottle(context.Background(), GetLoginThrottleParams{
+		Kind:    arg.Kind,
+		Subject: arg.Subject,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), throttle.FailedAttempts)
+
+	// 4. a locked throttle is not stale, deleting it unlocks the subject
+	_, err = testQueries.DeleteStaleLoginThrottles(context.Background(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	rows, err := testQueries.DeleteLoginThrottle(context.Background(), DeleteLoginThrottleParams{
+		Kind:    arg.Kind,
+		Subject: arg.Subject,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	_, err = testQueries.GetLoginThrottle(context.Background(), GetLoginThrottleParams{
+		Kind:    arg.Kind,
+		Subject: arg.Subject,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+)
+
+// EnableTOTPTxParams contains the user confirming the TOTP enrollment and the hashes of the new recovery codes
+type EnableTOTPTxParams struct {
+	Username           string   `json:"username"`
+	RecoveryCodeHashes []string `json:"recovery_code_hashes"`
+}
+
+// EnableTOTPTx enables the pending TOTP secret of the user and replaces the recovery codes.
+// It returns sql.ErrNoRows if there is no pending enrollment.
+func (s *SQLStore) EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (UserTotp, error) {
+	var totp UserTotp
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		totp, err = q.EnableUserTOTP(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteRecoveryCodes(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		for _, hash := range arg.RecoveryCodeHashes {
+			_, err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				Username: arg.Username,
+				CodeHash: hash,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return totp, err
+}
+
+// DisableTOTPTx removes the TOTP secret and the recovery codes of the user
+func (s *SQLStore) DisableTOTPTx(ctx context.Context, username string) error {
+	return s.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteRecoveryCodes(ctx, username)
+		if err != nil {
+			return err
+		}
+		return q.DeleteUserTOTP(ctx, username)
+	})
+}
@@ -0,0 +1,271 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: mfa.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const attemptMFAChallenge = `-- name: AttemptMFAChallenge :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING id, username, attempts, expires_at, consumed_at, created_at
+`
+
+func (q *Queries) AttemptMFAChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error) {
+	row := q.queryRow(ctx, q.attemptMFAChallengeStmt, attemptMFAChallenge, id)
+	var i MfaChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Attempts,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const consumeMFAChallenge = `-- name: ConsumeMFAChallenge :one
+UPDATE mfa_challenges
+SET consumed_at = now()
+WHERE id = $1 AND consumed_at IS NULL
+RETURNING id, username, attempts, expires_at, consumed_at, created_at
+`
+
+func (q *Queries) ConsumeMFAChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error) {
+	row := q.queryRow(ctx, q.consumeMFAChallengeStmt, consumeMFAChallenge, id)
+	var i MfaChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Attempts,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createMFAChallenge = `-- name: CreateMFAChallenge :one
+INSERT INTO mfa_challenges (
+    id,
+    username,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING id, username, attempts, expires_at, consumed_at, created_at
+`
+
+type CreateMFAChallengeParams struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateMFAChallenge(ctx context.Context, arg CreateMFAChallengeParams) (MfaChallenge, error) {
+	row := q.queryRow(ctx, q.createMFAChallengeStmt, createMFAChallenge, arg.ID, arg.Username, arg.ExpiresAt)
+	var i MfaChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Attempts,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+    username,
+    code_hash
+) VALUES (
+    $1, $2
+) RETURNING id, username, code_hash, used_at, created_at
+`
+
+type CreateRecoveryCodeParams struct {
+	Username string `json:"username"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.queryRow(ctx, q.createRecoveryCodeStmt, createRecoveryCode, arg.Username, arg.CodeHash)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE username = $1
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, username string) error {
+	_, err := q.exec(ctx, q.deleteRecoveryCodesStmt, deleteRecoveryCodes, username)
+	return err
+}
+
+const deleteUserTOTP = `-- name: DeleteUserTOTP :exec
+DELETE FROM user_totps
+WHERE username = $1
+`
+
+func (q *Queries) DeleteUserTOTP(ctx context.Context, username string) error {
+	_, err := q.exec(ctx, q.deleteUserTOTPStmt, deleteUserTOTP, username)
+	return err
+}
+
+const enableUserTOTP = `-- name: EnableUserTOTP :one
+UPDATE user_totps
+SET enabled_at = now()
+WHERE username = $1 AND enabled_at IS NULL
+RETURNING username, encrypted_secret, enabled_at, last_used_step, created_at
+`
+
+func (q *Queries) EnableUserTOTP(ctx context.Context, username string) (UserTotp, error) {
+	row := q.queryRow(ctx, q.enableUserTOTPStmt, enableUserTOTP, username)
+	var i UserTotp
+	err := row.Scan(
+		&i.Username,
+		&i.EncryptedSecret,
+		&i.EnabledAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getMFAChallenge = `-- name: GetMFAChallenge :one
+SELECT id, username, attempts, expires_at, consumed_at, created_at FROM mfa_challenges
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetMFAChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error) {
+	row := q.queryRow(ctx, q.getMFAChallengeStmt, getMFAChallenge, id)
+	var i MfaChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Attempts,
+		&i.ExpiresAt,
+		&i.ConsumedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserTOTP = `-- name: GetUserTOTP :one
+SELECT username, encrypted_secret, enabled_at, last_used_step, created_at FROM user_totps
+WHERE username = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserTOTP(ctx context.Context, username string) (UserTotp, error) {
+	row := q.queryRow(ctx, q.getUserTOTPStmt, getUserTOTP, username)
+	var i UserTotp
+	err := row.Scan(
+		&i.Username,
+		&i.EncryptedSecret,
+		&i.EnabledAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertUserTOTP = `-- name: UpsertUserTOTP :one
+INSERT INTO user_totps (
+    username,
+    encrypted_secret
+) VALUES (
+    $1, $2
+) ON CONFLICT (username) DO UPDATE
+SET encrypted_secret = EXCLUDED.encrypted_secret,
+    last_used_step = 0,
+    created_at = now()
+WHERE user_totps.enabled_at IS NULL
+RETURNING username, encrypted_secret, enabled_at, last_used_step, created_at
+`
+
+type UpsertUserTOTPParams struct {
+	Username        string `json:"username"`
+	EncryptedSecret []byte `json:"encrypted_secret"`
+}
+
+func (q *Queries) UpsertUserTOTP(ctx context.Context, arg UpsertUserTOTPParams) (UserTotp, error) {
+	row := q.queryRow(ctx, q.upsertUserTOTPStmt, upsertUserTOTP, arg.Username, arg.EncryptedSecret)
+	var i UserTotp
+	err := row.Scan(
+		&i.Username,
+		&i.EncryptedSecret,
+		&i.EnabledAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = now()
+WHERE username = $1 AND code_hash = $2 AND used_at IS NULL
+RETURNING id, username, code_hash, used_at, created_at
+`
+
+type UseRecoveryCodeParams struct {
+	Username string `json:"username"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.queryRow(ctx, q.useRecoveryCodeStmt, useRecoveryCode, arg.Username, arg.CodeHash)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useTOTPStep = `-- name: UseTOTPStep :one
+UPDATE user_totps
+SET last_used_step = $1
+WHERE username = $2 AND last_used_step < $1
+RETURNING username, encrypted_secret, enabled_at, last_used_step, created_at
+`
+
+type UseTOTPStepParams struct {
+	Step     int64  `json:"step"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (UserTotp, error) {
+	row := q.queryRow(ctx, q.useTOTPStepStmt, useTOTPStep, arg.Step, arg.Username)
+	var i UserTotp
+	err := row.Scan(
+		&i.Username,
+		&i.EncryptedSecret,
+		&i.EnabledAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableTOTPTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	// 1. the enrollment is pending until it is confirmed
+	totp, err := store.UpsertUserTOTP(context.Background(), UpsertUserTOTPParams{
+		Username:        user.Username,
+		EncryptedSecret: []byte(util.RandomString(40)),
+	})
+	require.NoError(t, err)
+	require.False(t, totp.EnabledAt.Valid)
+
+	// 2. enable it with the recovery codes
+	totp, err = store.EnableTOTPTx(context.Background(), EnableTOTPTxParams{
+		Username:           user.Username,
+		RecoveryCodeHashes: []string{util.RandomString(64), util.RandomString(64)},
+	})
+	require.NoError(t, err)
+	require.True(t, totp.EnabledAt.Valid)
+
+	// 3. an enabled secret is neither replaced nor enabled again
+	_, err = store.UpsertUserTOTP(context.Background(), UpsertUserTOTPParams{
+		Username:        user.Username,
+		EncryptedSecret: []byte(util.RandomString(40)),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.EnableTOTPTx(context.Background(), EnableTOTPTxParams{Username: user.Username})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// 4. disable removes the secret
+	err = store.DisableTOTPTx(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	_, err = store.GetUserTOTP(context.Background(), user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUseTOTPStep(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.UpsertUserTOTP(context.Background(), UpsertUserTOTPParams{
+		Username:        user.Username,
+		EncryptedSecret: []byte(util.RandomString(40)),
+	})
+	require.NoError(t, err)
+
+	totp, err := testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{Username: user.Username, Step: 100})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), totp.LastUsedStep)
+
+	// a step can be used only once and the steps before it are rejected too
+	_, err = testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{Username: user.Username, Step: 100})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{Username: user.Username, Step: 99})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	user := createRandomUser(t)
+	hash := util.RandomString(64)
+
+	_, err := testQueries.CreateRecoveryCode(context.Background(), CreateRecoveryCodeParams{
+		Username: user.Username,
+		CodeHash: hash,
+	})
+	require.NoError(t, err)
+
+	code, err := testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{Username: user.Username, CodeHash: hash})
+	require.NoError(t, err)
+	require.True(t, code.UsedAt.Valid)
+
+	_, err = testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{Username: user.Username, CodeHash: hash})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestMFAChallenge(t *testing.T) {
+	user := createRandomUser(t)
+
+	challenge, err := testQueries.CreateMFAChallenge(context.Background(), CreateMFAChallengeParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, challenge.Attempts)
+
+	challenge, err = testQueries.AttemptMFAChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), challenge.Attempts)
+
+	challenge, err = testQueries.ConsumeMFAChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.True(t, challenge.ConsumedAt.Valid)
+
+	_, err = testQueries.ConsumeMFAChallenge(context.Background(), challenge.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -6,17 +6,67 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// access tokens issued outside of a login, bound to the session, api key or oauth client they were issued under so revoking it revokes them
+type AccessToken struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	// session of the token a delegated token was created with
+	SessionID uuid.NullUUID `json:"session_id"`
+	// api key a delegated token was created with
+	ApiKeyID  uuid.NullUUID `json:"api_key_id"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	CreatedAt time.Time     `json:"created_at"`
+	// oauth client a token without a refresh token was issued to
+	ClientID sql.NullString `json:"client_id"`
+}
+
 type Account struct {
 	ID        int64     `json:"id"`
 	Owner     string    `json:"owner"`
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// balance less the amount reserved by the active holds, it is what the owner can spend
+	AvailableBalance int64 `json:"available_balance"`
+	// how far below zero the balances can go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// the overdraft of the accounts already overdrawn before the limits were added, it only shrinks as the account is credited
+	LegacyOverdraft int64 `json:"legacy_overdraft"`
+}
+
+// long lived keys of machine clients, only the hash of a key is stored
+type ApiKey struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Name     string    `json:"name"`
+	// start of the key shown in the listings so the owner can tell the keys apart
+	Prefix  string   `json:"prefix"`
+	KeyHash string   `json:"key_hash"`
+	Scopes  []string `json:"scopes"`
+	// addresses or CIDR ranges the key is accepted from, empty allows any address
+	AllowedIps []string       `json:"allowed_ips"`
+	ExpiresAt  sql.NullTime   `json:"expires_at"`
+	LastUsedAt sql.NullTime   `json:"last_used_at"`
+	LastUsedIp sql.NullString `json:"last_used_ip"`
+	RevokedAt  sql.NullTime   `json:"revoked_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type Currency struct {
+	// ISO 4217 alphabetic code
+	Code        string `json:"code"`
+	NumericCode int32  `json:"numeric_code"`
+	// number of decimal places of the minor unit
+	Exponent  int16     `json:"exponent"`
+	Symbol    string    `json:"symbol"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -25,6 +75,165 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer which posted this leg, null for non transfer entries
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+type ExchangeRate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	// price of one unit of base currency in quote currency
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// funds reserved on the from account, the ledger entries are posted only when the hold is captured
+type Hold struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+	// sum of the captures, the rest is released when the hold is voided, expires or the last capture is final
+	CapturedAmount int64     `json:"captured_amount"`
+	Status         string    `json:"status"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Owner       string `json:"owner"`
+	Key         string `json:"key"`
+	RequestHash string `json:"request_hash"`
+	// serialized TransferTxResult of the first request
+	Response  json.RawMessage `json:"response"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// failed login attempts per username and per client ip, shared by all the replicas
+type LoginThrottle struct {
+	Kind           string    `json:"kind"`
+	Subject        string    `json:"subject"`
+	FailedAttempts int32     `json:"failed_attempts"`
+	LastFailedAt   time.Time `json:"last_failed_at"`
+	// no login is tried for the subject before this time
+	LockedUntil sql.NullTime `json:"locked_until"`
+}
+
+// password step of a login which still needs a second factor
+type MfaChallenge struct {
+	ID         uuid.UUID    `json:"id"`
+	Username   string       `json:"username"`
+	Attempts   int32        `json:"attempts"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	ConsumedAt sql.NullTime `json:"consumed_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// single use codes of the authorization code grant, only the hash of a code is stored
+type OauthAuthorizationCode struct {
+	CodeHash            string       `json:"code_hash"`
+	ClientID            string       `json:"client_id"`
+	Username            string       `json:"username"`
+	RedirectUri         string       `json:"redirect_uri"`
+	Scopes              []string     `json:"scopes"`
+	CodeChallenge       string       `json:"code_challenge"`
+	CodeChallengeMethod string       `json:"code_challenge_method"`
+	ExpiresAt           time.Time    `json:"expires_at"`
+	UsedAt              sql.NullTime `json:"used_at"`
+	CreatedAt           time.Time    `json:"created_at"`
+}
+
+// third party apps allowed to get tokens of the users who consent
+type OauthClient struct {
+	ID    string `json:"id"`
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	// hash of the secret of a confidential client, public clients have no secret and must use PKCE
+	SecretHash   sql.NullString `json:"secret_hash"`
+	RedirectUris []string       `json:"redirect_uris"`
+	Scopes       []string       `json:"scopes"`
+	GrantTypes   []string       `json:"grant_types"`
+	RevokedAt    sql.NullTime   `json:"revoked_at"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// scopes a user granted to a client
+type OauthConsent struct {
+	Username  string    `json:"username"`
+	ClientID  string    `json:"client_id"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type RecoveryCode struct {
+	ID        int64        `json:"id"`
+	Username  string       `json:"username"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type RevokedToken struct {
+	// id of the revoked access token
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// transfers run by the scheduler once or on a recurrence, like rent every month
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Frequency     string `json:"frequency"`
+	Interval      int32  `json:"interval"`
+	// day a monthly transfer runs on, the last day of the shorter months
+	DayOfMonth     sql.NullInt32 `json:"day_of_month"`
+	StartAt        time.Time     `json:"start_at"`
+	EndAt          sql.NullTime  `json:"end_at"`
+	MaxOccurrences sql.NullInt32 `json:"max_occurrences"`
+	// occurrences which were run or given up after their last retry
+	Occurrences int32 `json:"occurrences"`
+	// occurrence which is run next, it stays the same while the occurrence is retried
+	OccurrenceAt time.Time `json:"occurrence_at"`
+	// when the scheduler runs the occurrence, later than the occurrence when it is retried
+	NextRunAt time.Time `json:"next_run_at"`
+	Attempts  int32     `json:"attempts"`
+	// a scheduler is running the occurrence, the others skip it until then
+	ClaimedUntil sql.NullTime `json:"claimed_until"`
+	Status       string       `json:"status"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+	// amount of the occurrence, frozen when it is first claimed so its retries transfer the same amount
+	OccurrenceAmount sql.NullInt64 `json:"occurrence_amount"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64     `json:"id"`
+	ScheduledTransferID int64     `json:"scheduled_transfer_id"`
+	OccurrenceAt        time.Time `json:"occurrence_at"`
+	Attempt             int32     `json:"attempt"`
+	// retrying when the occurrence is run again later, failed when it was given up
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	Error      string        `json:"error"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type SecurityEvent struct {
+	ID        int64         `json:"id"`
+	Username  string        `json:"username"`
+	EventType string        `json:"event_type"`
+	SessionID uuid.NullUUID `json:"session_id"`
+	UserAgent string        `json:"user_agent"`
+	ClientIp  string        `json:"client_ip"`
+	CreatedAt time.Time     `json:"created_at"`
 }
 
 type Session struct {
@@ -36,6 +245,16 @@ type Session struct {
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	CreatedAt    time.Time `json:"created_at"`
+	// id of the login session all rotated sessions descend from
+	FamilyID uuid.UUID     `json:"family_id"`
+	ParentID uuid.NullUUID `json:"parent_id"`
+	// set when the refresh token was exchanged for a new session
+	RotatedAt sql.NullTime `json:"rotated_at"`
+	// access token issued along with the refresh token of the session
+	AccessTokenID        uuid.NullUUID `json:"access_token_id"`
+	AccessTokenExpiresAt sql.NullTime  `json:"access_token_expires_at"`
+	// oauth client the refresh token was issued to, empty for the sessions of a login
+	ClientID sql.NullString `json:"client_id"`
 }
 
 type Transfer struct {
@@ -45,6 +264,34 @@ type Transfer struct {
 	// must be positive
 	Amount    int64        `json:"amount"`
 	CreatedAt sql.NullTime `json:"created_at"`
+	// credited to the to account in its currency
+	ToAmount int64 `json:"to_amount"`
+	// rate from the from account currency to the to account currency
+	ExchangeRate string        `json:"exchange_rate"`
+	QuoteID      uuid.NullUUID `json:"quote_id"`
+	// hold captured by this transfer, null for direct transfers
+	HoldID sql.NullInt64 `json:"hold_id"`
+	// posted until it is refunded, then partially_reversed or reversed once the whole amount is refunded
+	Status string `json:"status"`
+	// sum of the refunds in the from account currency, it can never exceed the amount
+	ReversedAmount int64 `json:"reversed_amount"`
+	// transfer refunded by this compensating transfer, null for the other transfers
+	ReversalOf sql.NullInt64 `json:"reversal_of"`
+}
+
+type TransferQuote struct {
+	ID            uuid.UUID    `json:"id"`
+	Owner         string       `json:"owner"`
+	FromAccountID int64        `json:"from_account_id"`
+	ToAccountID   int64        `json:"to_account_id"`
+	FromCurrency  string       `json:"from_currency"`
+	ToCurrency    string       `json:"to_currency"`
+	Rate          string       `json:"rate"`
+	FromAmount    int64        `json:"from_amount"`
+	ToAmount      int64        `json:"to_amount"`
+	ExpiresAt     time.Time    `json:"expires_at"`
+	UsedAt        sql.NullTime `json:"used_at"`
+	CreatedAt     time.Time    `json:"created_at"`
 }
 
 type User struct {
@@ -54,4 +301,44 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Role              string    `json:"role"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
+	// velocity limits the transfers of the user are checked against
+	Tier string `json:"tier"`
+}
+
+// single use tokens mailed to the users, only the hash of a token is stored
+type UserToken struct {
+	TokenHash string `json:"token_hash"`
+	Username  string `json:"username"`
+	Purpose   string `json:"purpose"`
+	// address the token was sent to, a verification token verifies only this address
+	Email     string       `json:"email"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type UserTotp struct {
+	Username        string `json:"username"`
+	EncryptedSecret []byte `json:"encrypted_secret"`
+	// null until the enrollment is confirmed with a valid code
+	EnabledAt sql.NullTime `json:"enabled_at"`
+	// time step of the last accepted code, a code can be used only once
+	LastUsedStep int64     `json:"last_used_step"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// caps on the outgoing transfers of an account by the tier of its owner and its currency, the transfers of a tier and currency without a row are rejected and a null cap is no limit
+type VelocityLimit struct {
+	Tier     string `json:"tier"`
+	Currency string `json:"currency"`
+	// largest single transfer in the minor unit of the currency
+	MaxAmount sql.NullInt64 `json:"max_amount"`
+	// total of the transfers over the last 24 hours in the minor unit of the currency
+	MaxDailyAmount sql.NullInt64 `json:"max_daily_amount"`
+	// number of transfers over the last hour
+	MaxHourlyCount sql.NullInt32 `json:"max_hourly_count"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	CreatedAt      time.Time     `json:"created_at"`
 }
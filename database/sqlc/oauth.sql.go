@@ -0,0 +1,285 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: oauth.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const createOAuthAuthorizationCode = `-- name: CreateOAuthAuthorizationCode :one
+INSERT INTO oauth_authorization_codes (
+    code_hash,
+    client_id,
+    username,
+    redirect_uri,
+    scopes,
+    code_challenge,
+    code_challenge_method,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING code_hash, client_id, username, redirect_uri, scopes, code_challenge, code_challenge_method, expires_at, used_at, created_at
+`
+
+type CreateOAuthAuthorizationCodeParams struct {
+	CodeHash            string    `json:"code_hash"`
+	ClientID            string    `json:"client_id"`
+	Username            string    `json:"username"`
+	RedirectUri         string    `json:"redirect_uri"`
+	Scopes              []string  `json:"scopes"`
+	CodeChallenge       string    `json:"code_challenge"`
+	CodeChallengeMethod string    `json:"code_challenge_method"`
+	ExpiresAt           time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) (OauthAuthorizationCode, error) {
+	row := q.queryRow(ctx, q.createOAuthAuthorizationCodeStmt, createOAuthAuthorizationCode,
+		arg.CodeHash,
+		arg.ClientID,
+		arg.Username,
+		arg.RedirectUri,
+		pq.Array(arg.Scopes),
+		arg.CodeChallenge,
+		arg.CodeChallengeMethod,
+		arg.ExpiresAt,
+	)
+	var i OauthAuthorizationCode
+	err := row.Scan(
+		&i.CodeHash,
+		&i.ClientID,
+		&i.Username,
+		&i.RedirectUri,
+		pq.Array(&i.Scopes),
+		&i.CodeChallenge,
+		&i.CodeChallengeMethod,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOAuthClient = `-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (
+    id,
+    owner,
+    name,
+    secret_hash,
+    redirect_uris,
+    scopes,
+    grant_types
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, owner, name, secret_hash, redirect_uris, scopes, grant_types, revoked_at, created_at
+`
+
+type CreateOAuthClientParams struct {
+	ID           string         `json:"id"`
+	Owner        string         `json:"owner"`
+	Name         string         `json:"name"`
+	SecretHash   sql.NullString `json:"secret_hash"`
+	RedirectUris []string       `json:"redirect_uris"`
+	Scopes       []string       `json:"scopes"`
+	GrantTypes   []string       `json:"grant_types"`
+}
+
+func (q *Queries) CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OauthClient, error) {
+	row := q.queryRow(ctx, q.createOAuthClientStmt, createOAuthClient,
+		arg.ID,
+		arg.Owner,
+		arg.Name,
+		arg.SecretHash,
+		pq.Array(arg.RedirectUris),
+		pq.Array(arg.Scopes),
+		pq.Array(arg.GrantTypes),
+	)
+	var i OauthClient
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Name,
+		&i.SecretHash,
+		pq.Array(&i.RedirectUris),
+		pq.Array(&i.Scopes),
+		pq.Array(&i.GrantTypes),
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOAuthClient = `-- name: GetOAuthClient :one
+SELECT id, owner, name, secret_hash, redirect_uris, scopes, grant_types, revoked_at, created_at FROM oauth_clients
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetOAuthClient(ctx context.Context, id string) (OauthClient, error) {
+	row := q.queryRow(ctx, q.getOAuthClientStmt, getOAuthClient, id)
+	var i OauthClient
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Name,
+		&i.SecretHash,
+		pq.Array(&i.RedirectUris),
+		pq.Array(&i.Scopes),
+		pq.Array(&i.GrantTypes),
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOAuthConsent = `-- name: GetOAuthConsent :one
+SELECT username, client_id, scopes, created_at, updated_at FROM oauth_consents
+WHERE username = $1 AND client_id = $2 LIMIT 1
+`
+
+type GetOAuthConsentParams struct {
+	Username string `json:"username"`
+	ClientID string `json:"client_id"`
+}
+
+func (q *Queries) GetOAuthConsent(ctx context.Context, arg GetOAuthConsentParams) (OauthConsent, error) {
+	row := q.queryRow(ctx, q.getOAuthConsentStmt, getOAuthConsent, arg.Username, arg.ClientID)
+	var i OauthConsent
+	err := row.Scan(
+		&i.Username,
+		&i.ClientID,
+		pq.Array(&i.Scopes),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listOAuthClients = `-- name: ListOAuthClients :many
+SELECT id, owner, name, secret_hash, redirect_uris, scopes, grant_types, revoked_at, created_at FROM oauth_clients
+WHERE owner = $1
+  AND revoked_at IS NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListOAuthClients(ctx context.Context, owner string) ([]OauthClient, error) {
+	rows, err := q.query(ctx, q.listOAuthClientsStmt, listOAuthClients, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OauthClient{}
+	for rows.Next() {
+		var i OauthClient
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Name,
+			&i.SecretHash,
+			pq.Array(&i.RedirectUris),
+			pq.Array(&i.Scopes),
+			pq.Array(&i.GrantTypes),
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeOAuthClient = `-- name: RevokeOAuthClient :one
+UPDATE oauth_clients
+SET revoked_at = now()
+WHERE id = $1
+  AND revoked_at IS NULL
+RETURNING id, owner, name, secret_hash, redirect_uris, scopes, grant_types, revoked_at, created_at
+`
+
+func (q *Queries) RevokeOAuthClient(ctx context.Context, id string) (OauthClient, error) {
+	row := q.queryRow(ctx, q.revokeOAuthClientStmt, revokeOAuthClient, id)
+	var i OauthClient
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Name,
+		&i.SecretHash,
+		pq.Array(&i.RedirectUris),
+		pq.Array(&i.Scopes),
+		pq.Array(&i.GrantTypes),
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertOAuthConsent = `-- name: UpsertOAuthConsent :one
+INSERT INTO oauth_consents (
+    username,
+    client_id,
+    scopes
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (username, client_id) DO UPDATE
+SET scopes = EXCLUDED.scopes,
+    updated_at = now()
+RETURNING username, client_id, scopes, created_at, updated_at
+`
+
+type UpsertOAuthConsentParams struct {
+	Username string   `json:"username"`
+	ClientID string   `json:"client_id"`
+	Scopes   []string `json:"scopes"`
+}
+
+func (q *Queries) UpsertOAuthConsent(ctx context.Context, arg UpsertOAuthConsentParams) (OauthConsent, error) {
+	row := q.queryRow(ctx, q.upsertOAuthConsentStmt, upsertOAuthConsent, arg.Username, arg.ClientID, pq.Array(arg.Scopes))
+	var i OauthConsent
+	err := row.Scan(
+		&i.Username,
+		&i.ClientID,
+		pq.Array(&i.Scopes),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const useOAuthAuthorizationCode = `-- name: UseOAuthAuthorizationCode :one
+UPDATE oauth_authorization_codes
+SET used_at = now()
+WHERE code_hash = $1
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING code_hash, client_id, username, redirect_uri, scopes, code_challenge, code_challenge_method, expires_at, used_at, created_at
+`
+
+func (q *Queries) UseOAuthAuthorizationCode(ctx context.Context, codeHash string) (OauthAuthorizationCode, error) {
+	row := q.queryRow(ctx, q.useOAuthAuthorizationCodeStmt, useOAuthAuthorizationCode, codeHash)
+	var i OauthAuthorizationCode
+	err := row.Scan(
+		&i.CodeHash,
+		&i.ClientID,
+		&i.Username,
+		&i.RedirectUri,
+		pq.Array(&i.Scopes),
+		&i.CodeChallenge,
+		&i.CodeChallengeMethod,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
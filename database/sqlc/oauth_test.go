@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomOAuthClient(t *testing.T, owner string) OauthClient {
+	arg := CreateOAuthClientParams{
+		ID:           util.RandomString(22),
+		Owner:        owner,
+		Name:         util.RandomOwner(),
+		SecretHash:   sql.NullString{String: util.RandomString(64), Valid: true},
+		RedirectUris: []string{"https://partner.example.com/callback"},
+		Scopes:       []string{"accounts:read"},
+		GrantTypes:   []string{"authorization_code", "refresh_token"},
+	}
+
+	client, err := testQueries.CreateOAuthClient(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, client.ID)
+	require.Equal(t, arg.RedirectUris, client.RedirectUris)
+	require.Equal(t, arg.GrantTypes, client.GrantTypes)
+	require.False(t, client.RevokedAt.Valid)
+	return client
+}
+
+func TestRevokeOAuthClient(t *testing.T) {
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, user.Username)
+
+	clients, err := testQueries.ListOAuthClients(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+
+	// 1. a revoked client is left out of the listing and can't be revoked again
+	revoked, err := testQueries.RevokeOAuthClient(context.Background(), client.ID)
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+
+	_, err = testQueries.RevokeOAuthClient(context.Background(), client.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	clients, err = testQueries.ListOAuthClients(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, clients)
+}
+
+func TestUpsertOAuthConsent(t *testing.T) {
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, createRandomUser(t).Username)
+
+	_, err := testQueries.GetOAuthConsent(context.Background(), GetOAuthConsentParams{Username: user.Username, ClientID: client.ID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// 1. a new consent replaces the scopes of the previous one
+	for _, scopes := range [][]string{{"accounts:read"}, {"accounts:read", "transfers:read"}} {
+		_, err := testQueries.UpsertOAuthConsent(context.Background(), UpsertOAuthConsentParams{
+			Username: user.Username,
+			ClientID: client.ID,
+			Scopes:   scopes,
+		})
+		require.NoError(t, err)
+
+		consent, err := testQueries.GetOAuthConsent(context.Background(), GetOAuthConsentParams{Username: user.Username, ClientID: client.ID})
+		require.NoError(t, err)
+		require.Equal(t, scopes, consent.Scopes)
+	}
+}
+
+func TestUseOAuthAuthorizationCode(t *testing.T) {
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, user.Username)
+
+	createCode := func(expiresAt time.Time) OauthAuthorizationCode {
+		code, err := testQueries.CreateOAuthAuthorizationCode(context.Background(), CreateOAuthAuthorizationCodeParams{
+			CodeHash:            util.RandomString(64),
+			ClientID:            client.ID,
+			Username:            user.Username,
+			RedirectUri:         client.RedirectUris[0],
+			Scopes:              client.Scopes,
+			CodeChallenge:       util.RandomString(43),
+			CodeChallengeMethod: "S256",
+			ExpiresAt:           expiresAt,
+		})
+		require.NoError(t, err)
+		return code
+	}
+
+	// 1. a code can be used only once
+	code := createCode(time.Now().Add(time.Minute))
+	used, err := testQueries.UseOAuthAuthorizationCode(context.Background(), code.CodeHash)
+	require.NoError(t, err)
+	require.True(t, used.UsedAt.Valid)
+
+	_, err = testQueries.UseOAuthAuthorizationCode(context.Background(), code.CodeHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// 2. an expired code can't be used
+	expired := createCode(time.Now().Add(-time.Minute))
+	_, err = testQueries.UseOAuthAuthorizationCode(context.Background(), expired.CodeHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestBlockClientSessions(t *testing.T) {
+	user := createRandomUser(t)
+	client := createRandomOAuthClient(t, createRandomUser(t).Username)
+	login := createRandomSession(t, user.Username)
+
+	id := uuid.New()
+	_, err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+		ID:           id,
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		ExpiresAt:    time.Now().Add(time.Hour),
+		FamilyID:     id,
+		ClientID:     sql.NullString{String: client.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// 1. only the sessions of the client are blocked
+	blocked, err := testQueries.BlockClientSessions(context.Background(), sql.NullString{String: client.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, blocked, 1)
+	require.Equal(t, id, blocked[0].ID)
+
+	session, err := testQueries.GetSession(context.Background(), login.ID)
+	require.NoError(t, err)
+	require.False(t, session.IsBlocked)
+}
@@ -0,0 +1,23 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Check constraints which keep the balances of an account above its overdraft limit
+const (
+	balanceConstraint          = "accounts_balance_check"
+	availableBalanceConstraint = "accounts_available_balance_check"
+)
+
+// fundsError returns ErrInsufficientFunds when err is the violation of the overdraft limit of an account.
+// The limit is checked by the database, so concurrent debits can never take an account past it.
+func fundsError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && (pqErr.Constraint == balanceConstraint || pqErr.Constraint == availableBalanceConstraint) {
+		return ErrInsufficientFunds
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 50)
+	account2 := createFundedAccount(t, 50)
+
+	// 1. the balance can't go negative without an overdraft limit
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        60,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// 2. the held funds can't be transferred
+	authorizeRandomHold(t, store, account1, account2, 30, time.Now().Add(time.Hour))
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        30,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// 3. the overdraft limit lets the available balance go below zero, down to the limit
+	_, err = testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 100,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        80,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-30), result.FromAccount.Balance)
+	require.Equal(t, int64(-60), result.FromAccount.AvailableBalance)
+
+	_, err = store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// 4. the limit can't be lowered below the overdraft in use
+	_, err = testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 50,
+	})
+	require.ErrorIs(t, fundsError(err), ErrInsufficientFunds)
+
+	// nothing was posted by the failed transfers
+	report, err := store.VerifyLedger(context.Background())
+	require.NoError(t, err)
+	require.NotContains(t, driftAccountIDs(report), account1.ID)
+	require.NotContains(t, driftAccountIDs(report), account2.ID)
+}
+
+func TestTransferTxOverdraftLimitConcurrency(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 0)
+
+	_, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 50,
+	})
+	require.NoError(t, err)
+
+	// 1. many transfers race to drain the account, only the ones within the limit succeed
+	n := 40
+	amount := int64(10)
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+	}
+	require.Equal(t, 15, succeeded)
+
+	// 2. the account stopped exactly at its limit
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-50), updatedAccount1.Balance)
+	require.Equal(t, int64(-50), updatedAccount1.AvailableBalance)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(150), updatedAccount2.Balance)
+}
+
+func TestLegacyOverdraft(t *testing.T) {
+	account := createRandomAccount(t)
+
+	// the account was overdrawn before the overdraft limits were added
+	_, err := testDB.Exec(`UPDATE accounts SET balance = -100, available_balance = -100, legacy_overdraft = 100 WHERE id = $1`, account.ID)
+	require.NoError(t, err)
+
+	// 1. the legacy overdraft is no credit line, the account can't be debited further
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account.ID, Amount: -10})
+	require.ErrorIs(t, fundsError(err), ErrInsufficientFunds)
+
+	// 2. a partial repayment is accepted and shrinks the legacy overdraft
+	account, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account.ID, Amount: 40})
+	require.NoError(t, err)
+	require.Equal(t, int64(-60), account.Balance)
+	require.Equal(t, int64(60), account.LegacyOverdraft)
+	require.Zero(t, account.OverdraftLimit)
+
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account.ID, Amount: -1})
+	require.ErrorIs(t, fundsError(err), ErrInsufficientFunds)
+
+	// 3. the legacy overdraft is gone once the account is back above zero
+	account, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account.ID, Amount: 70})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), account.Balance)
+	require.Zero(t, account.LegacyOverdraft)
+}
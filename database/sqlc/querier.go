@@ -6,28 +6,136 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AdvanceScheduledTransfer(ctx context.Context, arg AdvanceScheduledTransferParams) (ScheduledTransfer, error)
+	AttemptMFAChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error)
+	BlockClientSessions(ctx context.Context, clientID sql.NullString) ([]Session, error)
+	BlockSession(ctx context.Context, arg BlockSessionParams) (Session, error)
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) ([]Session, error)
+	BlockUserSessions(ctx context.Context, username string) ([]Session, error)
+	CancelScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	CaptureAccountBalance(ctx context.Context, arg CaptureAccountBalanceParams) (Account, error)
+	ClaimDueScheduledTransfers(ctx context.Context, arg ClaimDueScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ConsumeMFAChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateAccessToken(ctx context.Context, arg CreateAccessTokenParams) (AccessToken, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateCurrency(ctx context.Context, arg CreateCurrencyParams) (Currency, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateMFAChallenge(ctx context.Context, arg CreateMFAChallengeParams) (MfaChallenge, error)
+	CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) (OauthAuthorizationCode, error)
+	CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OauthClient, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateSecurityEvent(ctx context.Context, arg CreateSecurityEventParams) (SecurityEvent, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferQuote(ctx context.Context, arg CreateTransferQuoteParams) (TransferQuote, error)
 	CreateUSer(ctx context.Context, arg CreateUSerParams) (User, error)
+	CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteExpiredAccessTokens(ctx context.Context) (int64, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	DeleteLoginThrottle(ctx context.Context, arg DeleteLoginThrottleParams) (int64, error)
+	DeleteRecoveryCodes(ctx context.Context, username string) error
+	DeleteStaleLoginThrottles(ctx context.Context, resetBefore time.Time) (int64, error)
+	DeleteUserTOTP(ctx context.Context, username string) error
+	DeleteVelocityLimit(ctx context.Context, arg DeleteVelocityLimitParams) (VelocityLimit, error)
+	EnableUserTOTP(ctx context.Context, username string) (UserTotp, error)
+	GetAPIKey(ctx context.Context, id uuid.UUID) (ApiKey, error)
+	GetAccessToken(ctx context.Context, id uuid.UUID) (AccessToken, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountVelocityLimit(ctx context.Context, id int64) (VelocityLimit, error)
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (GetActiveAPIKeyByHashRow, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLoginThrottle(ctx context.Context, arg GetLoginThrottleParams) (LoginThrottle, error)
+	GetMFAChallenge(ctx context.Context, id uuid.UUID) (MfaChallenge, error)
+	GetNextExpiredHoldForUpdate(ctx context.Context) (Hold, error)
+	GetOAuthClient(ctx context.Context, id string) (OauthClient, error)
+	GetOAuthConsent(ctx context.Context, arg GetOAuthConsentParams) (OauthConsent, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSessionByAccessToken(ctx context.Context, accessTokenID uuid.NullUUID) (Session, error)
+	GetSessionForUpdate(ctx context.Context, id uuid.UUID) (Session, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferQuote(ctx context.Context, id uuid.UUID) (TransferQuote, error)
+	GetTransferVelocity(ctx context.Context, fromAccountID int64) (GetTransferVelocityRow, error)
+	GetUsableUserToken(ctx context.Context, arg GetUsableUserTokenParams) (UserToken, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserTOTP(ctx context.Context, username string) (UserTotp, error)
+	InvalidateUserTokens(ctx context.Context, arg InvalidateUserTokensParams) error
+	ListAPIKeyAccessTokens(ctx context.Context, apiKeyID uuid.NullUUID) ([]AccessToken, error)
+	ListAPIKeys(ctx context.Context, username string) ([]ApiKey, error)
+	ListAccountBalanceDrifts(ctx context.Context) ([]ListAccountBalanceDriftsRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListActiveSessions(ctx context.Context, username string) ([]Session, error)
+	ListAvailableBalanceDrifts(ctx context.Context) ([]ListAvailableBalanceDriftsRow, error)
+	ListClientAccessTokens(ctx context.Context, clientID sql.NullString) ([]AccessToken, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	ListEnabledCurrencies(ctx context.Context) ([]Currency, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListOAuthClients(ctx context.Context, owner string) ([]OauthClient, error)
+	ListPasswordChanges(ctx context.Context, changedAfter time.Time) ([]ListPasswordChangesRow, error)
+	ListReversalDrifts(ctx context.Context) ([]ListReversalDriftsRow, error)
+	ListRevokedTokens(ctx context.Context) ([]RevokedToken, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListSecurityEvents(ctx context.Context, arg ListSecurityEventsParams) ([]SecurityEvent, error)
+	ListSessionAccessTokens(ctx context.Context, sessionIds []uuid.UUID) ([]AccessToken, error)
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	ListTransferEntries(ctx context.Context, transferID sql.NullInt64) ([]Entry, error)
+	ListTransferReversals(ctx context.Context, reversalOf sql.NullInt64) ([]Transfer, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
-	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	ListUnbalancedTransfers(ctx context.Context) ([]ListUnbalancedTransfersRow, error)
+	ListVelocityLimits(ctx context.Context) ([]VelocityLimit, error)
+	LockLoginThrottle(ctx context.Context, arg LockLoginThrottleParams) (LoginThrottle, error)
+	RecordLoginAttempt(ctx context.Context, arg RecordLoginAttemptParams) (LoginThrottle, error)
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error)
+	ReleaseLoginAttempt(ctx context.Context, arg ReleaseLoginAttemptParams) error
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (ApiKey, error)
+	RevokeOAuthClient(ctx context.Context, id string) (OauthClient, error)
+	RevokeToken(ctx context.Context, arg RevokeTokenParams) error
+	RotateSession(ctx context.Context, id uuid.UUID) (Session, error)
+	SetCurrencyEnabled(ctx context.Context, arg SetCurrencyEnabledParams) (Currency, error)
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) error
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateTransferReversal(ctx context.Context, arg UpdateTransferReversalParams) (Transfer, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error)
+	UpsertOAuthConsent(ctx context.Context, arg UpsertOAuthConsentParams) (OauthConsent, error)
+	UpsertUserTOTP(ctx context.Context, arg UpsertUserTOTPParams) (UserTotp, error)
+	UpsertVelocityLimit(ctx context.Context, arg UpsertVelocityLimitParams) (VelocityLimit, error)
+	UseOAuthAuthorizationCode(ctx context.Context, codeHash string) (OauthAuthorizationCode, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (UserTotp, error)
+	UseTransferQuote(ctx context.Context, id uuid.UUID) (TransferQuote, error)
+	UseUserToken(ctx context.Context, arg UseUserTokenParams) (UserToken, error)
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+)
+
+// reversalRateDecimals is the precision of the inverse rate of a cross currency reversal, the same as the fx rates
+const reversalRateDecimals = 10
+
+var (
+	ErrTransferNotReversible = errors.New("a reversal can't be reversed")
+	ErrTransferReversed      = errors.New("transfer is already fully reversed")
+	ErrRefundExceeded        = errors.New("refund exceeds the amount left to reverse")
+	ErrRefundTooSmall        = errors.New("refund is too small to be converted back to the to account currency")
+)
+
+// Statuses of a transfer, a transfer is posted until it is refunded
+const (
+	TransferStatusPosted            = "posted"
+	TransferStatusPartiallyReversed = "partially_reversed"
+	TransferStatusReversed          = "reversed"
+)
+
+// Refundable returns the amount of the transfer which is not refunded yet, in the from account currency
+func (t Transfer) Refundable() int64 {
+	return t.Amount - t.ReversedAmount
+}
+
+// ReverseTransferTxParams contains the transfer to refund
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is given back to the from account of the transfer in its currency,
+	// it defaults to the amount left to reverse, a smaller amount is a partial refund
+	Amount int64 `json:"amount"`
+}
+
+// ReverseTransferTxResult is the refunded transfer and the compensating transfer which reversed it.
+// The compensating transfer goes from the to account of the original back to its from account.
+type ReverseTransferTxResult struct {
+	Original    Transfer `json:"original"`
+	Transfer    Transfer `json:"transfer"`
+	FromAccount Account  `json:"from_account"`
+	ToAccount   Account  `json:"to_account"`
+	FromEntry   Entry    `json:"from_entry"`
+	ToEntry     Entry    `json:"to_entry"`
+}
+
+// ReverseTransferTx refunds a transfer with a compensating transfer linked to it by reversal_of.
+// The refunds of a transfer can never exceed its amount. The to account of the original must have
+// the funds available within its overdraft limit, otherwise it fails with ErrInsufficientFunds.
+func (s *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		// 1. lock the original so concurrent refunds can't exceed its amount
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		if original.ReversalOf.Valid {
+			return ErrTransferNotReversible
+		}
+		if original.Status == TransferStatusReversed {
+			return ErrTransferReversed
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = original.Refundable()
+		}
+		if amount < 0 || amount > original.Refundable() {
+			return ErrRefundExceeded
+		}
+
+		// 2. post the compensating transfer, its amount is debited from the to account of the original
+		reversed := original.ReversedAmount + amount
+		debit := reversalDebit(original, reversed) - reversalDebit(original, original.ReversedAmount)
+		if debit <= 0 {
+			return ErrRefundTooSmall
+		}
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        debit,
+			ToAmount:      amount,
+			ExchangeRate:  inverseRate(original.ExchangeRate),
+			ReversalOf:    sql.NullInt64{Int64: original.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  result.Transfer.FromAccountID,
+			Amount:     -debit,
+			TransferID: transferID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  result.Transfer.ToAccountID,
+			Amount:     amount,
+			TransferID: transferID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// 3. update the accounts, the lowest id first as in TransferTx
+		if result.Transfer.FromAccountID < result.Transfer.ToAccountID {
+			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, result.Transfer.FromAccountID, result.Transfer.ToAccountID, -debit, amount)
+		} else {
+			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, result.Transfer.ToAccountID, result.Transfer.FromAccountID, amount, -debit)
+		}
+		if err != nil {
+			return err
+		}
+
+		// 4. record the refund on the original
+		status := TransferStatusPartiallyReversed
+		if reversed == original.Amount {
+			status = TransferStatusReversed
+		}
+		result.Original, err = q.UpdateTransferReversal(ctx, UpdateTransferReversalParams{
+			ID:             original.ID,
+			ReversedAmount: reversed,
+			Status:         status,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// reversalDebit returns how much of the credited amount of the transfer is taken back once reversed is refunded.
+// It is computed on the running total so the refunds of a cross currency transfer add up to its to amount,
+// and on the ratio of the stored amounts, which are both in minor units, so the currency exponents cancel out.
+func reversalDebit(transfer Transfer, reversed int64) int64 {
+	if transfer.Amount == transfer.ToAmount {
+		return reversed
+	}
+	debit := new(big.Int).Mul(big.NewInt(transfer.ToAmount), big.NewInt(reversed))
+	return debit.Quo(debit, big.NewInt(transfer.Amount)).Int64()
+}
+
+// inverseRate returns the rate from the to account currency back to the from account currency
+func inverseRate(rate string) string {
+	value, ok := new(big.Rat).SetString(rate)
+	if !ok || value.Sign() <= 0 || value.Cmp(big.NewRat(1, 1)) == 0 {
+		return rate
+	}
+	return value.Inv(value).FloatString(reversalRateDecimals)
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPosted, transfer.Transfer.Status)
+
+	// 1. a partial refund moves the amount back and is linked to the original
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     20,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPartiallyReversed, result.Original.Status)
+	require.Equal(t, int64(20), result.Original.ReversedAmount)
+	require.Equal(t, sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true}, result.Transfer.ReversalOf)
+	require.Equal(t, account2.ID, result.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(-20), result.FromEntry.Amount)
+	require.Equal(t, int64(20), result.ToEntry.Amount)
+	require.Equal(t, int64(130), result.FromAccount.Balance)
+	require.Equal(t, int64(70), result.ToAccount.Balance)
+
+	// 2. the refunds can't exceed the original amount
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     40,
+	})
+	require.ErrorIs(t, err, ErrRefundExceeded)
+
+	// 3. a reversal can't be reversed
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: result.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+
+	// 4. the rest is refunded by default
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: transfer.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusReversed, result.Original.Status)
+	require.Equal(t, int64(50), result.Original.ReversedAmount)
+	require.Equal(t, int64(100), result.FromAccount.Balance)
+	require.Equal(t, int64(100), result.ToAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: transfer.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferReversed)
+
+	reversals, err := testQueries.ListTransferReversals(context.Background(), sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, reversals, 2)
+
+	report, err := store.VerifyLedger(context.Background())
+	require.NoError(t, err)
+	require.NotContains(t, driftAccountIDs(report), account1.ID)
+	require.NotContains(t, driftAccountIDs(report), account2.ID)
+	for _, drift := range report.ReversalDrifts {
+		require.NotEqual(t, transfer.Transfer.ID, drift.ID)
+	}
+}
+
+func TestReverseTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 0)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        50,
+	})
+	require.NoError(t, err)
+
+	// the to account already spent what it received
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account2.ID, Amount: -40})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: transfer.Transfer.ID})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	original, err := testQueries.GetTransfer(context.Background(), transfer.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPosted, original.Status)
+	require.Zero(t, original.ReversedAmount)
+}
+
+func TestReversalDebit(t *testing.T) {
+	// a cross currency transfer of 1000 credited 833, the partial refunds add up to what was credited
+	transfer := Transfer{Amount: 1000, ToAmount: 833}
+
+	first := reversalDebit(transfer, 333) - reversalDebit(transfer, 0)
+	second := reversalDebit(transfer, 666) - reversalDebit(transfer, 333)
+	last := reversalDebit(transfer, 1000) - reversalDebit(transfer, 666)
+	require.Equal(t, int64(277), first)
+	require.Equal(t, int64(277), second)
+	require.Equal(t, int64(279), last)
+	require.Equal(t, transfer.ToAmount, first+second+last)
+
+	require.Equal(t, int64(40), reversalDebit(Transfer{Amount: 100, ToAmount: 100}, 40))
+	require.Equal(t, "1", inverseRate("1"))
+	require.Equal(t, "0.5000000000", inverseRate("2.0000000000"))
+}
@@ -0,0 +1,81 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: revoked_token.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteExpiredRevokedTokens = `-- name: DeleteExpiredRevokedTokens :execrows
+DELETE FROM revoked_tokens
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredRevokedTokens(ctx context.Context) (int64, error) {
+	result, err := q.exec(ctx, q.deleteExpiredRevokedTokensStmt, deleteExpiredRevokedTokens)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listRevokedTokens = `-- name: ListRevokedTokens :many
+SELECT id, username, expires_at, revoked_at FROM revoked_tokens
+WHERE expires_at > now()
+`
+
+func (q *Queries) ListRevokedTokens(ctx context.Context) ([]RevokedToken, error) {
+	rows, err := q.query(ctx, q.listRevokedTokensStmt, listRevokedTokens)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RevokedToken{}
+	for rows.Next() {
+		var i RevokedToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeToken = `-- name: RevokeToken :exec
+INSERT INTO revoked_tokens (
+    id,
+    username,
+    expires_at
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (id) DO NOTHING
+`
+
+type RevokeTokenParams struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) RevokeToken(ctx context.Context, arg RevokeTokenParams) error {
+	_, err := q.exec(ctx, q.revokeTokenStmt, revokeToken, arg.ID, arg.Username, arg.ExpiresAt)
+	return err
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+)
+
+// Frequencies of a scheduled transfer, a once transfer runs only on its start date
+const (
+	FrequencyOnce    = "once"
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Statuses of a scheduled transfer, only the active ones are run by the scheduler
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusPaused    = "paused"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusCancelled = "cancelled"
+)
+
+// Statuses of a run of a scheduled transfer
+const (
+	RunStatusSucceeded = "succeeded"
+	RunStatusRetrying  = "retrying"
+	RunStatusFailed    = "failed"
+)
+
+// IsEditable reports whether the schedule can still be updated or cancelled
+func (s ScheduledTransfer) IsEditable() bool {
+	return s.Status == ScheduleStatusActive || s.Status == ScheduleStatusPaused
+}
+
+// RecordScheduledTransferRunTxParams contains the outcome of a run and the state the schedule moves to
+type RecordScheduledTransferRunTxParams struct {
+	Run      CreateScheduledTransferRunParams `json:"run"`
+	Schedule AdvanceScheduledTransferParams   `json:"schedule"`
+}
+
+// RecordScheduledTransferRunTxResult is the recorded run and the advanced schedule
+type RecordScheduledTransferRunTxResult struct {
+	Run      ScheduledTransferRun `json:"run"`
+	Schedule ScheduledTransfer    `json:"schedule"`
+}
+
+// RecordScheduledTransferRunTx records a run and advances its schedule, which also releases the claim of the scheduler.
+// A schedule paused or cancelled while it was running keeps its status.
+func (s *SQLStore) RecordScheduledTransferRunTx(ctx context.Context, arg RecordScheduledTransferRunTxParams) (RecordScheduledTransferRunTxResult, error) {
+	var result RecordScheduledTransferRunTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Run, err = q.CreateScheduledTransferRun(ctx, arg.Run)
+		if err != nil {
+			return err
+		}
+
+		result.Schedule, err = q.AdvanceScheduledTransfer(ctx, arg.Schedule)
+		return err
+	})
+
+	return result, err
+}
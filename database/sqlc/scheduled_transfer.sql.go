@@ -0,0 +1,489 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: scheduled_transfer.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const advanceScheduledTransfer = `-- name: AdvanceScheduledTransfer :one
+UPDATE scheduled_transfers
+SET occurrences = $1,
+    occurrence_amount = CASE WHEN occurrence_at = $2 THEN occurrence_amount END,
+    occurrence_at = $2,
+    next_run_at = $3,
+    attempts = $4,
+    status = CASE WHEN status = 'active' THEN $5::varchar ELSE status END,
+    claimed_until = NULL,
+    updated_at = now()
+WHERE id = $6
+RETURNING id, owner, from_account_id, to_account_id, amount, currency, frequency, interval, day_of_month, start_at, end_at, max_occurrences, occurrences, occurrence_at, next_run_at, attempts, claimed_until, status, updated_at, created_at, occurrence_amount
+`
+
+type AdvanceScheduledTransferParams struct {
+	Occurrences  int32     `json:"occurrences"`
+	OccurrenceAt time.Time `json:"occurrence_at"`
+	NextRunAt    time.Time `json:"next_run_at"`
+	Attempts     int32     `json:"attempts"`
+	Status       string    `json:"status"`
+	ID           int64     `json:"id"`
+}
+
+func (q *Queries) AdvanceScheduledTransfer(ctx context.Context, arg AdvanceScheduledTransferParams) (ScheduledTransfer, error) {
+	row := q.queryRow(ctx, q.advanceScheduledTransferStmt, advanceScheduledTransfer,
+		arg.Occurrences,
+		arg.OccurrenceAt,
+		arg.NextRunAt,
+		arg.Attempts,
+		arg.Status,
+		arg.ID,
+	)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Frequency,
+		&i.Interval,
+		&i.DayOfMonth,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.OccurrenceAt,
+		&i.NextRunAt,
+		&i.Attempts,
+		&i.ClaimedUntil,
+		&i.Status,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.OccurrenceAmount,
+	)
+	return i, err
+}
+
+const cancelScheduledTransfer = `-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers
+SET status = 'cancelled',
+    updated_at = now()
+WHERE id = $1 AND status IN ('active', 'paused')
+RETURNING id, owner, from_account_id, to_account_id, amount, currency, frequency, interval, day_of_month, start_at, end_at, max_occurrences, occurrences, occurrence_at, next_run_at, attempts, claimed_until, status, updated_at, created_at, occurrence_amount
+`
+
+func (q *Queries) CancelScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error) {
+	row := q.queryRow(ctx, q.cancelScheduledTransferStmt, cancelScheduledTransfer, id)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Frequency,
+		&i.Interval,
+		&i.DayOfMonth,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.OccurrenceAt,
+		&i.NextRunAt,
+		&i.Attempts,
+		&i.ClaimedUntil,
+		&i.Status,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.OccurrenceAmount,
+	)
+	return i, err
+}
+
+const claimDueScheduledTransfers = `-- name: ClaimDueScheduledTransfers :many
+UPDATE scheduled_transfers
+SET claimed_until = $1::timestamptz,
+    occurrence_amount = COALESCE(occurrence_amount, amount)
+WHERE id IN (
+    SELECT id FROM scheduled_transfers
+    WHERE status = 'active'
+      AND next_run_at <= now()
+      AND (claimed_until IS NULL OR claimed_until < now())
+    ORDER BY next_run_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, owner, from_account_id, to_account_id, amount, currency, frequency, interval, day_of_month, start_at, end_at, max_occurrences, occurrences, occurrence_at, next_run_at, attempts, claimed_until, status, updated_at, created_at, occurrence_amount
+`
+
+type ClaimDueScheduledTransfersParams struct {
+	ClaimedUntil time.Time `json:"claimed_until"`
+	BatchSize    int32     `json:"batch_size"`
+}
+
+func (q *Queries) ClaimDueScheduledTransfers(ctx context.Context, arg ClaimDueScheduledTransfersParams) ([]ScheduledTransfer, error) {
+	rows, err := q.query(ctx, q.claimDueScheduledTransfersStmt, claimDueScheduledTransfers, arg.ClaimedUntil, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ScheduledTransfer{}
+	for rows.Next() {
+		var i ScheduledTransfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Currency,
+			&i.Frequency,
+			&i.Interval,
+			&i.DayOfMonth,
+			&i.StartAt,
+			&i.EndAt,
+			&i.MaxOccurrences,
+			&i.Occurrences,
+			&i.OccurrenceAt,
+			&i.NextRunAt,
+			&i.Attempts,
+			&i.ClaimedUntil,
+			&i.Status,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OccurrenceAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createScheduledTransfer = `-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+    owner,
+    from_account_id,
+    to_account_id,
+    amount,
+    currency,
+    frequency,
+    interval,
+    day_of_month,
+    start_at,
+    end_at,
+    max_occurrences,
+    occurrence_at,
+    next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12
+) RETURNING id, owner, from_account_id, to_account_id, amount, currency, frequency, interval, day_of_month, start_at, end_at, max_occurrences, occurrences, occurrence_at, next_run_at, attempts, claimed_until, status, updated_at, created_at, occurrence_amount
+`
+
+type CreateScheduledTransferParams struct {
+	Owner          string        `json:"owner"`
+	FromAccountID  int64         `json:"from_account_id"`
+	ToAccountID    int64         `json:"to_account_id"`
+	Amount         int64         `json:"amount"`
+	Currency       string        `json:"currency"`
+	Frequency      string        `json:"frequency"`
+	Interval       int32         `json:"interval"`
+	DayOfMonth     sql.NullInt32 `json:"day_of_month"`
+	StartAt        time.Time     `json:"start_at"`
+	EndAt          sql.NullTime  `json:"end_at"`
+	MaxOccurrences sql.NullInt32 `json:"max_occurrences"`
+	OccurrenceAt   time.Time     `json:"occurrence_at"`
+}
+
+func (q *Queries) CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	row := q.queryRow(ctx, q.createScheduledTransferStmt, createScheduledTransfer,
+		arg.Owner,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Currency,
+		arg.Frequency,
+		arg.Interval,
+		arg.DayOfMonth,
+		arg.StartAt,
+		arg.EndAt,
+		arg.MaxOccurrences,
+		arg.OccurrenceAt,
+	)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Frequency,
+		&i.Interval,
+		&i.DayOfMonth,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.OccurrenceAt,
+		&i.NextRunAt,
+		&i.Attempts,
+		&i.ClaimedUntil,
+		&i.Status,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.OccurrenceAmount,
+	)
+	return i, err
+}
+
+const createScheduledTransferRun = `-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+    scheduled_transfer_id,
+    occurrence_at,
+    attempt,
+    status,
+    transfer_id,
+    error
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, scheduled_transfer_id, occurrence_at, attempt, status, transfer_id, error, created_at
+`
+
+type CreateScheduledTransferRunParams struct {
+	ScheduledTransferID int64         `json:"scheduled_transfer_id"`
+	OccurrenceAt        time.Time     `json:"occurrence_at"`
+	Attempt             int32         `json:"attempt"`
+	Status              string        `json:"status"`
+	TransferID          sql.NullInt64 `json:"transfer_id"`
+	Error               string        `json:"error"`
+}
+
+func (q *Queries) CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error) {
+	row := q.queryRow(ctx, q.createScheduledTransferRunStmt, createScheduledTransferRun,
+		arg.ScheduledTransferID,
+		arg.OccurrenceAt,
+		arg.Attempt,
+		arg.Status,
+		arg.TransferID,
+		arg.Error,
+	)
+	var i ScheduledTransferRun
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduledTransferID,
+		&i.OccurrenceAt,
+		&i.Attempt,
+		&i.Status,
+		&i.TransferID,
+		&i.Error,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getScheduledTransfer = `-- name: GetScheduledTransfer :one
+SELECT id, owner, from_account_id, to_account_id, amount, currency, frequency, interval, day_of_month, start_at, end_at, max_occurrences, occurrences, occurrence_at, next_run_at, attempts, claimed_until, status, updated_at, created_at, occurrence_amount FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error) {
+	row := q.queryRow(ctx, q.getScheduledTransferStmt, getScheduledTransfer, id)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Frequency,
+		&i.Interval,
+		&i.DayOfMonth,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.OccurrenceAt,
+		&i.NextRunAt,
+		&i.Attempts,
+		&i.ClaimedUntil,
+		&i.Status,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.OccurrenceAmount,
+	)
+	return i, err
+}
+
+const listScheduledTransferRuns = `-- name: ListScheduledTransferRuns :many
+SELECT id, scheduled_transfer_id, occurrence_at, attempt, status, transfer_id, error, created_at FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListScheduledTransferRunsParams struct {
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	Limit               int32 `json:"limit"`
+	Offset              int32 `json:"offset"`
+}
+
+func (q *Queries) ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error) {
+	rows, err := q.query(ctx, q.listScheduledTransferRunsStmt, listScheduledTransferRuns, arg.ScheduledTransferID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ScheduledTransferRun{}
+	for rows.Next() {
+		var i ScheduledTransferRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduledTransferID,
+			&i.OccurrenceAt,
+			&i.Attempt,
+			&i.Status,
+			&i.TransferID,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduledTransfers = `-- name: ListScheduledTransfers :many
+SELECT id, owner, from_account_id, to_account_id, amount, currency, frequency, interval, day_of_month, start_at, end_at, max_occurrences, occurrences, occurrence_at, next_run_at, attempts, claimed_until, status, updated_at, created_at, occurrence_amount FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3
+`
+
+type ListScheduledTransfersParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error) {
+	rows, err := q.query(ctx, q.listScheduledTransfersStmt, listScheduledTransfers, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ScheduledTransfer{}
+	for rows.Next() {
+		var i ScheduledTransfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Currency,
+			&i.Frequency,
+			&i.Interval,
+			&i.DayOfMonth,
+			&i.StartAt,
+			&i.EndAt,
+			&i.MaxOccurrences,
+			&i.Occurrences,
+			&i.OccurrenceAt,
+			&i.NextRunAt,
+			&i.Attempts,
+			&i.ClaimedUntil,
+			&i.Status,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+			&i.OccurrenceAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateScheduledTransfer = `-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET amount = $2,
+    end_at = $3,
+    max_occurrences = $4,
+    status = $5,
+    updated_at = now()
+WHERE id = $1 AND status IN ('active', 'paused')
+RETURNING id, owner, from_account_id, to_account_id, amount, currency, frequency, interval, day_of_month, start_at, end_at, max_occurrences, occurrences, occurrence_at, next_run_at, attempts, claimed_until, status, updated_at, created_at, occurrence_amount
+`
+
+type UpdateScheduledTransferParams struct {
+	ID             int64         `json:"id"`
+	Amount         int64         `json:"amount"`
+	EndAt          sql.NullTime  `json:"end_at"`
+	MaxOccurrences sql.NullInt32 `json:"max_occurrences"`
+	Status         string        `json:"status"`
+}
+
+func (q *Queries) UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error) {
+	row := q.queryRow(ctx, q.updateScheduledTransferStmt, updateScheduledTransfer,
+		arg.ID,
+		arg.Amount,
+		arg.EndAt,
+		arg.MaxOccurrences,
+		arg.Status,
+	)
+	var i ScheduledTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Frequency,
+		&i.Interval,
+		&i.DayOfMonth,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.Occurrences,
+		&i.OccurrenceAt,
+		&i.NextRunAt,
+		&i.Attempts,
+		&i.ClaimedUntil,
+		&i.Status,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.OccurrenceAmount,
+	)
+	return i, err
+}
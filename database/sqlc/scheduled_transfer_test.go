@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createDueScheduledTransfer(t *testing.T, from, to Account) ScheduledTransfer {
+	due := time.Now().Add(-time.Minute)
+	arg := CreateScheduledTransferParams{
+		Owner:         from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Currency:      from.Currency,
+		Frequency:     FrequencyDaily,
+		Interval:      1,
+		StartAt:       due,
+		OccurrenceAt:  due,
+	}
+
+	schedule, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, ScheduleStatusActive, schedule.Status)
+	require.Zero(t, schedule.Occurrences)
+	require.WithinDuration(t, due, schedule.NextRunAt, time.Second)
+	require.False(t, schedule.ClaimedUntil.Valid)
+	return schedule
+}
+
+// claimedIDs claims the due schedules and returns their ids
+func claimedIDs(t *testing.T) []int64 {
+	schedules, err := testQueries.ClaimDueScheduledTransfers(context.Background(), ClaimDueScheduledTransfersParams{
+		ClaimedUntil: time.Now().Add(time.Minute),
+		BatchSize:    1000,
+	})
+	require.NoError(t, err)
+
+	ids := make([]int64, 0, len(schedules))
+	for _, schedule := range schedules {
+		ids = append(ids, schedule.ID)
+	}
+	return ids
+}
+
+func TestClaimDueScheduledTransfers(t *testing.T) {
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
+	schedule := createDueScheduledTransfer(t, account1, account2)
+
+	// 1. a claimed schedule is skipped until its claim ends
+	require.Contains(t, claimedIDs(t), schedule.ID)
+	require.NotContains(t, claimedIDs(t), schedule.ID)
+
+	// 2. once the claim is released, a schedule locked by another scheduler is still skipped
+	_, err := testQueries.AdvanceScheduledTransfer(context.Background(), AdvanceScheduledTransferParams{
+		ID:           schedule.ID,
+		OccurrenceAt: schedule.OccurrenceAt,
+		NextRunAt:    schedule.NextRunAt,
+		Status:       ScheduleStatusActive,
+	})
+	require.NoError(t, err)
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(context.Background(), "SELECT id FROM scheduled_transfers WHERE id = $1 FOR UPDATE", schedule.ID)
+	require.NoError(t, err)
+	require.NotContains(t, claimedIDs(t), schedule.ID)
+
+	require.NoError(t, tx.Rollback())
+	require.Contains(t, claimedIDs(t), schedule.ID)
+}
+
+func TestScheduledTransferOccurrenceAmount(t *testing.T) {
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
+	schedule := createDueScheduledTransfer(t, account1, account2)
+	require.False(t, schedule.OccurrenceAmount.Valid)
+
+	// 1. claiming the occurrence freezes its amount
+	require.Contains(t, claimedIDs(t), schedule.ID)
+	claimed, err := testQueries.GetScheduledTransfer(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, sql.NullInt64{Int64: schedule.Amount, Valid: true}, claimed.OccurrenceAmount)
+
+	// 2. a retry of the occurrence keeps the frozen amount after the schedule is updated
+	_, err = testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     schedule.ID,
+		Amount: schedule.Amount + 5,
+		Status: ScheduleStatusActive,
+	})
+	require.NoError(t, err)
+
+	retry, err := testQueries.AdvanceScheduledTransfer(context.Background(), AdvanceScheduledTransferParams{
+		ID:           schedule.ID,
+		Attempts:     1,
+		OccurrenceAt: schedule.OccurrenceAt,
+		NextRunAt:    schedule.NextRunAt,
+		Status:       ScheduleStatusActive,
+	})
+	require.NoError(t, err)
+	require.Equal(t, claimed.OccurrenceAmount, retry.OccurrenceAmount)
+
+	// 3. the next occurrence transfers the updated amount
+	next := schedule.OccurrenceAt.AddDate(0, 0, 1)
+	advanced, err := testQueries.AdvanceScheduledTransfer(context.Background(), AdvanceScheduledTransferParams{
+		ID:           schedule.ID,
+		Occurrences:  1,
+		OccurrenceAt: next,
+		NextRunAt:    next,
+		Status:       ScheduleStatusActive,
+	})
+	require.NoError(t, err)
+	require.False(t, advanced.OccurrenceAmount.Valid)
+}
+
+func TestRecordScheduledTransferRunTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
+	schedule := createDueScheduledTransfer(t, account1, account2)
+	require.Contains(t, claimedIDs(t), schedule.ID)
+
+	// 1. the run is recorded and the schedule moves to its next occurrence without its claim
+	next := schedule.OccurrenceAt.AddDate(0, 0, 1)
+	result, err := store.RecordScheduledTransferRunTx(context.Background(), RecordScheduledTransferRunTxParams{
+		Run: CreateScheduledTransferRunParams{
+			ScheduledTransferID: schedule.ID,
+			OccurrenceAt:        schedule.OccurrenceAt,
+			Attempt:             1,
+			Status:              RunStatusFailed,
+			Error:               ErrInsufficientFunds.Error(),
+		},
+		Schedule: AdvanceScheduledTransferParams{
+			ID:           schedule.ID,
+			Occurrences:  1,
+			OccurrenceAt: next,
+			NextRunAt:    next,
+			Status:       ScheduleStatusActive,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, RunStatusFailed, result.Run.Status)
+	require.False(t, result.Run.TransferID.Valid)
+	require.Equal(t, int32(1), result.Schedule.Occurrences)
+	require.WithinDuration(t, next, result.Schedule.NextRunAt, time.Second)
+	require.False(t, result.Schedule.ClaimedUntil.Valid)
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: schedule.ID,
+		Limit:               5,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, result.Run.ID, runs[0].ID)
+
+	// 2. a schedule cancelled while it was running stays cancelled
+	cancelled, err := testQueries.CancelScheduledTransfer(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduleStatusCancelled, cancelled.Status)
+
+	result, err = store.RecordScheduledTransferRunTx(context.Background(), RecordScheduledTransferRunTxParams{
+		Run: CreateScheduledTransferRunParams{
+			ScheduledTransferID: schedule.ID,
+			OccurrenceAt:        next,
+			Attempt:             1,
+			Status:              RunStatusSucceeded,
+		},
+		Schedule: AdvanceScheduledTransferParams{
+			ID:           schedule.ID,
+			Occurrences:  2,
+			OccurrenceAt: next.AddDate(0, 0, 1),
+			NextRunAt:    next.AddDate(0, 0, 1),
+			Status:       ScheduleStatusActive,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduleStatusCancelled, result.Schedule.Status)
+
+	_, err = testQueries.CancelScheduledTransfer(context.Background(), schedule.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,96 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: security_event.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const createSecurityEvent = `-- name: CreateSecurityEvent :one
+INSERT INTO security_events (
+    username,
+    event_type,
+    session_id,
+    user_agent,
+    client_ip
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING id, username, event_type, session_id, user_agent, client_ip, created_at
+`
+
+type CreateSecurityEventParams struct {
+	Username  string        `json:"username"`
+	EventType string        `json:"event_type"`
+	SessionID uuid.NullUUID `json:"session_id"`
+	UserAgent string        `json:"user_agent"`
+	ClientIp  string        `json:"client_ip"`
+}
+
+func (q *Queries) CreateSecurityEvent(ctx context.Context, arg CreateSecurityEventParams) (SecurityEvent, error) {
+	row := q.queryRow(ctx, q.createSecurityEventStmt, createSecurityEvent,
+		arg.Username,
+		arg.EventType,
+		arg.SessionID,
+		arg.UserAgent,
+		arg.ClientIp,
+	)
+	var i SecurityEvent
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.EventType,
+		&i.SessionID,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listSecurityEvents = `-- name: ListSecurityEvents :many
+SELECT id, username, event_type, session_id, user_agent, client_ip, created_at FROM security_events
+WHERE username = $1
+ORDER BY id DESC
+LIMIT $2
+`
+
+type ListSecurityEventsParams struct {
+	Username string `json:"username"`
+	Limit    int32  `json:"limit"`
+}
+
+func (q *Queries) ListSecurityEvents(ctx context.Context, arg ListSecurityEventsParams) ([]SecurityEvent, error) {
+	rows, err := q.query(ctx, q.listSecurityEventsStmt, listSecurityEvents, arg.Username, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SecurityEvent{}
+	for rows.Next() {
+		var i SecurityEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.EventType,
+			&i.SessionID,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
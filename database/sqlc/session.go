@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSessionBlocked     = errors.New("blocked session")
+	ErrRefreshTokenReused = errors.New("refresh token was already used, all sessions of its family are blocked")
+)
+
+// SecurityEventTokenReuse is recorded when a rotated refresh token is presented again
+const SecurityEventTokenReuse = "refresh_token_reuse"
+
+// RotateSessionTxParams contains the session whose refresh token is exchanged and the session replacing it
+type RotateSessionTxParams struct {
+	SessionID uuid.UUID `json:"session_id"`
+	// NewSessionID is the id of the new refresh token
+	NewSessionID    uuid.UUID `json:"new_session_id"`
+	NewRefreshToken string    `json:"new_refresh_token"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	UserAgent       string    `json:"user_agent"`
+	ClientIp        string    `json:"client_ip"`
+	// AccessTokenID is the access token issued along with the new refresh token
+	AccessTokenID        uuid.UUID `json:"access_token_id"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+}
+
+// RotateSessionTxResult is the rotated session and the new session of the same family
+type RotateSessionTxResult struct {
+	OldSession Session `json:"old_session"`
+	NewSession Session `json:"new_session"`
+	// BlockedSessions are the sessions of the family blocked because the refresh token was reused
+	BlockedSessions []Session `json:"blocked_sessions"`
+}
+
+// RotateSessionTx exchanges the refresh token of a session for a new session in the same token family,
+// the new session keeps the oauth client of the family.
+// A refresh token can be exchanged only once. Presenting it again means it was copied, so the whole family
+// is blocked, a security event is recorded and ErrRefreshTokenReused is returned.
+func (s *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error) {
+	var result RotateSessionTxResult
+	reused := false
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		// 1. lock the session so concurrent renewals with the same refresh token are serialized
+		result.OldSession, err = q.GetSessionForUpdate(ctx, arg.SessionID)
+		if err != nil {
+			return err
+		}
+
+		// 2. a rotated refresh token is reused, block the family and keep the event with the block
+		if result.OldSession.RotatedAt.Valid {
+			reused = true
+			result.BlockedSessions, err = q.BlockSessionFamily(ctx, result.OldSession.FamilyID)
+			if err != nil {
+				return err
+			}
+			_, err = q.CreateSecurityEvent(ctx, CreateSecurityEventParams{
+				Username:  result.OldSession.Username,
+				EventType: SecurityEventTokenReuse,
+				SessionID: uuid.NullUUID{UUID: result.OldSession.ID, Valid: true},
+				UserAgent: arg.UserAgent,
+				ClientIp:  arg.ClientIp,
+			})
+			return err
+		}
+
+		if result.OldSession.IsBlocked {
+			return ErrSessionBlocked
+		}
+
+		// 3. mark the session as rotated and create its successor in the same family
+		result.OldSession, err = q.RotateSession(ctx, arg.SessionID)
+		if err != nil {
+			return err
+		}
+
+		result.NewSession, err = q.CreateSession(ctx, CreateSessionParams{
+			ID:                   arg.NewSessionID,
+			Username:             result.OldSession.Username,
+			RefreshToken:         arg.NewRefreshToken,
+			UserAgent:            arg.UserAgent,
+			ClientIp:             arg.ClientIp,
+			IsBlocked:            false,
+			ExpiresAt:            arg.ExpiresAt,
+			FamilyID:             result.OldSession.FamilyID,
+			ParentID:             uuid.NullUUID{UUID: result.OldSession.ID, Valid: true},
+			AccessTokenID:        uuid.NullUUID{UUID: arg.AccessTokenID, Valid: true},
+			AccessTokenExpiresAt: sql.NullTime{Time: arg.AccessTokenExpiresAt, Valid: true},
+			ClientID:             result.OldSession.ClientID,
+		})
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// the block is committed before the reuse is reported
+	if reused {
+		return result, ErrRefreshTokenReused
+	}
+	return result, nil
+}
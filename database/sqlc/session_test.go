@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, username string) Session {
+	args := CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    "curl/8.0",
+		ClientIp:     "10.0.0.1",
+		IsBlocked:    false,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	args.FamilyID = args.ID
+
+	session, err := testQueries.CreateSession(context.Background(), args)
+	require.NoError(t, err)
+	require.Equal(t, args.ID, session.ID)
+	require.Equal(t, args.Username, session.Username)
+	require.False(t, session.IsBlocked)
+
+	return session
+}
+
+func TestBlockSession(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+	session2 := createRandomSession(t, user.Username)
+
+	sessions, err := testQueries.ListActiveSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	// a session can only be blocked by its owner
+	_, err = testQueries.BlockSession(context.Background(), BlockSessionParams{
+		ID:       session1.ID,
+		Username: createRandomUser(t).Username,
+	})
+	require.Error(t, err)
+
+	blocked, err := testQueries.BlockSession(context.Background(), BlockSessionParams{
+		ID:       session1.ID,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	sessions, err = testQueries.ListActiveSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, session2.ID, sessions[0].ID)
+}
+
+func TestBlockUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomSession(t, user.Username)
+	}
+
+	revoked, err := testQueries.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), revoked)
+
+	sessions, err := testQueries.ListActiveSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestRotateSessionTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username)
+
+	rotate := func(sessionID uuid.UUID) (RotateSessionTxResult, error) {
+		return store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+			SessionID:       sessionID,
+			NewSessionID:    uuid.New(),
+			NewRefreshToken: util.RandomString(32),
+			ExpiresAt:       time.Now().Add(time.Hour),
+			UserAgent:       "curl/8.0",
+			ClientIp:        "10.0.0.2",
+		})
+	}
+
+	// 1. the first renewal replaces the session with a new one in the same family
+	result, err := rotate(session.ID)
+	require.NoError(t, err)
+	require.True(t, result.OldSession.RotatedAt.Valid)
+	require.Equal(t, session.FamilyID, result.NewSession.FamilyID)
+	require.Equal(t, session.ID, result.NewSession.ParentID.UUID)
+
+	sessions, err := testQueries.ListActiveSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, result.NewSession.ID, sessions[0].ID)
+
+	// 2. presenting the rotated refresh token again blocks the whole family
+	_, err = rotate(session.ID)
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	newSession, err := testQueries.GetSession(context.Background(), result.NewSession.ID)
+	require.NoError(t, err)
+	require.True(t, newSession.IsBlocked)
+
+	events, err := testQueries.ListSecurityEvents(context.Background(), ListSecurityEventsParams{
+		Username: user.Username,
+		Limit:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, SecurityEventTokenReuse, events[0].EventType)
+	require.Equal(t, session.ID, events[0].SessionID.UUID)
+
+	// 3. the blocked successor can't be renewed either
+	_, err = rotate(newSession.ID)
+	require.ErrorIs(t, err, ErrSessionBlocked)
+}
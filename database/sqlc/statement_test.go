@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListStatementEntries(t *testing.T) {
+	store := NewStore(testDB)
+	account := createFundedAccount(t, 100)
+
+	counterparty, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account.ID,
+		ToAccountId:   counterparty.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+	end := time.Now().Add(time.Second)
+
+	// 1. the opening balance is the funding entry, it was posted before the period
+	opening, err := testQueries.GetAccountBalanceAt(context.Background(), GetAccountBalanceAtParams{AccountID: account.ID, At: start})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), opening)
+
+	// 2. the entry of the transfer is listed with the account on the other side
+	entries, err := testQueries.ListStatementEntries(context.Background(), ListStatementEntriesParams{
+		AccountID: account.ID,
+		FromTime:  start,
+		ToTime:    end,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, result.FromEntry.ID, entries[0].ID)
+	require.Equal(t, int64(-30), entries[0].Amount)
+	require.Equal(t, result.Transfer.ID, entries[0].TransferID.Int64)
+	require.Equal(t, counterparty.ID, entries[0].CounterpartyAccountID.Int64)
+	require.Equal(t, counterparty.Owner, entries[0].CounterpartyOwner.String)
+	require.False(t, entries[0].ReversalOf.Valid)
+
+	// 3. the counterparty sees the same transfer from the other side
+	entries, err = testQueries.ListStatementEntries(context.Background(), ListStatementEntriesParams{
+		AccountID: counterparty.ID,
+		FromTime:  start,
+		ToTime:    end,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, int64(30), entries[0].Amount)
+	require.Equal(t, account.ID, entries[0].CounterpartyAccountID.Int64)
+
+	closing, err := testQueries.GetAccountBalanceAt(context.Background(), GetAccountBalanceAtParams{AccountID: account.ID, At: end})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), closing)
+}
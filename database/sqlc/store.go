@@ -2,14 +2,39 @@ package database
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrIdempotencyKeyConflict = errors.New("idempotency key was already used with a different request")
+	ErrQuoteNotUsable         = errors.New("transfer quote is expired or already used")
+	ErrQuoteMismatch          = errors.New("transfer quote doesn't match the transfer")
 )
 
 // Store provides all functions to execute db queries and transactions.
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	UpdateAccountBalanceTx(ctx context.Context, arg UpdateAccountBalanceTxParams) (UpdateAccountBalanceTxResult, error)
+	AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParams) (AuthorizeHoldTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (ReleaseHoldTxResult, error)
+	ExpireNextHoldTx(ctx context.Context) (ReleaseHoldTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	RecordScheduledTransferRunTx(ctx context.Context, arg RecordScheduledTransferRunTxParams) (RecordScheduledTransferRunTxResult, error)
+	VerifyLedger(ctx context.Context) (LedgerReport, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error)
+	EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (UserTotp, error)
+	DisableTOTPTx(ctx context.Context, username string) error
+	VerifyEmailTx(ctx context.Context, tokenHash string) (User, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error)
 }
 
 // Store provides all functions to execute db queries and transactions.
@@ -28,9 +53,14 @@ func NewStore(db *sql.DB) Store {
 
 // execTx executes a function within a database transaction.
 func (s *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+	return s.execTxWithOptions(ctx, nil, fn)
+}
+
+// execTxWithOptions executes a function within a database transaction started with the given options.
+func (s *SQLStore) execTxWithOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
 
 	// 1. Begin the transaction
-	txn, err := s.db.BeginTx(ctx, nil)
+	txn, err := s.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -49,11 +79,30 @@ func (s *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
 	return txn.Commit()
 }
 
+// ReservedIdempotencyKeyPrefix starts the idempotency keys of the transfers made by the servers on behalf of
+// the owners, like the scheduled transfers. The keys of the clients can't start with it, so a client can never
+// take the key of a transfer the servers are about to make.
+const ReservedIdempotencyKeyPrefix = "reserved:"
+
 // TransferTxParams to perform a transfer b/w accounts
 type TransferTxParams struct {
 	FromAccountId int64 `json:"from_account_id"`
 	ToAccountId   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// IdempotencyKey is optional, when set a retried request returns the result of the first one.
+	IdempotencyKey string `json:"idempotency_key"`
+	// QuoteID is the locked exchange rate quote used to convert a cross currency transfer
+	QuoteID uuid.NullUUID `json:"quote_id"`
+}
+
+// requestHash returns the fingerprint of the transfer params stored along with the idempotency key
+func (arg TransferTxParams) requestHash() string {
+	request := fmt.Sprintf("%d:%d:%d", arg.FromAccountId, arg.ToAccountId, arg.Amount)
+	if arg.QuoteID.Valid {
+		request = fmt.Sprintf("%s:%s", request, arg.QuoteID.UUID)
+	}
+	sum := sha256.Sum256([]byte(request))
+	return hex.EncodeToString(sum[:])
 }
 
 // TransferTxResult to store the result of this txn
@@ -63,10 +112,15 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Replayed is true when the result is returned for an already used idempotency key
+	Replayed bool `json:"-"`
 }
 
 // TransferTx performs a money transfers from one account to the other account.
 // It creates a transfer record, add account entries and update accounts balance with in a single transaction.
+// It fails with ErrInsufficientFunds if the available balance of the from account would go past its overdraft limit,
+// and with a *VelocityError if the transfer exceeds a velocity limit of the from account or no limit is set
+// for the tier of its owner in its currency.
 func (s *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 
 	// 1. create a var of tx result
@@ -76,43 +130,89 @@ func (s *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Transf
 	err := s.execTx(ctx, func(q *Queries) error {
 		var err error
 
-		// 2.1 first create a transfer by calling the queries create transfer
+		// 2.0 reserve the idempotency key, if it is already used return the stored result
+		var owner string
+		if arg.IdempotencyKey != "" {
+			owner, result.Replayed, err = reserveIdempotencyKey(ctx, q, arg, &result)
+			if err != nil || result.Replayed {
+				return err
+			}
+		}
+
+		// 2.1 a cross currency transfer credits the amount converted at the quoted rate
+		toAmount, exchangeRate := arg.Amount, "1"
+		if arg.QuoteID.Valid {
+			quote, err := consumeTransferQuote(ctx, q, arg)
+			if err != nil {
+				return err
+			}
+			toAmount, exchangeRate = quote.ToAmount, quote.Rate
+		}
+
+		// 2.2 first create a transfer by calling the queries create transfer
 		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
 			FromAccountID: arg.FromAccountId,
 			ToAccountID:   arg.ToAccountId,
 			Amount:        arg.Amount,
+			ToAmount:      toAmount,
+			ExchangeRate:  exchangeRate,
+			QuoteID:       arg.QuoteID,
 		})
 		if err != nil {
 			return err
 		}
 
-		// 2.2 create an entry in from account for balance debited
+		// 2.3 create an entry in from account for balance debited, legs of a same currency transfer sum to zero
+		transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
 		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountId,
-			Amount:    arg.Amount,
+			AccountID:  arg.FromAccountId,
+			Amount:     -arg.Amount,
+			TransferID: transferID,
 		})
 		if err != nil {
 			return err
 		}
 
-		// 2.3 create an entry in to account for balance credited
+		// 2.4 create an entry in to account for balance credited
 		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountId,
-			Amount:    arg.Amount,
+			AccountID:  arg.ToAccountId,
+			Amount:     toAmount,
+			TransferID: transferID,
 		})
 		if err != nil {
 			return err
 		}
 
 		// To Avoid the exclusive lock happens during the transaction always update the lowest id's operation first
-		// 2.4 Update the From Account and To Account
+		// 2.5 Update the From Account and To Account
 		if arg.FromAccountId < arg.ToAccountId {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountId, arg.ToAccountId, -arg.Amount, arg.Amount)
+			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountId, arg.ToAccountId, -arg.Amount, toAmount)
 			if err != nil {
 				return err
 			}
 		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountId, arg.FromAccountId, arg.Amount, -arg.Amount)
+			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountId, arg.FromAccountId, toAmount, -arg.Amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		// 2.6 check the velocity limits of the from account, which is locked by its update
+		if err = checkVelocity(ctx, q, result.FromAccount, arg.Amount); err != nil {
+			return err
+		}
+
+		// 2.7 record the result against the idempotency key in the same transaction
+		if arg.IdempotencyKey != "" {
+			response, err := json.Marshal(result)
+			if err != nil {
+				return err
+			}
+			err = q.UpdateIdempotencyKeyResponse(ctx, UpdateIdempotencyKeyResponseParams{
+				Owner:    owner,
+				Key:      arg.IdempotencyKey,
+				Response: response,
+			})
 			if err != nil {
 				return err
 			}
@@ -123,6 +223,63 @@ func (s *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Transf
 	return result, err
 }
 
+// consumeTransferQuote marks the quote as used so it can't be used by another transfer,
+// and checks that it was created for the accounts and the amount of this transfer.
+func consumeTransferQuote(ctx context.Context, q *Queries, arg TransferTxParams) (TransferQuote, error) {
+	quote, err := q.UseTransferQuote(ctx, arg.QuoteID.UUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return quote, ErrQuoteNotUsable
+		}
+		return quote, err
+	}
+
+	if quote.FromAccountID != arg.FromAccountId || quote.ToAccountID != arg.ToAccountId || quote.FromAmount != arg.Amount {
+		return quote, ErrQuoteMismatch
+	}
+	return quote, nil
+}
+
+// reserveIdempotencyKey inserts the idempotency key for the owner of the from account.
+// If the key already exists it loads the stored result into result and reports it as a replay.
+// A concurrent request with the same key waits on the insert until the first transaction finishes.
+func reserveIdempotencyKey(ctx context.Context, q *Queries, arg TransferTxParams, result *TransferTxResult) (string, bool, error) {
+
+	// 1. the key is scoped to the owner of the from account
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountId)
+	if err != nil {
+		return "", false, err
+	}
+
+	// 2. try to reserve the key
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Owner:       fromAccount.Owner,
+		Key:         arg.IdempotencyKey,
+		RequestHash: arg.requestHash(),
+	})
+	if err == nil {
+		return fromAccount.Owner, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", false, err
+	}
+
+	// 3. key is already used, check it was used for the same request
+	idempotencyKey, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Owner: fromAccount.Owner,
+		Key:   arg.IdempotencyKey,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	if idempotencyKey.RequestHash != arg.requestHash() {
+		return "", false, ErrIdempotencyKeyConflict
+	}
+
+	err = json.Unmarshal(idempotencyKey.Response, result)
+	return fromAccount.Owner, true, err
+}
+
 func addMoney(
 	ctx context.Context,
 	q *Queries,
@@ -131,12 +288,13 @@ func addMoney(
 	amount1 int64,
 	amount2 int64) (account1 Account, account2 Account, err error) {
 
-	// 1. Update account 1 balance
+	// 1. Update account 1 balance, a debit past the overdraft limit fails with ErrInsufficientFunds
 	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
 		Amount: amount1,
 		ID:     accountID1,
 	})
 	if err != nil {
+		err = fundsError(err)
 		return
 	}
 
@@ -146,6 +304,7 @@ func addMoney(
 		ID:     accountID2,
 	})
 	if err != nil {
+		err = fundsError(err)
 		return
 	}
 	return
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/akshay237/backend-with-go/util"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -12,9 +14,9 @@ func TestTransferTx(t *testing.T) {
 	// 0. create store object
 	store := NewStore(testDB)
 
-	// 1. create two accounts for transfer
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	// 1. create two accounts for transfer, funded for all the transfers as the balances can't go negative
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
 
 	fmt.Println("Before transaction: ", account1.Balance, account2.Balance)
 
@@ -64,7 +66,8 @@ func TestTransferTx(t *testing.T) {
 		fromEntry := result.FromEntry
 		require.NotEmpty(t, fromEntry)
 		require.Equal(t, account1.ID, fromEntry.AccountID)
-		require.Equal(t, amount, fromEntry.Amount)
+		require.Equal(t, -amount, fromEntry.Amount)
+		require.Equal(t, transfer.ID, fromEntry.TransferID.Int64)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
 
@@ -76,6 +79,8 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, toEntry)
 		require.Equal(t, account2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.Amount)
+		require.Equal(t, transfer.ID, toEntry.TransferID.Int64)
+		require.Zero(t, fromEntry.Amount+toEntry.Amount)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
 
@@ -116,9 +121,9 @@ func TestTransferTxDeadLock(t *testing.T) {
 	// 0. create store object
 	store := NewStore(testDB)
 
-	// 1. create two accounts for transfer
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	// 1. create two accounts for transfer, funded for all the transfers as the balances can't go negative
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
 
 	fmt.Println("Before transaction: ", account1.Balance, account2.Balance)
 
@@ -164,3 +169,56 @@ func TestTransferTxDeadLock(t *testing.T) {
 	require.Equal(t, updatedAccount2.Balance, account2.Balance)
 	fmt.Println("After transaction: ", updateAccount1.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxIdempotency(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 100)
+	account2 := createFundedAccount(t, 100)
+
+	args := TransferTxParams{
+		FromAccountId:  account1.ID,
+		ToAccountId:    account2.ID,
+		Amount:         10,
+		IdempotencyKey: util.RandomString(16),
+	}
+
+	// 1. concurrent retries with the same key create only one transfer
+	n := 5
+	results := make(chan TransferTxResult)
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.TransferTx(context.Background(), args)
+			errs <- err
+			results <- result
+		}()
+	}
+
+	replayed := 0
+	var transferID int64
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+
+		result := <-results
+		require.NotZero(t, result.Transfer.ID)
+		if transferID == 0 {
+			transferID = result.Transfer.ID
+		}
+		require.Equal(t, transferID, result.Transfer.ID)
+		if result.Replayed {
+			replayed++
+		}
+	}
+	require.Equal(t, n-1, replayed)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-args.Amount, updatedAccount1.Balance)
+
+	// 2. the same key with a different request is rejected
+	args.Amount = 20
+	_, err = store.TransferTx(context.Background(), args)
+	require.ErrorIs(t, err, ErrIdempotencyKeyConflict)
+}
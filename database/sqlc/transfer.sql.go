@@ -7,26 +7,48 @@ package database
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    to_amount,
+    exchange_rate,
+    quote_id,
+    hold_id,
+    reversal_of
 ) values (
-    $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, hold_id, status, reversed_amount, reversal_of
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	ToAmount      int64         `json:"to_amount"`
+	ExchangeRate  string        `json:"exchange_rate"`
+	QuoteID       uuid.NullUUID `json:"quote_id"`
+	HoldID        sql.NullInt64 `json:"hold_id"`
+	ReversalOf    sql.NullInt64 `json:"reversal_of"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.queryRow(ctx, q.createTransferStmt, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.queryRow(ctx, q.createTransferStmt, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.QuoteID,
+		arg.HoldID,
+		arg.ReversalOf,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +56,19 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.HoldID,
+		&i.Status,
+		&i.ReversedAmount,
+		&i.ReversalOf,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, hold_id, status, reversed_amount, reversal_of FROM transfers
 where id = $1
 LIMIT 1
 `
@@ -53,12 +82,88 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.HoldID,
+		&i.Status,
+		&i.ReversedAmount,
+		&i.ReversalOf,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, hold_id, status, reversed_amount, reversal_of FROM transfers
+where id = $1
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.queryRow(ctx, q.getTransferForUpdateStmt, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.HoldID,
+		&i.Status,
+		&i.ReversedAmount,
+		&i.ReversalOf,
 	)
 	return i, err
 }
 
+const listTransferReversals = `-- name: ListTransferReversals :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, hold_id, status, reversed_amount, reversal_of FROM transfers
+where reversal_of = $1
+ORDER BY id
+`
+
+func (q *Queries) ListTransferReversals(ctx context.Context, reversalOf sql.NullInt64) ([]Transfer, error) {
+	rows, err := q.query(ctx, q.listTransferReversalsStmt, listTransferReversals, reversalOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.QuoteID,
+			&i.HoldID,
+			&i.Status,
+			&i.ReversedAmount,
+			&i.ReversalOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, hold_id, status, reversed_amount, reversal_of FROM transfers
 where   
     from_account_id = $1 or
     to_account_id = $2
@@ -94,6 +199,13 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.QuoteID,
+			&i.HoldID,
+			&i.Status,
+			&i.ReversedAmount,
+			&i.ReversalOf,
 		); err != nil {
 			return nil, err
 		}
@@ -107,3 +219,37 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	}
 	return items, nil
 }
+
+const updateTransferReversal = `-- name: UpdateTransferReversal :one
+UPDATE transfers
+SET reversed_amount = $2,
+    status = $3
+where id = $1
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, hold_id, status, reversed_amount, reversal_of
+`
+
+type UpdateTransferReversalParams struct {
+	ID             int64  `json:"id"`
+	ReversedAmount int64  `json:"reversed_amount"`
+	Status         string `json:"status"`
+}
+
+func (q *Queries) UpdateTransferReversal(ctx context.Context, arg UpdateTransferReversalParams) (Transfer, error) {
+	row := q.queryRow(ctx, q.updateTransferReversalStmt, updateTransferReversal, arg.ID, arg.ReversedAmount, arg.Status)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.HoldID,
+		&i.Status,
+		&i.ReversedAmount,
+		&i.ReversalOf,
+	)
+	return i, err
+}
@@ -0,0 +1,127 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: transfer_quote.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createTransferQuote = `-- name: CreateTransferQuote :one
+INSERT INTO transfer_quotes (
+    id,
+    owner,
+    from_account_id,
+    to_account_id,
+    from_currency,
+    to_currency,
+    rate,
+    from_amount,
+    to_amount,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING id, owner, from_account_id, to_account_id, from_currency, to_currency, rate, from_amount, to_amount, expires_at, used_at, created_at
+`
+
+type CreateTransferQuoteParams struct {
+	ID            uuid.UUID `json:"id"`
+	Owner         string    `json:"owner"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	FromCurrency  string    `json:"from_currency"`
+	ToCurrency    string    `json:"to_currency"`
+	Rate          string    `json:"rate"`
+	FromAmount    int64     `json:"from_amount"`
+	ToAmount      int64     `json:"to_amount"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateTransferQuote(ctx context.Context, arg CreateTransferQuoteParams) (TransferQuote, error) {
+	row := q.queryRow(ctx, q.createTransferQuoteStmt, createTransferQuote,
+		arg.ID,
+		arg.Owner,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.FromCurrency,
+		arg.ToCurrency,
+		arg.Rate,
+		arg.FromAmount,
+		arg.ToAmount,
+		arg.ExpiresAt,
+	)
+	var i TransferQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.FromAmount,
+		&i.ToAmount,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTransferQuote = `-- name: GetTransferQuote :one
+SELECT id, owner, from_account_id, to_account_id, from_currency, to_currency, rate, from_amount, to_amount, expires_at, used_at, created_at FROM transfer_quotes
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetTransferQuote(ctx context.Context, id uuid.UUID) (TransferQuote, error) {
+	row := q.queryRow(ctx, q.getTransferQuoteStmt, getTransferQuote, id)
+	var i TransferQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.FromAmount,
+		&i.ToAmount,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useTransferQuote = `-- name: UseTransferQuote :one
+UPDATE transfer_quotes
+SET used_at = now()
+WHERE id = $1 AND used_at IS NULL AND expires_at > now()
+RETURNING id, owner, from_account_id, to_account_id, from_currency, to_currency, rate, from_amount, to_amount, expires_at, used_at, created_at
+`
+
+func (q *Queries) UseTransferQuote(ctx context.Context, id uuid.UUID) (TransferQuote, error) {
+	row := q.queryRow(ctx, q.useTransferQuoteStmt, useTransferQuote, id)
+	var i TransferQuote
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.FromCurrency,
+		&i.ToCurrency,
+		&i.Rate,
+		&i.FromAmount,
+		&i.ToAmount,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -11,10 +11,13 @@ import (
 func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 
 	// 1. create the args for transfer
+	amount := util.RandomBalance()
 	args := CreateTransferParams{
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
-		Amount:        util.RandomBalance(),
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
 	}
 
 	// 2. calls the create transfer db function
@@ -56,3 +56,49 @@ func TestGetUser(t *testing.T) {
 	require.Equal(t, user1.FullName, user2.FullName)
 	require.Equal(t, user1.Email, user2.Email)
 }
+
+func TestUpdateUserRole(t *testing.T) {
+
+	// 1. new users are depositors
+	user1 := createRandomUser(t)
+	require.Equal(t, util.DepositorRole, user1.Role)
+
+	// 2. promote the user to admin
+	user2, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Username: user1.Username,
+		Role:     util.AdminRole,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, util.AdminRole, user2.Role)
+}
+
+func TestRehashUserPassword(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	newHashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	// 1. a hash which changed since it was checked is kept
+	rows, err := testQueries.RehashUserPassword(context.Background(), RehashUserPasswordParams{
+		NewHashedPassword: newHashedPassword,
+		Username:          user1.Username,
+		OldHashedPassword: "stale-hash",
+	})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	// 2. the checked hash is replaced without changing the password change time
+	rows, err = testQueries.RehashUserPassword(context.Background(), RehashUserPasswordParams{
+		NewHashedPassword: newHashedPassword,
+		Username:          user1.Username,
+		OldHashedPassword: user1.HashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	user2, err := testQueries.GetUser(context.Background(), user1.Username)
+	require.NoError(t, err)
+	require.Equal(t, newHashedPassword, user2.HashedPassword)
+	require.Equal(t, user1.PasswordChangedAt, user2.PasswordChangedAt)
+}
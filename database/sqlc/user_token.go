@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+)
+
+// Purposes of the tokens mailed to the users
+const (
+	UserTokenEmailVerification = "email_verification"
+	UserTokenPasswordReset     = "password_reset"
+)
+
+// VerifyEmailTx uses the email verification token and marks the address it was sent to as verified.
+// It returns sql.ErrNoRows if the token is unknown, expired or used, or the user changed the address since.
+func (s *SQLStore) VerifyEmailTx(ctx context.Context, tokenHash string) (User, error) {
+	var user User
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		token, err := q.UseUserToken(ctx, UseUserTokenParams{
+			TokenHash: tokenHash,
+			Purpose:   UserTokenEmailVerification,
+		})
+		if err != nil {
+			return err
+		}
+
+		user, err = q.VerifyUserEmail(ctx, VerifyUserEmailParams{
+			Username: token.Username,
+			Email:    token.Email,
+		})
+		return err
+	})
+	return user, err
+}
+
+// ResetPasswordTxParams contains the password reset token and the hash of the new password
+type ResetPasswordTxParams struct {
+	TokenHash      string `json:"token_hash"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+// ResetPasswordTxResult is the user with the new password and the sessions blocked by the reset
+type ResetPasswordTxResult struct {
+	User            User      `json:"user"`
+	BlockedSessions []Session `json:"blocked_sessions"`
+}
+
+// ResetPasswordTx uses the password reset token, changes the password, blocks all the sessions of the user
+// and revokes their access tokens. The other reset tokens of the user are invalidated.
+// It returns sql.ErrNoRows if the token is not usable.
+func (s *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error) {
+	var result ResetPasswordTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		token, err := q.UseUserToken(ctx, UseUserTokenParams{
+			TokenHash: arg.TokenHash,
+			Purpose:   UserTokenPasswordReset,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Username:       token.Username,
+			HashedPassword: arg.HashedPassword,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.InvalidateUserTokens(ctx, InvalidateUserTokensParams{
+			Username: token.Username,
+			Purpose:  UserTokenPasswordReset,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.BlockedSessions, err = q.BlockUserSessions(ctx, token.Username)
+		if err != nil {
+			return err
+		}
+
+		for _, session := range result.BlockedSessions {
+			if !session.AccessTokenID.Valid || !session.AccessTokenExpiresAt.Valid {
+				continue
+			}
+
+			err = q.RevokeToken(ctx, RevokeTokenParams{
+				ID:        session.AccessTokenID.UUID,
+				Username:  session.Username,
+				ExpiresAt: session.AccessTokenExpiresAt.Time,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return result, err
+}
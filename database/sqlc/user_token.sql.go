@@ -0,0 +1,129 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: user_token.sql
+
+package database
+
+import (
+	"context"
+	"time"
+)
+
+const createUserToken = `-- name: CreateUserToken :one
+INSERT INTO user_tokens (
+    token_hash,
+    username,
+    purpose,
+    email,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING token_hash, username, purpose, email, expires_at, used_at, created_at
+`
+
+type CreateUserTokenParams struct {
+	TokenHash string    `json:"token_hash"`
+	Username  string    `json:"username"`
+	Purpose   string    `json:"purpose"`
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error) {
+	row := q.queryRow(ctx, q.createUserTokenStmt, createUserToken,
+		arg.TokenHash,
+		arg.Username,
+		arg.Purpose,
+		arg.Email,
+		arg.ExpiresAt,
+	)
+	var i UserToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.Username,
+		&i.Purpose,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUsableUserToken = `-- name: GetUsableUserToken :one
+SELECT token_hash, username, purpose, email, expires_at, used_at, created_at FROM user_tokens
+WHERE token_hash = $1
+  AND purpose = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+LIMIT 1
+`
+
+type GetUsableUserTokenParams struct {
+	TokenHash string `json:"token_hash"`
+	Purpose   string `json:"purpose"`
+}
+
+func (q *Queries) GetUsableUserToken(ctx context.Context, arg GetUsableUserTokenParams) (UserToken, error) {
+	row := q.queryRow(ctx, q.getUsableUserTokenStmt, getUsableUserToken, arg.TokenHash, arg.Purpose)
+	var i UserToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.Username,
+		&i.Purpose,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const invalidateUserTokens = `-- name: InvalidateUserTokens :exec
+UPDATE user_tokens
+SET used_at = now()
+WHERE username = $1
+  AND purpose = $2
+  AND used_at IS NULL
+`
+
+type InvalidateUserTokensParams struct {
+	Username string `json:"username"`
+	Purpose  string `json:"purpose"`
+}
+
+func (q *Queries) InvalidateUserTokens(ctx context.Context, arg InvalidateUserTokensParams) error {
+	_, err := q.exec(ctx, q.invalidateUserTokensStmt, invalidateUserTokens, arg.Username, arg.Purpose)
+	return err
+}
+
+const useUserToken = `-- name: UseUserToken :one
+UPDATE user_tokens
+SET used_at = now()
+WHERE token_hash = $1
+  AND purpose = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING token_hash, username, purpose, email, expires_at, used_at, created_at
+`
+
+type UseUserTokenParams struct {
+	TokenHash string `json:"token_hash"`
+	Purpose   string `json:"purpose"`
+}
+
+func (q *Queries) UseUserToken(ctx context.Context, arg UseUserTokenParams) (UserToken, error) {
+	row := q.queryRow(ctx, q.useUserTokenStmt, useUserToken, arg.TokenHash, arg.Purpose)
+	var i UserToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.Username,
+		&i.Purpose,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
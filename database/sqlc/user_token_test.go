@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomUserToken(t *testing.T, user User, purpose string) UserToken {
+	token, err := testQueries.CreateUserToken(context.Background(), CreateUserTokenParams{
+		TokenHash: util.RandomString(64),
+		Username:  user.Username,
+		Purpose:   purpose,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.False(t, token.UsedAt.Valid)
+	return token
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	require.False(t, user.IsEmailVerified)
+
+	// 1. a reset token can't verify the email
+	resetToken := createRandomUserToken(t, user, UserTokenPasswordReset)
+	_, err := store.VerifyEmailTx(context.Background(), resetToken.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// 2. the verification token verifies the email once
+	token := createRandomUserToken(t, user, UserTokenEmailVerification)
+	verified, err := store.VerifyEmailTx(context.Background(), token.TokenHash)
+	require.NoError(t, err)
+	require.True(t, verified.IsEmailVerified)
+
+	_, err = store.VerifyEmailTx(context.Background(), token.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetUsableUserToken(t *testing.T) {
+	user := createRandomUser(t)
+	token1 := createRandomUserToken(t, user, UserTokenPasswordReset)
+
+	// 1. the token is found only with its purpose
+	token2, err := testQueries.GetUsableUserToken(context.Background(), GetUsableUserTokenParams{
+		TokenHash: token1.TokenHash,
+		Purpose:   UserTokenPasswordReset,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, token2.Username)
+
+	_, err = testQueries.GetUsableUserToken(context.Background(), GetUsableUserTokenParams{
+		TokenHash: token1.TokenHash,
+		Purpose:   UserTokenEmailVerification,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// 2. a used token is not usable anymore
+	_, err = testQueries.UseUserToken(context.Background(), UseUserTokenParams{
+		TokenHash: token1.TokenHash,
+		Purpose:   UserTokenPasswordReset,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetUsableUserToken(context.Background(), GetUsableUserTokenParams{
+		TokenHash: token1.TokenHash,
+		Purpose:   UserTokenPasswordReset,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session, err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+		ID:                   uuid.New(),
+		Username:             user.Username,
+		RefreshToken:         util.RandomString(32),
+		ExpiresAt:            time.Now().Add(time.Hour),
+		AccessTokenID:        uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		AccessTokenExpiresAt: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+	token1 := createRandomUserToken(t, user, UserTokenPasswordReset)
+	token2 := createRandomUserToken(t, user, UserTokenPasswordReset)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	// 1. the reset changes the password and blocks the sessions
+	result, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash:      token1.TokenHash,
+		HashedPassword: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, result.User.HashedPassword)
+	require.WithinDuration(t, time.Now(), result.User.PasswordChangedAt, time.Second)
+	require.Len(t, result.BlockedSessions, 1)
+	require.Equal(t, session.ID, result.BlockedSessions[0].ID)
+	require.True(t, result.BlockedSessions[0].IsBlocked)
+
+	// 1.1 the access token of the session is revoked and the change is listed for the revocation list
+	revoked, err := testQueries.ListRevokedTokens(context.Background())
+	require.NoError(t, err)
+	revokedIDs := make([]uuid.UUID, 0, len(revoked))
+	for _, token := range revoked {
+		revokedIDs = append(revokedIDs, token.ID)
+	}
+	require.Contains(t, revokedIDs, session.AccessTokenID.UUID)
+
+	changes, err := testQueries.ListPasswordChanges(context.Background(), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	require.Contains(t, changes, ListPasswordChangesRow{Username: user.Username, PasswordChangedAt: result.User.PasswordChangedAt})
+
+	// 2. neither the used token nor the other reset tokens of the user work again
+	for _, token := range []UserToken{token1, token2} {
+		_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+			TokenHash:      token.TokenHash,
+			HashedPassword: hashedPassword,
+		})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	}
+}
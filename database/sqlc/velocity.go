@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Reasons a transfer is rejected by a velocity limit
+const (
+	VelocityReasonMaxAmount      = "max_amount_exceeded"
+	VelocityReasonMaxDailyAmount = "max_daily_amount_exceeded"
+	VelocityReasonMaxHourlyCount = "max_hourly_count_exceeded"
+	VelocityReasonNotConfigured  = "velocity_limit_not_configured"
+)
+
+// VelocityError is returned by TransferTx when a transfer exceeds a velocity limit of its from account
+type VelocityError struct {
+	// Reason is one of the VelocityReason constants
+	Reason   string `json:"reason"`
+	Currency string `json:"currency"`
+	Limit    int64  `json:"limit"`
+	// Remaining is the amount, or the number of transfers for the hourly count, still allowed right now
+	Remaining int64 `json:"remaining"`
+}
+
+func (e *VelocityError) Error() string {
+	return fmt.Sprintf("transfer exceeds the velocity limit: %s", e.Reason)
+}
+
+// checkVelocity checks the transfer of amount just posted from the account against the limits of the tier
+// of its owner in the currency of the account. It must run after the account is updated: the row lock
+// held until the commit makes the concurrent transfers from the account wait, so they see this transfer
+// in their totals and can't jointly exceed a limit.
+// A tier without limits in the currency is rejected, so a newly added currency or tier has no transfers
+// until its limits are set. A null cap of a configured limit is no limit.
+func checkVelocity(ctx context.Context, q *Queries, account Account, amount int64) error {
+	limit, err := q.GetAccountVelocityLimit(ctx, account.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &VelocityError{Reason: VelocityReasonNotConfigured, Currency: account.Currency}
+		}
+		return err
+	}
+
+	if limit.MaxAmount.Valid && amount > limit.MaxAmount.Int64 {
+		return &VelocityError{
+			Reason:    VelocityReasonMaxAmount,
+			Currency:  limit.Currency,
+			Limit:     limit.MaxAmount.Int64,
+			Remaining: limit.MaxAmount.Int64,
+		}
+	}
+
+	// the totals include the transfer itself
+	velocity, err := q.GetTransferVelocity(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+
+	if limit.MaxDailyAmount.Valid && velocity.DailyAmount > limit.MaxDailyAmount.Int64 {
+		return &VelocityError{
+			Reason:    VelocityReasonMaxDailyAmount,
+			Currency:  limit.Currency,
+			Limit:     limit.MaxDailyAmount.Int64,
+			Remaining: max(limit.MaxDailyAmount.Int64-(velocity.DailyAmount-amount), 0),
+		}
+	}
+	if limit.MaxHourlyCount.Valid && velocity.HourlyCount > int64(limit.MaxHourlyCount.Int32) {
+		return &VelocityError{
+			Reason:    VelocityReasonMaxHourlyCount,
+			Currency:  limit.Currency,
+			Limit:     int64(limit.MaxHourlyCount.Int32),
+			Remaining: max(int64(limit.MaxHourlyCount.Int32)-(velocity.HourlyCount-1), 0),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: velocity_limit.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+const deleteVelocityLimit = `-- name: DeleteVelocityLimit :one
+DELETE FROM velocity_limits
+WHERE tier = $1 AND currency = $2
+RETURNING tier, currency, max_amount, max_daily_amount, max_hourly_count, updated_at, created_at
+`
+
+type DeleteVelocityLimitParams struct {
+	Tier     string `json:"tier"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) DeleteVelocityLimit(ctx context.Context, arg DeleteVelocityLimitParams) (VelocityLimit, error) {
+	row := q.queryRow(ctx, q.deleteVelocityLimitStmt, deleteVelocityLimit, arg.Tier, arg.Currency)
+	var i VelocityLimit
+	err := row.Scan(
+		&i.Tier,
+		&i.Currency,
+		&i.MaxAmount,
+		&i.MaxDailyAmount,
+		&i.MaxHourlyCount,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAccountVelocityLimit = `-- name: GetAccountVelocityLimit :one
+SELECT velocity_limits.tier, velocity_limits.currency, velocity_limits.max_amount, velocity_limits.max_daily_amount, velocity_limits.max_hourly_count, velocity_limits.updated_at, velocity_limits.created_at FROM velocity_limits
+JOIN users ON users.tier = velocity_limits.tier
+JOIN accounts ON accounts.owner = users.username AND accounts.currency = velocity_limits.currency
+WHERE accounts.id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetAccountVelocityLimit(ctx context.Context, id int64) (VelocityLimit, error) {
+	row := q.queryRow(ctx, q.getAccountVelocityLimitStmt, getAccountVelocityLimit, id)
+	var i VelocityLimit
+	err := row.Scan(
+		&i.Tier,
+		&i.Currency,
+		&i.MaxAmount,
+		&i.MaxDailyAmount,
+		&i.MaxHourlyCount,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTransferVelocity = `-- name: GetTransferVelocity :one
+SELECT
+    COALESCE(SUM(amount), 0)::bigint AS daily_amount,
+    COUNT(*) FILTER (WHERE created_at > now() - interval '1 hour') AS hourly_count
+FROM transfers
+WHERE from_account_id = $1
+  AND reversal_of IS NULL
+  AND created_at > now() - interval '1 day'
+`
+
+type GetTransferVelocityRow struct {
+	DailyAmount int64 `json:"daily_amount"`
+	HourlyCount int64 `json:"hourly_count"`
+}
+
+func (q *Queries) GetTransferVelocity(ctx context.Context, fromAccountID int64) (GetTransferVelocityRow, error) {
+	row := q.queryRow(ctx, q.getTransferVelocityStmt, getTransferVelocity, fromAccountID)
+	var i GetTransferVelocityRow
+	err := row.Scan(&i.DailyAmount, &i.HourlyCount)
+	return i, err
+}
+
+const listVelocityLimits = `-- name: ListVelocityLimits :many
+SELECT tier, currency, max_amount, max_daily_amount, max_hourly_count, updated_at, created_at FROM velocity_limits
+ORDER BY tier, currency
+`
+
+func (q *Queries) ListVelocityLimits(ctx context.Context) ([]VelocityLimit, error) {
+	rows, err := q.query(ctx, q.listVelocityLimitsStmt, listVelocityLimits)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []VelocityLimit{}
+	for rows.Next() {
+		var i VelocityLimit
+		if err := rows.Scan(
+			&i.Tier,
+			&i.Currency,
+			&i.MaxAmount,
+			&i.MaxDailyAmount,
+			&i.MaxHourlyCount,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertVelocityLimit = `-- name: UpsertVelocityLimit :one
+INSERT INTO velocity_limits (
+    tier,
+    currency,
+    max_amount,
+    max_daily_amount,
+    max_hourly_count
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (tier, currency) DO UPDATE
+SET max_amount = EXCLUDED.max_amount,
+    max_daily_amount = EXCLUDED.max_daily_amount,
+    max_hourly_count = EXCLUDED.max_hourly_count,
+    updated_at = now()
+RETURNING tier, currency, max_amount, max_daily_amount, max_hourly_count, updated_at, created_at
+`
+
+type UpsertVelocityLimitParams struct {
+	Tier           string        `json:"tier"`
+	Currency       string        `json:"currency"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	MaxDailyAmount sql.NullInt64 `json:"max_daily_amount"`
+	MaxHourlyCount sql.NullInt32 `json:"max_hourly_count"`
+}
+
+func (q *Queries) UpsertVelocityLimit(ctx context.Context, arg UpsertVelocityLimitParams) (VelocityLimit, error) {
+	row := q.queryRow(ctx, q.upsertVelocityLimitStmt, upsertVelocityLimit,
+		arg.Tier,
+		arg.Currency,
+		arg.MaxAmount,
+		arg.MaxDailyAmount,
+		arg.MaxHourlyCount,
+	)
+	var i VelocityLimit
+	err := row.Scan(
+		&i.Tier,
+		&i.Currency,
+		&i.MaxAmount,
+		&i.MaxDailyAmount,
+		&i.MaxHourlyCount,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/stretchr/testify/require"
+)
+
+// setVelocityLimit moves the owner of the account to a new tier with the limit in the currency of the account
+func setVelocityLimit(t *testing.T, account Account, maxAmount int64, maxDailyAmount int64, maxHourlyCount int32) {
+	tier := util.RandomString(8)
+	_, err := testQueries.UpsertVelocityLimit(context.Background(), UpsertVelocityLimitParams{
+		Tier:           tier,
+		Currency:       account.Currency,
+		MaxAmount:      sql.NullInt64{Int64: maxAmount, Valid: maxAmount > 0},
+		MaxDailyAmount: sql.NullInt64{Int64: maxDailyAmount, Valid: maxDailyAmount > 0},
+		MaxHourlyCount: sql.NullInt32{Int32: maxHourlyCount, Valid: maxHourlyCount > 0},
+	})
+	require.NoError(t, err)
+
+	user, err := testQueries.UpdateUserTier(context.Background(), UpdateUserTierParams{Username: account.Owner, Tier: tier})
+	require.NoError(t, err)
+	require.Equal(t, tier, user.Tier)
+}
+
+func TestTransferTxVelocityLimits(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 1000)
+	account2 := createFundedAccount(t, 1000)
+	setVelocityLimit(t, account1, 100, 250, 0)
+
+	transfer := func(amount int64) error {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountId: account1.ID,
+			ToAccountId:   account2.ID,
+			Amount:        amount,
+		})
+		return err
+	}
+
+	// 1. a single transfer can't exceed the max amount
+	err := transfer(101)
+	var velocityErr *VelocityError
+	require.ErrorAs(t, err, &velocityErr)
+	require.Equal(t, VelocityReasonMaxAmount, velocityErr.Reason)
+	require.Equal(t, account1.Currency, velocityErr.Currency)
+	require.Equal(t, int64(100), velocityErr.Remaining)
+
+	// 2. the daily total counts the previous transfers and reports what is left of it
+	require.NoError(t, transfer(100))
+	require.NoError(t, transfer(100))
+	err = transfer(60)
+	require.ErrorAs(t, err, &velocityErr)
+	require.Equal(t, VelocityReasonMaxDailyAmount, velocityErr.Reason)
+	require.Equal(t, int64(250), velocityErr.Limit)
+	require.Equal(t, int64(50), velocityErr.Remaining)
+	require.NoError(t, transfer(50))
+
+	// 3. the rejected transfers were rolled back
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(750), account.Balance)
+}
+
+func TestTransferTxVelocityNotConfigured(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 1000)
+	account2 := createFundedAccount(t, 1000)
+
+	// a tier without limits in the currency of the account can't transfer
+	_, err := testQueries.UpdateUserTier(context.Background(), UpdateUserTierParams{Username: account1.Owner, Tier: util.RandomString(8)})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 10})
+	var velocityErr *VelocityError
+	require.ErrorAs(t, err, &velocityErr)
+	require.Equal(t, VelocityReasonNotConfigured, velocityErr.Reason)
+	require.Equal(t, account1.Currency, velocityErr.Currency)
+}
+
+func TestCaptureHoldTxVelocityLimits(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 1000)
+	account2 := createFundedAccount(t, 1000)
+	setVelocityLimit(t, account1, 0, 100, 0)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 70})
+	require.NoError(t, err)
+
+	// 1. the captured amount counts towards the daily total of the from account
+	hold := authorizeRandomHold(t, store, account1, account2, 50, time.Now().Add(time.Hour))
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 40})
+	var velocityErr *VelocityError
+	require.ErrorAs(t, err, &velocityErr)
+	require.Equal(t, VelocityReasonMaxDailyAmount, velocityErr.Reason)
+	require.Equal(t, int64(30), velocityErr.Remaining)
+
+	// 2. the rejected capture left the hold as it was
+	rejected, err := testQueries.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusAuthorized, rejected.Status)
+	require.Zero(t, rejected.CapturedAmount)
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 30, Final: true})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusCaptured, result.Hold.Status)
+	require.Equal(t, int64(900), result.FromAccount.Balance)
+}
+
+func TestTransferTxHourlyCount(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 1000)
+	account2 := createFundedAccount(t, 1000)
+	setVelocityLimit(t, account1, 0, 0, 2)
+
+	for i := 0; i < 2; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 10})
+		require.NoError(t, err)
+	}
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 10})
+	var velocityErr *VelocityError
+	require.ErrorAs(t, err, &velocityErr)
+	require.Equal(t, VelocityReasonMaxHourlyCount, velocityErr.Reason)
+	require.Zero(t, velocityErr.Remaining)
+
+	// the transfers to the account don't count
+	_, err = store.TransferTx(context.Background(), TransferTxParams{FromAccountId: account2.ID, ToAccountId: account1.ID, Amount: 10})
+	require.NoError(t, err)
+}
+
+func TestTransferTxVelocityConcurrency(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFundedAccount(t, 1000)
+	account2 := createFundedAccount(t, 0)
+	setVelocityLimit(t, account1, 0, 200, 0)
+
+	// 1. concurrent transfers can't jointly exceed the daily total
+	n := 30
+	amount := int64(10)
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		var velocityErr *VelocityError
+		require.ErrorAs(t, err, &velocityErr)
+		require.Equal(t, VelocityReasonMaxDailyAmount, velocityErr.Reason)
+	}
+	require.Equal(t, 20, succeeded)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(800), updatedAccount1.Balance)
+}
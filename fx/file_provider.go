@@ -0,0 +1,61 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// rateFile is the format of the exchange rates file
+//
+//	{"as_of": "2025-01-01T00:00:00Z", "rates": [{"base": "USD", "quote": "INR", "rate": "83.12"}]}
+type rateFile struct {
+	AsOf  time.Time `json:"as_of"`
+	Rates []struct {
+		Base  string `json:"base"`
+		Quote string `json:"quote"`
+		Rate  string `json:"rate"`
+	} `json:"rates"`
+}
+
+// FileRateProvider serves exchange rates loaded from a JSON file.
+type FileRateProvider struct {
+	asOf  time.Time
+	rates map[string]string
+}
+
+// NewFileRateProvider loads the exchange rates from the file
+func NewFileRateProvider(path string) (RateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rates file: %v", err)
+	}
+
+	var file rateFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse rates file: %v", err)
+	}
+
+	provider := &FileRateProvider{
+		asOf:  file.AsOf,
+		rates: make(map[string]string, len(file.Rates)),
+	}
+	for _, rate := range file.Rates {
+		if _, err := ParseRate(rate.Rate); err != nil {
+			return nil, err
+		}
+		provider.rates[rate.Base+"/"+rate.Quote] = rate.Rate
+	}
+	return provider, nil
+}
+
+// GetRate returns the rate to convert an amount in base currency to quote currency
+func (p *FileRateProvider) GetRate(ctx context.Context, base string, quote string) (Rate, error) {
+	return lookupRate(base, quote, func(base, quote string) (string, time.Time, bool) {
+		value, ok := p.rates[base+"/"+quote]
+		return value, p.asOf, ok
+	})
+}
@@ -0,0 +1,24 @@
+package fx
+
+import (
+	"fmt"
+
+	"github.com/akshay237/backend-with-go/util"
+)
+
+// Supported rate providers
+const (
+	ProviderDB   = "db"
+	ProviderFile = "file"
+)
+
+// NewRateProvider creates the rate provider selected in the config, the database is used by default
+func NewRateProvider(config util.Config, store RateStore) (RateProvider, error) {
+	switch config.RateProvider {
+	case "", ProviderDB:
+		return NewStoreRateProvider(store), nil
+	case ProviderFile:
+		return NewFileRateProvider(config.RatesFilePath)
+	}
+	return nil, fmt.Errorf("unsupported rate provider %q", config.RateProvider)
+}
@@ -0,0 +1,100 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const rateDecimals = 10
+
+var (
+	ErrRateNotFound             = errors.New("exchange rate not found")
+	ErrConvertedAmountTooSmall  = errors.New("converted amount must be positive, the amount is too small for the exchange rate")
+	ErrConvertedAmountOverflows = errors.New("converted amount is too large")
+)
+
+// Rate is the price of one unit of the base currency in the quote currency.
+type Rate struct {
+	Base  string
+	Quote string
+	Value *big.Rat
+	AsOf  time.Time
+}
+
+// RateProvider provides the exchange rates used to convert cross currency transfers.
+type RateProvider interface {
+	// GetRate returns the rate to convert an amount in base currency to quote currency
+	GetRate(ctx context.Context, base string, quote string) (Rate, error)
+}
+
+// ParseRate parses a decimal rate like "83.1250" and checks it is positive
+func ParseRate(value string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid exchange rate %q", value)
+	}
+	if rate.Sign() <= 0 {
+		return nil, fmt.Errorf("exchange rate must be positive, got %q", value)
+	}
+	return rate, nil
+}
+
+// String returns the rate as a decimal string with the precision stored in the database
+func (r Rate) String() string {
+	return r.Value.FloatString(rateDecimals)
+}
+
+// Convert converts an amount of the base currency into the quote currency at the rate stored in the database,
+// so the converted amount can be recomputed from the stored rate. The rate is the price of a major unit while
+// the amounts are in minor units, so the amount is also scaled by the difference of the currency exponents.
+// The result is rounded down so a transfer never credits more than the debited amount is worth, it fails if
+// nothing would be credited or if it doesn't fit an int64.
+func (r Rate) Convert(amount int64, baseExponent int16, quoteExponent int16) (int64, error) {
+	stored, _ := new(big.Rat).SetString(r.String())
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), stored)
+
+	// e.g. 1 USD (100 cents) at 150 JPY is 150 yen, not 15000
+	exponent := int64(quoteExponent) - int64(baseExponent)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(max(exponent, -exponent)), nil))
+	if exponent < 0 {
+		scale.Inv(scale)
+	}
+	converted.Mul(converted, scale)
+
+	quotient := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !quotient.IsInt64() {
+		return 0, ErrConvertedAmountOverflows
+	}
+	if quotient.Sign() <= 0 {
+		return 0, ErrConvertedAmountTooSmall
+	}
+	return quotient.Int64(), nil
+}
+
+// lookupRate finds the rate b/w two currencies using find, falling back to the inverse of the opposite rate
+func lookupRate(base string, quote string, find func(base, quote string) (string, time.Time, bool)) (Rate, error) {
+	if base == quote {
+		return Rate{Base: base, Quote: quote, Value: big.NewRat(1, 1), AsOf: time.Now()}, nil
+	}
+
+	if value, asOf, ok := find(base, quote); ok {
+		rate, err := ParseRate(value)
+		if err != nil {
+			return Rate{}, err
+		}
+		return Rate{Base: base, Quote: quote, Value: rate, AsOf: asOf}, nil
+	}
+
+	if value, asOf, ok := find(quote, base); ok {
+		rate, err := ParseRate(value)
+		if err != nil {
+			return Rate{}, err
+		}
+		return Rate{Base: base, Quote: quote, Value: rate.Inv(rate), AsOf: asOf}, nil
+	}
+
+	return Rate{}, fmt.Errorf("%w for %s/%s", ErrRateNotFound, base, quote)
+}
@@ -0,0 +1,86 @@
+package fx
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateConvert(t *testing.T) {
+	value, err := ParseRate("83.125")
+	require.NoError(t, err)
+
+	rate := Rate{Base: "USD", Quote: "INR", Value: value}
+	requireConverted(t, rate, 100, 8312)
+	require.Equal(t, "83.1250000000", rate.String())
+
+	// nothing would be credited for an amount worth less than a minor unit
+	_, err = rate.Convert(0, 2, 2)
+	require.ErrorIs(t, err, ErrConvertedAmountTooSmall)
+	_, err = Rate{Value: new(big.Rat).Inv(value)}.Convert(83, 2, 2)
+	require.ErrorIs(t, err, ErrConvertedAmountTooSmall)
+
+	// the amount doesn't wrap around when it doesn't fit an int64
+	_, err = rate.Convert(math.MaxInt64, 2, 2)
+	require.ErrorIs(t, err, ErrConvertedAmountOverflows)
+
+	// the amount is converted at the rate stored in the database, not at the exact inverse
+	inverse := Rate{Value: big.NewRat(1, 3)}
+	require.Equal(t, "0.3333333333", inverse.String())
+	requireConverted(t, inverse, 30000000000, 9999999999)
+
+	// the amounts are in minor units, the rate is scaled by the difference of the exponents
+	jpy := Rate{Base: "USD", Quote: "JPY", Value: big.NewRat(150, 1)}
+	converted, err := jpy.Convert(100, 2, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(150), converted)
+
+	kwd := Rate{Base: "JPY", Quote: "KWD", Value: big.NewRat(2, 1000)}
+	converted, err = kwd.Convert(1500, 0, 3)
+	require.NoError(t, err)
+	require.Equal(t, int64(3000), converted)
+
+	_, err = Rate{Base: "JPY", Quote: "USD", Value: big.NewRat(1, 150)}.Convert(1, 0, 2)
+	require.ErrorIs(t, err, ErrConvertedAmountTooSmall)
+
+	_, err = ParseRate("-1")
+	require.Error(t, err)
+	_, err = ParseRate("abc")
+	require.Error(t, err)
+}
+
+func requireConverted(t *testing.T, rate Rate, amount int64, expected int64) {
+	converted, err := rate.Convert(amount, 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, expected, converted)
+}
+
+func TestFileRateProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{"as_of": "2025-01-01T00:00:00Z", "rates": [{"base": "USD", "quote": "INR", "rate": "80"}]}`), 0o600)
+	require.NoError(t, err)
+
+	provider, err := NewFileRateProvider(path)
+	require.NoError(t, err)
+
+	rate, err := provider.GetRate(context.Background(), "USD", "INR")
+	require.NoError(t, err)
+	requireConverted(t, rate, 100, 8000)
+
+	// the inverse rate is used when only the opposite pair is known
+	rate, err = provider.GetRate(context.Background(), "INR", "USD")
+	require.NoError(t, err)
+	requireConverted(t, rate, 80, 1)
+
+	rate, err = provider.GetRate(context.Background(), "EUR", "EUR")
+	require.NoError(t, err)
+	requireConverted(t, rate, 100, 100)
+
+	_, err = provider.GetRate(context.Background(), "EUR", "INR")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
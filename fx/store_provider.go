@@ -0,0 +1,50 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+)
+
+// RateStore is the part of the database store used to read exchange rates.
+type RateStore interface {
+	GetExchangeRate(ctx context.Context, arg db.GetExchangeRateParams) (db.ExchangeRate, error)
+}
+
+// StoreRateProvider serves exchange rates from the exchange_rates table.
+type StoreRateProvider struct {
+	store RateStore
+}
+
+// NewStoreRateProvider creates a rate provider backed by the database
+func NewStoreRateProvider(store RateStore) RateProvider {
+	return &StoreRateProvider{store: store}
+}
+
+// GetRate returns the rate to convert an amount in base currency to quote currency
+func (p *StoreRateProvider) GetRate(ctx context.Context, base string, quote string) (Rate, error) {
+	var lookupErr error
+	rate, err := lookupRate(base, quote, func(base, quote string) (string, time.Time, bool) {
+		if lookupErr != nil {
+			return "", time.Time{}, false
+		}
+		exchangeRate, err := p.store.GetExchangeRate(ctx, db.GetExchangeRateParams{
+			BaseCurrency:  base,
+			QuoteCurrency: quote,
+		})
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				lookupErr = err
+			}
+			return "", time.Time{}, false
+		}
+		return exchangeRate.Rate, exchangeRate.UpdatedAt, true
+	})
+	if lookupErr != nil {
+		return Rate{}, lookupErr
+	}
+	return rate, err
+}
@@ -0,0 +1,90 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/akshay237/backend-with-go/apikey"
+	"github.com/akshay237/backend-with-go/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "authorization"
+	authorizationBearer = "bearer"
+	authorizationAPIKey = "apikey"
+)
+
+// authPayloadKey is the context key of the payload of a verified access token
+type authPayloadKey struct{}
+
+// authorizeUser verifies the bearer token or the api key sent in the incoming metadata and returns its payload
+func (s *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "missing metadata")
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "authorization header is not provided")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid format of authorization header")
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case authorizationBearer:
+		payload, err := s.tokenMaker.VerifyToken(fields[1])
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid access token: %s", err)
+		}
+
+		// a refresh token only renews its session, it can't be used as an access token
+		if err := payload.CheckType(token.TokenTypeAccess); err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "invalid access token: %s", err)
+		}
+
+		if s.revocations.IsRevoked(payload.ID) {
+			return nil, status.Errorf(codes.Unauthenticated, "access token is revoked")
+		}
+
+		if s.revocations.IssuedBeforePasswordChange(payload) {
+			return nil, status.Errorf(codes.Unauthenticated, "access token was issued before the password changed")
+		}
+		return payload, nil
+	case authorizationAPIKey:
+		payload, err := s.apiKeys.Authenticate(ctx, fields[1], s.extractMetadata(ctx).ClientIP)
+		if err != nil {
+			if errors.Is(err, apikey.ErrIPNotAllowed) {
+				return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+			}
+			if errors.Is(err, apikey.ErrInvalidKey) {
+				return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+			}
+			return nil, status.Errorf(codes.Internal, "failed to check the api key: %s", err)
+		}
+		return payload, nil
+	default:
+		return nil, status.Errorf(codes.Unauthenticated, "unsupported authorization type %s", fields[0])
+	}
+}
+
+// contextWithAuthPayload returns a copy of ctx which carries the token payload
+func contextWithAuthPayload(ctx context.Context, payload *token.Payload) context.Context {
+	return context.WithValue(ctx, authPayloadKey{}, payload)
+}
+
+// getAuthPayload returns the token payload put into the context by the auth interceptor
+func getAuthPayload(ctx context.Context) (*token.Payload, error) {
+	payload, ok := ctx.Value(authPayloadKey{}).(*token.Payload)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "missing authorization payload")
+	}
+	return payload, nil
+}
@@ -1,8 +1,12 @@
 package gapi
 
 import (
+	"github.com/akshay237/backend-with-go/currency"
 	db "github.com/akshay237/backend-with-go/database/sqlc"
 	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/schedule"
+	"github.com/akshay237/backend-with-go/statement"
+	"github.com/google/uuid"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -13,5 +17,192 @@ func convertUser(user db.User) *pb.User {
 		Email:             user.Email,
 		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
 		CreatedAt:         timestamppb.New(user.CreatedAt),
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
+	}
+}
+
+// convertSession leaves out the refresh token of the session
+func convertSession(session db.Session) *pb.Session {
+	return &pb.Session{
+		Id:        session.ID.String(),
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+		CreatedAt: timestamppb.New(session.CreatedAt),
+	}
+}
+
+// convertAPIKey leaves out the hash of the key
+func convertAPIKey(apiKey db.ApiKey) *pb.ApiKey {
+	response := &pb.ApiKey{
+		Id:         apiKey.ID.String(),
+		Username:   apiKey.Username,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		AllowedIps: apiKey.AllowedIps,
+		LastUsedIp: apiKey.LastUsedIp.String,
+		CreatedAt:  timestamppb.New(apiKey.CreatedAt),
+	}
+	if apiKey.ExpiresAt.Valid {
+		response.ExpiresAt = timestamppb.New(apiKey.ExpiresAt.Time)
+	}
+	if apiKey.LastUsedAt.Valid {
+		response.LastUsedAt = timestamppb.New(apiKey.LastUsedAt.Time)
+	}
+	return response
+}
+
+func convertAccount(account db.Account, currencies *currency.Registry) *pb.Account {
+	return &pb.Account{
+		Id:                        account.ID,
+		Owner:                     account.Owner,
+		Balance:                   account.Balance,
+		Currency:                  account.Currency,
+		CreatedAt:                 timestamppb.New(account.CreatedAt),
+		FormattedBalance:          currencies.Format(account.Balance, account.Currency),
+		AvailableBalance:          account.AvailableBalance,
+		FormattedAvailableBalance: currencies.Format(account.AvailableBalance, account.Currency),
+		OverdraftLimit:            account.OverdraftLimit,
+	}
+}
+
+// convertHold formats the amounts in the currency of the accounts, holds are not cross currency
+func convertHold(hold db.Hold, accountCurrency string, currencies *currency.Registry) *pb.Hold {
+	return &pb.Hold{
+		Id:                      hold.ID,
+		FromAccountId:           hold.FromAccountID,
+		ToAccountId:             hold.ToAccountID,
+		Amount:                  hold.Amount,
+		CapturedAmount:          hold.CapturedAmount,
+		Status:                  hold.Status,
+		ExpiresAt:               timestamppb.New(hold.ExpiresAt),
+		UpdatedAt:               timestamppb.New(hold.UpdatedAt),
+		CreatedAt:               timestamppb.New(hold.CreatedAt),
+		FormattedAmount:         currencies.Format(hold.Amount, accountCurrency),
+		FormattedCapturedAmount: currencies.Format(hold.CapturedAmount, accountCurrency),
+	}
+}
+
+// convertTransfer formats the amount in the from account currency and the to amount in the to account currency
+func convertTransfer(transfer db.Transfer, fromCurrency string, toCurrency string, currencies *currency.Registry) *pb.Transfer {
+	return &pb.Transfer{
+		Id:                      transfer.ID,
+		FromAccountId:           transfer.FromAccountID,
+		ToAccountId:             transfer.ToAccountID,
+		Amount:                  transfer.Amount,
+		CreatedAt:               timestamppb.New(transfer.CreatedAt.Time),
+		ToAmount:                transfer.ToAmount,
+		ExchangeRate:            transfer.ExchangeRate,
+		QuoteId:                 convertNullUUID(transfer.QuoteID),
+		FormattedAmount:         currencies.Format(transfer.Amount, fromCurrency),
+		FormattedToAmount:       currencies.Format(transfer.ToAmount, toCurrency),
+		HoldId:                  transfer.HoldID.Int64,
+		Status:                  transfer.Status,
+		ReversedAmount:          transfer.ReversedAmount,
+		ReversalOf:              transfer.ReversalOf.Int64,
+		FormattedReversedAmount: currencies.Format(transfer.ReversedAmount, fromCurrency),
+	}
+}
+
+func convertTransferQuote(quote db.TransferQuote, currencies *currency.Registry) *pb.TransferQuote {
+	return &pb.TransferQuote{
+		Id:                  quote.ID.String(),
+		FromAccountId:       quote.FromAccountID,
+		ToAccountId:         quote.ToAccountID,
+		FromCurrency:        quote.FromCurrency,
+		ToCurrency:          quote.ToCurrency,
+		Rate:                quote.Rate,
+		FromAmount:          quote.FromAmount,
+		ToAmount:            quote.ToAmount,
+		ExpiresAt:           timestamppb.New(quote.ExpiresAt),
+		FormattedFromAmount: currencies.Format(quote.FromAmount, quote.FromCurrency),
+		FormattedToAmount:   currencies.Format(quote.ToAmount, quote.ToCurrency),
+	}
+}
+
+// convertScheduledTransfer formats the amount in the currency of the schedule and adds its rule in the RRULE syntax
+func convertScheduledTransfer(scheduled db.ScheduledTransfer, currencies *currency.Registry) *pb.ScheduledTransfer {
+	response := &pb.ScheduledTransfer{
+		Id:              scheduled.ID,
+		Owner:           scheduled.Owner,
+		FromAccountId:   scheduled.FromAccountID,
+		ToAccountId:     scheduled.ToAccountID,
+		Amount:          scheduled.Amount,
+		Currency:        scheduled.Currency,
+		Frequency:       scheduled.Frequency,
+		Interval:        scheduled.Interval,
+		DayOfMonth:      scheduled.DayOfMonth.Int32,
+		StartAt:         timestamppb.New(scheduled.StartAt),
+		MaxOccurrences:  scheduled.MaxOccurrences.Int32,
+		Occurrences:     scheduled.Occurrences,
+		NextRunAt:       timestamppb.New(scheduled.NextRunAt),
+		Attempts:        scheduled.Attempts,
+		Status:          scheduled.Status,
+		Rule:            schedule.RuleOf(scheduled).String(),
+		FormattedAmount: currencies.Format(scheduled.Amount, scheduled.Currency),
+		CreatedAt:       timestamppb.New(scheduled.CreatedAt),
+	}
+	if scheduled.EndAt.Valid {
+		response.EndAt = timestamppb.New(scheduled.EndAt.Time)
+	}
+	return response
+}
+
+func convertScheduledTransferRun(run db.ScheduledTransferRun) *pb.ScheduledTransferRun {
+	return &pb.ScheduledTransferRun{
+		Id:                  run.ID,
+		ScheduledTransferId: run.ScheduledTransferID,
+		OccurrenceAt:        timestamppb.New(run.OccurrenceAt),
+		Attempt:             run.Attempt,
+		Status:              run.Status,
+		TransferId:          run.TransferID.Int64,
+		Error:               run.Error,
+		CreatedAt:           timestamppb.New(run.CreatedAt),
+	}
+}
+
+func convertNullUUID(id uuid.NullUUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return id.UUID.String()
+}
+
+// convertEntry formats the amount in the currency of the account the entry belongs to
+func convertEntry(entry db.Entry, accountCurrency string, currencies *currency.Registry) *pb.Entry {
+	return &pb.Entry{
+		Id:              entry.ID,
+		AccountId:       entry.AccountID,
+		Amount:          entry.Amount,
+		TransferId:      entry.TransferID.Int64,
+		CreatedAt:       timestamppb.New(entry.CreatedAt),
+		FormattedAmount: currencies.Format(entry.Amount, accountCurrency),
+	}
+}
+
+func convertCurrency(currency currency.Currency) *pb.Currency {
+	return &pb.Currency{
+		Code:        currency.Code,
+		NumericCode: currency.NumericCode,
+		Exponent:    int32(currency.Exponent),
+		Symbol:      currency.Symbol,
+	}
+}
+
+// convertStatementLine formats the amounts in the currency of the statement
+func convertStatementLine(line statement.Line, st statement.Statement) *pb.StatementLine {
+	return &pb.StatementLine{
+		EntryId:                 line.ID,
+		TransferId:              line.TransferID.Int64,
+		ReversalOf:              line.ReversalOf.Int64,
+		CounterpartyAccountId:   line.CounterpartyAccountID.Int64,
+		CounterpartyOwner:       line.CounterpartyOwner.String,
+		Amount:                  line.Amount,
+		FormattedAmount:         st.Format(line.Amount),
+		RunningBalance:          line.RunningBalance,
+		FormattedRunningBalance: st.Format(line.RunningBalance),
+		CreatedAt:               timestamppb.New(line.CreatedAt),
 	}
 }
@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/textproto"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// gatewayHeaders are the HTTP headers which are forwarded to gRPC as metadata and back
+var gatewayHeaders = map[string]string{
+	textproto.CanonicalMIMEHeaderKey(idempotencyKeyHeader):     idempotencyKeyHeader,
+	textproto.CanonicalMIMEHeaderKey(idempotentReplayedHeader): idempotentReplayedHeader,
+	textproto.CanonicalMIMEHeaderKey(retryAfterHeader):         retryAfterHeader,
+}
+
+// NewGatewayHandler creates the HTTP/JSON gateway which translates the requests into calls to the gRPC server.
+// The calls go through the gRPC server so they pass through the same interceptors as native gRPC clients.
+func NewGatewayHandler(ctx context.Context, config util.Config) (http.Handler, error) {
+	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			UseProtoNames:   true,
+			EmitUnpopulated: true,
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			DiscardUnknown: true,
+		},
+	})
+
+	grpcMux := runtime.NewServeMux(
+		jsonOption,
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+	)
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	err := pb.RegisterSimpleBankHandlerFromEndpoint(ctx, grpcMux, config.GRPCServerAddress, opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot register gateway handler: %v", err)
+	}
+
+	return grpcMux, nil
+}
+
+// incomingHeaderMatcher forwards the gateway headers along with the default ones like authorization
+func incomingHeaderMatcher(key string) (string, bool) {
+	if header, ok := gatewayHeaders[textproto.CanonicalMIMEHeaderKey(key)]; ok {
+		return header, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// outgoingHeaderMatcher returns the gateway headers set by the gRPC handlers without the metadata prefix
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if _, ok := gatewayHeaders[textproto.CanonicalMIMEHeaderKey(key)]; ok {
+		return textproto.CanonicalMIMEHeaderKey(key), true
+	}
+	return fmt.Sprintf("%s%s", runtime.MetadataHeaderPrefix, key), true
+}
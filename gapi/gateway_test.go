@@ -0,0 +1,26 @@
+package gapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatewayHeaderMatchers(t *testing.T) {
+	// 1. the idempotency key header is forwarded as metadata
+	key, ok := incomingHeaderMatcher("Idempotency-Key")
+	require.True(t, ok)
+	require.Equal(t, idempotencyKeyHeader, key)
+
+	_, ok = incomingHeaderMatcher("X-Custom-Header")
+	require.False(t, ok)
+
+	// 2. the replay header is returned without the metadata prefix
+	key, ok = outgoingHeaderMatcher(idempotentReplayedHeader)
+	require.True(t, ok)
+	require.Equal(t, "Idempotent-Replayed", key)
+
+	key, ok = outgoingHeaderMatcher("custom")
+	require.True(t, ok)
+	require.Equal(t, "Grpc-Metadata-custom", key)
+}
@@ -0,0 +1,171 @@
+package gapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods can be called without an access token
+var publicMethods = map[string]bool{
+	pb.SimpleBank_CreateUser_FullMethodName:           true,
+	pb.SimpleBank_LoginUser_FullMethodName:            true,
+	pb.SimpleBank_VerifyLoginMFA_FullMethodName:       true,
+	pb.SimpleBank_VerifyEmail_FullMethodName:          true,
+	pb.SimpleBank_RequestPasswordReset_FullMethodName: true,
+	pb.SimpleBank_ResetPassword_FullMethodName:        true,
+	pb.SimpleBank_RenewAccessToken_FullMethodName:     true,
+	pb.SimpleBank_LogoutUser_FullMethodName:           true,
+	pb.SimpleBank_ListCurrencies_FullMethodName:       true,
+}
+
+// adminMethods can be called only by users with the admin role
+var adminMethods = map[string]bool{
+	pb.SimpleBank_GetUser_FullMethodName:              true,
+	pb.SimpleBank_UnlockUser_FullMethodName:           true,
+	pb.SimpleBank_UpdateAccount_FullMethodName:        true,
+	pb.SimpleBank_UpdateOverdraftLimit_FullMethodName: true,
+	pb.SimpleBank_DeleteAccount_FullMethodName:        true,
+}
+
+// methodScopes is the scope each protected method requires. A method missing here is denied to the tokens
+// with scopes, the delegation has no scope since the new token can only have fewer rights than the caller.
+var methodScopes = map[string]string{
+	pb.SimpleBank_DelegateAccessToken_FullMethodName:       "",
+	pb.SimpleBank_GetUser_FullMethodName:                   token.ScopeUsersRead,
+	pb.SimpleBank_UnlockUser_FullMethodName:                token.ScopeUsersWrite,
+	pb.SimpleBank_RequestEmailVerification_FullMethodName:  token.ScopeSecurityWrite,
+	pb.SimpleBank_EnrollTOTP_FullMethodName:                token.ScopeSecurityWrite,
+	pb.SimpleBank_ConfirmTOTP_FullMethodName:               token.ScopeSecurityWrite,
+	pb.SimpleBank_DisableTOTP_FullMethodName:               token.ScopeSecurityWrite,
+	pb.SimpleBank_ListSessions_FullMethodName:              token.ScopeSecurityRead,
+	pb.SimpleBank_RevokeSession_FullMethodName:             token.ScopeSecurityWrite,
+	pb.SimpleBank_RevokeAllSessions_FullMethodName:         token.ScopeSecurityWrite,
+	pb.SimpleBank_CreateApiKey_FullMethodName:              token.ScopeSecurityWrite,
+	pb.SimpleBank_ListApiKeys_FullMethodName:               token.ScopeSecurityRead,
+	pb.SimpleBank_RevokeApiKey_FullMethodName:              token.ScopeSecurityWrite,
+	pb.SimpleBank_CreateAccount_FullMethodName:             token.ScopeAccountsWrite,
+	pb.SimpleBank_GetAccount_FullMethodName:                token.ScopeAccountsRead,
+	pb.SimpleBank_ListAccounts_FullMethodName:              token.ScopeAccountsRead,
+	pb.SimpleBank_GetAccountStatement_FullMethodName:       token.ScopeAccountsRead,
+	pb.SimpleBank_UpdateAccount_FullMethodName:             token.ScopeAccountsWrite,
+	pb.SimpleBank_UpdateOverdraftLimit_FullMethodName:      token.ScopeAccountsWrite,
+	pb.SimpleBank_DeleteAccount_FullMethodName:             token.ScopeAccountsWrite,
+	pb.SimpleBank_CreateTransfer_FullMethodName:            token.ScopeTransfersWrite,
+	pb.SimpleBank_CreateTransferQuote_FullMethodName:       token.ScopeTransfersRead,
+	pb.SimpleBank_GetTransfer_FullMethodName:               token.ScopeTransfersRead,
+	pb.SimpleBank_ReverseTransfer_FullMethodName:           token.ScopeTransfersWrite,
+	pb.SimpleBank_CreateScheduledTransfer_FullMethodName:   token.ScopeTransfersWrite,
+	pb.SimpleBank_GetScheduledTransfer_FullMethodName:      token.ScopeTransfersRead,
+	pb.SimpleBank_ListScheduledTransfers_FullMethodName:    token.ScopeTransfersRead,
+	pb.SimpleBank_UpdateScheduledTransfer_FullMethodName:   token.ScopeTransfersWrite,
+	pb.SimpleBank_CancelScheduledTransfer_FullMethodName:   token.ScopeTransfersWrite,
+	pb.SimpleBank_ListScheduledTransferRuns_FullMethodName: token.ScopeTransfersRead,
+	pb.SimpleBank_AuthorizeHold_FullMethodName:             token.ScopeTransfersWrite,
+	pb.SimpleBank_GetHold_FullMethodName:                   token.ScopeTransfersRead,
+	pb.SimpleBank_CaptureHold_FullMethodName:               token.ScopeTransfersWrite,
+	pb.SimpleBank_VoidHold_FullMethodName:                  token.ScopeTransfersWrite,
+}
+
+// isPublicMethod reports whether the method is allowed without an access token.
+// The reflection service is public so that tools like evans can discover the API.
+func isPublicMethod(fullMethod string) bool {
+	return publicMethods[fullMethod] || strings.HasPrefix(fullMethod, "/grpc.reflection.")
+}
+
+// checkRole denies the admin methods to users without the admin role
+func checkRole(fullMethod string, payload *token.Payload) error {
+	if adminMethods[fullMethod] && payload.Role != util.AdminRole {
+		return status.Errorf(codes.PermissionDenied, "role %q is not allowed to call %s", payload.Role, fullMethod)
+	}
+	return nil
+}
+
+// checkScope denies the method to tokens without its scope, a token without scopes is not limited
+func checkScope(fullMethod string, payload *token.Payload) error {
+	if len(payload.Scopes) == 0 {
+		return nil
+	}
+
+	scope, ok := methodScopes[fullMethod]
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "no scope allows to call %s", fullMethod)
+	}
+	if scope != "" && !payload.HasScope(scope) {
+		return status.Errorf(codes.PermissionDenied, "token is missing the %q scope to call %s", scope, fullMethod)
+	}
+	return nil
+}
+
+// UnaryAuthInterceptor verifies the access token of every unary call to a protected method
+// and puts the token payload into the context of the handler.
+func (s *Server) UnaryAuthInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		payload, err := s.authorizeUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkRole(info.FullMethod, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		err = checkScope(info.FullMethod, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(contextWithAuthPayload(ctx, payload), req)
+	}
+}
+
+// StreamAuthInterceptor verifies the access token of every streaming call to a protected method
+// and puts the token payload into the context of the stream.
+func (s *Server) StreamAuthInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublicMethod(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		payload, err := s.authorizeUser(stream.Context())
+		if err != nil {
+			return err
+		}
+
+		err = checkRole(info.FullMethod, payload)
+		if err != nil {
+			return err
+		}
+
+		err = checkScope(info.FullMethod, payload)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authenticatedStream{
+			ServerStream: stream,
+			ctx:          contextWithAuthPayload(stream.Context(), payload),
+		})
+	}
+}
+
+// authenticatedStream overrides the context of a server stream with the one carrying the token payload
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
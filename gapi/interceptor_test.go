@@ -0,0 +1,309 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/apikey"
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryAuthInterceptor(t *testing.T) {
+	testcases := []struct {
+		name          string
+		method        string
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, payload *token.Payload, err error)
+	}{
+		{
+			name:   "OK",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "user", time.Minute)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "user", payload.Username)
+			},
+		},
+		{
+			name:   "PublicMethod",
+			method: pb.SimpleBank_LoginUser_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Nil(t, payload)
+			},
+		},
+		{
+			name:   "No Authorization",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "Unsupported Authorization",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				accessToken, _, err := tokenMaker.CreateToken("user", util.DepositorRole, nil, token.TokenTypeAccess, time.Minute)
+				require.NoError(t, err)
+				md := metadata.Pairs(authorizationHeader, fmt.Sprintf("basic %s", accessToken))
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "Invalid Authorization",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				md := metadata.Pairs(authorizationHeader, "bearer")
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "AdminMethod",
+			method: pb.SimpleBank_DeleteAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithRoleToken(t, tokenMaker, "admin", util.AdminRole)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.AdminRole, payload.Role)
+			},
+		},
+		{
+			name:   "AdminMethodForbidden",
+			method: pb.SimpleBank_DeleteAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "user", time.Minute)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name:   "ExpiredToken",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "user", -time.Minute)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "RefreshTokenAsBearer",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				refreshToken, _, err := tokenMaker.CreateToken("user", util.DepositorRole, nil, token.TokenTypeRefresh, time.Minute)
+				require.NoError(t, err)
+				md := metadata.Pairs(authorizationHeader, fmt.Sprintf("%s %s", authorizationBearer, refreshToken))
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+				require.Nil(t, payload)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+			interceptor := server.UnaryAuthInterceptor()
+
+			// the handler returns the payload it got from the context
+			handler := func(ctx context.Context, req any) (any, error) {
+				payload, _ := ctx.Value(authPayloadKey{}).(*token.Payload)
+				return payload, nil
+			}
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			info := &grpc.UnaryServerInfo{FullMethod: tc.method}
+			res, err := interceptor(ctx, nil, info, handler)
+
+			payload, _ := res.(*token.Payload)
+			tc.checkResponse(t, payload, err)
+		})
+	}
+}
+
+func TestUnaryAuthInterceptorRevokedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	interceptor := server.UnaryAuthInterceptor()
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SimpleBank_GetAccount_FullMethodName}
+
+	accessToken, payload, err := server.tokenMaker.CreateToken("user", util.DepositorRole, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	md := metadata.Pairs(authorizationHeader, fmt.Sprintf("bearer %s", accessToken))
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	_, err = interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+	err = server.revocations.Revoke(context.Background(), payload.ID, payload.Username, payload.ExpiredAT)
+	require.NoError(t, err)
+
+	_, err = interceptor(ctx, nil, info, handler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestUnaryAuthInterceptorPasswordChanged(t *testing.T) {
+	server := newTestServer(t, nil)
+	interceptor := server.UnaryAuthInterceptor()
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SimpleBank_GetAccount_FullMethodName}
+
+	accessToken, _, err := server.tokenMaker.CreateToken("user", util.DepositorRole, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	md := metadata.Pairs(authorizationHeader, fmt.Sprintf("bearer %s", accessToken))
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	server.revocations.PasswordChanged("other", time.Now().Add(2*time.Second))
+	_, err = interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+
+	// the token was issued before the password of its user changed
+	server.revocations.PasswordChanged("user", time.Now().Add(2*time.Second))
+	_, err = interceptor(ctx, nil, info, handler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestUnaryAuthInterceptorAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	interceptor := server.UnaryAuthInterceptor()
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return getAuthPayload(ctx)
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SimpleBank_GetAccount_FullMethodName}
+
+	key, err := apikey.GenerateKey()
+	require.NoError(t, err)
+	apiKey := db.ApiKey{ID: uuid.New(), Username: "batch", AllowedIps: []string{"203.0.113.0/24"}}
+
+	// 1. the key is accepted from an allowed address, the gateway forwards the address of the client
+	store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Eq(apikey.HashKey(key))).Times(3).
+		Return(db.GetActiveAPIKeyByHashRow{ApiKey: apiKey, Role: util.ServiceRole}, nil)
+	store.EXPECT().TouchAPIKey(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+	gateway := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 51234}})
+	md := metadata.Pairs(authorizationHeader, fmt.Sprintf("ApiKey %s", key), xForwardedForHeader, "198.51.100.1, 203.0.113.7")
+	res, err := interceptor(metadata.NewIncomingContext(gateway, md), nil, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, apiKey.Username, res.(*token.Payload).Username)
+	require.Equal(t, util.ServiceRole, res.(*token.Payload).Role)
+
+	// 2. and rejected from any other address
+	md = metadata.Pairs(authorizationHeader, fmt.Sprintf("ApiKey %s", key), xForwardedForHeader, "203.0.113.7, 198.51.100.1")
+	_, err = interceptor(metadata.NewIncomingContext(gateway, md), nil, info, handler)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// 3. an address forwarded by a client which is not a trusted proxy is ignored
+	direct := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("198.51.100.1"), Port: 51234}})
+	md = metadata.Pairs(authorizationHeader, fmt.Sprintf("ApiKey %s", key), xForwardedForHeader, "203.0.113.7")
+	_, err = interceptor(metadata.NewIncomingContext(direct, md), nil, info, handler)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// 4. a revoked or expired key is not found
+	store.EXPECT().GetActiveAPIKeyByHash(gomock.Any(), gomock.Any()).Times(1).Return(db.GetActiveAPIKeyByHashRow{}, sql.ErrNoRows)
+	_, err = interceptor(metadata.NewIncomingContext(context.Background(), md), nil, info, handler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestMethodScopes(t *testing.T) {
+	// every protected method declares the scope it requires
+	for _, method := range pb.SimpleBank_ServiceDesc.Methods {
+		fullMethod := fmt.Sprintf("/%s/%s", pb.SimpleBank_ServiceDesc.ServiceName, method.MethodName)
+		if isPublicMethod(fullMethod) {
+			continue
+		}
+
+		scope, ok := methodScopes[fullMethod]
+		require.True(t, ok, "%s has no scope", fullMethod)
+		if scope != "" {
+			require.True(t, token.IsSupportedScope(scope), "%s requires an unknown scope", fullMethod)
+		}
+	}
+}
+
+func TestUnaryAuthInterceptorScopes(t *testing.T) {
+	server := newTestServer(t, nil)
+	interceptor := server.UnaryAuthInterceptor()
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}
+
+	// a read only dashboard token
+	accessToken, _, err := server.tokenMaker.CreateToken("user", util.DepositorRole, []string{token.ScopeAccountsRead}, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	md := metadata.Pairs(authorizationHeader, fmt.Sprintf("%s %s", authorizationBearer, accessToken))
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	// 1. the token can read the accounts
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: pb.SimpleBank_GetAccount_FullMethodName}, handler)
+	require.NoError(t, err)
+
+	// 2. the token can't move money
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: pb.SimpleBank_CreateTransfer_FullMethodName}, handler)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// 3. the delegation has no scope, the new token can only have fewer rights
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: pb.SimpleBank_DelegateAccessToken_FullMethodName}, handler)
+	require.NoError(t, err)
+
+	// 4. a method without a declared scope is denied to scoped tokens
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pb.SimpleBank/Unknown"}, handler)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func newContextWithRoleToken(t *testing.T, tokenMaker token.Maker, username string, role string) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, role, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	md := metadata.Pairs(authorizationHeader, fmt.Sprintf("%s %s", authorizationBearer, accessToken))
+	return metadata.NewIncomingContext(context.Background(), md)
+}
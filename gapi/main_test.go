@@ -0,0 +1,57 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/currency"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/mail"
+	"github.com/akshay237/backend-with-go/revocation"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		TOTPEncryptionKey:   util.RandomString(32),
+		AccessTokenDuration: time.Minute * 5,
+		Mailer:              mail.MailerMemory,
+	}
+
+	server, err := NewServerHandler(config, store, currency.NewRegistry(store), revocation.NewList(store, config))
+	require.NoError(t, err)
+	return server
+}
+
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, duration time.Duration) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, util.DepositorRole, nil, token.TokenTypeAccess, duration)
+	require.NoError(t, err)
+
+	md := metadata.MD{
+		authorizationHeader: []string{fmt.Sprintf("%s %s", authorizationBearer, accessToken)},
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+// newContextWithAuthPayload returns the context a protected handler gets from the auth interceptor
+func newContextWithAuthPayload(t *testing.T, username string) context.Context {
+	payload, err := token.NewPayload(username, util.DepositorRole, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	return contextWithAuthPayload(context.Background(), payload)
+}
+
+func randomAccount(owner string, currency string) db.Account {
+	return db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    owner,
+		Balance:  util.RandomBalance(),
+		Currency: currency,
+	}
+}
@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/akshay237/backend-with-go/util"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	idempotencyKeyHeader     = "idempotency-key"
+	idempotentReplayedHeader = "idempotent-replayed"
+	maxIdempotencyKeyLength  = 255
+	retryAfterHeader         = "retry-after"
+
+	// domain of the ErrorInfo detail of a transfer rejected by a velocity limit
+	velocityLimitErrorDomain = "velocity-limit.simplebank"
+
+	// the gateway forwards the HTTP user agent and client address with these keys
+	grpcGatewayUserAgentHeader = "grpcgateway-user-agent"
+	xForwardedForHeader        = "x-forwarded-for"
+	userAgentHeader            = "user-agent"
+)
+
+// Metadata describes the client of a call, it is stored with the sessions the client creates
+type Metadata struct {
+	UserAgent string
+	ClientIP  string
+}
+
+// extractMetadata reads the client details of a call made directly or through the gateway. The forwarded
+// client address is only used when the peer is a trusted proxy like the gateway, see util.ClientIP.
+func (s *Server) extractMetadata(ctx context.Context) *Metadata {
+	mtdt := &Metadata{}
+
+	var forwardedFor []string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgents := md.Get(grpcGatewayUserAgentHeader); len(userAgents) > 0 {
+			mtdt.UserAgent = userAgents[0]
+		} else if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
+			mtdt.UserAgent = userAgents[0]
+		}
+
+		forwardedFor = md.Get(xForwardedForHeader)
+	}
+
+	var peerAddr string
+	if p, ok := peer.FromContext(ctx); ok {
+		peerAddr = p.Addr.String()
+	}
+	mtdt.ClientIP = util.ClientIP(peerAddr, forwardedFor, s.trustedProxies)
+
+	return mtdt
+}
+
+// idempotencyKeyFromContext returns the idempotency key sent by the client in the incoming metadata
+func idempotencyKeyFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if values := md.Get(idempotencyKeyHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestExtractMetadata(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	// a call through the gateway carries the HTTP client details in the metadata
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 5000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(
+		grpcGatewayUserAgentHeader, "Mozilla/5.0",
+		userAgentHeader, "grpc-go/1.0",
+		xForwardedForHeader, "198.51.100.1, 203.0.113.7",
+	))
+	mtdt := server.extractMetadata(ctx)
+	require.Equal(t, "Mozilla/5.0", mtdt.UserAgent)
+	require.Equal(t, "203.0.113.7", mtdt.ClientIP)
+
+	// a direct call falls back to the gRPC user agent and the peer address, the forwarded address is ignored
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		userAgentHeader, "grpc-go/1.0",
+		xForwardedForHeader, "203.0.113.7",
+	))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 5000}})
+	mtdt = server.extractMetadata(ctx)
+	require.Equal(t, "grpc-go/1.0", mtdt.UserAgent)
+	require.Equal(t, "10.0.0.2", mtdt.ClientIP)
+}
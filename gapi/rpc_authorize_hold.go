@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/hold"
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizeHold reserves funds of the from account of the authenticated user for the to account.
+// Nothing is posted to the ledger until the hold is captured.
+func (s *Server) AuthorizeHold(ctx context.Context, req *pb.AuthorizeHoldRequest) (*pb.AuthorizeHoldResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetFromAccountId() < 1 || req.GetToAccountId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id")
+	}
+	if req.GetAmount() <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must be positive")
+	}
+	if req.GetExpiresIn() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "expires_in must not be negative")
+	}
+	if !s.currencies.IsSupported(req.GetCurrency()) {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported currency: %s", req.GetCurrency())
+	}
+
+	// 3. the from account must belong to the user and both accounts must use the currency of the hold
+	fromAccount, err := s.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+	if _, err = s.validAccount(ctx, req.GetToAccountId(), req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	// 4. reserve the funds
+	result, err := s.store.AuthorizeHoldTx(ctx, db.AuthorizeHoldTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		ExpiresAt:     hold.ExpiresAt(s.config, time.Duration(req.GetExpiresIn())*time.Second),
+	})
+	if err != nil {
+		return nil, holdError(err)
+	}
+
+	response := &pb.AuthorizeHoldResponse{
+		Hold:        convertHold(result.Hold, result.FromAccount.Currency, s.currencies),
+		FromAccount: convertAccount(result.FromAccount, s.currencies),
+	}
+	return response, nil
+}
@@ -0,0 +1,32 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/schedule"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CancelScheduledTransfer stops a schedule of the authenticated user, its runs are kept
+func (s *Server) CancelScheduledTransfer(ctx context.Context, req *pb.CancelScheduledTransferRequest) (*pb.CancelScheduledTransferResponse, error) {
+	if _, err := s.getScheduledTransfer(ctx, req.GetId(), false); err != nil {
+		return nil, err
+	}
+
+	scheduled, err := s.store.CancelScheduledTransfer(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", schedule.ErrNotEditable)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to cancel scheduled transfer: %s", err)
+	}
+
+	response := &pb.CancelScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduled, s.currencies),
+	}
+	return response, nil
+}
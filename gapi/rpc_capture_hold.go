@@ -0,0 +1,55 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CaptureHold posts the captured amount of a hold to the ledger, only the owner of the to account can capture.
+// The amount defaults to what is left on the hold.
+func (s *Server) CaptureHold(ctx context.Context, req *pb.CaptureHoldRequest) (*pb.CaptureHoldResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetAmount() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must not be negative")
+	}
+
+	// 3. the hold is captured by the account it pays
+	_, _, toAccount, err := s.getHold(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if toAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "to account of the hold doesn't belong to the authenticated user")
+	}
+
+	// 4. capture the hold
+	result, err := s.store.CaptureHoldTx(ctx, db.CaptureHoldTxParams{
+		HoldID: req.GetId(),
+		Amount: req.GetAmount(),
+		Final:  req.GetFinal(),
+	})
+	if err != nil {
+		return nil, holdError(err)
+	}
+
+	response := &pb.CaptureHoldResponse{
+		Hold:        convertHold(result.Hold, result.FromAccount.Currency, s.currencies),
+		Transfer:    convertTransfer(result.Transfer, result.FromAccount.Currency, result.ToAccount.Currency, s.currencies),
+		FromAccount: convertAccount(result.FromAccount, s.currencies),
+		ToAccount:   convertAccount(result.ToAccount, s.currencies),
+		FromEntry:   convertEntry(result.FromEntry, result.FromAccount.Currency, s.currencies),
+		ToEntry:     convertEntry(result.ToEntry, result.ToAccount.Currency, s.currencies),
+	}
+	return response, nil
+}
@@ -0,0 +1,36 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConfirmTOTP enables the pending TOTP secret with a code from the authenticator app.
+// The recovery codes are returned only once.
+func (s *Server) ConfirmTOTP(ctx context.Context, req *pb.ConfirmTOTPRequest) (*pb.ConfirmTOTPResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetCode() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "code is required")
+	}
+
+	// 3. enable the secret and create the recovery codes
+	recoveryCodes, err := s.mfa.Confirm(ctx, authPayload.Username, req.GetCode())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	response := &pb.ConfirmTOTPResponse{
+		RecoveryCodes: recoveryCodes,
+	}
+	return response, nil
+}
@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+
+	// 1. get the authenticated user, the account is created for the token owner
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if !s.currencies.IsSupported(req.GetCurrency()) {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported currency: %s", req.GetCurrency())
+	}
+
+	// 3. calls the store account func of database to create an account
+	account, err := s.store.CreateAccount(ctx, db.CreateAccountParams{
+		Owner:    authPayload.Username,
+		Currency: req.GetCurrency(),
+		Balance:  0,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case ForeignKeyConstraint:
+				return nil, status.Errorf(codes.PermissionDenied, "create user before creating account using this owner name [%s]", authPayload.Username)
+			case UniqueKeyConstraint:
+				return nil, status.Errorf(codes.AlreadyExists, "account already exists with this username [%s] and currency [%s]", authPayload.Username, req.GetCurrency())
+			}
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create account: %s", err)
+	}
+
+	// 4. return the account details to the end user
+	response := &pb.CreateAccountResponse{
+		Account: convertAccount(account, s.currencies),
+	}
+	return response, nil
+}
@@ -0,0 +1,99 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/akshay237/backend-with-go/apikey"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateApiKey creates an API key of the authenticated user, admins can create the keys of service accounts.
+// The key is returned only once.
+func (s *Server) CreateApiKey(ctx context.Context, req *pb.CreateApiKeyRequest) (*pb.CreateApiKeyResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetName() == "" || len(req.GetName()) > 64 {
+		return nil, status.Errorf(codes.InvalidArgument, "name must have between 1 and 64 characters")
+	}
+	if len(req.GetScopes()) > 32 || len(req.GetAllowedIps()) > 32 {
+		return nil, status.Errorf(codes.InvalidArgument, "at most 32 scopes and 32 allowed ips are supported")
+	}
+
+	// 2.1 the key can't have scopes the caller doesn't have, a caller limited by scopes passes them on
+	scopes, err := token.NarrowScopes(authPayload.Scopes, req.GetScopes())
+	if err != nil {
+		if errors.Is(err, token.ErrScopeNotGranted) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "invalid scopes: %s", err)
+	}
+
+	arg := apikey.CreateParams{
+		Name:       req.GetName(),
+		Scopes:     scopes,
+		AllowedIPs: req.GetAllowedIps(),
+		Caller:     authPayload,
+	}
+	if req.ExpiresAt != nil {
+		if err := req.GetExpiresAt().CheckValid(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid expires_at: %s", err)
+		}
+		arg.ExpiresAt = req.GetExpiresAt().AsTime()
+		if !arg.ExpiresAt.After(time.Now()) {
+			return nil, status.Errorf(codes.InvalidArgument, "expires_at must be in the future")
+		}
+	}
+
+	// 3. get the owner of the new key
+	arg.Username, err = apiKeyOwner(authPayload, req.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. create the key, only its hash is stored
+	key, apiKey, err := s.apiKeys.Create(ctx, arg)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Name() == ForeignKeyConstraint {
+			return nil, status.Errorf(codes.NotFound, "no user exists with username %s", arg.Username)
+		}
+		if errors.Is(err, apikey.ErrInvalidAllowedIP) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		if errors.Is(err, apikey.ErrWiderThanCaller) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create api key: %s", err)
+	}
+
+	response := &pb.CreateApiKeyResponse{
+		Key:    key,
+		ApiKey: convertAPIKey(apiKey),
+	}
+	return response, nil
+}
+
+// apiKeyOwner returns the user whose keys are managed, only admins manage the keys of other users
+// such as the service accounts
+func apiKeyOwner(authPayload *token.Payload, username string) (string, error) {
+	if username == "" || username == authPayload.Username {
+		return authPayload.Username, nil
+	}
+	if authPayload.Role != util.AdminRole {
+		return "", status.Errorf(codes.PermissionDenied, "role %q is not allowed to manage the api keys of another user", authPayload.Role)
+	}
+	return username, nil
+}
@@ -0,0 +1,113 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCreateApiKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	ctx := newContextWithAuthPayload(t, "user")
+
+	// the callers logged in with a password
+	store.EXPECT().GetAPIKey(gomock.Any(), gomock.Any()).AnyTimes().Return(db.ApiKey{}, sql.ErrNoRows)
+	store.EXPECT().GetAccessToken(gomock.Any(), gomock.Any()).AnyTimes().Return(db.AccessToken{}, sql.ErrNoRows)
+
+	// 1. the key is returned once, only its hash is stored
+	store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+			require.Equal(t, "user", arg.Username)
+			require.True(t, arg.ExpiresAt.Valid)
+			return db.ApiKey{ID: arg.ID, Username: arg.Username, Name: arg.Name, Prefix: arg.Prefix, KeyHash: arg.KeyHash,
+				ExpiresAt: arg.ExpiresAt, CreatedAt: time.Now()}, nil
+		})
+
+	res, err := server.CreateApiKey(ctx, &pb.CreateApiKeyRequest{
+		Name:      "batch",
+		ExpiresAt: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(res.GetKey(), res.GetApiKey().GetPrefix()))
+	require.NotNil(t, res.GetApiKey().GetExpiresAt())
+	require.Nil(t, res.GetApiKey().GetLastUsedAt())
+
+	// 2. only admins create the keys of other users
+	_, err = server.CreateApiKey(ctx, &pb.CreateApiKeyRequest{Name: "batch", Username: "batchjobs"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	payload, err := token.NewPayload("admin", util.AdminRole, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+			require.Equal(t, "batchjobs", arg.Username)
+			return db.ApiKey{ID: arg.ID, Username: arg.Username}, nil
+		})
+	_, err = server.CreateApiKey(contextWithAuthPayload(context.Background(), payload), &pb.CreateApiKeyRequest{Name: "batch", Username: "batchjobs"})
+	require.NoError(t, err)
+
+	// 3. invalid requests
+	_, err = server.CreateApiKey(ctx, &pb.CreateApiKeyRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = server.CreateApiKey(ctx, &pb.CreateApiKeyRequest{Name: "batch", AllowedIps: []string{"not-an-ip"}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = server.CreateApiKey(ctx, &pb.CreateApiKeyRequest{Name: "batch", ExpiresAt: timestamppb.New(time.Now().Add(-time.Hour))})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCreateApiKeyWithDelegatedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	payload, err := token.NewPayload("batchjobs", util.ServiceRole, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	ctx := contextWithAuthPayload(context.Background(), payload)
+
+	// the token was delegated from a key which is limited to an address range and expires
+	callerKey := db.ApiKey{
+		ID:         uuid.New(),
+		Username:   "batchjobs",
+		AllowedIps: []string{"10.0.0.0/8"},
+		ExpiresAt:  sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(payload.ID)).AnyTimes().Return(db.ApiKey{}, sql.ErrNoRows)
+	store.EXPECT().GetAccessToken(gomock.Any(), gomock.Eq(payload.ID)).AnyTimes().
+		Return(db.AccessToken{ID: payload.ID, ApiKeyID: uuid.NullUUID{UUID: callerKey.ID, Valid: true}}, nil)
+	store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(callerKey.ID)).AnyTimes().Return(callerKey, nil)
+
+	// 1. a narrower key is created
+	store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+			require.Equal(t, []string{"10.1.0.0/16"}, arg.AllowedIps)
+			require.Equal(t, callerKey.ExpiresAt, arg.ExpiresAt)
+			return db.ApiKey{ID: arg.ID, Username: arg.Username}, nil
+		})
+	_, err = server.CreateApiKey(ctx, &pb.CreateApiKeyRequest{Name: "batch", AllowedIps: []string{"10.1.0.0/16"}})
+	require.NoError(t, err)
+
+	// 2. a key allowed from more addresses or for longer is refused
+	_, err = server.CreateApiKey(ctx, &pb.CreateApiKeyRequest{Name: "batch", AllowedIps: []string{"192.0.2.1"}})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = server.CreateApiKey(ctx, &pb.CreateApiKeyRequest{Name: "batch", ExpiresAt: timestamppb.New(time.Now().Add(2 * time.Hour))})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,80 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/schedule"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateScheduledTransfer schedules a transfer from an account of the authenticated user, once or on a recurrence
+func (s *Server) CreateScheduledTransfer(ctx context.Context, req *pb.CreateScheduledTransferRequest) (*pb.CreateScheduledTransferResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetFromAccountId() < 1 || req.GetToAccountId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id")
+	}
+	if req.GetAmount() <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must be positive")
+	}
+	if !s.currencies.IsSupported(req.GetCurrency()) {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported currency: %s", req.GetCurrency())
+	}
+	if req.GetStartAt() == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "start_at is required")
+	}
+
+	// 3. the from account must belong to the user and both accounts must use the currency of the transfer
+	fromAccount, err := s.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+	if _, err = s.validAccount(ctx, req.GetToAccountId(), req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	// 4. validate the recurrence and create the schedule
+	rule := schedule.Rule{
+		Frequency:  req.GetFrequency(),
+		Interval:   req.GetInterval(),
+		DayOfMonth: req.GetDayOfMonth(),
+		Count:      req.GetMaxOccurrences(),
+	}
+	if req.GetEndAt() != nil {
+		rule.Until = req.GetEndAt().AsTime()
+	}
+
+	arg, err := schedule.NewScheduledTransfer(schedule.CreateParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		Currency:      req.GetCurrency(),
+		Rule:          rule,
+		Start:         req.GetStartAt().AsTime(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	scheduled, err := s.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create scheduled transfer: %s", err)
+	}
+
+	response := &pb.CreateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduled, s.currencies),
+	}
+	return response, nil
+}
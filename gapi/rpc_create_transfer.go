@@ -0,0 +1,157 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetFromAccountId() < 1 || req.GetToAccountId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id")
+	}
+	if req.GetAmount() <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must be positive")
+	}
+	if !s.currencies.IsSupported(req.GetCurrency()) {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported currency: %s", req.GetCurrency())
+	}
+
+	// 2.1 optional idempotency key sent by the clients which retry the request, the reserved keys are used by the servers
+	idempotencyKey := idempotencyKeyFromContext(ctx)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		return nil, status.Errorf(codes.InvalidArgument, "%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)
+	}
+	if strings.HasPrefix(idempotencyKey, db.ReservedIdempotencyKeyPrefix) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s must not start with %q", idempotencyKeyHeader, db.ReservedIdempotencyKeyPrefix)
+	}
+
+	// 3. check the from account belongs to the user and both accounts use the same currency
+	fromAccount, err := s.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	// 3.1 a cross currency transfer uses the quote which locked its exchange rate
+	var quoteID uuid.NullUUID
+	if req.GetQuoteId() == "" {
+		_, err = s.validAccount(ctx, req.GetToAccountId(), req.GetCurrency())
+	} else {
+		quoteID.UUID, err = uuid.Parse(req.GetQuoteId())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid quote id: %s", err)
+		}
+		quoteID.Valid = true
+		_, err = s.getAccount(ctx, req.GetToAccountId())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. perform the transfer
+	result, err := s.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountId:  req.GetFromAccountId(),
+		ToAccountId:    req.GetToAccountId(),
+		Amount:         req.GetAmount(),
+		IdempotencyKey: idempotencyKey,
+		QuoteID:        quoteID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrIdempotencyKeyConflict):
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		case errors.Is(err, db.ErrQuoteNotUsable):
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		case errors.Is(err, db.ErrQuoteMismatch):
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		case errors.Is(err, db.ErrInsufficientFunds):
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+
+		var velocityErr *db.VelocityError
+		if errors.As(err, &velocityErr) {
+			return nil, velocityLimitError(velocityErr)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create transfer: %s", err)
+	}
+
+	if result.Replayed {
+		grpc.SetHeader(ctx, metadata.Pairs(idempotentReplayedHeader, "true"))
+	}
+
+	// 5. return the transfer result
+	response := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer, result.FromAccount.Currency, result.ToAccount.Currency, s.currencies),
+		FromAccount: convertAccount(result.FromAccount, s.currencies),
+		ToAccount:   convertAccount(result.ToAccount, s.currencies),
+		FromEntry:   convertEntry(result.FromEntry, result.FromAccount.Currency, s.currencies),
+		ToEntry:     convertEntry(result.ToEntry, result.ToAccount.Currency, s.currencies),
+	}
+	return response, nil
+}
+
+// validAccount checks the account exists and uses the currency of the transfer
+func (s *Server) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := s.getAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch is %s and %s", accountID, account.Currency, currency)
+	}
+	return account, nil
+}
+
+// getAccount returns the account or a status error if it doesn't exist
+func (s *Server) getAccount(ctx context.Context, accountID int64) (db.Account, error) {
+	account, err := s.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return account, status.Errorf(codes.NotFound, "no account exists for id %d", accountID)
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+	return account, nil
+}
+
+// velocityLimitError returns the status of a transfer rejected by a velocity limit,
+// the reason and the remaining allowance are attached as an ErrorInfo detail
+func velocityLimitError(err *db.VelocityError) error {
+	st := status.Newf(codes.ResourceExhausted, "%s, %d %s remaining", err, err.Remaining, err.Currency)
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: err.Reason,
+		Domain: velocityLimitErrorDomain,
+		Metadata: map[string]string{
+			"currency":  err.Currency,
+			"limit":     strconv.FormatInt(err.Limit, 10),
+			"remaining": strconv.FormatInt(err.Remaining, 10),
+		},
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
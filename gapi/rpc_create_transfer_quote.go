@@ -0,0 +1,95 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/fx"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultQuoteLockDuration is used when the quote lock duration is not configured
+const defaultQuoteLockDuration = 30 * time.Second
+
+func (s *Server) CreateTransferQuote(ctx context.Context, req *pb.CreateTransferQuoteRequest) (*pb.CreateTransferQuoteResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetFromAccountId() < 1 || req.GetToAccountId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id")
+	}
+	if req.GetAmount() <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must be positive")
+	}
+
+	// 3. the from account must belong to the authenticated user
+	fromAccount, err := s.getAccount(ctx, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	toAccount, err := s.getAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. get the current rate b/w the account currencies
+	rate, err := s.rateProvider.GetRate(ctx, fromAccount.Currency, toAccount.Currency)
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get exchange rate: %s", err)
+	}
+
+	// 5. convert the amount b/w the minor units of the currencies, a quote which would credit nothing is rejected
+	fromCurrency, fromOK := s.currencies.Lookup(fromAccount.Currency)
+	toCurrency, toOK := s.currencies.Lookup(toAccount.Currency)
+	if !fromOK || !toOK {
+		return nil, status.Errorf(codes.FailedPrecondition, "unsupported currency: %s/%s", fromAccount.Currency, toAccount.Currency)
+	}
+
+	toAmount, err := rate.Convert(req.GetAmount(), fromCurrency.Exponent, toCurrency.Exponent)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	// 6. store the quote with the locked rate and the converted amount
+	lockDuration := s.config.QuoteLockDuration
+	if lockDuration <= 0 {
+		lockDuration = defaultQuoteLockDuration
+	}
+	quote, err := s.store.CreateTransferQuote(ctx, db.CreateTransferQuoteParams{
+		ID:            uuid.New(),
+		Owner:         authPayload.Username,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		FromCurrency:  fromAccount.Currency,
+		ToCurrency:    toAccount.Currency,
+		Rate:          rate.String(),
+		FromAmount:    req.GetAmount(),
+		ToAmount:      toAmount,
+		ExpiresAt:     time.Now().Add(lockDuration),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create transfer quote: %s", err)
+	}
+
+	response := &pb.CreateTransferQuoteResponse{
+		Quote: convertTransferQuote(quote, s.currencies),
+	}
+	return response, nil
+}
@@ -2,11 +2,11 @@ package gapi
 
 import (
 	"context"
+	"log"
 
 	db "github.com/akshay237/backend-with-go/database/sqlc"
 
 	"github.com/akshay237/backend-with-go/pb"
-	"github.com/akshay237/backend-with-go/util"
 	"github.com/lib/pq"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -15,12 +15,18 @@ import (
 const (
 	UniqueKeyConstraint  = "unique_voilation"
 	ForeignKeyConstraint = "foreign_key_violation"
+	CheckConstraint      = "check_violation"
 )
 
 func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
 
-	// 1. hash the password before saving it to database
-	hashedPassword, err := util.HashPassword(req.GetPassword())
+	// 1. check the password policy and hash the password before saving it to database
+	err := s.policy.Validate(req.GetUsername(), req.GetPassword())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	hashedPassword, err := s.passwords.HashPassword(req.GetPassword())
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to hash password: %v", err)
 	}
@@ -45,6 +51,12 @@ func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb
 		return nil, status.Errorf(codes.Internal, "failed to create user: %s", err)
 	}
 
+	// 4. mail the email verification token, the user can ask for a new one if it doesn't arrive
+	err = s.userTokens.SendEmailVerification(ctx, user)
+	if err != nil {
+		log.Printf("failed to send the email verification to %s: %v", user.Username, err)
+	}
+
 	// 5. create user response
 	response := &pb.CreateUserResponse{
 		User: convertUser(user),
@@ -0,0 +1,80 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/revocation"
+	"github.com/akshay237/backend-with-go/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DelegateAccessToken creates a short lived access token with fewer scopes than the caller, for example
+// for a read only dashboard. The token is bound to the session or api key of the caller, so it is revoked
+// along with it, and doesn't outlive the token it is created with.
+func (s *Server) DelegateAccessToken(ctx context.Context, req *pb.DelegateAccessTokenRequest) (*pb.DelegateAccessTokenResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request, the scopes must be granted to the caller
+	if len(req.GetScopes()) == 0 || len(req.GetScopes()) > 16 {
+		return nil, status.Errorf(codes.InvalidArgument, "between 1 and 16 scopes are required")
+	}
+	if req.GetExpiresIn() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "expires_in must be positive")
+	}
+
+	scopes, err := token.NarrowScopes(authPayload.Scopes, req.GetScopes())
+	if err != nil {
+		if errors.Is(err, token.ErrScopeNotGranted) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "invalid scopes: %s", err)
+	}
+
+	// 3. create the token
+	duration := delegatedTokenDuration(authPayload, time.Duration(req.GetExpiresIn())*time.Second, s.config.AccessTokenDuration)
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(authPayload.Username, authPayload.Role, scopes, token.TokenTypeAccess, duration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token: %s", err)
+	}
+
+	// 4. bind the token to the session or api key of the caller, so it is revoked along with it
+	err = s.revocations.Delegate(ctx, authPayload, accessPayload)
+	if err != nil {
+		if errors.Is(err, revocation.ErrUnboundToken) {
+			return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to record delegated token: %s", err)
+	}
+
+	response := &pb.DelegateAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: timestamppb.New(accessPayload.ExpiredAT),
+		Scopes:               accessPayload.Scopes,
+	}
+	return response, nil
+}
+
+// delegatedTokenDuration returns the lifetime of a delegated token. It is at most the access token duration
+// and ends with the token of the caller, an api key without expiry doesn't limit it.
+func delegatedTokenDuration(authPayload *token.Payload, requested time.Duration, maxDuration time.Duration) time.Duration {
+	duration := maxDuration
+	if requested > 0 && requested < duration {
+		duration = requested
+	}
+	if !authPayload.ExpiredAT.IsZero() {
+		if remaining := time.Until(authPayload.ExpiredAT); remaining < duration {
+			duration = remaining
+		}
+	}
+	return duration
+}
@@ -0,0 +1,71 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDelegateAccessToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	// the caller has a token limited to reading, which expires in a minute
+	payload, err := token.NewPayload(util.RandomOwner(), util.DepositorRole, []string{token.ScopeAccountsRead, token.ScopeTransfersRead}, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	ctx := contextWithAuthPayload(context.Background(), payload)
+	session := db.Session{ID: uuid.New(), Username: payload.Username}
+
+	// 1. the delegated token has the requested scopes, doesn't outlive the token of the caller
+	// and is recorded under the session of the caller
+	store.EXPECT().GetAccessToken(gomock.Any(), gomock.Eq(payload.ID)).Times(1).Return(db.AccessToken{}, sql.ErrNoRows)
+	store.EXPECT().GetSessionByAccessToken(gomock.Any(), gomock.Eq(uuid.NullUUID{UUID: payload.ID, Valid: true})).Times(1).Return(session, nil)
+	store.EXPECT().CreateAccessToken(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAccessTokenParams) (db.AccessToken, error) {
+			require.Equal(t, uuid.NullUUID{UUID: session.ID, Valid: true}, arg.SessionID)
+			return db.AccessToken{ID: arg.ID, Username: arg.Username, SessionID: arg.SessionID, ExpiresAt: arg.ExpiresAt}, nil
+		})
+
+	res, err := server.DelegateAccessToken(ctx, &pb.DelegateAccessTokenRequest{
+		Scopes:    []string{token.ScopeAccountsRead},
+		ExpiresIn: 3600,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{token.ScopeAccountsRead}, res.GetScopes())
+	require.WithinDuration(t, payload.ExpiredAT, res.GetAccessTokenExpiresAt().AsTime(), time.Second)
+
+	delegated, err := server.tokenMaker.VerifyToken(res.GetAccessToken())
+	require.NoError(t, err)
+	require.Equal(t, payload.Username, delegated.Username)
+	require.Equal(t, []string{token.ScopeAccountsRead}, delegated.Scopes)
+
+	// 2. a scope the caller doesn't have is denied
+	_, err = server.DelegateAccessToken(ctx, &pb.DelegateAccessTokenRequest{Scopes: []string{token.ScopeTransfersWrite}})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// 3. the scopes are required
+	_, err = server.DelegateAccessToken(ctx, &pb.DelegateAccessTokenRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// 4. a token without a session or api key can't delegate
+	store.EXPECT().GetAccessToken(gomock.Any(), gomock.Any()).Times(1).Return(db.AccessToken{}, sql.ErrNoRows)
+	store.EXPECT().GetSessionByAccessToken(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+	store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(payload.ID)).Times(1).Return(db.ApiKey{}, sql.ErrNoRows)
+	_, err = server.DelegateAccessToken(ctx, &pb.DelegateAccessTokenRequest{Scopes: []string{token.ScopeAccountsRead}})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
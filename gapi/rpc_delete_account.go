@@ -0,0 +1,31 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+
+	// 1. validate the request
+	if req.GetId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id: %d", req.GetId())
+	}
+
+	// 2. calls the delete account func
+	err := s.store.DeleteAccount(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "no account exists for id %d", req.GetId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete account: %s", err)
+	}
+
+	// 3. account is deleted
+	return &pb.DeleteAccountResponse{}, nil
+}
@@ -0,0 +1,47 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/akshay237/backend-with-go/mfa"
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DisableTOTP removes the second factor of the authenticated user, it needs a TOTP or a recovery code
+func (s *Server) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetCode() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "code is required")
+	}
+
+	// 3. disable the second factor
+	err = s.mfa.Disable(ctx, authPayload.Username, req.GetCode())
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &pb.DisableTOTPResponse{}, nil
+}
+
+// mfaError converts an error of the MFA authenticator to a gRPC status
+func mfaError(err error) error {
+	switch {
+	case errors.Is(err, mfa.ErrAlreadyEnabled):
+		return status.Errorf(codes.AlreadyExists, "%s", err)
+	case errors.Is(err, mfa.ErrNotEnabled):
+		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	case errors.Is(err, mfa.ErrInvalidCode), errors.Is(err, mfa.ErrChallengeNotUsable):
+		return status.Errorf(codes.Unauthenticated, "%s", err)
+	}
+	return status.Errorf(codes.Internal, "two factor authentication failed: %s", err)
+}
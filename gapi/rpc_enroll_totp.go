@@ -0,0 +1,29 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/akshay237/backend-with-go/pb"
+)
+
+// EnrollTOTP creates a pending TOTP secret for the authenticated user, it is enabled by ConfirmTOTP
+func (s *Server) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. create the pending secret
+	enrollment, err := s.mfa.Enroll(ctx, authPayload.Username)
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	response := &pb.EnrollTOTPResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningUri: enrollment.ProvisioningURI,
+	}
+	return response, nil
+}
@@ -0,0 +1,46 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id: %d", req.GetId())
+	}
+
+	// 3. calls the get account database function by passing the id
+	account, err := s.store.GetAccount(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "no account exists for id %d", req.GetId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	// 4. check if the account belong to the right user, admins can read every account
+	if account.Owner != authPayload.Username && authPayload.Role != util.AdminRole {
+		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	// 5. return the account details
+	response := &pb.GetAccountResponse{
+		Account: convertAccount(account, s.currencies),
+	}
+	return response, nil
+}
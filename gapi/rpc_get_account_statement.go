@@ -0,0 +1,93 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/akshay237/backend-with-go/currency"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/statement"
+	"github.com/akshay237/backend-with-go/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetAccountStatement returns the entries of an account over a period with the running balance after each of them.
+// The statement is checked like GetAccount, a csv, ofx or pdf format also returns it as a document to download.
+func (s *Server) GetAccountStatement(ctx context.Context, req *pb.GetAccountStatementRequest) (*pb.GetAccountStatementResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id: %d", req.GetId())
+	}
+
+	from, to, err := statement.ParsePeriod(req.GetFrom(), req.GetTo())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	format := req.GetFormat()
+	switch format {
+	case "", statement.FormatJSON, statement.FormatCSV, statement.FormatOFX, statement.FormatPDF:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "%s", statement.ErrInvalidFormat)
+	}
+
+	// 3. get the account and check it belongs to the user, admins can read every statement
+	account, err := s.store.GetAccount(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "no account exists for id %d", req.GetId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if account.Owner != authPayload.Username && authPayload.Role != util.AdminRole {
+		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	// 4. build the statement, a currency which is not enabled anymore is formatted without decimal places
+	cur, ok := s.currencies.Lookup(account.Currency)
+	if !ok {
+		cur = currency.Currency{Code: account.Currency}
+	}
+
+	st, err := statement.New(ctx, s.store, account, cur, from, to)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to build statement: %s", err)
+	}
+
+	response := &pb.GetAccountStatementResponse{
+		Account:                 convertAccount(account, s.currencies),
+		From:                    st.From.Format(statement.DateLayout),
+		To:                      st.To.Format(statement.DateLayout),
+		OpeningBalance:          st.OpeningBalance,
+		FormattedOpeningBalance: st.Format(st.OpeningBalance),
+		ClosingBalance:          st.ClosingBalance,
+		FormattedClosingBalance: st.Format(st.ClosingBalance),
+		Lines:                   make([]*pb.StatementLine, 0, len(st.Lines)),
+	}
+	for _, line := range st.Lines {
+		response.Lines = append(response.Lines, convertStatementLine(line, st))
+	}
+
+	// 5. export the statement as a document
+	if format != "" && format != statement.FormatJSON {
+		var document bytes.Buffer
+		if err := st.Export(&document, format); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to export statement: %s", err)
+		}
+		response.Document = document.Bytes()
+		response.ContentType = statement.ContentType(format)
+		response.Filename = st.Filename(format)
+	}
+	return response, nil
+}
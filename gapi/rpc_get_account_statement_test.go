@@ -0,0 +1,136 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetAccountStatementAPI(t *testing.T) {
+	account := randomAccount(util.RandomOwner(), util.USD)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []db.ListStatementEntriesRow{
+		{
+			ID:                    util.RandomInt(1, 1000),
+			Amount:                250,
+			TransferID:            sql.NullInt64{Int64: util.RandomInt(1, 1000), Valid: true},
+			CreatedAt:             from.Add(time.Hour),
+			CounterpartyAccountID: sql.NullInt64{Int64: util.RandomInt(1001, 2000), Valid: true},
+			CounterpartyOwner:     sql.NullString{String: util.RandomOwner(), Valid: true},
+		},
+	}
+
+	buildStatementStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+		store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Any()).Times(1).Return(int64(-100), nil)
+		store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(1).Return(entries, nil)
+	}
+
+	testcases := []struct {
+		name          string
+		req           *pb.GetAccountStatementRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.GetAccountStatementResponse, err error)
+	}{
+		{
+			name:       "OK",
+			req:        &pb.GetAccountStatementRequest{Id: account.ID, From: "2024-01-01", To: "2024-01-31"},
+			buildStubs: buildStatementStubs,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithAuthPayload(t, account.Owner)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountStatementResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(-100), res.GetOpeningBalance())
+				require.Equal(t, "-1.00", res.GetFormattedOpeningBalance())
+				require.Equal(t, int64(150), res.GetClosingBalance())
+				require.Len(t, res.GetLines(), 1)
+				require.Equal(t, int64(150), res.GetLines()[0].GetRunningBalance())
+				require.Equal(t, entries[0].CounterpartyOwner.String, res.GetLines()[0].GetCounterpartyOwner())
+				require.Empty(t, res.GetDocument())
+			},
+		},
+		{
+			name:       "CSV",
+			req:        &pb.GetAccountStatementRequest{Id: account.ID, From: "2024-01-01", To: "2024-01-31", Format: "csv"},
+			buildStubs: buildStatementStubs,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithAuthPayload(t, account.Owner)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountStatementResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "text/csv", res.GetContentType())
+				require.True(t, strings.HasSuffix(res.GetFilename(), ".csv"))
+				require.Contains(t, string(res.GetDocument()), "Closing balance")
+			},
+		},
+		{
+			name: "AccountOfAnotherUser",
+			req:  &pb.GetAccountStatementRequest{Id: account.ID, From: "2024-01-01", To: "2024-01-31"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithAuthPayload(t, util.RandomOwner())
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountStatementResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidPeriod",
+			req:  &pb.GetAccountStatementRequest{Id: account.ID, From: "2024-01-01", To: "2025-06-01"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithAuthPayload(t, account.Owner)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountStatementResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidFormat",
+			req:  &pb.GetAccountStatementRequest{Id: account.ID, From: "2024-01-01", To: "2024-01-31", Format: "xls"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithAuthPayload(t, account.Owner)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountStatementResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.GetAccountStatement(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
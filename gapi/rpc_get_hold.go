@@ -0,0 +1,79 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetHold returns a hold to the owner of one of its accounts, admins can read every hold
+func (s *Server) GetHold(ctx context.Context, req *pb.GetHoldRequest) (*pb.GetHoldResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. get the hold and check it belongs to the user
+	hold, fromAccount, toAccount, err := s.getHold(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if authPayload.Username != fromAccount.Owner && authPayload.Username != toAccount.Owner && authPayload.Role != util.AdminRole {
+		return nil, status.Errorf(codes.PermissionDenied, "hold doesn't belong to the authenticated user")
+	}
+
+	response := &pb.GetHoldResponse{
+		Hold: convertHold(hold, fromAccount.Currency, s.currencies),
+	}
+	return response, nil
+}
+
+// getHold returns the hold and its accounts or a status error if one of them doesn't exist
+func (s *Server) getHold(ctx context.Context, id int64) (db.Hold, db.Account, db.Account, error) {
+	if id < 1 {
+		return db.Hold{}, db.Account{}, db.Account{}, status.Errorf(codes.InvalidArgument, "invalid hold id: %d", id)
+	}
+
+	hold, err := s.store.GetHold(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return hold, db.Account{}, db.Account{}, status.Errorf(codes.NotFound, "no hold exists for id %d", id)
+		}
+		return hold, db.Account{}, db.Account{}, status.Errorf(codes.Internal, "failed to get hold: %s", err)
+	}
+
+	fromAccount, err := s.getAccount(ctx, hold.FromAccountID)
+	if err != nil {
+		return hold, fromAccount, db.Account{}, err
+	}
+	toAccount, err := s.getAccount(ctx, hold.ToAccountID)
+	return hold, fromAccount, toAccount, err
+}
+
+// holdError converts an error of the hold transactions to a status error
+func holdError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return status.Errorf(codes.NotFound, "%s", err)
+	case errors.Is(err, db.ErrInsufficientFunds),
+		errors.Is(err, db.ErrHoldNotActive),
+		errors.Is(err, db.ErrHoldExpired):
+		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	case errors.Is(err, db.ErrHoldAmountExceeded):
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	var velocityErr *db.VelocityError
+	if errors.As(err, &velocityErr) {
+		return velocityLimitError(velocityErr)
+	}
+	return status.Errorf(codes.Internal, "failed to update hold: %s", err)
+}
@@ -0,0 +1,51 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetScheduledTransfer returns a schedule of the authenticated user, admins can read every schedule
+func (s *Server) GetScheduledTransfer(ctx context.Context, req *pb.GetScheduledTransferRequest) (*pb.GetScheduledTransferResponse, error) {
+	scheduled, err := s.getScheduledTransfer(ctx, req.GetId(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.GetScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduled, s.currencies),
+	}
+	return response, nil
+}
+
+// getScheduledTransfer returns the schedule if the authenticated user owns it, or the admins when allowAdmin is set.
+// Otherwise it returns a status error.
+func (s *Server) getScheduledTransfer(ctx context.Context, id int64, allowAdmin bool) (db.ScheduledTransfer, error) {
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return db.ScheduledTransfer{}, err
+	}
+	if id < 1 {
+		return db.ScheduledTransfer{}, status.Errorf(codes.InvalidArgument, "invalid scheduled transfer id: %d", id)
+	}
+
+	scheduled, err := s.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return scheduled, status.Errorf(codes.NotFound, "no scheduled transfer exists for id %d", id)
+		}
+		return scheduled, status.Errorf(codes.Internal, "failed to get scheduled transfer: %s", err)
+	}
+
+	if scheduled.Owner != authPayload.Username && !(allowAdmin && authPayload.Role == util.AdminRole) {
+		return scheduled, status.Errorf(codes.PermissionDenied, "scheduled transfer doesn't belong to the authenticated user")
+	}
+	return scheduled, nil
+}
@@ -0,0 +1,72 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetTransfer returns a transfer with its status and refunds to the owner of one of its accounts, admins can read every transfer
+func (s *Server) GetTransfer(ctx context.Context, req *pb.GetTransferRequest) (*pb.GetTransferResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. get the transfer and check it belongs to the user
+	transfer, err := s.getTransfer(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	fromAccount, err := s.getAccount(ctx, transfer.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := s.getAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if authPayload.Username != fromAccount.Owner && authPayload.Username != toAccount.Owner && authPayload.Role != util.AdminRole {
+		return nil, status.Errorf(codes.PermissionDenied, "transfer doesn't belong to the authenticated user")
+	}
+
+	// 3. the refunds go the other way, from the to account back to the from account
+	reversals, err := s.store.ListTransferReversals(ctx, sql.NullInt64{Int64: transfer.ID, Valid: true})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list reversals: %s", err)
+	}
+
+	response := &pb.GetTransferResponse{
+		Transfer:  convertTransfer(transfer, fromAccount.Currency, toAccount.Currency, s.currencies),
+		Reversals: make([]*pb.Transfer, 0, len(reversals)),
+	}
+	for _, reversal := range reversals {
+		response.Reversals = append(response.Reversals, convertTransfer(reversal, toAccount.Currency, fromAccount.Currency, s.currencies))
+	}
+	return response, nil
+}
+
+// getTransfer returns the transfer or a status error if it doesn't exist
+func (s *Server) getTransfer(ctx context.Context, id int64) (db.Transfer, error) {
+	if id < 1 {
+		return db.Transfer{}, status.Errorf(codes.InvalidArgument, "invalid transfer id: %d", id)
+	}
+
+	transfer, err := s.store.GetTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return transfer, status.Errorf(codes.NotFound, "no transfer exists for id %d", id)
+		}
+		return transfer, status.Errorf(codes.Internal, "failed to get transfer: %s", err)
+	}
+	return transfer, nil
+}
@@ -0,0 +1,51 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	minPageSize = 5
+	maxPageSize = 10
+)
+
+func (s *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+
+	// 1. get the authenticated user, only the accounts of the token owner are listed
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetPageId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "page_id must be at least 1")
+	}
+	if req.GetPageSize() < minPageSize || req.GetPageSize() > maxPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be between %d and %d", minPageSize, maxPageSize)
+	}
+
+	// 3. calls the list account db function
+	accounts, err := s.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  authPayload.Username,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	// 4. return the accounts
+	response := &pb.ListAccountsResponse{
+		Accounts: make([]*pb.Account, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		response.Accounts = append(response.Accounts, convertAccount(account, s.currencies))
+	}
+	return response, nil
+}
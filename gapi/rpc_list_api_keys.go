@@ -0,0 +1,35 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) ListApiKeys(ctx context.Context, req *pb.ListApiKeysRequest) (*pb.ListApiKeysResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := apiKeyOwner(authPayload, req.GetUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. list the keys which are not revoked
+	apiKeys, err := s.apiKeys.List(ctx, owner)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list api keys: %s", err)
+	}
+
+	response := &pb.ListApiKeysResponse{}
+	for _, apiKey := range apiKeys {
+		response.ApiKeys = append(response.ApiKeys, convertAPIKey(apiKey))
+	}
+	return response, nil
+}
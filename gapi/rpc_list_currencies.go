@@ -0,0 +1,15 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/akshay237/backend-with-go/pb"
+)
+
+func (s *Server) ListCurrencies(ctx context.Context, req *pb.ListCurrenciesRequest) (*pb.ListCurrenciesResponse, error) {
+	response := &pb.ListCurrenciesResponse{}
+	for _, currency := range s.currencies.List() {
+		response.Currencies = append(response.Currencies, convertCurrency(currency))
+	}
+	return response, nil
+}
@@ -0,0 +1,54 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListScheduledTransfers returns the schedules of the authenticated user, including the completed and cancelled ones
+func (s *Server) ListScheduledTransfers(ctx context.Context, req *pb.ListScheduledTransfersRequest) (*pb.ListScheduledTransfersResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if err := validatePage(req.GetPageId(), req.GetPageSize()); err != nil {
+		return nil, err
+	}
+
+	// 3. list the schedules
+	schedules, err := s.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list scheduled transfers: %s", err)
+	}
+
+	response := &pb.ListScheduledTransfersResponse{
+		ScheduledTransfers: make([]*pb.ScheduledTransfer, 0, len(schedules)),
+	}
+	for _, scheduled := range schedules {
+		response.ScheduledTransfers = append(response.ScheduledTransfers, convertScheduledTransfer(scheduled, s.currencies))
+	}
+	return response, nil
+}
+
+// validatePage checks the page of a listing
+func validatePage(pageID int32, pageSize int32) error {
+	if pageID < 1 {
+		return status.Errorf(codes.InvalidArgument, "page_id must be at least 1")
+	}
+	if pageSize < minPageSize || pageSize > maxPageSize {
+		return status.Errorf(codes.InvalidArgument, "page_size must be between %d and %d", minPageSize, maxPageSize)
+	}
+	return nil
+}
@@ -5,54 +5,169 @@ import (
 	"database/sql"
 	"errors"
 	"log"
+	"math"
+	"strconv"
 
 	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/lockout"
 	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/token"
 	"github.com/akshay237/backend-with-go/util"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
 
-	// 1. check if the user exists or not
+	// 1. reject unknown scopes, count the attempt and reject the login while the username or the client is locked out,
+	// before the password is checked
+	scopes, err := token.NarrowScopes(nil, req.GetScopes())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid scopes: %s", err)
+	}
+
+	clientIP := s.extractMetadata(ctx).ClientIP
+	err = s.loginGuard.Attempt(ctx, req.GetUsername(), clientIP)
+	if err != nil {
+		return nil, loginLockedError(ctx, err)
+	}
+
+	// 2. check if the user exists or not, unknown usernames count as failed logins too
 	user, err := s.store.GetUser(ctx, req.GetUsername())
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
+			s.recordLoginFailure(ctx, req.GetUsername(), clientIP)
 			return nil, status.Errorf(codes.NotFound, "user not found: %s", err)
 		}
 		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
 	}
 
-	// 2. check if the password of the user is same or not
-	err = util.CheckPassword(req.Password, user.HashedPassword)
+	// 2.1 check if the password of the user is same or not
+	outdated, err := s.passwords.CheckPassword(req.Password, user.HashedPassword)
 	if err != nil {
 		log.Printf("Check password err: %v", err)
+		s.recordLoginFailure(ctx, req.GetUsername(), clientIP)
 		return nil, status.Errorf(codes.Unauthenticated, "wrong password: %s", err)
 	}
 
-	// 3.1 create a access token for the user
-	accessToken, accessPayload, err := s.tokenMaker.CreateToken(req.Username, s.config.AccessTokenDuration)
+	// 2.2 a hash of older settings is replaced now that the password is known
+	if outdated {
+		s.rehashPassword(ctx, user, req.GetPassword())
+	}
+
+	// 2.3 service accounts authenticate with api keys only
+	if user.Role == util.ServiceRole {
+		return nil, status.Errorf(codes.PermissionDenied, "service accounts can't log in with a password, use an api key")
+	}
+
+	// 3. users with two factor authentication get a challenge for the second step instead of the tokens,
+	// the failed attempts of the username are kept until the code is checked
+	enabled, err := s.mfa.IsEnabled(ctx, user.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get two factor authentication: %s", err)
+	}
+	if enabled {
+		err = s.loginGuard.RecordFirstFactor(ctx, clientIP)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to record the login: %s", err)
+		}
+
+		challenge, err := s.mfa.CreateChallenge(ctx, user.Username)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create MFA challenge: %s", err)
+		}
+		response := &pb.LoginUserResponse{
+			MfaRequired:           true,
+			MfaChallengeToken:     challenge.ID.String(),
+			MfaChallengeExpiresAt: timestamppb.New(challenge.ExpiresAt),
+		}
+		return response, nil
+	}
+
+	// 4. the login is complete, the failed attempts of the username are forgotten
+	err = s.loginGuard.RecordSuccess(ctx, user.Username, clientIP)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to reset the failed logins: %s", err)
+	}
+
+	// 5. create the tokens and the session
+	return s.createLoginSession(ctx, user, scopes)
+}
+
+// rehashPassword stores the password hashed with the current settings, the login doesn't depend on it so errors are only logged.
+// The hash is replaced only if it didn't change since it was checked, so a concurrent password reset wins.
+func (s *Server) rehashPassword(ctx context.Context, user db.User, password string) {
+	hashedPassword, err := s.passwords.HashPassword(password)
+	if err != nil {
+		log.Printf("failed to rehash the password of %s: %v", user.Username, err)
+		return
+	}
+
+	_, err = s.store.RehashUserPassword(ctx, db.RehashUserPasswordParams{
+		Username:          user.Username,
+		OldHashedPassword: user.HashedPassword,
+		NewHashedPassword: hashedPassword,
+	})
+	if err != nil {
+		log.Printf("failed to store the rehashed password of %s: %v", user.Username, err)
+	}
+}
+
+// recordLoginFailure counts a failed login, the login response doesn't depend on it so errors are only logged
+func (s *Server) recordLoginFailure(ctx context.Context, username string, clientIP string) {
+	err := s.loginGuard.RecordFailure(ctx, username, clientIP)
+	if err != nil {
+		log.Printf("failed to record the failed login of %s: %v", username, err)
+	}
+}
+
+// loginLockedError returns the status of a login which is locked out, the wait is also sent in the retry-after header
+func loginLockedError(ctx context.Context, err error) error {
+	var locked *lockout.LockedError
+	if !errors.As(err, &locked) {
+		return status.Errorf(codes.Internal, "failed to check the failed logins: %s", err)
+	}
+
+	retryAfter := int(math.Ceil(locked.RetryAfter.Seconds()))
+	if err := grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeader, strconv.Itoa(retryAfter))); err != nil {
+		log.Printf("failed to set the %s header: %v", retryAfterHeader, err)
+	}
+	return status.Errorf(codes.ResourceExhausted, "%s", err)
+}
+
+// createLoginSession creates the access and refresh tokens of a logged in user and stores the session.
+// Both tokens carry the scopes, so the renewed access tokens keep them.
+func (s *Server) createLoginSession(ctx context.Context, user db.User, scopes []string) (*pb.LoginUserResponse, error) {
+
+	// 1. create a access token for the user
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, scopes, token.TokenTypeAccess, s.config.AccessTokenDuration)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "internal server error access token creation failed: %s", err)
 	}
 
-	// 3.2 create a refresh token
-	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(req.Username, s.config.RefreshTokenDuration)
+	// 2. create a refresh token
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, scopes, token.TokenTypeRefresh, s.config.RefreshTokenDuration)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "internal server error refresh token failed: %s", err)
 	}
 
-	// 3.3 create the session and store to DB
+	// 3. create the session and store to DB
+	mtdt := s.extractMetadata(ctx)
 	session, err := s.store.CreateSession(ctx, db.CreateSessionParams{
-		ID:           refreshPayload.ID,
-		Username:     user.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    "",
-		ClientIp:     "192.128.10.21",
-		IsBlocked:    false,
-		ExpiresAt:    refreshPayload.ExpiredAT,
+		ID:                   refreshPayload.ID,
+		Username:             user.Username,
+		RefreshToken:         refreshToken,
+		UserAgent:            mtdt.UserAgent,
+		ClientIp:             mtdt.ClientIP,
+		IsBlocked:            false,
+		ExpiresAt:            refreshPayload.ExpiredAT,
+		FamilyID:             refreshPayload.ID,
+		AccessTokenID:        uuid.NullUUID{UUID: accessPayload.ID, Valid: true},
+		AccessTokenExpiresAt: sql.NullTime{Time: accessPayload.ExpiredAT, Valid: true},
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create session: %s", err)
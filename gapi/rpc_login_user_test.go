@@ -0,0 +1,259 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/lockout"
+	"github.com/akshay237/backend-with-go/mfa"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func randomUser(t *testing.T) (db.User, string) {
+	password := util.RandomString(10)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	return db.User{
+		Username:       util.RandomOwner(),
+		Role:           util.DepositorRole,
+		HashedPassword: hashedPassword,
+		FullName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+	}, password
+}
+
+func TestLoginUserMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user, password := randomUser(t)
+
+	// 1. the password step returns a challenge instead of the tokens
+	var challenge db.MfaChallenge
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginThrottle{FailedAttempts: 1}, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserTotp{
+		Username:  user.Username,
+		EnabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}, nil)
+	store.EXPECT().CreateMFAChallenge(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateMFAChallengeParams) (db.MfaChallenge, error) {
+			challenge = db.MfaChallenge{ID: arg.ID, Username: arg.Username, Attempts: 1, ExpiresAt: arg.ExpiresAt}
+			return challenge, nil
+		})
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+
+	res, err := server.LoginUser(context.Background(), &pb.LoginUserRequest{
+		Username: user.Username,
+		Password: password,
+	})
+	require.NoError(t, err)
+	require.True(t, res.GetMfaRequired())
+	require.Equal(t, challenge.ID.String(), res.GetMfaChallengeToken())
+	require.Empty(t, res.GetAccessToken())
+
+	// 2. a wrong code counts as a failed login of the username
+	recoveryCode := "abcde-fghij"
+	store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginThrottle{FailedAttempts: 2}, nil)
+	store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+	store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserTotp{
+		Username:  user.Username,
+		EnabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}, nil)
+	store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RecoveryCode{}, sql.ErrNoRows)
+	store.EXPECT().GetLoginThrottle(gomock.Any(), gomock.Eq(db.GetLoginThrottleParams{
+		Kind:    lockout.KindUsername,
+		Subject: user.Username,
+	})).Times(1).Return(db.LoginThrottle{FailedAttempts: 2}, nil)
+
+	_, err = server.VerifyLoginMFA(context.Background(), &pb.VerifyLoginMFARequest{
+		MfaChallengeToken: res.GetMfaChallengeToken(),
+		Code:              "wrong-code",
+	})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// 3. the second step exchanges the challenge and a recovery code for the tokens, the failed attempts are forgotten
+	challenge.Attempts = 2
+	store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginThrottle{FailedAttempts: 3}, nil)
+	store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+	store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserTotp{
+		Username:  user.Username,
+		EnabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}, nil)
+	store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Eq(db.UseRecoveryCodeParams{
+		Username: user.Username,
+		CodeHash: mfa.HashRecoveryCode(recoveryCode),
+	})).Times(1).Return(db.RecoveryCode{}, nil)
+	store.EXPECT().ConsumeMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+	store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Eq(db.DeleteLoginThrottleParams{
+		Kind:    lockout.KindUsername,
+		Subject: user.Username,
+	})).Times(1).Return(int64(1), nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+			return db.Session{ID: arg.ID, Username: arg.Username}, nil
+		})
+
+	mfaRes, err := server.VerifyLoginMFA(context.Background(), &pb.VerifyLoginMFARequest{
+		MfaChallengeToken: res.GetMfaChallengeToken(),
+		Code:              recoveryCode,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, mfaRes.GetAccessToken())
+	require.NotEmpty(t, mfaRes.GetRefreshToken())
+	require.Equal(t, user.Username, mfaRes.GetUser().GetUsername())
+}
+
+func TestVerifyLoginMFAInvalidChallenge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	_, err := server.VerifyLoginMFA(context.Background(), &pb.VerifyLoginMFARequest{
+		MfaChallengeToken: "invalid",
+		Code:              "123456",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Any()).Times(1).Return(db.MfaChallenge{}, sql.ErrNoRows)
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(0)
+	_, err = server.VerifyLoginMFA(context.Background(), &pb.VerifyLoginMFARequest{
+		MfaChallengeToken: uuid.NewString(),
+		Code:              "123456",
+	})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestLoginUserLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user, password := randomUser(t)
+
+	// the password is not checked while the username is locked out
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+			require.Equal(t, lockout.KindUsername, arg.Kind)
+			require.Equal(t, user.Username, arg.Subject)
+			return db.LoginThrottle{
+				Kind:           arg.Kind,
+				Subject:        arg.Subject,
+				FailedAttempts: lockout.DefaultMaxAttempts,
+				LockedUntil:    sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+			}, nil
+		})
+	store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := server.LoginUser(context.Background(), &pb.LoginUserRequest{
+		Username: user.Username,
+		Password: password,
+	})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestLoginUserWrongPasswordRecordsFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user, _ := randomUser(t)
+
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginThrottle{FailedAttempts: 1}, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().GetLoginThrottle(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.GetLoginThrottleParams) (db.LoginThrottle, error) {
+			require.Equal(t, lockout.KindUsername, arg.Kind)
+			require.Equal(t, user.Username, arg.Subject)
+			return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: 1}, nil
+		})
+	store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := server.LoginUser(context.Background(), &pb.LoginUserRequest{
+		Username: user.Username,
+		Password: "wrong-password",
+	})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestLoginUserRehashesPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user, password := randomUser(t)
+
+	// the user still has a bcrypt hash from before argon2id was the default
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	user.HashedPassword = string(bcryptHash)
+
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginThrottle{FailedAttempts: 1}, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	store.EXPECT().RehashUserPassword(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RehashUserPasswordParams) (int64, error) {
+			require.Equal(t, user.Username, arg.Username)
+			require.Equal(t, user.HashedPassword, arg.OldHashedPassword)
+			require.NoError(t, util.CheckPassword(password, arg.NewHashedPassword))
+			require.True(t, strings.HasPrefix(arg.NewHashedPassword, "$argon2id$"))
+			return 1, nil
+		})
+	store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.UserTotp{}, sql.ErrNoRows)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+			return db.Session{ID: arg.ID, Username: arg.Username, ExpiresAt: arg.ExpiresAt}, nil
+		})
+
+	res, err := server.LoginUser(context.Background(), &pb.LoginUserRequest{
+		Username: user.Username,
+		Password: password,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.GetAccessToken())
+}
+
+func TestLoginUserServiceAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user, password := randomUser(t)
+	user.Role = util.ServiceRole
+
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(1).Return(db.LoginThrottle{FailedAttempts: 1}, nil)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := server.LoginUser(context.Background(), &pb.LoginUserRequest{
+		Username: user.Username,
+		Password: password,
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,51 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LogoutUser blocks the token family of the refresh token. The refresh token proves the session
+// belongs to the caller, so the method is public and works with an expired access token.
+func (s *Server) LogoutUser(ctx context.Context, req *pb.LogoutUserRequest) (*pb.LogoutUserResponse, error) {
+
+	// 1. verify the refresh token
+	refreshPayload, err := s.tokenMaker.VerifyToken(req.GetRefreshToken())
+	if err == nil {
+		err = refreshPayload.CheckType(token.TokenTypeRefresh)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token: %s", err)
+	}
+
+	// 2. the session must be the one the refresh token was issued for
+	session, err := s.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "session not found: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+	if session.Username != refreshPayload.Username || session.RefreshToken != req.GetRefreshToken() {
+		return nil, status.Errorf(codes.Unauthenticated, "mismatched session token")
+	}
+
+	// 3. block the session along with the sessions it was rotated from or into and revoke their access tokens
+	sessions, err := s.store.BlockSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+
+	err = s.revocations.RevokeSessions(ctx, sessions...)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke access tokens: %s", err)
+	}
+
+	return &pb.LogoutUserResponse{}, nil
+}
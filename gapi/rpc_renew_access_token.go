@@ -0,0 +1,112 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RenewAccessToken exchanges a refresh token for a new access token and a new refresh token.
+// The refresh token can be used only once, its session is replaced by a new one in the same token family.
+func (s *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+
+	// 1. verify the refresh token
+	refreshPayload, err := s.tokenMaker.VerifyToken(req.GetRefreshToken())
+	if err == nil {
+		err = refreshPayload.CheckType(token.TokenTypeRefresh)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token: %s", err)
+	}
+
+	// 2. get the session details for the refresh token
+	session, err := s.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "session not found: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	// 2.1 check if session is for the same user
+	if session.Username != refreshPayload.Username {
+		return nil, status.Errorf(codes.Unauthenticated, "incorrect session user")
+	}
+
+	// 2.2 check if session refresh and the requested refresh token is same or not
+	if session.RefreshToken != req.GetRefreshToken() {
+		return nil, status.Errorf(codes.Unauthenticated, "mismatched session token")
+	}
+
+	// 2.3 check if the session is expired
+	if time.Now().After(session.ExpiresAt) {
+		return nil, status.Errorf(codes.Unauthenticated, "expired session")
+	}
+
+	// 2.4 the sessions of the oauth clients are renewed at the token endpoint with the client credentials
+	if session.ClientID.Valid {
+		return nil, status.Errorf(codes.Unauthenticated, "session belongs to an oauth client, renew it at /oauth/token")
+	}
+
+	// 3. create a access token and the refresh token replacing the used one,
+	// the role is read again so a changed role is applied at the next renewal, the scopes of the session are kept
+	user, err := s.store.GetUser(ctx, session.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, refreshPayload.Scopes, token.TokenTypeAccess, s.config.AccessTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token: %s", err)
+	}
+
+	refreshToken, newRefreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, refreshPayload.Scopes, token.TokenTypeRefresh, s.config.RefreshTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create refresh token: %s", err)
+	}
+
+	// 4. rotate the session, a blocked or already rotated session can't be renewed
+	mtdt := s.extractMetadata(ctx)
+	result, err := s.store.RotateSessionTx(ctx, db.RotateSessionTxParams{
+		SessionID:            session.ID,
+		NewSessionID:         newRefreshPayload.ID,
+		NewRefreshToken:      refreshToken,
+		ExpiresAt:            newRefreshPayload.ExpiredAT,
+		UserAgent:            mtdt.UserAgent,
+		ClientIp:             mtdt.ClientIP,
+		AccessTokenID:        accessPayload.ID,
+		AccessTokenExpiresAt: accessPayload.ExpiredAT,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrRefreshTokenReused):
+			log.Printf("security event: %s for user %s, session %s from %s", db.SecurityEventTokenReuse, session.Username, session.ID, mtdt.ClientIP)
+			if err := s.revocations.RevokeSessions(ctx, result.BlockedSessions...); err != nil {
+				log.Println("failed to revoke the access tokens of the blocked sessions:", err)
+			}
+			return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+		case errors.Is(err, db.ErrSessionBlocked):
+			return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to rotate session: %s", err)
+	}
+
+	// 5. return newly created tokens
+	response := &pb.RenewAccessTokenResponse{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAT),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(newRefreshPayload.ExpiredAT),
+		SessionId:             result.NewSession.ID.String(),
+	}
+	return response, nil
+}
@@ -0,0 +1,32 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestEmailVerification mails a new verification token to the email address of the authenticated user
+func (s *Server) RequestEmailVerification(ctx context.Context, req *pb.RequestEmailVerificationRequest) (*pb.RequestEmailVerificationResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. get the current email address of the user
+	user, err := s.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	// 3. mail the token
+	err = s.userTokens.SendEmailVerification(ctx, user)
+	if err != nil {
+		return nil, userTokenError(err)
+	}
+	return &pb.RequestEmailVerificationResponse{}, nil
+}
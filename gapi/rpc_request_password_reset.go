@@ -0,0 +1,26 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestPasswordReset mails a password reset token. The response is the same for unknown addresses,
+// so it doesn't tell who has an account.
+func (s *Server) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+
+	// 1. validate the request
+	if req.GetEmail() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "email is required")
+	}
+
+	// 2. mail the token
+	err := s.userTokens.SendPasswordReset(ctx, req.GetEmail())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to send the password reset: %s", err)
+	}
+	return &pb.RequestPasswordResetResponse{}, nil
+}
@@ -0,0 +1,57 @@
+package gapi
+
+import (
+	"context"
+	"log"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResetPassword sets a new password with the mailed token. All the sessions of the user are blocked
+// and the tokens issued before the reset are rejected, whether or not they belong to a session.
+func (s *Server) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+
+	// 1. validate the request
+	if req.GetToken() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	// 2. check the new password against the policy of the user the token belongs to
+	userToken, err := s.userTokens.LookupPasswordReset(ctx, req.GetToken())
+	if err != nil {
+		return nil, userTokenError(err)
+	}
+
+	err = s.policy.Validate(userToken.Username, req.GetNewPassword())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	// 2.1 hash the new password
+	hashedPassword, err := s.passwords.HashPassword(req.GetNewPassword())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
+	}
+
+	// 3. redeem the token, the password is changed and the sessions are blocked and revoked in one transaction
+	result, err := s.userTokens.ResetPassword(ctx, req.GetToken(), hashedPassword)
+	if err != nil {
+		return nil, userTokenError(err)
+	}
+
+	// 4. reject the tokens issued before the reset right away, the other processes do after their next refresh
+	s.revocations.PasswordChanged(result.User.Username, result.User.PasswordChangedAt)
+
+	// 5. the owner of the email proved who they are, a login lockout is lifted
+	_, err = s.loginGuard.Unlock(ctx, result.User.Username)
+	if err != nil {
+		log.Printf("failed to unlock the user %s after the password reset: %v", result.User.Username, err)
+	}
+
+	response := &pb.ResetPasswordResponse{
+		User: convertUser(result.User),
+	}
+	return response, nil
+}
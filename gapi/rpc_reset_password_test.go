@@ -0,0 +1,85 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/usertoken"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user, _ := randomUser(t)
+	session := db.Session{
+		ID:                   uuid.New(),
+		Username:             user.Username,
+		AccessTokenID:        uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		AccessTokenExpiresAt: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+	}
+
+	userToken := db.UserToken{
+		TokenHash: usertoken.HashToken("reset-token"),
+		Username:  user.Username,
+		Purpose:   db.UserTokenPasswordReset,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	// 1. the reset rejects the tokens issued before it
+	store.EXPECT().GetUsableUserToken(gomock.Any(), gomock.Any()).Times(1).Return(userToken, nil)
+	store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ResetPasswordTxParams) (db.ResetPasswordTxResult, error) {
+			require.Equal(t, usertoken.HashToken("reset-token"), arg.TokenHash)
+			updated := user
+			updated.PasswordChangedAt = time.Now()
+			return db.ResetPasswordTxResult{User: updated, BlockedSessions: []db.Session{session}}, nil
+		})
+	store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+
+	res, err := server.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+		Token:       "reset-token",
+		NewPassword: "new-secret",
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, res.GetUser().GetUsername())
+	issued := &token.Payload{Username: user.Username, IssuedAt: time.Now().Add(-time.Minute)}
+	require.True(t, server.revocations.IssuedBeforePasswordChange(issued))
+
+	// 2. a used token is rejected
+	store.EXPECT().GetUsableUserToken(gomock.Any(), gomock.Any()).Times(1).Return(db.UserToken{}, sql.ErrNoRows)
+	_, err = server.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+		Token:       "reset-token",
+		NewPassword: "new-secret",
+	})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// 3. a short password or the username as password is rejected before the token is used
+	store.EXPECT().GetUsableUserToken(gomock.Any(), gomock.Any()).Times(1).Return(userToken, nil)
+	_, err = server.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+		Token:       "reset-token",
+		NewPassword: "123",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	userToken.Username = "username"
+	store.EXPECT().GetUsableUserToken(gomock.Any(), gomock.Any()).Times(1).Return(userToken, nil)
+	_, err = server.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+		Token:       "reset-token",
+		NewPassword: "UserName",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,74 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReverseTransfer refunds a transfer with a compensating transfer from its to account back to its from account.
+// Only the owner of the to account or an admin can refund, the amount defaults to what is left to refund.
+func (s *Server) ReverseTransfer(ctx context.Context, req *pb.ReverseTransferRequest) (*pb.ReverseTransferResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	if req.GetAmount() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must not be negative")
+	}
+
+	// 3. the transfer is refunded by the account it paid
+	transfer, err := s.getTransfer(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := s.getAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if toAccount.Owner != authPayload.Username && authPayload.Role != util.AdminRole {
+		return nil, status.Errorf(codes.PermissionDenied, "to account of the transfer doesn't belong to the authenticated user")
+	}
+
+	// 4. post the refund
+	result, err := s.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: req.GetId(),
+		Amount:     req.GetAmount(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, status.Errorf(codes.NotFound, "%s", err)
+		case errors.Is(err, db.ErrInsufficientFunds),
+			errors.Is(err, db.ErrTransferReversed):
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		case errors.Is(err, db.ErrTransferNotReversible),
+			errors.Is(err, db.ErrRefundExceeded),
+			errors.Is(err, db.ErrRefundTooSmall):
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reverse transfer: %s", err)
+	}
+
+	// 5. the compensating transfer goes the other way, its from account is the to account of the original
+	fromCurrency, toCurrency := result.FromAccount.Currency, result.ToAccount.Currency
+	response := &pb.ReverseTransferResponse{
+		Original:    convertTransfer(result.Original, toCurrency, fromCurrency, s.currencies),
+		Transfer:    convertTransfer(result.Transfer, fromCurrency, toCurrency, s.currencies),
+		FromAccount: convertAccount(result.FromAccount, s.currencies),
+		ToAccount:   convertAccount(result.ToAccount, s.currencies),
+		FromEntry:   convertEntry(result.FromEntry, fromCurrency, s.currencies),
+		ToEntry:     convertEntry(result.ToEntry, toCurrency, s.currencies),
+	}
+	return response, nil
+}
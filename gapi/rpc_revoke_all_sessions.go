@@ -0,0 +1,34 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) RevokeAllSessions(ctx context.Context, req *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. log the user out everywhere by blocking all its sessions
+	sessions, err := s.store.BlockUserSessions(ctx, authPayload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %s", err)
+	}
+
+	err = s.revocations.RevokeSessions(ctx, sessions...)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke access tokens: %s", err)
+	}
+
+	response := &pb.RevokeAllSessionsResponse{
+		RevokedSessions: int64(len(sessions)),
+	}
+	return response, nil
+}
@@ -0,0 +1,53 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/akshay237/backend-with-go/apikey"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) RevokeApiKey(ctx context.Context, req *pb.RevokeApiKeyRequest) (*pb.RevokeApiKeyResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid api key id: %s", err)
+	}
+
+	// 3. revoke the key, a key of another user is reported as not found, admins can revoke any key
+	owner := authPayload.Username
+	if authPayload.Role == util.AdminRole {
+		owner = ""
+	}
+
+	apiKey, err := s.apiKeys.Revoke(ctx, id, owner)
+	if err != nil {
+		if errors.Is(err, apikey.ErrKeyNotFound) {
+			return nil, status.Errorf(codes.NotFound, "no api key exists for id %s", id)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to revoke api key: %s", err)
+	}
+
+	// 4. revoke the tokens delegated with the key
+	err = s.revocations.RevokeAPIKey(ctx, apiKey.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke delegated tokens: %s", err)
+	}
+
+	response := &pb.RevokeApiKeyResponse{
+		ApiKey: convertAPIKey(apiKey),
+	}
+	return response, nil
+}
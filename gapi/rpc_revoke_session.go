@@ -0,0 +1,51 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. validate the request
+	sessionID, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid session id: %s", err)
+	}
+
+	// 3. block the session, a session of another user is reported as not found
+	session, err := s.store.BlockSession(ctx, db.BlockSessionParams{
+		ID:       sessionID,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "no session exists for id %s", sessionID)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to revoke session: %s", err)
+	}
+
+	// 4. the access token issued with the session stops working right away
+	err = s.revocations.RevokeSessions(ctx, session)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke access token: %s", err)
+	}
+
+	response := &pb.RevokeSessionResponse{
+		Session: convertSession(session),
+	}
+	return response, nil
+}
@@ -0,0 +1,31 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnlockUser removes the login lockout of a user, it is allowed only for admins by the auth interceptor
+func (s *Server) UnlockUser(ctx context.Context, req *pb.UnlockUserRequest) (*pb.UnlockUserResponse, error) {
+
+	// 1. validate the request
+	if req.GetUsername() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "username is required")
+	}
+
+	// 2. forget the failed logins of the user, unlocked is false if there were none
+	unlocked, err := s.loginGuard.Unlock(ctx, req.GetUsername())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to unlock user: %s", err)
+	}
+
+	// 3. return the response
+	response := &pb.UnlockUserResponse{
+		Username: req.GetUsername(),
+		Unlocked: unlocked,
+	}
+	return response, nil
+}
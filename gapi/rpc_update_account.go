@@ -0,0 +1,41 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.UpdateAccountResponse, error) {
+
+	// 1. validate the request
+	if req.GetId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id: %d", req.GetId())
+	}
+
+	// 2. post the difference as an adjustment entry and update the balance
+	result, err := s.store.UpdateAccountBalanceTx(ctx, db.UpdateAccountBalanceTxParams{
+		ID:      req.GetId(),
+		Balance: req.GetBalance(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "no account exists for id %d", req.GetId())
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update account: %s", err)
+	}
+
+	// 3. return the updated account
+	response := &pb.UpdateAccountResponse{
+		Account: convertAccount(result.Account, s.currencies),
+	}
+	return response, nil
+}
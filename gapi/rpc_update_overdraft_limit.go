@@ -0,0 +1,46 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UpdateOverdraftLimit sets the overdraft limit of an account, the limit can't be lowered below the overdraft in use
+func (s *Server) UpdateOverdraftLimit(ctx context.Context, req *pb.UpdateOverdraftLimitRequest) (*pb.UpdateOverdraftLimitResponse, error) {
+
+	// 1. validate the request
+	if req.GetId() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid account id: %d", req.GetId())
+	}
+	if req.GetOverdraftLimit() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "overdraft limit must not be negative")
+	}
+
+	// 2. update the limit, the balance constraints of the account reject a limit below its overdraft
+	account, err := s.store.UpdateAccountOverdraftLimit(ctx, db.UpdateAccountOverdraftLimitParams{
+		ID:             req.GetId(),
+		OverdraftLimit: req.GetOverdraftLimit(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "no account exists for id %d", req.GetId())
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == CheckConstraint {
+			return nil, status.Errorf(codes.FailedPrecondition, "overdraft limit is lower than the overdraft of the account")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update overdraft limit: %s", err)
+	}
+
+	// 3. return the updated account
+	response := &pb.UpdateOverdraftLimitResponse{
+		Account: convertAccount(account, s.currencies),
+	}
+	return response, nil
+}
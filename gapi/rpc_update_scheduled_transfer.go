@@ -0,0 +1,59 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/schedule"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UpdateScheduledTransfer changes a schedule of the authenticated user, the zero fields are left unchanged.
+// The status pauses or resumes the schedule.
+func (s *Server) UpdateScheduledTransfer(ctx context.Context, req *pb.UpdateScheduledTransferRequest) (*pb.UpdateScheduledTransferResponse, error) {
+
+	// 1. validate the request
+	if req.GetAmount() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must not be negative")
+	}
+
+	// 2. only the owner can change the schedule
+	scheduled, err := s.getScheduledTransfer(ctx, req.GetId(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. validate the changes and update the schedule
+	changes := schedule.UpdateParams{
+		Amount:         req.GetAmount(),
+		MaxOccurrences: req.GetMaxOccurrences(),
+		Status:         req.GetStatus(),
+	}
+	if req.GetEndAt() != nil {
+		changes.EndAt = req.GetEndAt().AsTime()
+	}
+
+	arg, err := schedule.UpdateScheduledTransfer(scheduled, changes)
+	if err != nil {
+		if errors.Is(err, schedule.ErrNotEditable) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	scheduled, err = s.store.UpdateScheduledTransfer(ctx, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", schedule.ErrNotEditable)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update scheduled transfer: %s", err)
+	}
+
+	response := &pb.UpdateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduled, s.currencies),
+	}
+	return response, nil
+}
@@ -0,0 +1,114 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdateScheduledTransferAPI(t *testing.T) {
+	scheduled := db.ScheduledTransfer{
+		ID:           util.RandomInt(1, 1000),
+		Owner:        util.RandomOwner(),
+		Amount:       50,
+		Currency:     util.USD,
+		Frequency:    db.FrequencyDaily,
+		Interval:     1,
+		Occurrences:  3,
+		OccurrenceAt: time.Now().Add(time.Hour),
+		Status:       db.ScheduleStatusPaused,
+	}
+
+	testcases := []struct {
+		name          string
+		req           *pb.UpdateScheduledTransferRequest
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error)
+	}{
+		{
+			name:     "Resume",
+			req:      &pb.UpdateScheduledTransferRequest{Id: scheduled.ID, Status: db.ScheduleStatusActive, MaxOccurrences: 5},
+			username: scheduled.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), scheduled.ID).Times(1).Return(scheduled, nil)
+
+				resumed := scheduled
+				resumed.Status = db.ScheduleStatusActive
+				resumed.MaxOccurrences = sql.NullInt32{Int32: 5, Valid: true}
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Eq(db.UpdateScheduledTransferParams{
+					ID:             scheduled.ID,
+					Amount:         scheduled.Amount,
+					MaxOccurrences: resumed.MaxOccurrences,
+					Status:         db.ScheduleStatusActive,
+				})).Times(1).Return(resumed, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.ScheduleStatusActive, res.GetScheduledTransfer().GetStatus())
+				require.Equal(t, "FREQ=DAILY;INTERVAL=1;COUNT=5", res.GetScheduledTransfer().GetRule())
+			},
+		},
+		{
+			name:     "PermissionDenied",
+			req:      &pb.UpdateScheduledTransferRequest{Id: scheduled.ID, Amount: 20},
+			username: util.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), scheduled.ID).Times(1).Return(scheduled, nil)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name:     "InvalidCount",
+			req:      &pb.UpdateScheduledTransferRequest{Id: scheduled.ID, MaxOccurrences: 3},
+			username: scheduled.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), scheduled.ID).Times(1).Return(scheduled, nil)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			// the schedule completed or was cancelled after it was read
+			name:     "NotEditable",
+			req:      &pb.UpdateScheduledTransferRequest{Id: scheduled.ID, Amount: 20},
+			username: scheduled.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), scheduled.ID).Times(1).Return(scheduled, nil)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithAuthPayload(t, tc.username)
+			res, err := server.UpdateScheduledTransfer(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/usertoken"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerifyEmail marks the email address of a user as verified with the mailed token
+func (s *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+
+	// 1. validate the request
+	if req.GetToken() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	// 2. redeem the token
+	user, err := s.userTokens.VerifyEmail(ctx, req.GetToken())
+	if err != nil {
+		return nil, userTokenError(err)
+	}
+
+	response := &pb.VerifyEmailResponse{
+		User: convertUser(user),
+	}
+	return response, nil
+}
+
+// userTokenError converts an error of the user token service into a gRPC status
+func userTokenError(err error) error {
+	switch {
+	case errors.Is(err, usertoken.ErrInvalidToken):
+		return status.Errorf(codes.Unauthenticated, "%s", err)
+	case errors.Is(err, usertoken.ErrAlreadyVerified):
+		return status.Errorf(codes.AlreadyExists, "%s", err)
+	}
+	return status.Errorf(codes.Internal, "failed to process the token: %s", err)
+}
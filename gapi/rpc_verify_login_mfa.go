@@ -0,0 +1,78 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/akshay237/backend-with-go/mfa"
+	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerifyLoginMFA is the second step of the login, it exchanges the MFA challenge token
+// and a TOTP or recovery code for the access and refresh tokens.
+func (s *Server) VerifyLoginMFA(ctx context.Context, req *pb.VerifyLoginMFARequest) (*pb.VerifyLoginMFAResponse, error) {
+
+	// 1. validate the request
+	challengeID, err := uuid.Parse(req.GetMfaChallengeToken())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid MFA challenge token: %s", err)
+	}
+	if req.GetCode() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "code is required")
+	}
+	scopes, err := token.NarrowScopes(nil, req.GetScopes())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid scopes: %s", err)
+	}
+
+	// 2. count the attempt for the user of the challenge, the codes are throttled like the passwords
+	username, err := s.mfa.ChallengeUsername(ctx, challengeID)
+	if err != nil {
+		return nil, mfaError(err)
+	}
+	clientIP := s.extractMetadata(ctx).ClientIP
+	err = s.loginGuard.Attempt(ctx, username, clientIP)
+	if err != nil {
+		return nil, loginLockedError(ctx, err)
+	}
+
+	// 2.1 check the code for the challenge
+	_, err = s.mfa.VerifyChallenge(ctx, challengeID, req.GetCode())
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) || errors.Is(err, mfa.ErrChallengeNotUsable) {
+			s.recordLoginFailure(ctx, username, clientIP)
+		}
+		return nil, mfaError(err)
+	}
+
+	// 2.2 the login is complete, the failed attempts of the username are forgotten
+	err = s.loginGuard.RecordSuccess(ctx, username, clientIP)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to reset the failed logins: %s", err)
+	}
+
+	// 3. create the tokens and the session
+	user, err := s.store.GetUser(ctx, username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	login, err := s.createLoginSession(ctx, user, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.VerifyLoginMFAResponse{
+		User:                  login.User,
+		SessionId:             login.SessionId,
+		AccessToken:           login.AccessToken,
+		RefreshToken:          login.RefreshToken,
+		AccessTokenExpiresAt:  login.AccessTokenExpiresAt,
+		RefreshTokenExpiresAt: login.RefreshTokenExpiresAt,
+	}
+	return response, nil
+}
@@ -0,0 +1,41 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/akshay237/backend-with-go/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VoidHold cancels a hold and gives what is left on it back to the from account.
+// Only the owner of the to account can void, the payer waits for the hold to expire.
+func (s *Server) VoidHold(ctx context.Context, req *pb.VoidHoldRequest) (*pb.VoidHoldResponse, error) {
+
+	// 1. get the authenticated user
+	authPayload, err := getAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. the hold is voided by the account it pays
+	_, _, toAccount, err := s.getHold(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if toAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "to account of the hold doesn't belong to the authenticated user")
+	}
+
+	// 3. release the funds
+	result, err := s.store.VoidHoldTx(ctx, req.GetId())
+	if err != nil {
+		return nil, holdError(err)
+	}
+
+	response := &pb.VoidHoldResponse{
+		Hold:        convertHold(result.Hold, result.FromAccount.Currency, s.currencies),
+		FromAccount: convertAccount(result.FromAccount, s.currencies),
+	}
+	return response, nil
+}
@@ -2,31 +2,87 @@ package gapi
 
 import (
 	"fmt"
+	"net/netip"
 
+	"github.com/akshay237/backend-with-go/apikey"
+	"github.com/akshay237/backend-with-go/currency"
 	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/fx"
+	"github.com/akshay237/backend-with-go/lockout"
+	"github.com/akshay237/backend-with-go/mail"
+	"github.com/akshay237/backend-with-go/mfa"
 	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/revocation"
 	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/usertoken"
 	"github.com/akshay237/backend-with-go/util"
 )
 
 // Server serves gRPC requests for our banking service.
 type Server struct {
 	pb.UnimplementedSimpleBankServer
-	config     util.Config
-	store      db.Store
-	tokenMaker token.Maker
+	config       util.Config
+	store        db.Store
+	tokenMaker   token.Maker
+	rateProvider fx.RateProvider
+	currencies   *currency.Registry
+	revocations  *revocation.List
+	mfa          *mfa.Authenticator
+	loginGuard   *lockout.Guard
+	userTokens   *usertoken.Service
+	passwords    *util.PasswordHasher
+	policy       *util.PasswordPolicy
+	apiKeys      *apikey.Service
+	// trustedProxies may forward the address of the client, the gateway is one of them
+	trustedProxies []netip.Prefix
 }
 
 // New Server creates a new gRPC server.
-func NewServerHandler(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewPasteoMaker(config.TokenSymmetricKey)
+// The currency registry and the token revocation list are shared with the caller, which keeps them refreshed.
+func NewServerHandler(config util.Config, store db.Store, currencies *currency.Registry, revocations *revocation.List) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %v", err)
 	}
+	rateProvider, err := fx.NewRateProvider(config, store)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %v", err)
+	}
+	authenticator, err := mfa.NewAuthenticator(store, config.TOTPEncryptionKey, config.MFAChallengeDuration)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create MFA authenticator: %v", err)
+	}
+	mailer, err := mail.NewMailer(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mailer: %v", err)
+	}
+	passwords, err := util.NewPasswordHasher(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %v", err)
+	}
+	policy, err := util.NewPasswordPolicy(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password policy: %v", err)
+	}
+	trustedProxies, err := util.ParseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse trusted proxies: %v", err)
+	}
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:       config,
+		store:        store,
+		tokenMaker:   tokenMaker,
+		rateProvider: rateProvider,
+		currencies:   currencies,
+		revocations:  revocations,
+		mfa:          authenticator,
+		loginGuard:   lockout.NewGuard(store, config),
+		userTokens:   usertoken.NewService(store, mailer, config),
+		passwords:    passwords,
+		policy:       policy,
+		apiKeys:      apikey.NewService(store),
+
+		trustedProxies: trustedProxies,
 	}
 
 	return server, nil
@@ -0,0 +1,82 @@
+package hold
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/util"
+)
+
+const (
+	// DefaultDuration is the lifetime of a hold when it is not configured, it is also the longest a hold can ask for
+	DefaultDuration = 7 * 24 * time.Hour
+	// DefaultSweepInterval is how often the expired holds are released when it is not configured
+	DefaultSweepInterval = time.Minute
+	// maxSweepBatch bounds the holds released by one sweep, the rest is left to the next one
+	maxSweepBatch = 1000
+)
+
+// ExpiresAt returns when a hold authorized now expires. The requested lifetime is capped
+// by the configured one, which is also used when no lifetime is requested.
+func ExpiresAt(config util.Config, requested time.Duration) time.Time {
+	duration := config.HoldDuration
+	if duration <= 0 {
+		duration = DefaultDuration
+	}
+	if requested > 0 && requested < duration {
+		duration = requested
+	}
+	return time.Now().Add(duration)
+}
+
+// Store is the part of the database store used to expire the holds
+type Store interface {
+	ExpireNextHoldTx(ctx context.Context) (db.ReleaseHoldTxResult, error)
+}
+
+// Sweeper releases the funds of the holds which expired without being captured or voided.
+// Several servers can sweep at the same time, a hold locked by one of them is skipped by the others.
+type Sweeper struct {
+	store Store
+}
+
+// NewSweeper creates a sweeper of the expired holds
+func NewSweeper(store Store) *Sweeper {
+	return &Sweeper{store: store}
+}
+
+// Sweep expires the holds which are due and returns how many were expired
+func (s *Sweeper) Sweep(ctx context.Context) (int, error) {
+	for expired := 0; expired < maxSweepBatch; expired++ {
+		_, err := s.store.ExpireNextHoldTx(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return expired, nil
+			}
+			return expired, err
+		}
+	}
+	return maxSweepBatch, nil
+}
+
+// Run sweeps the expired holds on every interval until the context is done
+func (s *Sweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := s.Sweep(ctx)
+			if err != nil {
+				log.Println("failed to expire the holds:", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package hold
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiresAt(t *testing.T) {
+	config := util.Config{HoldDuration: time.Hour}
+
+	// the requested lifetime is used only when it is shorter than the configured one
+	require.WithinDuration(t, time.Now().Add(time.Hour), ExpiresAt(config, 0), time.Second)
+	require.WithinDuration(t, time.Now().Add(time.Minute), ExpiresAt(config, time.Minute), time.Second)
+	require.WithinDuration(t, time.Now().Add(time.Hour), ExpiresAt(config, 2*time.Hour), time.Second)
+	require.WithinDuration(t, time.Now().Add(DefaultDuration), ExpiresAt(util.Config{}, 0), time.Second)
+}
+
+func TestSweep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	sweeper := NewSweeper(store)
+
+	// the holds are expired one by one until none is due
+	gomock.InOrder(
+		store.EXPECT().ExpireNextHoldTx(gomock.Any()).Times(2).Return(db.ReleaseHoldTxResult{}, nil),
+		store.EXPECT().ExpireNextHoldTx(gomock.Any()).Times(1).Return(db.ReleaseHoldTxResult{}, sql.ErrNoRows),
+	)
+	expired, err := sweeper.Sweep(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, expired)
+
+	// a failure stops the sweep, the rest is left to the next one
+	gomock.InOrder(
+		store.EXPECT().ExpireNextHoldTx(gomock.Any()).Times(1).Return(db.ReleaseHoldTxResult{}, nil),
+		store.EXPECT().ExpireNextHoldTx(gomock.Any()).Times(1).Return(db.ReleaseHoldTxResult{}, sql.ErrConnDone),
+	)
+	expired, err = sweeper.Sweep(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Equal(t, 1, expired)
+}
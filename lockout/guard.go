@@ -0,0 +1,278 @@
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/util"
+)
+
+// Kinds of login throttles
+const (
+	KindUsername = "username"
+	KindIP       = "ip"
+)
+
+// Defaults used when the login throttling is not configured
+const (
+	DefaultMaxAttempts     = 10
+	DefaultIPMaxAttempts   = 100
+	DefaultLockoutDuration = 15 * time.Minute
+	DefaultBackoff         = time.Second
+)
+
+var ErrLoginLocked = errors.New("too many failed login attempts")
+
+// LockedError is returned while a username or a client ip has to wait before the next login
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrLoginLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// Store is the part of the database store used to keep the failed login attempts
+type Store interface {
+	GetLoginThrottle(ctx context.Context, arg db.GetLoginThrottleParams) (db.LoginThrottle, error)
+	RecordLoginAttempt(ctx context.Context, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error)
+	ReleaseLoginAttempt(ctx context.Context, arg db.ReleaseLoginAttemptParams) error
+	LockLoginThrottle(ctx context.Context, arg db.LockLoginThrottleParams) (db.LoginThrottle, error)
+	DeleteLoginThrottle(ctx context.Context, arg db.DeleteLoginThrottleParams) (int64, error)
+	DeleteStaleLoginThrottles(ctx context.Context, resetBefore time.Time) (int64, error)
+}
+
+// policy is the throttling of one kind. The first failures are free, every further failure doubles
+// the wait before the next attempt and reaching maxAttempts locks the subject for the lockout duration.
+type policy struct {
+	freeAttempts int32
+	maxAttempts  int32
+}
+
+// Guard throttles the password logins per username and per client ip. The state is kept in Postgres,
+// so a client can't get around it by spreading the attempts over the replicas.
+type Guard struct {
+	store           Store
+	policies        map[string]policy
+	lockoutDuration time.Duration
+	backoff         time.Duration
+}
+
+// NewGuard creates the login guard with the limits of the config
+func NewGuard(store Store, config util.Config) *Guard {
+	maxAttempts := int32(config.LoginMaxAttempts)
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	ipMaxAttempts := int32(config.LoginIPMaxAttempts)
+	if ipMaxAttempts <= 0 {
+		ipMaxAttempts = DefaultIPMaxAttempts
+	}
+	lockoutDuration := config.LoginLockoutDuration
+	if lockoutDuration <= 0 {
+		lockoutDuration = DefaultLockoutDuration
+	}
+	backoff := config.LoginBackoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+
+	// a fifth of the attempts are free, so a few typos don't slow down a user
+	return &Guard{
+		store: store,
+		policies: map[string]policy{
+			KindUsername: {freeAttempts: maxAttempts / 5, maxAttempts: maxAttempts},
+			KindIP:       {freeAttempts: ipMaxAttempts / 5, maxAttempts: ipMaxAttempts},
+		},
+		lockoutDuration: lockoutDuration,
+		backoff:         backoff,
+	}
+}
+
+// Attempt counts a login attempt of the username from the client ip and returns a LockedError if either
+// has to wait. It must be called before the password or the code is checked: the attempt is counted and
+// checked in one statement, so concurrent attempts can't get past the limit between the check and the count.
+func (g *Guard) Attempt(ctx context.Context, username string, clientIP string) error {
+	now := time.Now()
+	var retryAfter time.Duration
+	for kind, subject := range subjects(username, clientIP) {
+		// attempts older than the lockout duration are forgotten, a locked subject keeps its count
+		throttle, err := g.store.RecordLoginAttempt(ctx, db.RecordLoginAttemptParams{
+			Kind:        kind,
+			Subject:     subject,
+			ResetBefore: now.Add(-g.lockoutDuration),
+		})
+		if err != nil {
+			return err
+		}
+
+		var wait time.Duration
+		if throttle.LockedUntil.Valid {
+			wait = time.Until(throttle.LockedUntil.Time)
+		}
+		if wait <= 0 && throttle.FailedAttempts > g.policies[kind].maxAttempts {
+			// concurrent attempts went past the limit before the failures locked the subject
+			wait = g.lockoutDuration
+			err = g.lock(ctx, kind, subject, now.Add(wait))
+			if err != nil {
+				return err
+			}
+		}
+		if wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure delays the next attempt after a failed login of the username from the client ip,
+// the attempt itself was counted by Attempt
+func (g *Guard) RecordFailure(ctx context.Context, username string, clientIP string) error {
+	now := time.Now()
+	for kind, subject := range subjects(username, clientIP) {
+		throttle, err := g.store.GetLoginThrottle(ctx, db.GetLoginThrottleParams{
+			Kind:    kind,
+			Subject: subject,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return err
+		}
+
+		wait := g.delay(g.policies[kind], throttle.FailedAttempts)
+		if wait <= 0 {
+			continue
+		}
+		if throttle.FailedAttempts >= g.policies[kind].maxAttempts {
+			log.Printf("login of %s %s locked for %s after %d failed attempts", kind, subject, wait, throttle.FailedAttempts)
+		}
+
+		err = g.lock(ctx, kind, subject, now.Add(wait))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordFirstFactor gives the attempt back to the client ip after the password of a login with a second factor
+// was right. The username keeps it until the login completes, so the password alone doesn't reset the count
+// of the wrong codes.
+func (g *Guard) RecordFirstFactor(ctx context.Context, clientIP string) error {
+	ip := normalizeIP(clientIP)
+	if ip == "" {
+		return nil
+	}
+	return g.store.ReleaseLoginAttempt(ctx, db.ReleaseLoginAttemptParams{
+		Kind:    KindIP,
+		Subject: ip,
+	})
+}
+
+// RecordSuccess resets the failed attempts of the username once the login completed. The client ip only
+// gets its attempt back, otherwise an attacker could reset its count by logging into an account of their own.
+func (g *Guard) RecordSuccess(ctx context.Context, username string, clientIP string) error {
+	_, err := g.store.DeleteLoginThrottle(ctx, db.DeleteLoginThrottleParams{
+		Kind:    KindUsername,
+		Subject: username,
+	})
+	if err != nil {
+		return err
+	}
+	return g.RecordFirstFactor(ctx, clientIP)
+}
+
+// Unlock removes the lock and the failed attempts of the username, it reports whether there were any
+func (g *Guard) Unlock(ctx context.Context, username string) (bool, error) {
+	rows, err := g.store.DeleteLoginThrottle(ctx, db.DeleteLoginThrottleParams{
+		Kind:    KindUsername,
+		Subject: username,
+	})
+	return rows > 0, err
+}
+
+// Run deletes the throttles which are neither locked nor counting on every interval until the context is done
+func (g *Guard) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := g.store.DeleteStaleLoginThrottles(ctx, time.Now().Add(-g.lockoutDuration))
+			if err != nil {
+				log.Println("failed to delete the stale login throttles:", err)
+			}
+		}
+	}
+}
+
+// delay returns how long the subject waits after the failed attempts
+func (g *Guard) delay(p policy, failedAttempts int32) time.Duration {
+	if failedAttempts >= p.maxAttempts {
+		return g.lockoutDuration
+	}
+	if failedAttempts <= p.freeAttempts {
+		return 0
+	}
+
+	wait := g.backoff
+	for i := p.freeAttempts + 1; i < failedAttempts && wait < g.lockoutDuration; i++ {
+		wait *= 2
+	}
+	if wait > g.lockoutDuration {
+		wait = g.lockoutDuration
+	}
+	return wait
+}
+
+// lock makes the subject wait until the time, an existing longer lock is kept
+func (g *Guard) lock(ctx context.Context, kind string, subject string, lockedUntil time.Time) error {
+	_, err := g.store.LockLoginThrottle(ctx, db.LockLoginThrottleParams{
+		Kind:        kind,
+		Subject:     subject,
+		LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true},
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+// subjects returns the throttles a login is counted in
+func subjects(username string, clientIP string) map[string]string {
+	subjects := map[string]string{KindUsername: username}
+	if ip := normalizeIP(clientIP); ip != "" {
+		subjects[KindIP] = ip
+	}
+	return subjects
+}
+
+// normalizeIP returns the client ip without the port. The ip must be the trusted client address,
+// an address forwarded by the client would let it pick the throttle it is counted in.
+func normalizeIP(clientIP string) string {
+	ip := strings.TrimSpace(clientIP)
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return ip
+}
@@ -0,0 +1,148 @@
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardDelay(t *testing.T) {
+	guard := NewGuard(nil, util.Config{
+		LoginMaxAttempts:     10,
+		LoginLockoutDuration: time.Minute,
+		LoginBackoff:         time.Second,
+	})
+	p := guard.policies[KindUsername]
+
+	// the first fifth of the attempts are free, then the wait doubles until the lockout
+	require.Zero(t, guard.delay(p, 1))
+	require.Zero(t, guard.delay(p, 2))
+	require.Equal(t, time.Second, guard.delay(p, 3))
+	require.Equal(t, 2*time.Second, guard.delay(p, 4))
+	require.Equal(t, 32*time.Second, guard.delay(p, 8))
+	require.Equal(t, time.Minute, guard.delay(p, 9))
+	require.Equal(t, time.Minute, guard.delay(p, 10))
+	require.Equal(t, time.Minute, guard.delay(p, 1000))
+}
+
+func TestGuardAttempt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	guard := NewGuard(store, util.Config{LoginMaxAttempts: 5, LoginLockoutDuration: time.Minute})
+
+	// the longest lock of the username and the ip is returned
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+		DoAndReturn(func(_ context.Context, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+			require.WithinDuration(t, time.Now().Add(-time.Minute), arg.ResetBefore, time.Second)
+			lockedUntil := time.Now().Add(time.Second)
+			if arg.Kind == KindIP {
+				require.Equal(t, "10.0.0.1", arg.Subject)
+				lockedUntil = time.Now().Add(time.Hour)
+			}
+			return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true}}, nil
+		})
+
+	err := guard.Attempt(context.Background(), "user", "10.0.0.1:4321")
+	require.ErrorIs(t, err, ErrLoginLocked)
+
+	var locked *LockedError
+	require.True(t, errors.As(err, &locked))
+	require.InDelta(t, time.Hour.Seconds(), locked.RetryAfter.Seconds(), 1)
+
+	// an expired lock and the attempts below the limit don't block the login
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+		DoAndReturn(func(_ context.Context, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+			return db.LoginThrottle{
+				Kind:           arg.Kind,
+				Subject:        arg.Subject,
+				FailedAttempts: 5,
+				LockedUntil:    sql.NullTime{Time: time.Now().Add(-time.Second), Valid: true},
+			}, nil
+		})
+	require.NoError(t, guard.Attempt(context.Background(), "user", "10.0.0.1"))
+
+	// concurrent attempts past the limit are rejected and lock the username before its failures are recorded
+	store.EXPECT().RecordLoginAttempt(gomock.Any(), gomock.Any()).Times(2).
+		DoAndReturn(func(_ context.Context, arg db.RecordLoginAttemptParams) (db.LoginThrottle, error) {
+			attempts := int32(1)
+			if arg.Kind == KindUsername {
+				attempts = 6
+			}
+			return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: attempts}, nil
+		})
+	store.EXPECT().LockLoginThrottle(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.LockLoginThrottleParams) (db.LoginThrottle, error) {
+			require.Equal(t, KindUsername, arg.Kind)
+			require.WithinDuration(t, time.Now().Add(time.Minute), arg.LockedUntil.Time, time.Second)
+			return db.LoginThrottle{}, nil
+		})
+
+	err = guard.Attempt(context.Background(), "user", "10.0.0.1")
+	require.True(t, errors.As(err, &locked))
+	require.InDelta(t, time.Minute.Seconds(), locked.RetryAfter.Seconds(), 1)
+}
+
+func TestGuardRecordFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	guard := NewGuard(store, util.Config{LoginMaxAttempts: 5, LoginLockoutDuration: time.Minute})
+
+	// only the username reached its limit, the ip is still in its free attempts
+	store.EXPECT().GetLoginThrottle(gomock.Any(), gomock.Any()).Times(2).
+		DoAndReturn(func(_ context.Context, arg db.GetLoginThrottleParams) (db.LoginThrottle, error) {
+			attempts := int32(1)
+			if arg.Kind == KindUsername {
+				attempts = 5
+			}
+			return db.LoginThrottle{Kind: arg.Kind, Subject: arg.Subject, FailedAttempts: attempts}, nil
+		})
+	store.EXPECT().LockLoginThrottle(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.LockLoginThrottleParams) (db.LoginThrottle, error) {
+			require.Equal(t, KindUsername, arg.Kind)
+			require.Equal(t, "user", arg.Subject)
+			require.WithinDuration(t, time.Now().Add(time.Minute), arg.LockedUntil.Time, time.Second)
+			return db.LoginThrottle{}, nil
+		})
+
+	require.NoError(t, guard.RecordFailure(context.Background(), "user", "2001:db8::1"))
+}
+
+func TestGuardRecordSuccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	guard := NewGuard(store, util.Config{})
+
+	// the username is reset, the ip only gets the attempt of the login back
+	store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Eq(db.DeleteLoginThrottleParams{Kind: KindUsername, Subject: "user"})).
+		Times(1).Return(int64(1), nil)
+	store.EXPECT().ReleaseLoginAttempt(gomock.Any(), gomock.Eq(db.ReleaseLoginAttemptParams{Kind: KindIP, Subject: "10.0.0.1"})).
+		Times(1).Return(nil)
+	require.NoError(t, guard.RecordSuccess(context.Background(), "user", "10.0.0.1"))
+
+	// the first factor of a login keeps the attempt of the username
+	store.EXPECT().ReleaseLoginAttempt(gomock.Any(), gomock.Eq(db.ReleaseLoginAttemptParams{Kind: KindIP, Subject: "10.0.0.1"})).
+		Times(1).Return(nil)
+	require.NoError(t, guard.RecordFirstFactor(context.Background(), "10.0.0.1"))
+}
+
+func TestNormalizeIP(t *testing.T) {
+	require.Equal(t, "10.0.0.1", normalizeIP("10.0.0.1"))
+	require.Equal(t, "10.0.0.1", normalizeIP("10.0.0.1:8080"))
+	require.Equal(t, "2001:db8::1", normalizeIP("[2001:db8::1]:8080"))
+	require.Equal(t, "2001:db8::1", normalizeIP("2001:db8::1"))
+	require.Empty(t, normalizeIP(""))
+}
@@ -0,0 +1,46 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileMailer appends the emails to a file instead of sending them, it is meant for local development
+type FileMailer struct {
+	path string
+	from string
+
+	mu sync.Mutex
+}
+
+// NewFileMailer creates a mailer writing to the file, the file is created on the first email
+func NewFileMailer(path string, from string) (*FileMailer, error) {
+	if path == "" {
+		return nil, fmt.Errorf("mail file path is required")
+	}
+	return &FileMailer{path: path, from: from}, nil
+}
+
+// Send appends the message to the file
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	err := validate(msg)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data := append(format(m.from, msg, time.Now()), "\r\n\r\n"...)
+	_, err = file.Write(data)
+	return err
+}
@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+)
+
+// Supported mailers
+const (
+	MailerSMTP   = "smtp"
+	MailerFile   = "file"
+	MailerMemory = "memory"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends the emails to the users
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer creates the mailer selected in the config
+func NewMailer(config util.Config) (Mailer, error) {
+	switch config.Mailer {
+	case MailerSMTP:
+		return NewSMTPMailer(config.SMTPAddress, config.SMTPUsername, config.SMTPPassword, config.MailSender)
+	case MailerFile:
+		return NewFileMailer(config.MailFilePath, config.MailSender)
+	case MailerMemory:
+		return NewMemoryMailer(), nil
+	}
+	return nil, fmt.Errorf("unsupported mailer %q", config.Mailer)
+}
+
+// format returns the message in the RFC 5322 format
+func format(from string, msg Message, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// validate rejects the messages which could inject headers through a line break
+func validate(msg Message) error {
+	if msg.To == "" {
+		return fmt.Errorf("message has no recipient")
+	}
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return fmt.Errorf("recipient and subject must be a single line")
+	}
+	return nil
+}
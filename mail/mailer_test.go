@@ -0,0 +1,85 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	data := string(format("Bank <bank@example.com>", Message{
+		To:      "user@example.com",
+		Subject: "Hello",
+		Body:    "line 1\nline 2",
+	}, date))
+
+	require.Contains(t, data, "From: Bank <bank@example.com>\r\n")
+	require.Contains(t, data, "To: user@example.com\r\n")
+	require.Contains(t, data, "Subject: Hello\r\n")
+	require.Contains(t, data, "Date: Thu, 02 Jan 2025 03:04:05 +0000\r\n")
+	require.True(t, strings.HasSuffix(data, "\r\n\r\nline 1\r\nline 2"))
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, validate(Message{To: "user@example.com", Subject: "Hello"}))
+	require.Error(t, validate(Message{Subject: "Hello"}))
+	require.Error(t, validate(Message{To: "user@example.com", Subject: "Hello\r\nBcc: other@example.com"}))
+	require.Error(t, validate(Message{To: "user@example.com\nBcc: other@example.com"}))
+}
+
+func TestFileMailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	mailer, err := NewFileMailer(path, "bank@example.com")
+	require.NoError(t, err)
+
+	for _, subject := range []string{"first", "second"} {
+		err = mailer.Send(context.Background(), Message{To: "user@example.com", Subject: subject, Body: "body"})
+		require.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "Subject: first\r\n")
+	require.Contains(t, string(data), "Subject: second\r\n")
+
+	_, err = NewFileMailer("", "bank@example.com")
+	require.Error(t, err)
+}
+
+func TestMemoryMailer(t *testing.T) {
+	mailer := NewMemoryMailer()
+	_, ok := mailer.Last("user@example.com")
+	require.False(t, ok)
+
+	require.NoError(t, mailer.Send(context.Background(), Message{To: "user@example.com", Subject: "first"}))
+	require.NoError(t, mailer.Send(context.Background(), Message{To: "other@example.com", Subject: "other"}))
+	require.NoError(t, mailer.Send(context.Background(), Message{To: "user@example.com", Subject: "second"}))
+
+	msg, ok := mailer.Last("user@example.com")
+	require.True(t, ok)
+	require.Equal(t, "second", msg.Subject)
+	require.Len(t, mailer.Messages(), 3)
+}
+
+func TestNewMailer(t *testing.T) {
+	mailer, err := NewMailer(util.Config{Mailer: MailerMemory})
+	require.NoError(t, err)
+	require.IsType(t, &MemoryMailer{}, mailer)
+
+	mailer, err = NewMailer(util.Config{Mailer: MailerSMTP, SMTPAddress: "localhost:25", MailSender: "Bank <bank@example.com>"})
+	require.NoError(t, err)
+	require.IsType(t, &SMTPMailer{}, mailer)
+
+	_, err = NewMailer(util.Config{Mailer: MailerSMTP, SMTPAddress: "localhost", MailSender: "bank@example.com"})
+	require.Error(t, err)
+
+	_, err = NewMailer(util.Config{Mailer: "pigeon"})
+	require.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTPMailer sends the emails through an SMTP server, the connection is upgraded with STARTTLS if the server supports it
+type SMTPMailer struct {
+	address string
+	auth    smtp.Auth
+	from    string
+	sender  string
+}
+
+// NewSMTPMailer creates a mailer for the server address, the username and password are optional
+func NewSMTPMailer(address string, username string, password string, from string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP address %q: %v", address, err)
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mail sender %q: %v", from, err)
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		address: address,
+		auth:    auth,
+		from:    from,
+		sender:  sender.Address,
+	}, nil
+}
+
+// Send delivers the message to the SMTP server
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	err := validate(msg)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.address, m.auth, m.sender, []string{msg.To}, format(m.from, msg, time.Now()))
+}
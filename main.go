@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"net"
@@ -10,6 +11,9 @@ import (
 	"os"
 	"os/signal"
 	"path"
+	"sort"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -18,9 +22,15 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/akshay237/backend-with-go/api"
+	"github.com/akshay237/backend-with-go/currency"
 	db "github.com/akshay237/backend-with-go/database/sqlc"
 	"github.com/akshay237/backend-with-go/gapi"
+	"github.com/akshay237/backend-with-go/hold"
+	"github.com/akshay237/backend-with-go/lockout"
 	"github.com/akshay237/backend-with-go/pb"
+	"github.com/akshay237/backend-with-go/revocation"
+	"github.com/akshay237/backend-with-go/schedule"
+	"github.com/akshay237/backend-with-go/token"
 	"github.com/akshay237/backend-with-go/util"
 	"google.golang.org/grpc/reflection"
 )
@@ -59,24 +69,57 @@ func main() {
 	// 2. create the database store using the db connection
 	store := db.NewStore(conn)
 
-	// 3. create an server and start the server
-	errs := make(chan error)
-	ready := make(chan struct{})
-	var httpSrv *http.Server
-	var grpcSrv *grpc.Server
+	// 2.1 run the maintenance command instead of the server if one is given
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(config, store, os.Args[1:]))
+	}
+
+	// 3. create the gRPC server and the HTTP server which serves the gateway and the gin router
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 3.1 load the enabled currencies and keep them refreshed, so newly enabled currencies don't need a redeploy
+	currencies := currency.NewRegistry(store)
+	err = currencies.Refresh(ctx)
+	if err != nil {
+		log.Println("failed to load the currencies, serving the built in currencies:", err)
+	}
+	go currencies.Run(ctx, currencyRefreshInterval(config))
+
+	// 3.2 load the revoked access tokens which are checked on every authenticated request
+	revocations := revocation.NewList(store, config)
+	err = revocations.Refresh(ctx)
+	if err != nil {
+		log.Fatal("failed to load the token revocation list: ", err)
+	}
+	go revocations.Run(ctx, revocationRefreshInterval(config))
+
+	// 3.3 forget the failed logins which no longer delay or lock anyone
+	go lockout.NewGuard(store, config).Run(ctx, time.Hour)
+
+	// 3.4 give the funds of the expired holds back to their accounts
+	go hold.NewSweeper(store).Run(ctx, holdSweepInterval(config))
+
+	// 3.5 run the due scheduled transfers, every replica can run them
+	go schedule.NewScheduler(store, config).Run(ctx, scheduledTransferInterval(config))
 
+	grpcSrv, err := newGRPCServer(config, store, currencies, revocations)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	httpSrv, err := newHTTPServer(ctx, config, store, currencies, revocations)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 3.6 start both the servers
+	errs := make(chan error, 3)
 	go func() {
-		log.Println("Application server is starting")
-		var err error
-		if config.RunGRPC {
-			grpcSrv, err = runGRPCServer(config, store)
-		} else {
-			httpSrv, err = runGinServer(config, store)
-		}
-		close(ready)
-		if err != nil {
-			errs <- err
-		}
+		errs <- runGRPCServer(config, grpcSrv)
+	}()
+	go func() {
+		errs <- runHTTPServer(config, httpSrv)
 	}()
 
 	// 4. Graceful shutdown of the application using os signal and stopfile
@@ -104,67 +147,479 @@ func main() {
 	}
 	atomic.StoreUint32(&stoppedflag, 1)
 
-	select {
-	case <-ready:
-		log.Println("Server is ready to stop")
-	case <-time.After(10 * time.Second):
-		log.Println("Server startup timed out")
-	}
-
-	if config.RunGRPC && grpcSrv != nil {
-		log.Println("Stopping gRPC server")
-		grpcSrv.GracefulStop()
-	} else if !config.RunGRPC && httpSrv != nil {
-		log.Println("Stopping HTTP server")
-		err := httpSrv.Shutdown(context.Background())
-		if err != nil {
-			log.Println("Server Shutdown:", err)
-		}
+	// 5. stop the HTTP server first as the gateway forwards its requests to the gRPC server
+	log.Println("Stopping HTTP server")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	err = httpSrv.Shutdown(shutdownCtx)
+	if err != nil {
+		log.Println("Server Shutdown:", err)
 	}
+	cancel()
+
+	log.Println("Stopping gRPC server")
+	grpcSrv.GracefulStop()
+
 	log.Println("Server is shutdown successfully")
 }
 
-func runGinServer(config util.Config, store db.Store) (*http.Server, error) {
-	handler, err := api.NewServerHandler(config, store)
+// newHTTPServer creates the HTTP server which serves the gRPC gateway under /v1/.
+// During the migration to the gateway the gin router stays mounted for all other paths.
+func newHTTPServer(ctx context.Context, config util.Config, store db.Store, currencies *currency.Registry, revocations *revocation.List) (*http.Server, error) {
+	gatewayHandler, err := gapi.NewGatewayHandler(ctx, config)
 	if err != nil {
-		return nil, fmt.Errorf("cannot create server handler: %v", err)
+		return nil, fmt.Errorf("cannot create gateway handler: %v", err)
 	}
 
-	srv := &http.Server{
-		Addr:    config.HTTPServerAddress,
-		Handler: handler.Router,
+	// the public keys of the token maker let other services verify the access tokens
+	tokenMaker, err := token.NewMaker(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %v", err)
 	}
 
-	log.Println("Starting Gin server on", config.HTTPServerAddress)
+	mux := http.NewServeMux()
+	mux.Handle("/v1/", gatewayHandler)
+	mux.Handle("/.well-known/jwks.json", token.NewJWKSHandler(tokenMaker))
 
-	err = srv.ListenAndServe()
-	if err != nil && err != http.ErrServerClosed {
-		log.Fatalf("listen: %s\n", err)
+	if config.ServeGinRouter {
+		handler, err := api.NewServerHandler(config, store, currencies, revocations)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create server handler: %v", err)
+		}
+		mux.Handle("/", handler.Router)
 	}
 
-	return srv, nil
+	return &http.Server{
+		Addr:    config.HTTPServerAddress,
+		Handler: mux,
+	}, nil
 }
 
-func runGRPCServer(config util.Config, store db.Store) (*grpc.Server, error) {
-	handler, err := gapi.NewServerHandler(config, store)
+func runHTTPServer(config util.Config, srv *http.Server) error {
+	log.Println("Starting HTTP server on", config.HTTPServerAddress)
+
+	err := srv.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("failed to serve HTTP server: %v", err)
+	}
+	return nil
+}
+
+func newGRPCServer(config util.Config, store db.Store, currencies *currency.Registry, revocations *revocation.List) (*grpc.Server, error) {
+	handler, err := gapi.NewServerHandler(config, store, currencies, revocations)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create gRPC server handler: %v", err)
 	}
 
-	gRPCServer := grpc.NewServer()
+	gRPCServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(handler.UnaryAuthInterceptor()),
+		grpc.ChainStreamInterceptor(handler.StreamAuthInterceptor()),
+	)
 	pb.RegisterSimpleBankServer(gRPCServer, handler)
 	reflection.Register(gRPCServer)
 
+	return gRPCServer, nil
+}
+
+func runGRPCServer(config util.Config, srv *grpc.Server) error {
 	lis, err := net.Listen("tcp", config.GRPCServerAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to listen on %s: %v", config.GRPCServerAddress, err)
+		return fmt.Errorf("failed to listen on %s: %v", config.GRPCServerAddress, err)
 	}
 
 	log.Println("Starting gRPC server on", config.GRPCServerAddress)
-	err = gRPCServer.Serve(lis)
+	err = srv.Serve(lis)
 	if err != nil {
-		log.Fatalf("failed to serve gRPC server: %v", err)
+		return fmt.Errorf("failed to serve gRPC server: %v", err)
+	}
+	return nil
+}
+
+// runCommand runs a maintenance sub command and returns the exit code of the process.
+func runCommand(config util.Config, store db.Store, args []string) int {
+	if len(args) == 2 && args[0] == "ledger" && args[1] == "verify" {
+		return runLedgerVerify(store)
+	}
+	if len(args) > 1 && args[0] == "currency" {
+		return runCurrencyCommand(store, args[1:])
+	}
+	if len(args) > 1 && args[0] == "velocity" {
+		return runVelocityCommand(store, args[1:])
+	}
+	if len(args) == 4 && args[0] == "token" && args[1] == "keygen" {
+		return runTokenKeygen(config, args[2], args[3])
+	}
+	if len(args) == 4 && args[0] == "user" && args[1] == "role" {
+		return runUserRole(store, args[2], args[3])
+	}
+	if len(args) == 4 && args[0] == "user" && args[1] == "tier" {
+		return runUserTier(store, args[2], args[3])
+	}
+	if len(args) == 3 && args[0] == "user" && args[1] == "unlock" {
+		return runUserUnlock(config, store, args[2])
 	}
 
-	return gRPCServer, nil
+	log.Printf("unknown command %q, usage:\n"+
+		"  %[2]s ledger verify\n"+
+		"  %[2]s currency list\n"+
+		"  %[2]s currency add CODE NUMERIC_CODE EXPONENT SYMBOL\n"+
+		"  %[2]s currency enable|disable CODE\n"+
+		"  %[2]s velocity list\n"+
+		"  %[2]s velocity set TIER CURRENCY MAX_AMOUNT|- MAX_DAILY_AMOUNT|- MAX_HOURLY_COUNT|-\n"+
+		"  %[2]s velocity remove TIER CURRENCY\n"+
+		"  %[2]s user role USERNAME depositor|admin|service\n"+
+		"  %[2]s user tier USERNAME TIER\n"+
+		"  %[2]s user unlock USERNAME\n"+
+		"  %[2]s token keygen KID EdDSA|ES256", strings.Join(args, " "), os.Args[0])
+	return 2
+}
+
+// currencyRefreshInterval returns how often the running servers reload the enabled currencies
+func currencyRefreshInterval(config util.Config) time.Duration {
+	if config.CurrencyRefresh > 0 {
+		return config.CurrencyRefresh
+	}
+	return time.Minute
+}
+
+// revocationRefreshInterval returns how often the revocation list picks up tokens revoked by other servers
+func revocationRefreshInterval(config util.Config) time.Duration {
+	if config.RevocationRefresh > 0 {
+		return config.RevocationRefresh
+	}
+	return 10 * time.Second
+}
+
+// holdSweepInterval returns how often the expired holds are released
+func holdSweepInterval(config util.Config) time.Duration {
+	if config.HoldSweepInterval > 0 {
+		return config.HoldSweepInterval
+	}
+	return hold.DefaultSweepInterval
+}
+
+// scheduledTransferInterval returns how often the due scheduled transfers are run
+func scheduledTransferInterval(config util.Config) time.Duration {
+	if config.ScheduledTransferInterval > 0 {
+		return config.ScheduledTransferInterval
+	}
+	return schedule.DefaultInterval
+}
+
+// runCurrencyCommand lets admins manage the currencies accounts can be opened in.
+// The running servers pick up the change on their next currency refresh. A currency is added disabled
+// and can only be enabled once every tier has velocity limits in it, the transfers without limits are rejected.
+func runCurrencyCommand(store db.Store, args []string) int {
+	ctx := context.Background()
+
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		currencies, err := store.ListCurrencies(ctx)
+		if err != nil {
+			log.Println("failed to list the currencies:", err)
+			return 1
+		}
+		for _, c := range currencies {
+			fmt.Printf("%s %03d exponent %d symbol %s enabled %t\n", c.Code, c.NumericCode, c.Exponent, c.Symbol, c.Enabled)
+		}
+		return 0
+
+	case len(args) == 5 && args[0] == "add":
+		numericCode, err := strconv.ParseInt(args[2], 10, 32)
+		if err != nil {
+			log.Printf("invalid numeric code %q: %v", args[2], err)
+			return 2
+		}
+		exponent, err := strconv.ParseInt(args[3], 10, 16)
+		if err != nil {
+			log.Printf("invalid exponent %q: %v", args[3], err)
+			return 2
+		}
+		c, err := store.CreateCurrency(ctx, db.CreateCurrencyParams{
+			Code:        strings.ToUpper(args[1]),
+			NumericCode: int32(numericCode),
+			Exponent:    int16(exponent),
+			Symbol:      args[4],
+			Enabled:     false,
+		})
+		if err != nil {
+			log.Println("failed to add the currency:", err)
+			return 1
+		}
+		fmt.Printf("currency %s added disabled, set its velocity limits for every tier with: velocity set, then enable it\n", c.Code)
+		return 0
+
+	case len(args) == 2 && (args[0] == "enable" || args[0] == "disable"):
+		if args[0] == "enable" {
+			missing, err := missingVelocityTiers(ctx, store, strings.ToUpper(args[1]))
+			if err != nil {
+				log.Println("failed to list the velocity limits:", err)
+				return 1
+			}
+			if len(missing) > 0 {
+				log.Printf("currency %s has no velocity limits for the tiers %s, set them first with: velocity set",
+					strings.ToUpper(args[1]), strings.Join(missing, ", "))
+				return 2
+			}
+		}
+
+		c, err := store.SetCurrencyEnabled(ctx, db.SetCurrencyEnabledParams{
+			Code:    strings.ToUpper(args[1]),
+			Enabled: args[0] == "enable",
+		})
+		if err != nil {
+			log.Printf("failed to %s the currency %s: %v", args[0], args[1], err)
+			return 1
+		}
+		fmt.Printf("currency %s enabled %t\n", c.Code, c.Enabled)
+		return 0
+	}
+
+	log.Printf("unknown currency command %q", strings.Join(args, " "))
+	return 2
+}
+
+// missingVelocityTiers returns the tiers without velocity limits in the currency, their transfers in it would be rejected
+func missingVelocityTiers(ctx context.Context, store db.Store, code string) ([]string, error) {
+	limits, err := store.ListVelocityLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configured := make(map[string]bool)
+	for _, limit := range limits {
+		configured[limit.Tier] = configured[limit.Tier] || limit.Currency == code
+	}
+
+	var missing []string
+	for tier, ok := range configured {
+		if !ok {
+			missing = append(missing, tier)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// runVelocityCommand lets admins manage the velocity limits of the tiers in each currency. The transfers
+// of a tier in a currency without limits are rejected, so the limits of a newly added currency must be set
+// before it can be used. A "-" cap is no limit. The transfers are checked against the limits right away.
+func runVelocityCommand(store db.Store, args []string) int {
+	ctx := context.Background()
+
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		limits, err := store.ListVelocityLimits(ctx)
+		if err != nil {
+			log.Println("failed to list the velocity limits:", err)
+			return 1
+		}
+		for _, limit := range limits {
+			fmt.Printf("%s %s max amount %s max daily amount %s max hourly count %s\n", limit.Tier, limit.Currency,
+				formatVelocityCap(limit.MaxAmount.Int64, limit.MaxAmount.Valid),
+				formatVelocityCap(limit.MaxDailyAmount.Int64, limit.MaxDailyAmount.Valid),
+				formatVelocityCap(int64(limit.MaxHourlyCount.Int32), limit.MaxHourlyCount.Valid))
+		}
+		return 0
+
+	case len(args) == 6 && args[0] == "set":
+		maxAmount, err := parseVelocityCap(args[3], 64)
+		if err != nil {
+			log.Printf("invalid max amount %q: %v", args[3], err)
+			return 2
+		}
+		maxDailyAmount, err := parseVelocityCap(args[4], 64)
+		if err != nil {
+			log.Printf("invalid max daily amount %q: %v", args[4], err)
+			return 2
+		}
+		maxHourlyCount, err := parseVelocityCap(args[5], 32)
+		if err != nil {
+			log.Printf("invalid max hourly count %q: %v", args[5], err)
+			return 2
+		}
+
+		limit, err := store.UpsertVelocityLimit(ctx, db.UpsertVelocityLimitParams{
+			Tier:           args[1],
+			Currency:       strings.ToUpper(args[2]),
+			MaxAmount:      maxAmount,
+			MaxDailyAmount: maxDailyAmount,
+			MaxHourlyCount: sql.NullInt32{Int32: int32(maxHourlyCount.Int64), Valid: maxHourlyCount.Valid},
+		})
+		if err != nil {
+			log.Println("failed to set the velocity limit:", err)
+			return 1
+		}
+		fmt.Printf("velocity limit of the tier %s in %s set\n", limit.Tier, limit.Currency)
+		return 0
+
+	case len(args) == 3 && args[0] == "remove":
+		limit, err := store.DeleteVelocityLimit(ctx, db.DeleteVelocityLimitParams{
+			Tier:     args[1],
+			Currency: strings.ToUpper(args[2]),
+		})
+		if err != nil {
+			log.Printf("failed to remove the velocity limit of the tier %s in %s: %v", args[1], args[2], err)
+			return 1
+		}
+		fmt.Printf("velocity limit of the tier %s in %s removed, its transfers in %[2]s are rejected\n", limit.Tier, limit.Currency)
+		return 0
+	}
+
+	log.Printf("unknown velocity command %q", strings.Join(args, " "))
+	return 2
+}
+
+// parseVelocityCap parses a positive cap of a velocity limit, "-" is no cap
+func parseVelocityCap(arg string, bitSize int) (sql.NullInt64, error) {
+	if arg == "-" {
+		return sql.NullInt64{}, nil
+	}
+
+	value, err := strconv.ParseInt(arg, 10, bitSize)
+	if err != nil {
+		return sql.NullInt64{}, err
+	}
+	if value <= 0 {
+		return sql.NullInt64{}, errors.New("must be positive")
+	}
+	return sql.NullInt64{Int64: value, Valid: true}, nil
+}
+
+// formatVelocityCap prints a cap of a velocity limit, "-" is no cap
+func formatVelocityCap(value int64, valid bool) string {
+	if !valid {
+		return "-"
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+// runUserTier moves a user to another tier of velocity limits, it applies to the next transfer.
+// A tier without any limits is refused, it would reject all the transfers of the user.
+func runUserTier(store db.Store, username string, tier string) int {
+	ctx := context.Background()
+
+	limits, err := store.ListVelocityLimits(ctx)
+	if err != nil {
+		log.Println("failed to list the velocity limits:", err)
+		return 1
+	}
+	configured := false
+	for _, limit := range limits {
+		configured = configured || limit.Tier == tier
+	}
+	if !configured {
+		log.Printf("tier %q has no velocity limits, set them first with: velocity set", tier)
+		return 2
+	}
+
+	user, err := store.UpdateUserTier(ctx, db.UpdateUserTierParams{
+		Username: username,
+		Tier:     tier,
+	})
+	if err != nil {
+		log.Printf("failed to change the tier of the user %s: %v", username, err)
+		return 1
+	}
+
+	fmt.Printf("user %s has the tier %s\n", user.Username, user.Tier)
+	return 0
+}
+
+// runUserRole changes the role of a user, it is how the first admin is created.
+// The new role is applied when the user logs in or renews the access token.
+func runUserRole(store db.Store, username string, role string) int {
+	if !util.IsSupportedRole(role) {
+		log.Printf("unsupported role %q", role)
+		return 2
+	}
+
+	user, err := store.UpdateUserRole(context.Background(), db.UpdateUserRoleParams{
+		Username: username,
+		Role:     role,
+	})
+	if err != nil {
+		log.Printf("failed to change the role of the user %s: %v", username, err)
+		return 1
+	}
+
+	fmt.Printf("user %s has the role %s\n", user.Username, user.Role)
+	return 0
+}
+
+// runUserUnlock removes the login lockout of a user, the next login is not delayed
+func runUserUnlock(config util.Config, store db.Store, username string) int {
+	unlocked, err := lockout.NewGuard(store, config).Unlock(context.Background(), username)
+	if err != nil {
+		log.Printf("failed to unlock the user %s: %v", username, err)
+		return 1
+	}
+
+	if !unlocked {
+		fmt.Printf("user %s has no failed logins\n", username)
+		return 0
+	}
+	fmt.Printf("user %s is unlocked\n", username)
+	return 0
+}
+
+// runTokenKeygen adds a new signing key to the keys directory. The key only verifies tokens
+// until TOKEN_ACTIVE_KEY_ID is switched to it, see token.KeySet for the rotation procedure.
+func runTokenKeygen(config util.Config, kid string, algorithm string) int {
+	key, err := token.GenerateSigningKey(kid, algorithm)
+	if err != nil {
+		log.Println("failed to generate the signing key:", err)
+		return 2
+	}
+
+	err = os.MkdirAll(config.TokenKeysDir, 0700)
+	if err != nil {
+		log.Println("failed to create the keys directory:", err)
+		return 1
+	}
+
+	path, err := token.WriteSigningKey(config.TokenKeysDir, key)
+	if err != nil {
+		log.Println("failed to write the signing key:", err)
+		return 1
+	}
+
+	fmt.Printf("%s key %s written to %s\n", key.Algorithm, key.ID, path)
+	return 0
+}
+
+// runLedgerVerify prints every ledger invariant violation and fails if the ledger is not balanced.
+func runLedgerVerify(store db.Store) int {
+	report, err := store.VerifyLedger(context.Background())
+	if err != nil {
+		log.Println("failed to verify the ledger:", err)
+		return 1
+	}
+
+	for _, drift := range report.AccountDrifts {
+		fmt.Printf("account %d (%s, %s): balance %d does not match entries sum %d\n",
+			drift.ID, drift.Owner, drift.Currency, drift.Balance, drift.EntriesSum)
+	}
+	for _, transfer := range report.UnbalancedTransfers {
+		fmt.Printf("transfer %d (%d -> %d, amount %d -> %d): %d legs debited %d and credited %d\n",
+			transfer.ID, transfer.FromAccountID, transfer.ToAccountID, transfer.Amount, transfer.ToAmount,
+			transfer.Legs, transfer.Debited, transfer.Credited)
+	}
+	for _, drift := range report.AvailableBalanceDrifts {
+		fmt.Printf("account %d (%s, %s): available balance %d does not match balance %d less held amount %d\n",
+			drift.ID, drift.Owner, drift.Currency, drift.AvailableBalance, drift.Balance, drift.HeldAmount)
+	}
+	for _, drift := range report.ReversalDrifts {
+		fmt.Printf("transfer %d (amount %d, %s): reversed amount %d does not match refunds sum %d\n",
+			drift.ID, drift.Amount, drift.Status, drift.ReversedAmount, drift.Refunded)
+	}
+
+	if !report.IsBalanced() {
+		fmt.Printf("ledger is not balanced: %d account drifts, %d unbalanced transfers, %d available balance drifts, %d reversal drifts\n",
+			len(report.AccountDrifts), len(report.UnbalancedTransfers), len(report.AvailableBalanceDrifts), len(report.ReversalDrifts))
+		return 1
+	}
+
+	fmt.Println("ledger is balanced")
+	return 0
 }
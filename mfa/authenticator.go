@@ -0,0 +1,275 @@
+package mfa
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/google/uuid"
+)
+
+// Issuer is shown by the authenticator apps next to the account name
+const Issuer = "Simple Bank"
+
+// MaxChallengeAttempts is the number of codes which can be tried with one MFA challenge
+const MaxChallengeAttempts = 5
+
+// DefaultChallengeDuration is used when the MFA challenge duration is not configured
+const DefaultChallengeDuration = 5 * time.Minute
+
+var (
+	ErrAlreadyEnabled     = errors.New("two factor authentication is already enabled")
+	ErrNotEnabled         = errors.New("two factor authentication is not enabled")
+	ErrInvalidCode        = errors.New("invalid two factor authentication code")
+	ErrChallengeNotUsable = errors.New("MFA challenge is expired, already used or has too many attempts")
+)
+
+// Store is the part of the database store used for the second factor
+type Store interface {
+	GetUserTOTP(ctx context.Context, username string) (db.UserTotp, error)
+	UpsertUserTOTP(ctx context.Context, arg db.UpsertUserTOTPParams) (db.UserTotp, error)
+	UseTOTPStep(ctx context.Context, arg db.UseTOTPStepParams) (db.UserTotp, error)
+	UseRecoveryCode(ctx context.Context, arg db.UseRecoveryCodeParams) (db.RecoveryCode, error)
+	EnableTOTPTx(ctx context.Context, arg db.EnableTOTPTxParams) (db.UserTotp, error)
+	DisableTOTPTx(ctx context.Context, username string) error
+	CreateMFAChallenge(ctx context.Context, arg db.CreateMFAChallengeParams) (db.MfaChallenge, error)
+	GetMFAChallenge(ctx context.Context, id uuid.UUID) (db.MfaChallenge, error)
+	AttemptMFAChallenge(ctx context.Context, id uuid.UUID) (db.MfaChallenge, error)
+	ConsumeMFAChallenge(ctx context.Context, id uuid.UUID) (db.MfaChallenge, error)
+}
+
+// Enrollment is the pending TOTP secret shown to the user once
+type Enrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// Authenticator manages the TOTP second factor of the users and the MFA challenges of the two step login
+type Authenticator struct {
+	store             Store
+	cipher            *Cipher
+	challengeDuration time.Duration
+}
+
+// NewAuthenticator creates an authenticator storing the TOTP secrets encrypted with the key
+func NewAuthenticator(store Store, encryptionKey string, challengeDuration time.Duration) (*Authenticator, error) {
+	cipher, err := NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	if challengeDuration <= 0 {
+		challengeDuration = DefaultChallengeDuration
+	}
+
+	return &Authenticator{
+		store:             store,
+		cipher:            cipher,
+		challengeDuration: challengeDuration,
+	}, nil
+}
+
+// Enroll stores a new pending TOTP secret for the user, it is enabled by Confirm.
+// Enrolling again before the confirmation replaces the pending secret.
+func (a *Authenticator) Enroll(ctx context.Context, username string) (Enrollment, error) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		return Enrollment{}, err
+	}
+
+	encrypted, err := a.cipher.Encrypt(secret, username)
+	if err != nil {
+		return Enrollment{}, err
+	}
+
+	// the upsert doesn't touch an enabled secret
+	_, err = a.store.UpsertUserTOTP(ctx, db.UpsertUserTOTPParams{
+		Username:        username,
+		EncryptedSecret: encrypted,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Enrollment{}, ErrAlreadyEnabled
+		}
+		return Enrollment{}, err
+	}
+
+	return Enrollment{
+		Secret:          EncodeSecret(secret),
+		ProvisioningURI: ProvisioningURI(Issuer, username, secret),
+	}, nil
+}
+
+// Confirm enables the pending secret if the code is valid and returns the new recovery codes,
+// they are stored hashed so they can be shown only once.
+func (a *Authenticator) Confirm(ctx context.Context, username string, code string) ([]string, error) {
+	totp, err := a.store.GetUserTOTP(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotEnabled
+		}
+		return nil, err
+	}
+	if totp.EnabledAt.Valid {
+		return nil, ErrAlreadyEnabled
+	}
+
+	err = a.verifyTOTP(ctx, totp, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = HashRecoveryCode(code)
+	}
+
+	_, err = a.store.EnableTOTPTx(ctx, db.EnableTOTPTxParams{
+		Username:           username,
+		RecoveryCodeHashes: hashes,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAlreadyEnabled
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable removes the second factor of the user, it requires a valid TOTP or recovery code
+func (a *Authenticator) Disable(ctx context.Context, username string, code string) error {
+	err := a.Verify(ctx, username, code)
+	if err != nil {
+		return err
+	}
+	return a.store.DisableTOTPTx(ctx, username)
+}
+
+// IsEnabled reports whether the user has to pass the second factor at login
+func (a *Authenticator) IsEnabled(ctx context.Context, username string) (bool, error) {
+	totp, err := a.store.GetUserTOTP(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return totp.EnabledAt.Valid, nil
+}
+
+// Verify checks a TOTP code or a recovery code of a user with an enabled second factor.
+// Both kinds of code are accepted only once.
+func (a *Authenticator) Verify(ctx context.Context, username string, code string) error {
+	totp, err := a.store.GetUserTOTP(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotEnabled
+		}
+		return err
+	}
+	if !totp.EnabledAt.Valid {
+		return ErrNotEnabled
+	}
+
+	if IsTOTPCode(code) {
+		return a.verifyTOTP(ctx, totp, code)
+	}
+
+	_, err = a.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		Username: username,
+		CodeHash: HashRecoveryCode(code),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidCode
+		}
+		return err
+	}
+	return nil
+}
+
+// verifyTOTP checks the code against the secret and marks its time step as used
+func (a *Authenticator) verifyTOTP(ctx context.Context, totp db.UserTotp, code string) error {
+	secret, err := a.cipher.Decrypt(totp.EncryptedSecret, totp.Username)
+	if err != nil {
+		return err
+	}
+
+	step, ok := Validate(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidCode
+	}
+
+	// the step is updated only if it is after the last used one, so a replayed code is rejected
+	_, err = a.store.UseTOTPStep(ctx, db.UseTOTPStepParams{
+		Username: totp.Username,
+		Step:     step,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidCode
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateChallenge starts the second step of a login after the password was checked
+func (a *Authenticator) CreateChallenge(ctx context.Context, username string) (db.MfaChallenge, error) {
+	return a.store.CreateMFAChallenge(ctx, db.CreateMFAChallengeParams{
+		ID:        uuid.New(),
+		Username:  username,
+		ExpiresAt: time.Now().Add(a.challengeDuration),
+	})
+}
+
+// ChallengeUsername returns the user of the challenge, so the attempts can be throttled before the code is checked
+func (a *Authenticator) ChallengeUsername(ctx context.Context, challengeID uuid.UUID) (string, error) {
+	challenge, err := a.store.GetMFAChallenge(ctx, challengeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrChallengeNotUsable
+		}
+		return "", err
+	}
+	return challenge.Username, nil
+}
+
+// VerifyChallenge checks the code for the challenge and returns the user who passed both steps.
+// A challenge can be completed once and allows MaxChallengeAttempts codes.
+func (a *Authenticator) VerifyChallenge(ctx context.Context, challengeID uuid.UUID, code string) (string, error) {
+
+	// 1. count the attempt first so the codes can't be guessed with many concurrent requests
+	challenge, err := a.store.AttemptMFAChallenge(ctx, challengeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrChallengeNotUsable
+		}
+		return "", err
+	}
+	if challenge.ConsumedAt.Valid || time.Now().After(challenge.ExpiresAt) || challenge.Attempts > MaxChallengeAttempts {
+		return "", ErrChallengeNotUsable
+	}
+
+	// 2. check the code
+	err = a.Verify(ctx, challenge.Username, code)
+	if err != nil {
+		return "", err
+	}
+
+	// 3. complete the challenge, only one of the concurrent requests with valid codes wins
+	_, err = a.store.ConsumeMFAChallenge(ctx, challengeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrChallengeNotUsable
+		}
+		return "", err
+	}
+	return challenge.Username, nil
+}
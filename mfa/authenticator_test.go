@@ -0,0 +1,286 @@
+package mfa
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuthenticator(t *testing.T, store Store) *Authenticator {
+	authenticator, err := NewAuthenticator(store, util.RandomString(32), time.Minute)
+	require.NoError(t, err)
+	return authenticator
+}
+
+// newEnabledTOTP returns the stored TOTP of a user who confirmed the enrollment
+func newEnabledTOTP(t *testing.T, authenticator *Authenticator, username string) (db.UserTotp, []byte) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	encrypted, err := authenticator.cipher.Encrypt(secret, username)
+	require.NoError(t, err)
+
+	return db.UserTotp{
+		Username:        username,
+		EncryptedSecret: encrypted,
+		EnabledAt:       sql.NullTime{Time: time.Now(), Valid: true},
+	}, secret
+}
+
+func TestEnrollAndConfirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	authenticator := newTestAuthenticator(t, store)
+	username := util.RandomOwner()
+
+	// 1. enroll stores the encrypted secret
+	var stored db.UserTotp
+	store.EXPECT().UpsertUserTOTP(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.UpsertUserTOTPParams) (db.UserTotp, error) {
+			require.Equal(t, username, arg.Username)
+			stored = db.UserTotp{Username: arg.Username, EncryptedSecret: arg.EncryptedSecret}
+			return stored, nil
+		})
+
+	enrollment, err := authenticator.Enroll(context.Background(), username)
+	require.NoError(t, err)
+	require.NotEmpty(t, enrollment.Secret)
+	require.Contains(t, enrollment.ProvisioningURI, enrollment.Secret)
+	require.NotContains(t, string(stored.EncryptedSecret), enrollment.Secret)
+
+	secret, err := authenticator.cipher.Decrypt(stored.EncryptedSecret, username)
+	require.NoError(t, err)
+	require.Equal(t, enrollment.Secret, EncodeSecret(secret))
+
+	// 2. a wrong code doesn't enable the secret
+	store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(username)).Times(2).Return(stored, nil)
+	code := Code(secret, Step(time.Now()))
+	wrongCode := "000000"
+	if wrongCode == code {
+		wrongCode = "111111"
+	}
+	_, err = authenticator.Confirm(context.Background(), username, wrongCode)
+	require.ErrorIs(t, err, ErrInvalidCode)
+
+	// 3. the code of the authenticator app enables the secret and creates the recovery codes
+	store.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any()).Times(1).Return(stored, nil)
+	store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.EnableTOTPTxParams) (db.UserTotp, error) {
+			require.Equal(t, username, arg.Username)
+			require.Len(t, arg.RecoveryCodeHashes, RecoveryCodeCount)
+			return stored, nil
+		})
+
+	codes, err := authenticator.Confirm(context.Background(), username, code)
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+}
+
+func TestEnrollAlreadyEnabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	authenticator := newTestAuthenticator(t, store)
+
+	// the upsert doesn't update an enabled secret
+	store.EXPECT().UpsertUserTOTP(gomock.Any(), gomock.Any()).Times(1).Return(db.UserTotp{}, sql.ErrNoRows)
+
+	_, err := authenticator.Enroll(context.Background(), util.RandomOwner())
+	require.ErrorIs(t, err, ErrAlreadyEnabled)
+}
+
+func TestVerifyChallenge(t *testing.T) {
+	username := util.RandomOwner()
+	challengeID := uuid.New()
+	challenge := db.MfaChallenge{
+		ID:        challengeID,
+		Username:  username,
+		Attempts:  1,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	testcases := []struct {
+		name       string
+		code       func(secret []byte) string
+		buildStubs func(store *mockdb.MockStore, totp db.UserTotp)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "TOTPCode",
+			code: func(secret []byte) string {
+				return Code(secret, Step(time.Now()))
+			},
+			buildStubs: func(store *mockdb.MockStore, totp db.UserTotp) {
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challengeID)).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(username)).Times(1).Return(totp, nil)
+				store.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UseTOTPStepParams) (db.UserTotp, error) {
+						require.Equal(t, username, arg.Username)
+						require.InDelta(t, Step(time.Now()), arg.Step, 1)
+						return totp, nil
+					})
+				store.EXPECT().ConsumeMFAChallenge(gomock.Any(), gomock.Eq(challengeID)).Times(1).Return(challenge, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			code: func(secret []byte) string {
+				return "abcde-fghij"
+			},
+			buildStubs: func(store *mockdb.MockStore, totp db.UserTotp) {
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challengeID)).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(username)).Times(1).Return(totp, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Eq(db.UseRecoveryCodeParams{
+					Username: username,
+					CodeHash: HashRecoveryCode("abcde-fghij"),
+				})).Times(1).Return(db.RecoveryCode{}, nil)
+				store.EXPECT().ConsumeMFAChallenge(gomock.Any(), gomock.Eq(challengeID)).Times(1).Return(challenge, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			code: func(secret []byte) string {
+				return "abcde-fghij"
+			},
+			buildStubs: func(store *mockdb.MockStore, totp db.UserTotp) {
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challengeID)).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(username)).Times(1).Return(totp, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RecoveryCode{}, sql.ErrNoRows)
+				store.EXPECT().ConsumeMFAChallenge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidCode)
+			},
+		},
+		{
+			name: "ReplayedTOTPCode",
+			code: func(secret []byte) string {
+				return Code(secret, Step(time.Now()))
+			},
+			buildStubs: func(store *mockdb.MockStore, totp db.UserTotp) {
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challengeID)).Times(1).Return(challenge, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Eq(username)).Times(1).Return(totp, nil)
+				store.EXPECT().UseTOTPStep(gomock.Any(), gomock.Any()).Times(1).Return(db.UserTotp{}, sql.ErrNoRows)
+				store.EXPECT().ConsumeMFAChallenge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidCode)
+			},
+		},
+		{
+			name: "ExpiredChallenge",
+			code: func(secret []byte) string {
+				return Code(secret, Step(time.Now()))
+			},
+			buildStubs: func(store *mockdb.MockStore, totp db.UserTotp) {
+				expired := challenge
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challengeID)).Times(1).Return(expired, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrChallengeNotUsable)
+			},
+		},
+		{
+			name: "TooManyAttempts",
+			code: func(secret []byte) string {
+				return Code(secret, Step(time.Now()))
+			},
+			buildStubs: func(store *mockdb.MockStore, totp db.UserTotp) {
+				attempted := challenge
+				attempted.Attempts = MaxChallengeAttempts + 1
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challengeID)).Times(1).Return(attempted, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrChallengeNotUsable)
+			},
+		},
+		{
+			name: "ConsumedChallenge",
+			code: func(secret []byte) string {
+				return Code(secret, Step(time.Now()))
+			},
+			buildStubs: func(store *mockdb.MockStore, totp db.UserTotp) {
+				consumed := challenge
+				consumed.ConsumedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challengeID)).Times(1).Return(consumed, nil)
+				store.EXPECT().GetUserTOTP(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrChallengeNotUsable)
+			},
+		},
+		{
+			name: "UnknownChallenge",
+			code: func(secret []byte) string {
+				return Code(secret, Step(time.Now()))
+			},
+			buildStubs: func(store *mockdb.MockStore, totp db.UserTotp) {
+				store.EXPECT().AttemptMFAChallenge(gomock.Any(), gomock.Eq(challengeID)).Times(1).Return(db.MfaChallenge{}, sql.ErrNoRows)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrChallengeNotUsable)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			authenticator := newTestAuthenticator(t, store)
+			totp, secret := newEnabledTOTP(t, authenticator, username)
+			tc.buildStubs(store, totp)
+
+			verified, err := authenticator.VerifyChallenge(context.Background(), challengeID, tc.code(secret))
+			tc.checkError(t, err)
+			if err == nil {
+				require.Equal(t, username, verified)
+			}
+		})
+	}
+}
+
+func TestChallengeUsername(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	authenticator := newTestAuthenticator(t, store)
+
+	username := util.RandomOwner()
+	challengeID := uuid.New()
+	store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Eq(challengeID)).Times(1).
+		Return(db.MfaChallenge{ID: challengeID, Username: username}, nil)
+
+	challengeUsername, err := authenticator.ChallengeUsername(context.Background(), challengeID)
+	require.NoError(t, err)
+	require.Equal(t, username, challengeUsername)
+
+	// an unknown challenge is not usable
+	store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Any()).Times(1).Return(db.MfaChallenge{}, sql.ErrNoRows)
+
+	_, err = authenticator.ChallengeUsername(context.Background(), uuid.New())
+	require.ErrorIs(t, err, ErrChallengeNotUsable)
+}
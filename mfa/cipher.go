@@ -0,0 +1,54 @@
+package mfa
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var ErrInvalidCiphertext = errors.New("invalid encrypted secret")
+
+// Cipher encrypts the TOTP secrets before they are stored, a database dump alone doesn't reveal them
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a XChaCha20-Poly1305 cipher from the encryption key
+func NewCipher(key string) (*Cipher, error) {
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d characters", chacha20poly1305.KeySize)
+	}
+
+	aead, err := chacha20poly1305.NewX([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt seals the secret, the username is authenticated so a secret can't be moved to another user
+func (c *Cipher) Encrypt(secret []byte, username string) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(secret)+c.aead.Overhead())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, secret, []byte(username)), nil
+}
+
+// Decrypt opens a secret sealed by Encrypt for the same username
+func (c *Cipher) Decrypt(ciphertext []byte, username string) ([]byte, error) {
+	if len(ciphertext) < c.aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, sealed := ciphertext[:c.aead.NonceSize()], ciphertext[c.aead.NonceSize():]
+	secret, err := c.aead.Open(nil, nonce, sealed, []byte(username))
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return secret, nil
+}
@@ -0,0 +1,40 @@
+package mfa
+
+import (
+	"testing"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher(t *testing.T) {
+	cipher, err := NewCipher(util.RandomString(32))
+	require.NoError(t, err)
+
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	encrypted, err := cipher.Encrypt(secret, "alice")
+	require.NoError(t, err)
+	require.NotContains(t, string(encrypted), string(secret))
+
+	decrypted, err := cipher.Decrypt(encrypted, "alice")
+	require.NoError(t, err)
+	require.Equal(t, secret, decrypted)
+
+	// the secret is bound to the user
+	_, err = cipher.Decrypt(encrypted, "bob")
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	// a secret can't be opened with another key
+	otherCipher, err := NewCipher(util.RandomString(32))
+	require.NoError(t, err)
+	_, err = otherCipher.Decrypt(encrypted, "alice")
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = cipher.Decrypt([]byte("short"), "alice")
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = NewCipher("short")
+	require.Error(t, err)
+}
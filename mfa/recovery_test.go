@@ -0,0 +1,36 @@
+package mfa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		require.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, code)
+		require.False(t, IsTOTPCode(code))
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	hash := HashRecoveryCode("abcde-fghij")
+	require.Len(t, hash, 64)
+	require.Equal(t, hash, HashRecoveryCode("ABCDE FGHIJ"))
+	require.Equal(t, hash, HashRecoveryCode("abcdefghij"))
+	require.NotEqual(t, hash, HashRecoveryCode("abcde-fghik"))
+}
+
+func TestIsTOTPCode(t *testing.T) {
+	require.True(t, IsTOTPCode("012345"))
+	require.False(t, IsTOTPCode("01234"))
+	require.False(t, IsTOTPCode("01234a"))
+	require.False(t, IsTOTPCode("0123456"))
+}
@@ -0,0 +1,65 @@
+package mfa
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCode(t *testing.T) {
+	// test vectors of RFC 6238 for SHA1, truncated to 6 digits
+	secret := []byte("12345678901234567890")
+	testcases := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+
+	for _, tc := range testcases {
+		require.Equal(t, tc.code, Code(secret, Step(time.Unix(tc.unix, 0))))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, SecretSize)
+
+	now := time.Now()
+	current := Step(now)
+
+	// the codes of the previous and the next step are accepted for clock drift
+	for _, step := range []int64{current - 1, current, current + 1} {
+		matched, ok := Validate(secret, Code(secret, step), now)
+		require.True(t, ok)
+		require.Equal(t, step, matched)
+	}
+
+	_, ok := Validate(secret, Code(secret, current-2), now)
+	require.False(t, ok)
+	_, ok = Validate(secret, Code(secret, current+2), now)
+	require.False(t, ok)
+	_, ok = Validate(secret, "12345", now)
+	require.False(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	uri, err := url.Parse(ProvisioningURI(Issuer, "alice", secret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/Simple Bank:alice", uri.Path)
+	require.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", uri.Query().Get("secret"))
+	require.Equal(t, Issuer, uri.Query().Get("issuer"))
+	require.Equal(t, "6", uri.Query().Get("digits"))
+	require.Equal(t, "30", uri.Query().Get("period"))
+}
@@ -0,0 +1,204 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+)
+
+// CodeChallengeS256 is the only PKCE method supported, the plain method would send the verifier in the clear
+const CodeChallengeS256 = "S256"
+
+// AuthorizeRequest is an authorization request of a client, made on behalf of an authenticated user
+type AuthorizeRequest struct {
+	Username string
+	// UserScopes are the scopes of the token of the user, a user can't grant more than the token has
+	UserScopes          []string
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// Authorization is a valid authorization request the user is asked to consent to
+type Authorization struct {
+	Client      db.OauthClient
+	RedirectURI string
+	Scopes      []string
+	// ConsentRequired is false when the user already granted the scopes to the client
+	ConsentRequired bool
+}
+
+// CheckAuthorization validates the authorization request. The errors are returned to the user
+// instead of the client, since the client and its redirect uri may not be trusted.
+func (s *Service) CheckAuthorization(ctx context.Context, req AuthorizeRequest) (Authorization, error) {
+	// 1. the client must be allowed to use the authorization code grant
+	client, err := s.store.GetOAuthClient(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Authorization{}, newError(ErrorInvalidRequest, "unknown client")
+		}
+		return Authorization{}, err
+	}
+	if client.RevokedAt.Valid {
+		return Authorization{}, newError(ErrorInvalidRequest, "client is revoked")
+	}
+	if !slices.Contains(client.GrantTypes, GrantAuthorizationCode) {
+		return Authorization{}, newError(ErrorUnauthorizedClient, "client can't use the authorization code grant")
+	}
+
+	// 2. the redirect uri must be registered, it can be left out if the client has only one
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectUris) == 1 {
+		redirectURI = client.RedirectUris[0]
+	}
+	if !slices.Contains(client.RedirectUris, redirectURI) {
+		return Authorization{}, newError(ErrorInvalidRequest, "redirect_uri is not registered for the client")
+	}
+
+	// 3. check the response type and PKCE, which is required for every client
+	if req.ResponseType != "code" {
+		return Authorization{}, newError(ErrorUnsupportedResponseType, "response_type must be code")
+	}
+	if req.CodeChallengeMethod != CodeChallengeS256 {
+		return Authorization{}, newError(ErrorInvalidRequest, "code_challenge_method must be S256")
+	}
+	if !isPKCEValue(req.CodeChallenge) {
+		return Authorization{}, newError(ErrorInvalidRequest, "invalid code_challenge")
+	}
+
+	// 4. the scopes default to the scopes of the client, the user can't grant more than the token of the user has
+	scopes, err := token.NarrowScopes(client.Scopes, strings.Fields(req.Scope))
+	if err != nil {
+		return Authorization{}, newError(ErrorInvalidScope, err.Error())
+	}
+	if _, err := token.NarrowScopes(req.UserScopes, scopes); err != nil {
+		return Authorization{}, newError(ErrorInvalidScope, err.Error())
+	}
+
+	// 5. the consent is required again for scopes the user didn't grant yet
+	consentRequired := true
+	consent, err := s.store.GetOAuthConsent(ctx, db.GetOAuthConsentParams{
+		Username: req.Username,
+		ClientID: client.ID,
+	})
+	if err == nil {
+		_, err := token.NarrowScopes(consent.Scopes, scopes)
+		consentRequired = err != nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return Authorization{}, err
+	}
+
+	return Authorization{
+		Client:          client,
+		RedirectURI:     redirectURI,
+		Scopes:          scopes,
+		ConsentRequired: consentRequired,
+	}, nil
+}
+
+// Authorize records the decision of the user and returns the redirect uri the user agent is sent back to
+// the client with. It carries an authorization code if the user approved, the access_denied error otherwise.
+func (s *Service) Authorize(ctx context.Context, req AuthorizeRequest, approved bool) (string, error) {
+	authorization, err := s.CheckAuthorization(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	if !approved {
+		return redirectWith(authorization.RedirectURI, url.Values{
+			"error": {ErrorAccessDenied},
+			"state": {req.State},
+		})
+	}
+
+	// 1. record the consent
+	_, err = s.store.UpsertOAuthConsent(ctx, db.UpsertOAuthConsentParams{
+		Username: req.Username,
+		ClientID: authorization.Client.ID,
+		Scopes:   authorization.Scopes,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// 2. create the single use code, only its hash is stored
+	code, err := randomString(32)
+	if err != nil {
+		return "", err
+	}
+	_, err = s.store.CreateOAuthAuthorizationCode(ctx, db.CreateOAuthAuthorizationCodeParams{
+		CodeHash:            hashSecret(code),
+		ClientID:            authorization.Client.ID,
+		Username:            req.Username,
+		RedirectUri:         authorization.RedirectURI,
+		Scopes:              authorization.Scopes,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		ExpiresAt:           time.Now().Add(s.codeDuration),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return redirectWith(authorization.RedirectURI, url.Values{
+		"code":  {code},
+		"state": {req.State},
+	})
+}
+
+// redirectWith adds the parameters to the query of the redirect uri, the empty ones are left out
+func redirectWith(redirectURI string, params url.Values) (string, error) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// isPKCEValue reports whether the code verifier or challenge has between 43 and 128 unreserved characters
+func isPKCEValue(value string) bool {
+	if len(value) < 43 || len(value) > 128 {
+		return false
+	}
+	for _, c := range value {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '.', c == '_', c == '~':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// verifyPKCE checks the code verifier against the S256 challenge of the authorization request
+func verifyPKCE(challenge string, verifier string) bool {
+	if !isPKCEValue(verifier) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
@@ -0,0 +1,213 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+)
+
+// ClientSecretPrefix starts every client secret, so leaked secrets are easy to spot like the api keys
+const ClientSecretPrefix = "sbs_"
+
+var (
+	ErrInvalidClientMetadata = errors.New("invalid client metadata")
+	ErrClientNotFound        = errors.New("oauth client not found")
+)
+
+// RegisterClientParams describes a new client
+type RegisterClientParams struct {
+	Owner        string
+	Name         string
+	RedirectURIs []string
+	// Scopes are the most a token of the client can have, they must be known scopes
+	Scopes []string
+	// GrantTypes default to the authorization code and the refresh token grants
+	GrantTypes []string
+	// Confidential clients get a secret, public clients such as mobile apps have none and must use PKCE
+	Confidential bool
+}
+
+// RegisterClient stores a new client of the owner and returns its secret, it can't be recovered later.
+// Public clients have no secret.
+func (s *Service) RegisterClient(ctx context.Context, arg RegisterClientParams) (string, db.OauthClient, error) {
+	// 1. validate the client metadata
+	grantTypes := arg.GrantTypes
+	if len(grantTypes) == 0 {
+		grantTypes = []string{GrantAuthorizationCode, GrantRefreshToken}
+	}
+	err := validateClientMetadata(arg, grantTypes)
+	if err != nil {
+		return "", db.OauthClient{}, err
+	}
+
+	// 2. create the id and the secret of a confidential client
+	id, err := randomString(16)
+	if err != nil {
+		return "", db.OauthClient{}, err
+	}
+
+	var secret string
+	var secretHash sql.NullString
+	if arg.Confidential {
+		secret, err = randomString(32)
+		if err != nil {
+			return "", db.OauthClient{}, err
+		}
+		secret = ClientSecretPrefix + secret
+		secretHash = sql.NullString{String: hashSecret(secret), Valid: true}
+	}
+
+	client, err := s.store.CreateOAuthClient(ctx, db.CreateOAuthClientParams{
+		ID:           id,
+		Owner:        arg.Owner,
+		Name:         arg.Name,
+		SecretHash:   secretHash,
+		RedirectUris: append([]string{}, arg.RedirectURIs...),
+		Scopes:       append([]string{}, arg.Scopes...),
+		GrantTypes:   grantTypes,
+	})
+	if err != nil {
+		return "", db.OauthClient{}, err
+	}
+	return secret, client, nil
+}
+
+// validateClientMetadata returns an error wrapping ErrInvalidClientMetadata if the client can't be registered
+func validateClientMetadata(arg RegisterClientParams, grantTypes []string) error {
+	if len(arg.Scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidClientMetadata)
+	}
+	for _, scope := range arg.Scopes {
+		if !token.IsSupportedScope(scope) {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidClientMetadata, scope)
+		}
+	}
+
+	for _, grantType := range grantTypes {
+		switch grantType {
+		case GrantAuthorizationCode, GrantRefreshToken:
+		case GrantClientCredentials:
+			if !arg.Confidential {
+				return fmt.Errorf("%w: a public client can't use the client credentials grant", ErrInvalidClientMetadata)
+			}
+		default:
+			return fmt.Errorf("%w: unsupported grant type %q", ErrInvalidClientMetadata, grantType)
+		}
+	}
+
+	if slices.Contains(grantTypes, GrantAuthorizationCode) && len(arg.RedirectURIs) == 0 {
+		return fmt.Errorf("%w: the authorization code grant needs a redirect uri", ErrInvalidClientMetadata)
+	}
+	for _, redirectURI := range arg.RedirectURIs {
+		err := validateRedirectURI(redirectURI)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRedirectURI accepts absolute uris without a fragment. Plain http is allowed only for the loopback
+// address of native apps, the code would be sent in the clear otherwise.
+func validateRedirectURI(redirectURI string) error {
+	u, err := url.Parse(redirectURI)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("%w: redirect uri %q must be absolute", ErrInvalidClientMetadata, redirectURI)
+	}
+	if u.Fragment != "" {
+		return fmt.Errorf("%w: redirect uri %q must not have a fragment", ErrInvalidClientMetadata, redirectURI)
+	}
+	if u.Scheme == "http" {
+		ip := net.ParseIP(u.Hostname())
+		if u.Hostname() != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("%w: redirect uri %q must use https", ErrInvalidClientMetadata, redirectURI)
+		}
+	}
+	return nil
+}
+
+// ListClients returns the clients of the owner which are not revoked
+func (s *Service) ListClients(ctx context.Context, owner string) ([]db.OauthClient, error) {
+	return s.store.ListOAuthClients(ctx, owner)
+}
+
+// RevokeClient revokes a client of the owner, its refresh tokens are blocked and its access tokens revoked.
+// An empty owner revokes the client of any user, a client of another user is reported as not found.
+func (s *Service) RevokeClient(ctx context.Context, id string, owner string) (db.OauthClient, error) {
+	client, err := s.store.GetOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.OauthClient{}, ErrClientNotFound
+		}
+		return db.OauthClient{}, err
+	}
+	if owner != "" && client.Owner != owner {
+		return db.OauthClient{}, ErrClientNotFound
+	}
+
+	client, err = s.store.RevokeOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.OauthClient{}, ErrClientNotFound
+		}
+		return db.OauthClient{}, err
+	}
+
+	sessions, err := s.store.BlockClientSessions(ctx, sql.NullString{String: id, Valid: true})
+	if err != nil {
+		return db.OauthClient{}, err
+	}
+	err = s.revocations.RevokeSessions(ctx, sessions...)
+	if err != nil {
+		return db.OauthClient{}, err
+	}
+
+	// the tokens issued without a refresh token have no session
+	err = s.revocations.RevokeClient(ctx, id)
+	if err != nil {
+		return db.OauthClient{}, err
+	}
+	return client, nil
+}
+
+// AuthenticateClient returns the client of the credentials. A public client has no secret,
+// a confidential client must send the right one.
+func (s *Service) AuthenticateClient(ctx context.Context, clientID string, secret string) (db.OauthClient, error) {
+	if clientID == "" {
+		return db.OauthClient{}, newError(ErrorInvalidClient, "client_id is required")
+	}
+
+	client, err := s.store.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.OauthClient{}, newError(ErrorInvalidClient, "unknown client")
+		}
+		return db.OauthClient{}, err
+	}
+	if client.RevokedAt.Valid {
+		return db.OauthClient{}, newError(ErrorInvalidClient, "client is revoked")
+	}
+
+	if client.SecretHash.Valid {
+		hash := hashSecret(secret)
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash.String)) != 1 {
+			return db.OauthClient{}, newError(ErrorInvalidClient, "wrong client secret")
+		}
+	} else if secret != "" {
+		return db.OauthClient{}, newError(ErrorInvalidClient, "public client has no secret")
+	}
+	return client, nil
+}
+
+// isConfidential reports whether the client authenticated with a secret
+func isConfidential(client db.OauthClient) bool {
+	return client.SecretHash.Valid
+}
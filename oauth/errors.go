@@ -0,0 +1,44 @@
+package oauth
+
+import "net/http"
+
+// Error codes of RFC 6749 and RFC 7009
+const (
+	ErrorInvalidRequest          = "invalid_request"
+	ErrorInvalidClient           = "invalid_client"
+	ErrorInvalidGrant            = "invalid_grant"
+	ErrorUnauthorizedClient      = "unauthorized_client"
+	ErrorUnsupportedGrantType    = "unsupported_grant_type"
+	ErrorUnsupportedResponseType = "unsupported_response_type"
+	ErrorInvalidScope            = "invalid_scope"
+	ErrorAccessDenied            = "access_denied"
+	ErrorServerError             = "server_error"
+)
+
+// Error is an error response of the OAuth endpoints
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+// StatusCode returns the HTTP status of the error, a client which failed to authenticate gets 401
+func (e *Error) StatusCode() int {
+	switch e.Code {
+	case ErrorInvalidClient:
+		return http.StatusUnauthorized
+	case ErrorServerError:
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
+
+func newError(code string, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
@@ -0,0 +1,92 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/revocation"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/google/uuid"
+)
+
+// DefaultCodeDuration is used when the lifetime of the authorization codes is not configured
+const DefaultCodeDuration = time.Minute
+
+// Grant types of the token endpoint
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
+// Store is the part of the database store used by the OAuth server
+type Store interface {
+	GetUser(ctx context.Context, username string) (db.User, error)
+	CreateOAuthClient(ctx context.Context, arg db.CreateOAuthClientParams) (db.OauthClient, error)
+	GetOAuthClient(ctx context.Context, id string) (db.OauthClient, error)
+	ListOAuthClients(ctx context.Context, owner string) ([]db.OauthClient, error)
+	RevokeOAuthClient(ctx context.Context, id string) (db.OauthClient, error)
+	GetOAuthConsent(ctx context.Context, arg db.GetOAuthConsentParams) (db.OauthConsent, error)
+	UpsertOAuthConsent(ctx context.Context, arg db.UpsertOAuthConsentParams) (db.OauthConsent, error)
+	CreateOAuthAuthorizationCode(ctx context.Context, arg db.CreateOAuthAuthorizationCodeParams) (db.OauthAuthorizationCode, error)
+	UseOAuthAuthorizationCode(ctx context.Context, codeHash string) (db.OauthAuthorizationCode, error)
+	CreateSession(ctx context.Context, arg db.CreateSessionParams) (db.Session, error)
+	GetSession(ctx context.Context, id uuid.UUID) (db.Session, error)
+	GetSessionByAccessToken(ctx context.Context, accessTokenID uuid.NullUUID) (db.Session, error)
+	GetAccessToken(ctx context.Context, id uuid.UUID) (db.AccessToken, error)
+	RotateSessionTx(ctx context.Context, arg db.RotateSessionTxParams) (db.RotateSessionTxResult, error)
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) ([]db.Session, error)
+	BlockClientSessions(ctx context.Context, clientID sql.NullString) ([]db.Session, error)
+}
+
+// Service is the OAuth2 authorization server of the third party apps. The access tokens are made
+// by the token maker of the logins, the refresh tokens are sessions of the client.
+type Service struct {
+	store                Store
+	tokenMaker           token.Maker
+	revocations          *revocation.List
+	accessTokenDuration  time.Duration
+	refreshTokenDuration time.Duration
+	codeDuration         time.Duration
+}
+
+// NewService creates the OAuth server with the token durations of the config
+func NewService(store Store, tokenMaker token.Maker, revocations *revocation.List, config util.Config) *Service {
+	codeDuration := config.OAuthCodeDuration
+	if codeDuration <= 0 {
+		codeDuration = DefaultCodeDuration
+	}
+
+	return &Service{
+		store:                store,
+		tokenMaker:           tokenMaker,
+		revocations:          revocations,
+		accessTokenDuration:  config.AccessTokenDuration,
+		refreshTokenDuration: config.RefreshTokenDuration,
+		codeDuration:         codeDuration,
+	}
+}
+
+// randomString returns a random url safe string of n bytes
+func randomString(n int) (string, error) {
+	raw := make([]byte, n)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashSecret returns the hash stored for a client secret or an authorization code.
+// They are random, so a fast hash is enough.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,698 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/revocation"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirectURI = "https://partner.example.com/callback"
+
+func newTestService(t *testing.T, store *mockdb.MockStore) (*Service, token.Maker) {
+	tokenMaker, err := token.NewPasteoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	config := util.Config{
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}
+	service := NewService(store, tokenMaker, revocation.NewList(store, config), config)
+	return service, tokenMaker
+}
+
+func randomClient(owner string, confidential bool) (string, db.OauthClient) {
+	client := db.OauthClient{
+		ID:           util.RandomString(16),
+		Owner:        owner,
+		Name:         "partner app",
+		RedirectUris: []string{testRedirectURI},
+		Scopes:       []string{token.ScopeAccountsRead, token.ScopeTransfersRead},
+		GrantTypes:   []string{GrantAuthorizationCode, GrantRefreshToken},
+		CreatedAt:    time.Now(),
+	}
+
+	var secret string
+	if confidential {
+		secret = ClientSecretPrefix + util.RandomString(32)
+		client.SecretHash = sql.NullString{String: hashSecret(secret), Valid: true}
+		client.GrantTypes = append(client.GrantTypes, GrantClientCredentials)
+	}
+	return secret, client
+}
+
+func pkcePair() (string, string) {
+	verifier := util.RandomString(64)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestRegisterClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service, _ := newTestService(t, store)
+	owner := util.RandomOwner()
+
+	// 1. a confidential client gets a secret, only its hash is stored
+	var stored db.CreateOAuthClientParams
+	store.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateOAuthClientParams) (db.OauthClient, error) {
+			stored = arg
+			return db.OauthClient{ID: arg.ID, Owner: arg.Owner, SecretHash: arg.SecretHash, GrantTypes: arg.GrantTypes}, nil
+		})
+
+	secret, client, err := service.RegisterClient(context.Background(), RegisterClientParams{
+		Owner:        owner,
+		Name:         "partner app",
+		RedirectURIs: []string{testRedirectURI, "http://127.0.0.1:8080/callback"},
+		Scopes:       []string{token.ScopeAccountsRead},
+		Confidential: true,
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, ClientSecretPrefix))
+	require.Equal(t, hashSecret(secret), stored.SecretHash.String)
+	require.Equal(t, []string{GrantAuthorizationCode, GrantRefreshToken}, stored.GrantTypes)
+	require.Equal(t, owner, client.Owner)
+	require.NotEmpty(t, client.ID)
+
+	// 2. a public client has no secret
+	store.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateOAuthClientParams) (db.OauthClient, error) {
+			require.False(t, arg.SecretHash.Valid)
+			return db.OauthClient{ID: arg.ID}, nil
+		})
+
+	secret, _, err = service.RegisterClient(context.Background(), RegisterClientParams{
+		Owner:        owner,
+		Name:         "mobile app",
+		RedirectURIs: []string{"com.partner.app://callback"},
+		Scopes:       []string{token.ScopeAccountsRead},
+	})
+	require.NoError(t, err)
+	require.Empty(t, secret)
+
+	// 3. invalid metadata is rejected before anything is stored
+	testCases := []struct {
+		name string
+		arg  RegisterClientParams
+	}{
+		{
+			name: "NoScopes",
+			arg:  RegisterClientParams{RedirectURIs: []string{testRedirectURI}},
+		},
+		{
+			name: "UnknownScope",
+			arg:  RegisterClientParams{RedirectURIs: []string{testRedirectURI}, Scopes: []string{"admin"}},
+		},
+		{
+			name: "PublicClientCredentials",
+			arg:  RegisterClientParams{Scopes: []string{token.ScopeAccountsRead}, GrantTypes: []string{GrantClientCredentials}},
+		},
+		{
+			name: "UnsupportedGrantType",
+			arg:  RegisterClientParams{Scopes: []string{token.ScopeAccountsRead}, GrantTypes: []string{"password"}, Confidential: true},
+		},
+		{
+			name: "NoRedirectURI",
+			arg:  RegisterClientParams{Scopes: []string{token.ScopeAccountsRead}},
+		},
+		{
+			name: "RelativeRedirectURI",
+			arg:  RegisterClientParams{RedirectURIs: []string{"/callback"}, Scopes: []string{token.ScopeAccountsRead}},
+		},
+		{
+			name: "RedirectURIWithFragment",
+			arg:  RegisterClientParams{RedirectURIs: []string{testRedirectURI + "#done"}, Scopes: []string{token.ScopeAccountsRead}},
+		},
+		{
+			name: "PlainHTTPRedirectURI",
+			arg:  RegisterClientParams{RedirectURIs: []string{"http://partner.example.com/callback"}, Scopes: []string{token.ScopeAccountsRead}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := service.RegisterClient(context.Background(), tc.arg)
+			require.ErrorIs(t, err, ErrInvalidClientMetadata)
+		})
+	}
+}
+
+func TestAuthenticateClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service, _ := newTestService(t, store)
+	secret, confidential := randomClient(util.RandomOwner(), true)
+	_, public := randomClient(util.RandomOwner(), false)
+	_, revoked := randomClient(util.RandomOwner(), false)
+	revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	store.EXPECT().GetOAuthClient(gomock.Any(), confidential.ID).AnyTimes().Return(confidential, nil)
+	store.EXPECT().GetOAuthClient(gomock.Any(), public.ID).AnyTimes().Return(public, nil)
+	store.EXPECT().GetOAuthClient(gomock.Any(), revoked.ID).AnyTimes().Return(revoked, nil)
+	store.EXPECT().GetOAuthClient(gomock.Any(), "unknown").AnyTimes().Return(db.OauthClient{}, sql.ErrNoRows)
+
+	client, err := service.AuthenticateClient(context.Background(), confidential.ID, secret)
+	require.NoError(t, err)
+	require.Equal(t, confidential.ID, client.ID)
+
+	client, err = service.AuthenticateClient(context.Background(), public.ID, "")
+	require.NoError(t, err)
+	require.Equal(t, public.ID, client.ID)
+
+	for _, credentials := range [][2]string{
+		{"", ""},
+		{"unknown", ""},
+		{confidential.ID, ""},
+		{confidential.ID, secret + "x"},
+		{public.ID, "secret"},
+		{revoked.ID, ""},
+	} {
+		_, err := service.AuthenticateClient(context.Background(), credentials[0], credentials[1])
+		var oauthErr *Error
+		require.ErrorAs(t, err, &oauthErr)
+		require.Equal(t, ErrorInvalidClient, oauthErr.Code)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service, _ := newTestService(t, store)
+	username := util.RandomOwner()
+	_, client := randomClient(util.RandomOwner(), false)
+	_, challenge := pkcePair()
+
+	store.EXPECT().GetOAuthClient(gomock.Any(), client.ID).AnyTimes().Return(client, nil)
+
+	req := AuthorizeRequest{
+		Username:            username,
+		ResponseType:        "code",
+		ClientID:            client.ID,
+		Scope:               token.ScopeAccountsRead,
+		State:               "xyz",
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: CodeChallengeS256,
+	}
+
+	// 1. the consent is required the first time, the redirect uri defaults to the only one
+	store.EXPECT().GetOAuthConsent(gomock.Any(), gomock.Any()).Times(1).Return(db.OauthConsent{}, sql.ErrNoRows)
+	authorization, err := service.CheckAuthorization(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, authorization.ConsentRequired)
+	require.Equal(t, testRedirectURI, authorization.RedirectURI)
+	require.Equal(t, []string{token.ScopeAccountsRead}, authorization.Scopes)
+
+	// 2. an approval records the consent and redirects with a code of which only the hash is stored
+	store.EXPECT().GetOAuthConsent(gomock.Any(), gomock.Any()).Times(1).Return(db.OauthConsent{}, sql.ErrNoRows)
+	store.EXPECT().UpsertOAuthConsent(gomock.Any(), db.UpsertOAuthConsentParams{
+		Username: username,
+		ClientID: client.ID,
+		Scopes:   []string{token.ScopeAccountsRead},
+	}).Times(1).Return(db.OauthConsent{}, nil)
+
+	var stored db.CreateOAuthAuthorizationCodeParams
+	store.EXPECT().CreateOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateOAuthAuthorizationCodeParams) (db.OauthAuthorizationCode, error) {
+			stored = arg
+			return db.OauthAuthorizationCode{}, nil
+		})
+
+	redirectURI, err := service.Authorize(context.Background(), req, true)
+	require.NoError(t, err)
+	redirect, err := url.Parse(redirectURI)
+	require.NoError(t, err)
+	require.Equal(t, "xyz", redirect.Query().Get("state"))
+	require.Equal(t, hashSecret(redirect.Query().Get("code")), stored.CodeHash)
+	require.Equal(t, challenge, stored.CodeChallenge)
+	require.WithinDuration(t, time.Now().Add(DefaultCodeDuration), stored.ExpiresAt, time.Second)
+
+	// 3. the consent is not asked again for the scopes already granted
+	store.EXPECT().GetOAuthConsent(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.OauthConsent{Scopes: []string{token.ScopeAccountsRead, token.ScopeTransfersRead}}, nil)
+	authorization, err = service.CheckAuthorization(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, authorization.ConsentRequired)
+
+	// 4. a denial redirects with the access_denied error
+	store.EXPECT().GetOAuthConsent(gomock.Any(), gomock.Any()).Times(1).Return(db.OauthConsent{}, sql.ErrNoRows)
+	redirectURI, err = service.Authorize(context.Background(), req, false)
+	require.NoError(t, err)
+	redirect, err = url.Parse(redirectURI)
+	require.NoError(t, err)
+	require.Equal(t, ErrorAccessDenied, redirect.Query().Get("error"))
+	require.Empty(t, redirect.Query().Get("code"))
+
+	// 5. invalid requests are reported to the user
+	testCases := []struct {
+		name   string
+		update func(req *AuthorizeRequest)
+		code   string
+	}{
+		{
+			name:   "UnregisteredRedirectURI",
+			update: func(req *AuthorizeRequest) { req.RedirectURI = "https://evil.example.com/callback" },
+			code:   ErrorInvalidRequest,
+		},
+		{
+			name:   "UnsupportedResponseType",
+			update: func(req *AuthorizeRequest) { req.ResponseType = "token" },
+			code:   ErrorUnsupportedResponseType,
+		},
+		{
+			name:   "PlainChallenge",
+			update: func(req *AuthorizeRequest) { req.CodeChallengeMethod = "plain" },
+			code:   ErrorInvalidRequest,
+		},
+		{
+			name:   "MissingChallenge",
+			update: func(req *AuthorizeRequest) { req.CodeChallenge = "" },
+			code:   ErrorInvalidRequest,
+		},
+		{
+			name:   "ScopeOfClientNotGranted",
+			update: func(req *AuthorizeRequest) { req.Scope = token.ScopeTransfersWrite },
+			code:   ErrorInvalidScope,
+		},
+		{
+			name: "ScopeOfUserNotGranted",
+			update: func(req *AuthorizeRequest) {
+				req.UserScopes = []string{token.ScopeTransfersRead}
+			},
+			code: ErrorInvalidScope,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			invalid := req
+			tc.update(&invalid)
+
+			_, err := service.CheckAuthorization(context.Background(), invalid)
+			var oauthErr *Error
+			require.ErrorAs(t, err, &oauthErr)
+			require.Equal(t, tc.code, oauthErr.Code)
+		})
+	}
+}
+
+func TestTokenAuthorizationCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service, tokenMaker := newTestService(t, store)
+	user := db.User{Username: util.RandomOwner(), Role: util.DepositorRole}
+	_, client := randomClient(util.RandomOwner(), false)
+	verifier, challenge := pkcePair()
+	code := util.RandomString(32)
+
+	authorizationCode := db.OauthAuthorizationCode{
+		CodeHash:            hashSecret(code),
+		ClientID:            client.ID,
+		Username:            user.Username,
+		RedirectUri:         testRedirectURI,
+		Scopes:              []string{token.ScopeAccountsRead},
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: CodeChallengeS256,
+	}
+	req := TokenRequest{
+		GrantType:    GrantAuthorizationCode,
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: verifier,
+	}
+
+	// 1. the code is exchanged for an access token and a refresh token stored as a session of the client
+	store.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), hashSecret(code)).Times(1).Return(authorizationCode, nil)
+	store.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(user, nil)
+
+	var session db.CreateSessionParams
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+			session = arg
+			return db.Session{}, nil
+		})
+
+	response, err := service.Token(context.Background(), client, req)
+	require.NoError(t, err)
+	require.Equal(t, "Bearer", response.TokenType)
+	require.Equal(t, token.ScopeAccountsRead, response.Scope)
+	require.Equal(t, int64(60), response.ExpiresIn)
+	require.Equal(t, client.ID, session.ClientID.String)
+	require.Equal(t, response.RefreshToken, session.RefreshToken)
+
+	payload, err := tokenMaker.VerifyToken(response.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, payload.Username)
+	require.Equal(t, []string{token.ScopeAccountsRead}, payload.Scopes)
+	require.Equal(t, payload.ID, session.AccessTokenID.UUID)
+
+	// 2. the code can't be redeemed without the right verifier, redirect uri or client
+	_, otherClient := randomClient(client.Owner, false)
+	otherVerifier, _ := pkcePair()
+	testCases := []struct {
+		name   string
+		client db.OauthClient
+		update func(req *TokenRequest)
+	}{
+		{
+			name:   "WrongVerifier",
+			client: client,
+			update: func(req *TokenRequest) { req.CodeVerifier = otherVerifier },
+		},
+		{
+			name:   "WrongRedirectURI",
+			client: client,
+			update: func(req *TokenRequest) { req.RedirectURI = "https://partner.example.com/other" },
+		},
+		{
+			name:   "OtherClient",
+			client: otherClient,
+			update: func(req *TokenRequest) {},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(1).Return(authorizationCode, nil)
+
+			invalid := req
+			tc.update(&invalid)
+			_, err := service.Token(context.Background(), tc.client, invalid)
+			var oauthErr *Error
+			require.ErrorAs(t, err, &oauthErr)
+			require.Equal(t, ErrorInvalidGrant, oauthErr.Code)
+		})
+	}
+
+	// 3. a code used already or expired is not found
+	store.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Times(1).Return(db.OauthAuthorizationCode{}, sql.ErrNoRows)
+	_, err = service.Token(context.Background(), client, req)
+	var oauthErr *Error
+	require.ErrorAs(t, err, &oauthErr)
+	require.Equal(t, ErrorInvalidGrant, oauthErr.Code)
+
+	// 4. a grant the client wasn't registered for
+	_, err = service.Token(context.Background(), client, TokenRequest{GrantType: GrantClientCredentials})
+	require.ErrorAs(t, err, &oauthErr)
+	require.Equal(t, ErrorUnauthorizedClient, oauthErr.Code)
+
+	_, err = service.Token(context.Background(), client, TokenRequest{GrantType: "password"})
+	require.ErrorAs(t, err, &oauthErr)
+	require.Equal(t, ErrorUnsupportedGrantType, oauthErr.Code)
+
+	// 5. without the refresh grant there is no session, the access token is recorded for the client
+	client.GrantTypes = []string{GrantAuthorizationCode}
+	store.EXPECT().UseOAuthAuthorizationCode(gomock.Any(), hashSecret(code)).Times(1).Return(authorizationCode, nil)
+	store.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(user, nil)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+
+	var issued db.CreateAccessTokenParams
+	store.EXPECT().CreateAccessToken(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAccessTokenParams) (db.AccessToken, error) {
+			issued = arg
+			return db.AccessToken{ID: arg.ID, Username: arg.Username, ClientID: arg.ClientID, ExpiresAt: arg.ExpiresAt}, nil
+		})
+
+	response, err = service.Token(context.Background(), client, req)
+	require.NoError(t, err)
+	require.Empty(t, response.RefreshToken)
+
+	payload, err = tokenMaker.VerifyToken(response.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, issued.ID)
+	require.Equal(t, sql.NullString{String: client.ID, Valid: true}, issued.ClientID)
+}
+
+func TestTokenRefreshToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service, tokenMaker := newTestService(t, store)
+	user := db.User{Username: util.RandomOwner(), Role: util.DepositorRole}
+	_, client := randomClient(util.RandomOwner(), false)
+	scopes := []string{token.ScopeAccountsRead, token.ScopeTransfersRead}
+
+	refreshToken, refreshPayload, err := tokenMaker.CreateToken(user.Username, user.Role, scopes, token.TokenTypeRefresh, time.Hour)
+	require.NoError(t, err)
+	session := db.Session{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    refreshPayload.ExpiredAT,
+		FamilyID:     refreshPayload.ID,
+		ClientID:     sql.NullString{String: client.ID, Valid: true},
+	}
+
+	// 1. the session is rotated, the access token can ask for fewer scopes
+	store.EXPECT().GetSession(gomock.Any(), session.ID).AnyTimes().Return(session, nil)
+	store.EXPECT().GetUser(gomock.Any(), user.Username).AnyTimes().Return(user, nil)
+	store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RotateSessionTxParams) (db.RotateSessionTxResult, error) {
+			require.Equal(t, session.ID, arg.SessionID)
+			return db.RotateSessionTxResult{}, nil
+		})
+
+	response, err := service.Token(context.Background(), client, TokenRequest{
+		GrantType:    GrantRefreshToken,
+		RefreshToken: refreshToken,
+		Scope:        token.ScopeAccountsRead,
+	})
+	require.NoError(t, err)
+	require.Equal(t, token.ScopeAccountsRead, response.Scope)
+
+	newRefreshPayload, err := tokenMaker.VerifyToken(response.RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, scopes, newRefreshPayload.Scopes)
+
+	// 2. the refresh token of another client
+	_, otherClient := randomClient(client.Owner, false)
+	_, err = service.Token(context.Background(), otherClient, TokenRequest{
+		GrantType:    GrantRefreshToken,
+		RefreshToken: refreshToken,
+	})
+	var oauthErr *Error
+	require.ErrorAs(t, err, &oauthErr)
+	require.Equal(t, ErrorInvalidGrant, oauthErr.Code)
+
+	// 3. a reused refresh token blocks the grant and revokes its access tokens
+	accessTokenID := uuid.New()
+	store.EXPECT().RotateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.RotateSessionTxResult{BlockedSessions: []db.Session{{
+			Username:             user.Username,
+			AccessTokenID:        uuid.NullUUID{UUID: accessTokenID, Valid: true},
+			AccessTokenExpiresAt: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+		}}}, db.ErrRefreshTokenReused)
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+	store.EXPECT().ListSessionAccessTokens(gomock.Any(), gomock.Any()).Times(1).Return([]db.AccessToken{}, nil)
+
+	_, err = service.Token(context.Background(), client, TokenRequest{
+		GrantType:    GrantRefreshToken,
+		RefreshToken: refreshToken,
+	})
+	require.ErrorAs(t, err, &oauthErr)
+	require.Equal(t, ErrorInvalidGrant, oauthErr.Code)
+	require.True(t, service.revocations.IsRevoked(accessTokenID))
+}
+
+func TestTokenClientCredentials(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service, tokenMaker := newTestService(t, store)
+	owner := db.User{Username: util.RandomOwner(), Role: util.DepositorRole}
+	_, client := randomClient(owner.Username, true)
+
+	// 1. the token belongs to the owner and has the scopes of the client, there is no refresh token.
+	// It is recorded for the client, so revoking the client revokes it.
+	var issued db.CreateAccessTokenParams
+	store.EXPECT().GetUser(gomock.Any(), owner.Username).Times(1).Return(owner, nil)
+	store.EXPECT().CreateAccessToken(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAccessTokenParams) (db.AccessToken, error) {
+			issued = arg
+			return db.AccessToken{ID: arg.ID, Username: arg.Username, ClientID: arg.ClientID, ExpiresAt: arg.ExpiresAt}, nil
+		})
+	response, err := service.Token(context.Background(), client, TokenRequest{GrantType: GrantClientCredentials})
+	require.NoError(t, err)
+	require.Empty(t, response.RefreshToken)
+
+	payload, err := tokenMaker.VerifyToken(response.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, owner.Username, payload.Username)
+	require.Equal(t, client.Scopes, payload.Scopes)
+	require.Equal(t, payload.ID, issued.ID)
+	require.Equal(t, sql.NullString{String: client.ID, Valid: true}, issued.ClientID)
+
+	// 2. the scopes can't exceed the scopes of the client
+	_, err = service.Token(context.Background(), client, TokenRequest{
+		GrantType: GrantClientCredentials,
+		Scope:     token.ScopeTransfersWrite,
+	})
+	var oauthErr *Error
+	require.ErrorAs(t, err, &oauthErr)
+	require.Equal(t, ErrorInvalidScope, oauthErr.Code)
+}
+
+func TestIntrospectAndRevoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service, tokenMaker := newTestService(t, store)
+	username := util.RandomOwner()
+	_, client := randomClient(util.RandomOwner(), true)
+	_, public := randomClient(util.RandomOwner(), false)
+
+	accessToken, accessPayload, err := tokenMaker.CreateToken(username, util.DepositorRole, []string{token.ScopeAccountsRead}, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	refreshToken, refreshPayload, err := tokenMaker.CreateToken(username, util.DepositorRole, []string{token.ScopeAccountsRead}, token.TokenTypeRefresh, time.Hour)
+	require.NoError(t, err)
+	session := db.Session{
+		ID:                   refreshPayload.ID,
+		Username:             username,
+		RefreshToken:         refreshToken,
+		ExpiresAt:            refreshPayload.ExpiredAT,
+		FamilyID:             refreshPayload.ID,
+		AccessTokenID:        uuid.NullUUID{UUID: accessPayload.ID, Valid: true},
+		AccessTokenExpiresAt: sql.NullTime{Time: accessPayload.ExpiredAT, Valid: true},
+		ClientID:             sql.NullString{String: client.ID, Valid: true},
+	}
+	store.EXPECT().GetSessionByAccessToken(gomock.Any(), session.AccessTokenID).AnyTimes().Return(session, nil)
+	store.EXPECT().GetSession(gomock.Any(), refreshPayload.ID).AnyTimes().Return(session, nil)
+
+	// a client credentials token of another client and a token of a login session
+	otherToken, otherPayload, err := tokenMaker.CreateToken(username, util.DepositorRole, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	store.EXPECT().GetSessionByAccessToken(gomock.Any(), uuid.NullUUID{UUID: otherPayload.ID, Valid: true}).AnyTimes().Return(db.Session{}, sql.ErrNoRows)
+	store.EXPECT().GetAccessToken(gomock.Any(), otherPayload.ID).AnyTimes().Return(db.AccessToken{
+		ID:        otherPayload.ID,
+		Username:  username,
+		ClientID:  sql.NullString{String: public.ID, Valid: true},
+		ExpiresAt: otherPayload.ExpiredAT,
+	}, nil)
+
+	loginToken, loginPayload, err := tokenMaker.CreateToken(username, util.DepositorRole, nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	store.EXPECT().GetSessionByAccessToken(gomock.Any(), uuid.NullUUID{UUID: loginPayload.ID, Valid: true}).AnyTimes().Return(db.Session{ID: uuid.New(), Username: username}, nil)
+
+	// 1. both tokens are active, an invalid token is not
+	introspection, err := service.Introspect(context.Background(), client, accessToken)
+	require.NoError(t, err)
+	require.True(t, introspection.Active)
+	require.Equal(t, "Bearer", introspection.TokenType)
+	require.Equal(t, username, introspection.Username)
+	require.Equal(t, token.ScopeAccountsRead, introspection.Scope)
+
+	introspection, err = service.Introspect(context.Background(), client, refreshToken)
+	require.NoError(t, err)
+	require.True(t, introspection.Active)
+	require.Equal(t, client.ID, introspection.ClientID)
+
+	introspection, err = service.Introspect(context.Background(), client, "invalid")
+	require.NoError(t, err)
+	require.Equal(t, Introspection{}, introspection)
+
+	// 2. public clients can't introspect
+	_, err = service.Introspect(context.Background(), public, accessToken)
+	var oauthErr *Error
+	require.ErrorAs(t, err, &oauthErr)
+	require.Equal(t, ErrorUnauthorizedClient, oauthErr.Code)
+
+	// 2.1 the tokens of another client or of a login session are reported as inactive and can't be revoked
+	for _, other := range []string{otherToken, loginToken} {
+		introspection, err = service.Introspect(context.Background(), client, other)
+		require.NoError(t, err)
+		require.Equal(t, Introspection{Active: false}, introspection)
+
+		err = service.Revoke(context.Background(), client, other)
+		require.ErrorAs(t, err, &oauthErr)
+		require.Equal(t, ErrorUnauthorizedClient, oauthErr.Code)
+	}
+
+	// 3. a revoked access token is not active anymore
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+	require.NoError(t, service.Revoke(context.Background(), client, accessToken))
+
+	introspection, err = service.Introspect(context.Background(), client, accessToken)
+	require.NoError(t, err)
+	require.False(t, introspection.Active)
+
+	// 4. a refresh token can't be revoked by another client, its own client blocks the whole grant
+	err = service.Revoke(context.Background(), public, refreshToken)
+	require.ErrorAs(t, err, &oauthErr)
+	require.Equal(t, ErrorUnauthorizedClient, oauthErr.Code)
+
+	store.EXPECT().BlockSessionFamily(gomock.Any(), session.FamilyID).Times(1).Return([]db.Session{session}, nil)
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+	store.EXPECT().ListSessionAccessTokens(gomock.Any(), gomock.Eq([]uuid.UUID{session.ID})).Times(1).Return([]db.AccessToken{}, nil)
+	require.NoError(t, service.Revoke(context.Background(), client, refreshToken))
+
+	// 5. an invalid token is ignored
+	require.NoError(t, service.Revoke(context.Background(), client, "invalid"))
+}
+
+func TestRevokeClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service, _ := newTestService(t, store)
+	_, client := randomClient(util.RandomOwner(), true)
+
+	// the access tokens issued to the client without a session are revoked along with its sessions
+	issued := db.AccessToken{
+		ID:        uuid.New(),
+		Username:  client.Owner,
+		ClientID:  sql.NullString{String: client.ID, Valid: true},
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	store.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+	store.EXPECT().RevokeOAuthClient(gomock.Any(), client.ID).Times(1).Return(client, nil)
+	store.EXPECT().BlockClientSessions(gomock.Any(), issued.ClientID).Times(1).Return([]db.Session{}, nil)
+	store.EXPECT().ListClientAccessTokens(gomock.Any(), issued.ClientID).Times(1).Return([]db.AccessToken{issued}, nil)
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Eq(db.RevokeTokenParams{
+		ID:        issued.ID,
+		Username:  issued.Username,
+		ExpiresAt: issued.ExpiresAt,
+	})).Times(1).Return(nil)
+
+	_, err := service.RevokeClient(context.Background(), client.ID, client.Owner)
+	require.NoError(t, err)
+	require.True(t, service.revocations.IsRevoked(issued.ID))
+}
+
+func TestVerifyPKCE(t *testing.T) {
+	verifier, challenge := pkcePair()
+	require.True(t, verifyPKCE(challenge, verifier))
+	require.False(t, verifyPKCE(challenge, verifier+"a"))
+	require.False(t, verifyPKCE(challenge, ""))
+	require.False(t, verifyPKCE(verifier, verifier))
+
+	require.False(t, isPKCEValue(strings.Repeat("a", 42)))
+	require.True(t, isPKCEValue(strings.Repeat("a", 43)))
+	require.True(t, isPKCEValue(strings.Repeat("a", 128)))
+	require.False(t, isPKCEValue(strings.Repeat("a", 129)))
+	require.False(t, isPKCEValue(strings.Repeat("a", 42)+"+"))
+}
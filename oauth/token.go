@@ -0,0 +1,372 @@
+package oauth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/google/uuid"
+)
+
+// TokenRequest is a request of the token endpoint, the fields used depend on the grant type
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+	UserAgent    string
+	ClientIP     string
+}
+
+// TokenResponse is the successful response of the token endpoint
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// Token issues the tokens of the grant to the authenticated client
+func (s *Service) Token(ctx context.Context, client db.OauthClient, req TokenRequest) (TokenResponse, error) {
+	switch req.GrantType {
+	case GrantAuthorizationCode, GrantRefreshToken, GrantClientCredentials:
+	default:
+		return TokenResponse{}, newError(ErrorUnsupportedGrantType, "")
+	}
+	if !slices.Contains(client.GrantTypes, req.GrantType) {
+		return TokenResponse{}, newError(ErrorUnauthorizedClient, "client can't use the "+req.GrantType+" grant")
+	}
+
+	switch req.GrantType {
+	case GrantAuthorizationCode:
+		return s.exchangeAuthorizationCode(ctx, client, req)
+	case GrantRefreshToken:
+		return s.refresh(ctx, client, req)
+	}
+	return s.clientCredentials(ctx, client, req)
+}
+
+// exchangeAuthorizationCode redeems a code of the client, a code can be redeemed only once
+func (s *Service) exchangeAuthorizationCode(ctx context.Context, client db.OauthClient, req TokenRequest) (TokenResponse, error) {
+	// 1. use the code
+	code, err := s.store.UseOAuthAuthorizationCode(ctx, hashSecret(req.Code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return TokenResponse{}, newError(ErrorInvalidGrant, "authorization code is invalid, expired or already used")
+		}
+		return TokenResponse{}, err
+	}
+
+	// 2. the code must be redeemed by the client it was issued to, with the verifier of its challenge
+	if code.ClientID != client.ID {
+		return TokenResponse{}, newError(ErrorInvalidGrant, "authorization code was issued to another client")
+	}
+	if code.RedirectUri != req.RedirectURI {
+		return TokenResponse{}, newError(ErrorInvalidGrant, "redirect_uri doesn't match the authorization request")
+	}
+	if !verifyPKCE(code.CodeChallenge, req.CodeVerifier) {
+		return TokenResponse{}, newError(ErrorInvalidGrant, "code_verifier doesn't match the code_challenge")
+	}
+
+	// 3. issue the tokens of the user, the refresh token only if the client can use it.
+	// Without a refresh token there is no session, the access token is recorded for the client.
+	user, err := s.store.GetUser(ctx, code.Username)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, code.Scopes, token.TokenTypeAccess, s.accessTokenDuration)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	response := newTokenResponse(accessToken, accessPayload)
+	if !slices.Contains(client.GrantTypes, GrantRefreshToken) {
+		err = s.revocations.IssueToClient(ctx, accessPayload, client.ID)
+		if err != nil {
+			return TokenResponse{}, err
+		}
+		return response, nil
+	}
+
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, code.Scopes, token.TokenTypeRefresh, s.refreshTokenDuration)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	_, err = s.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:                   refreshPayload.ID,
+		Username:             user.Username,
+		RefreshToken:         refreshToken,
+		UserAgent:            req.UserAgent,
+		ClientIp:             req.ClientIP,
+		IsBlocked:            false,
+		ExpiresAt:            refreshPayload.ExpiredAT,
+		FamilyID:             refreshPayload.ID,
+		AccessTokenID:        uuid.NullUUID{UUID: accessPayload.ID, Valid: true},
+		AccessTokenExpiresAt: sql.NullTime{Time: accessPayload.ExpiredAT, Valid: true},
+		ClientID:             sql.NullString{String: client.ID, Valid: true},
+	})
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	response.RefreshToken = refreshToken
+	return response, nil
+}
+
+// refresh exchanges a refresh token of the client like the renewal of a login session. The access token
+// can ask for fewer scopes, the new refresh token keeps the scopes of the grant.
+func (s *Service) refresh(ctx context.Context, client db.OauthClient, req TokenRequest) (TokenResponse, error) {
+	// 1. verify the refresh token and its session
+	refreshPayload, err := s.tokenMaker.VerifyToken(req.RefreshToken)
+	if err != nil || refreshPayload.CheckType(token.TokenTypeRefresh) != nil {
+		return TokenResponse{}, newError(ErrorInvalidGrant, "invalid refresh token")
+	}
+
+	session, err := s.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return TokenResponse{}, newError(ErrorInvalidGrant, "invalid refresh token")
+		}
+		return TokenResponse{}, err
+	}
+	if session.ClientID.String != client.ID || session.RefreshToken != req.RefreshToken {
+		return TokenResponse{}, newError(ErrorInvalidGrant, "refresh token was issued to another client")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return TokenResponse{}, newError(ErrorInvalidGrant, "expired refresh token")
+	}
+
+	scopes, err := token.NarrowScopes(refreshPayload.Scopes, strings.Fields(req.Scope))
+	if err != nil {
+		return TokenResponse{}, newError(ErrorInvalidScope, err.Error())
+	}
+
+	// 2. create the tokens, the role is read again as for the login sessions
+	user, err := s.store.GetUser(ctx, session.Username)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, scopes, token.TokenTypeAccess, s.accessTokenDuration)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	refreshToken, newRefreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, refreshPayload.Scopes, token.TokenTypeRefresh, s.refreshTokenDuration)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	// 3. rotate the session, a reused refresh token blocks the whole grant
+	result, err := s.store.RotateSessionTx(ctx, db.RotateSessionTxParams{
+		SessionID:            session.ID,
+		NewSessionID:         newRefreshPayload.ID,
+		NewRefreshToken:      refreshToken,
+		ExpiresAt:            newRefreshPayload.ExpiredAT,
+		UserAgent:            req.UserAgent,
+		ClientIp:             req.ClientIP,
+		AccessTokenID:        accessPayload.ID,
+		AccessTokenExpiresAt: accessPayload.ExpiredAT,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrRefreshTokenReused):
+			log.Printf("security event: %s for user %s, oauth client %s, session %s", db.SecurityEventTokenReuse, session.Username, client.ID, session.ID)
+			if err := s.revocations.RevokeSessions(ctx, result.BlockedSessions...); err != nil {
+				log.Println("failed to revoke the access tokens of the blocked sessions:", err)
+			}
+			return TokenResponse{}, newError(ErrorInvalidGrant, err.Error())
+		case errors.Is(err, db.ErrSessionBlocked):
+			return TokenResponse{}, newError(ErrorInvalidGrant, err.Error())
+		}
+		return TokenResponse{}, err
+	}
+
+	response := newTokenResponse(accessToken, accessPayload)
+	response.RefreshToken = refreshToken
+	return response, nil
+}
+
+// clientCredentials issues an access token of the owner of a confidential client, limited to the scopes
+// of the client. There is no refresh token, the client asks for a new token with its credentials.
+// The token is recorded for the client, so revoking the client revokes it.
+func (s *Service) clientCredentials(ctx context.Context, client db.OauthClient, req TokenRequest) (TokenResponse, error) {
+	if !isConfidential(client) {
+		return TokenResponse{}, newError(ErrorUnauthorizedClient, "public clients can't use the client credentials grant")
+	}
+
+	scopes, err := token.NarrowScopes(client.Scopes, strings.Fields(req.Scope))
+	if err != nil {
+		return TokenResponse{}, newError(ErrorInvalidScope, err.Error())
+	}
+
+	owner, err := s.store.GetUser(ctx, client.Owner)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(owner.Username, owner.Role, scopes, token.TokenTypeAccess, s.accessTokenDuration)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	err = s.revocations.IssueToClient(ctx, accessPayload, client.ID)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	return newTokenResponse(accessToken, accessPayload), nil
+}
+
+func newTokenResponse(accessToken string, payload *token.Payload) TokenResponse {
+	return TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(payload.ExpiredAT).Round(time.Second).Seconds()),
+		Scope:       strings.Join(payload.Scopes, " "),
+	}
+}
+
+// Introspection is the response of the introspection endpoint, only active tokens have the other fields
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	ID        string `json:"jti,omitempty"`
+}
+
+// Introspect tells a confidential client whether a token issued to it is active. A refresh token is active
+// while its session can be renewed, an access token until it expires or is revoked. A token issued to another
+// client or to a login session is reported as inactive, so a client can't learn about the tokens of others.
+func (s *Service) Introspect(ctx context.Context, client db.OauthClient, tokenString string) (Introspection, error) {
+	if !isConfidential(client) {
+		return Introspection{}, newError(ErrorUnauthorizedClient, "public clients can't introspect tokens")
+	}
+
+	payload, err := s.tokenMaker.VerifyToken(tokenString)
+	if err != nil {
+		return Introspection{Active: false}, nil
+	}
+
+	introspection := Introspection{
+		Active:    true,
+		Scope:     strings.Join(payload.Scopes, " "),
+		ClientID:  client.ID,
+		Username:  payload.Username,
+		ExpiresAt: payload.ExpiredAT.Unix(),
+		IssuedAt:  payload.IssuedAt.Unix(),
+		Subject:   payload.Username,
+		ID:        payload.ID.String(),
+	}
+
+	// the refresh tokens are the only tokens with a session of their own
+	if payload.Type == token.TokenTypeRefresh {
+		session, err := s.store.GetSession(ctx, payload.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return Introspection{Active: false}, nil
+			}
+			return Introspection{}, err
+		}
+
+		if session.ClientID.String != client.ID || session.RefreshToken != tokenString ||
+			session.IsBlocked || session.RotatedAt.Valid || time.Now().After(session.ExpiresAt) {
+			return Introspection{Active: false}, nil
+		}
+		return introspection, nil
+	}
+
+	clientID, err := s.accessTokenClient(ctx, payload.ID)
+	if err != nil {
+		return Introspection{}, err
+	}
+	if clientID != client.ID || s.revocations.IsRevoked(payload.ID) || s.revocations.IssuedBeforePasswordChange(payload) {
+		return Introspection{Active: false}, nil
+	}
+	introspection.TokenType = "Bearer"
+	return introspection, nil
+}
+
+// Revoke revokes a token issued to the client. Revoking a refresh token ends the whole grant
+// along with its access tokens. Invalid tokens are ignored, as the client can't do anything about them.
+func (s *Service) Revoke(ctx context.Context, client db.OauthClient, tokenString string) error {
+	payload, err := s.tokenMaker.VerifyToken(tokenString)
+	if err != nil {
+		return nil
+	}
+
+	if payload.Type != token.TokenTypeRefresh {
+		clientID, err := s.accessTokenClient(ctx, payload.ID)
+		if err != nil {
+			return err
+		}
+		if clientID != client.ID {
+			return newError(ErrorUnauthorizedClient, "token was issued to another client")
+		}
+		return s.revocations.Revoke(ctx, payload.ID, payload.Username, payload.ExpiredAT)
+	}
+
+	session, err := s.store.GetSession(ctx, payload.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if session.RefreshToken != tokenString {
+		return nil
+	}
+	if session.ClientID.String != client.ID {
+		return newError(ErrorUnauthorizedClient, "token was issued to another client")
+	}
+
+	sessions, err := s.store.BlockSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		return err
+	}
+	return s.revocations.RevokeSessions(ctx, sessions...)
+}
+
+// accessTokenClient returns the oauth client an access token was issued to, through the session it was
+// issued with or its record. It is empty for the tokens of a login session or an api key.
+func (s *Service) accessTokenClient(ctx context.Context, tokenID uuid.UUID) (string, error) {
+	session, err := s.store.GetSessionByAccessToken(ctx, uuid.NullUUID{UUID: tokenID, Valid: true})
+	if err == nil {
+		return session.ClientID.String, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	accessToken, err := s.store.GetAccessToken(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	if !accessToken.SessionID.Valid {
+		return accessToken.ClientID.String, nil
+	}
+
+	// a token delegated under a session belongs to the client of the session
+	session, err = s.store.GetSession(ctx, accessToken.SessionID.UUID)
+	if err != nil {
+		return "", err
+	}
+	return session.ClientID.String, nil
+}
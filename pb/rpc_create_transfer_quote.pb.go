@@ -0,0 +1,190 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: rpc_create_transfer_quote.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateTransferQuoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromAccountId int64                  `protobuf:"varint,1,opt,name=from_account_id,json=fromAccountId,proto3" json:"from_account_id,omitempty"`
+	ToAccountId   int64                  `protobuf:"varint,2,opt,name=to_account_id,json=toAccountId,proto3" json:"to_account_id,omitempty"`
+	Amount        int64                  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTransferQuoteRequest) Reset() {
+	*x = CreateTransferQuoteRequest{}
+	mi := &file_rpc_create_transfer_quote_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransferQuoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransferQuoteRequest) ProtoMessage() {}
+
+func (x *CreateTransferQuoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_create_transfer_quote_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransferQuoteRequest.ProtoReflect.Descriptor instead.
+func (*CreateTransferQuoteRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_create_transfer_quote_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateTransferQuoteRequest) GetFromAccountId() int64 {
+	if x != nil {
+		return x.FromAccountId
+	}
+	return 0
+}
+
+func (x *CreateTransferQuoteRequest) GetToAccountId() int64 {
+	if x != nil {
+		return x.ToAccountId
+	}
+	return 0
+}
+
+func (x *CreateTransferQuoteRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type CreateTransferQuoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Quote         *TransferQuote         `protobuf:"bytes,1,opt,name=quote,proto3" json:"quote,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTransferQuoteResponse) Reset() {
+	*x = CreateTransferQuoteResponse{}
+	mi := &file_rpc_create_transfer_quote_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransferQuoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransferQuoteResponse) ProtoMessage() {}
+
+func (x *CreateTransferQuoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_create_transfer_quote_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransferQuoteResponse.ProtoReflect.Descriptor instead.
+func (*CreateTransferQuoteResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_create_transfer_quote_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateTransferQuoteResponse) GetQuote() *TransferQuote {
+	if x != nil {
+		return x.Quote
+	}
+	return nil
+}
+
+var File_rpc_create_transfer_quote_proto protoreflect.FileDescriptor
+
+const file_rpc_create_transfer_quote_proto_rawDesc = "" +
+	"\n" +
+	"\x1frpc_create_transfer_quote.proto\x12\x02pb\x1a\x0etransfer.proto\"\x80\x01\n" +
+	"\x1aCreateTransferQuoteRequest\x12&\n" +
+	"\x0ffrom_account_id\x18\x01 \x01(\x03R\rfromAccountId\x12\"\n" +
+	"\rto_account_id\x18\x02 \x01(\x03R\vtoAccountId\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x03R\x06amount\"F\n" +
+	"\x1bCreateTransferQuoteResponse\x12'\n" +
+	"\x05quote\x18\x01 \x01(\v2\x11.pb.TransferQuoteR\x05quoteB)Z'github.com/akshay237/backend-with-go/pbb\x06proto3"
+
+var (
+	file_rpc_create_transfer_quote_proto_rawDescOnce sync.Once
+	file_rpc_create_transfer_quote_proto_rawDescData []byte
+)
+
+func file_rpc_create_transfer_quote_proto_rawDescGZIP() []byte {
+	file_rpc_create_transfer_quote_proto_rawDescOnce.Do(func() {
+		file_rpc_create_transfer_quote_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_create_transfer_quote_proto_rawDesc), len(file_rpc_create_transfer_quote_proto_rawDesc)))
+	})
+	return file_rpc_create_transfer_quote_proto_rawDescData
+}
+
+var file_rpc_create_transfer_quote_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_create_transfer_quote_proto_goTypes = []any{
+	(*CreateTransferQuoteRequest)(nil),  // 0: pb.CreateTransferQuoteRequest
+	(*CreateTransferQuoteResponse)(nil), // 1: pb.CreateTransferQuoteResponse
+	(*TransferQuote)(nil),               // 2: pb.TransferQuote
+}
+var file_rpc_create_transfer_quote_proto_depIdxs = []int32{
+	2, // 0: pb.CreateTransferQuoteResponse.quote:type_name -> pb.TransferQuote
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rpc_create_transfer_quote_proto_init() }
+func file_rpc_create_transfer_quote_proto_init() {
+	if File_rpc_create_transfer_quote_proto != nil {
+		return
+	}
+	file_transfer_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_create_transfer_quote_proto_rawDesc), len(file_rpc_create_transfer_quote_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_create_transfer_quote_proto_goTypes,
+		DependencyIndexes: file_rpc_create_transfer_quote_proto_depIdxs,
+		MessageInfos:      file_rpc_create_transfer_quote_proto_msgTypes,
+	}.Build()
+	File_rpc_create_transfer_quote_proto = out.File
+	file_rpc_create_transfer_quote_proto_goTypes = nil
+	file_rpc_create_transfer_quote_proto_depIdxs = nil
+}
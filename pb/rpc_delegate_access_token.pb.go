@@ -0,0 +1,200 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: rpc_delegate_access_token.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DelegateAccessTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scopes        []string               `protobuf:"bytes,1,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	ExpiresIn     int64                  `protobuf:"varint,2,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DelegateAccessTokenRequest) Reset() {
+	*x = DelegateAccessTokenRequest{}
+	mi := &file_rpc_delegate_access_token_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DelegateAccessTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DelegateAccessTokenRequest) ProtoMessage() {}
+
+func (x *DelegateAccessTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_delegate_access_token_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DelegateAccessTokenRequest.ProtoReflect.Descriptor instead.
+func (*DelegateAccessTokenRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_delegate_access_token_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DelegateAccessTokenRequest) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *DelegateAccessTokenRequest) GetExpiresIn() int64 {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return 0
+}
+
+type DelegateAccessTokenResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	AccessToken          string                 `protobuf:"bytes,1,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	AccessTokenExpiresAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=access_token_expires_at,json=accessTokenExpiresAt,proto3" json:"access_token_expires_at,omitempty"`
+	Scopes               []string               `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *DelegateAccessTokenResponse) Reset() {
+	*x = DelegateAccessTokenResponse{}
+	mi := &file_rpc_delegate_access_token_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DelegateAccessTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DelegateAccessTokenResponse) ProtoMessage() {}
+
+func (x *DelegateAccessTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_delegate_access_token_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DelegateAccessTokenResponse.ProtoReflect.Descriptor instead.
+func (*DelegateAccessTokenResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_delegate_access_token_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DelegateAccessTokenResponse) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+func (x *DelegateAccessTokenResponse) GetAccessTokenExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AccessTokenExpiresAt
+	}
+	return nil
+}
+
+func (x *DelegateAccessTokenResponse) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+var File_rpc_delegate_access_token_proto protoreflect.FileDescriptor
+
+const file_rpc_delegate_access_token_proto_rawDesc = "" +
+	"\n" +
+	"\x1frpc_delegate_access_token.proto\x12\x02pb\x1a\x1fgoogle/protobuf/timestamp.proto\"S\n" +
+	"\x1aDelegateAccessTokenRequest\x12\x16\n" +
+	"\x06scopes\x18\x01 \x03(\tR\x06scopes\x12\x1d\n" +
+	"\n" +
+	"expires_in\x18\x02 \x01(\x03R\texpiresIn\"\xab\x01\n" +
+	"\x1bDelegateAccessTokenResponse\x12!\n" +
+	"\faccess_token\x18\x01 \x01(\tR\vaccessToken\x12Q\n" +
+	"\x17access_token_expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x14accessTokenExpiresAt\x12\x16\n" +
+	"\x06scopes\x18\x03 \x03(\tR\x06scopesB)Z'github.com/akshay237/backend-with-go/pbb\x06proto3"
+
+var (
+	file_rpc_delegate_access_token_proto_rawDescOnce sync.Once
+	file_rpc_delegate_access_token_proto_rawDescData []byte
+)
+
+func file_rpc_delegate_access_token_proto_rawDescGZIP() []byte {
+	file_rpc_delegate_access_token_proto_rawDescOnce.Do(func() {
+		file_rpc_delegate_access_token_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_delegate_access_token_proto_rawDesc), len(file_rpc_delegate_access_token_proto_rawDesc)))
+	})
+	return file_rpc_delegate_access_token_proto_rawDescData
+}
+
+var file_rpc_delegate_access_token_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_delegate_access_token_proto_goTypes = []any{
+	(*DelegateAccessTokenRequest)(nil),  // 0: pb.DelegateAccessTokenRequest
+	(*DelegateAccessTokenResponse)(nil), // 1: pb.DelegateAccessTokenResponse
+	(*timestamppb.Timestamp)(nil),       // 2: google.protobuf.Timestamp
+}
+var file_rpc_delegate_access_token_proto_depIdxs = []int32{
+	2, // 0: pb.DelegateAccessTokenResponse.access_token_expires_at:type_name -> google.protobuf.Timestamp
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rpc_delegate_access_token_proto_init() }
+func file_rpc_delegate_access_token_proto_init() {
+	if File_rpc_delegate_access_token_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_delegate_access_token_proto_rawDesc), len(file_rpc_delegate_access_token_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_delegate_access_token_proto_goTypes,
+		DependencyIndexes: file_rpc_delegate_access_token_proto_depIdxs,
+		MessageInfos:      file_rpc_delegate_access_token_proto_msgTypes,
+	}.Build()
+	File_rpc_delegate_access_token_proto = out.File
+	file_rpc_delegate_access_token_proto_goTypes = nil
+	file_rpc_delegate_access_token_proto_depIdxs = nil
+}
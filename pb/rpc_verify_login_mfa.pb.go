@@ -0,0 +1,241 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: rpc_verify_login_mfa.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type VerifyLoginMFARequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	MfaChallengeToken string                 `protobuf:"bytes,1,opt,name=mfa_challenge_token,json=mfaChallengeToken,proto3" json:"mfa_challenge_token,omitempty"`
+	Code              string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Scopes            []string               `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *VerifyLoginMFARequest) Reset() {
+	*x = VerifyLoginMFARequest{}
+	mi := &file_rpc_verify_login_mfa_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyLoginMFARequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyLoginMFARequest) ProtoMessage() {}
+
+func (x *VerifyLoginMFARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_verify_login_mfa_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyLoginMFARequest.ProtoReflect.Descriptor instead.
+func (*VerifyLoginMFARequest) Descriptor() ([]byte, []int) {
+	return file_rpc_verify_login_mfa_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *VerifyLoginMFARequest) GetMfaChallengeToken() string {
+	if x != nil {
+		return x.MfaChallengeToken
+	}
+	return ""
+}
+
+func (x *VerifyLoginMFARequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *VerifyLoginMFARequest) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+type VerifyLoginMFAResponse struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	User                  *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	SessionId             string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	AccessToken           string                 `protobuf:"bytes,3,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	RefreshToken          string                 `protobuf:"bytes,4,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	AccessTokenExpiresAt  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=access_token_expires_at,json=accessTokenExpiresAt,proto3" json:"access_token_expires_at,omitempty"`
+	RefreshTokenExpiresAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=refresh_token_expires_at,json=refreshTokenExpiresAt,proto3" json:"refresh_token_expires_at,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *VerifyLoginMFAResponse) Reset() {
+	*x = VerifyLoginMFAResponse{}
+	mi := &file_rpc_verify_login_mfa_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyLoginMFAResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyLoginMFAResponse) ProtoMessage() {}
+
+func (x *VerifyLoginMFAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_verify_login_mfa_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyLoginMFAResponse.ProtoReflect.Descriptor instead.
+func (*VerifyLoginMFAResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_verify_login_mfa_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *VerifyLoginMFAResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *VerifyLoginMFAResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *VerifyLoginMFAResponse) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+func (x *VerifyLoginMFAResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *VerifyLoginMFAResponse) GetAccessTokenExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AccessTokenExpiresAt
+	}
+	return nil
+}
+
+func (x *VerifyLoginMFAResponse) GetRefreshTokenExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RefreshTokenExpiresAt
+	}
+	return nil
+}
+
+var File_rpc_verify_login_mfa_proto protoreflect.FileDescriptor
+
+const file_rpc_verify_login_mfa_proto_rawDesc = "" +
+	"\n" +
+	"\x1arpc_verify_login_mfa.proto\x12\x02pb\x1a\n" +
+	"user.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"s\n" +
+	"\x15VerifyLoginMFARequest\x12.\n" +
+	"\x13mfa_challenge_token\x18\x01 \x01(\tR\x11mfaChallengeToken\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x16\n" +
+	"\x06scopes\x18\x03 \x03(\tR\x06scopes\"\xc5\x02\n" +
+	"\x16VerifyLoginMFAResponse\x12\x1c\n" +
+	"\x04user\x18\x01 \x01(\v2\b.pb.UserR\x04user\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12!\n" +
+	"\faccess_token\x18\x03 \x01(\tR\vaccessToken\x12#\n" +
+	"\rrefresh_token\x18\x04 \x01(\tR\frefreshToken\x12Q\n" +
+	"\x17access_token_expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x14accessTokenExpiresAt\x12S\n" +
+	"\x18refresh_token_expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x15refreshTokenExpiresAtB)Z'github.com/akshay237/backend-with-go/pbb\x06proto3"
+
+var (
+	file_rpc_verify_login_mfa_proto_rawDescOnce sync.Once
+	file_rpc_verify_login_mfa_proto_rawDescData []byte
+)
+
+func file_rpc_verify_login_mfa_proto_rawDescGZIP() []byte {
+	file_rpc_verify_login_mfa_proto_rawDescOnce.Do(func() {
+		file_rpc_verify_login_mfa_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rpc_verify_login_mfa_proto_rawDesc), len(file_rpc_verify_login_mfa_proto_rawDesc)))
+	})
+	return file_rpc_verify_login_mfa_proto_rawDescData
+}
+
+var file_rpc_verify_login_mfa_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_verify_login_mfa_proto_goTypes = []any{
+	(*VerifyLoginMFARequest)(nil),  // 0: pb.VerifyLoginMFARequest
+	(*VerifyLoginMFAResponse)(nil), // 1: pb.VerifyLoginMFAResponse
+	(*User)(nil),                   // 2: pb.User
+	(*timestamppb.Timestamp)(nil),  // 3: google.protobuf.Timestamp
+}
+var file_rpc_verify_login_mfa_proto_depIdxs = []int32{
+	2, // 0: pb.VerifyLoginMFAResponse.user:type_name -> pb.User
+	3, // 1: pb.VerifyLoginMFAResponse.access_token_expires_at:type_name -> google.protobuf.Timestamp
+	3, // 2: pb.VerifyLoginMFAResponse.refresh_token_expires_at:type_name -> google.protobuf.Timestamp
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_rpc_verify_login_mfa_proto_init() }
+func file_rpc_verify_login_mfa_proto_init() {
+	if File_rpc_verify_login_mfa_proto != nil {
+		return
+	}
+	file_user_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rpc_verify_login_mfa_proto_rawDesc), len(file_rpc_verify_login_mfa_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_verify_login_mfa_proto_goTypes,
+		DependencyIndexes: file_rpc_verify_login_mfa_proto_depIdxs,
+		MessageInfos:      file_rpc_verify_login_mfa_proto_msgTypes,
+	}.Build()
+	File_rpc_verify_login_mfa_proto = out.File
+	file_rpc_verify_login_mfa_proto_goTypes = nil
+	file_rpc_verify_login_mfa_proto_depIdxs = nil
+}
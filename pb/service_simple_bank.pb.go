@@ -7,6 +7,7 @@
 package pb
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -24,29 +25,238 @@ var File_service_simple_bank_proto protoreflect.FileDescriptor
 
 const file_service_simple_bank_proto_rawDesc = "" +
 	"\n" +
-	"\x19service_simple_bank.proto\x12\x02pb\x1a\x15rpc_create_user.proto\x1a\x14rpc_login_user.proto2\x87\x01\n" +
+	"\x19service_simple_bank.proto\x12\x02pb\x1a\x1cgoogle/api/annotations.proto\x1a\x15rpc_create_user.proto\x1a\x14rpc_login_user.proto\x1a\x1crpc_renew_access_token.proto\x1a\x18rpc_create_account.proto\x1a\x15rpc_get_account.proto\x1a\x17rpc_list_accounts.proto\x1a\x1frpc_get_account_statement.proto\x1a\x18rpc_update_account.proto\x1a rpc_update_overdraft_limit.proto\x1a\x18rpc_delete_account.proto\x1a\x19rpc_create_transfer.proto\x1a\x1frpc_create_transfer_quote.proto\x1a\x16rpc_get_transfer.proto\x1a\x1arpc_reverse_transfer.proto\x1a\x18rpc_authorize_hold.proto\x1a\x12rpc_get_hold.proto\x1a\x16rpc_capture_hold.proto\x1a\x13rpc_void_hold.proto\x1a#rpc_create_scheduled_transfer.proto\x1a rpc_get_scheduled_transfer.proto\x1a\"rpc_list_scheduled_transfers.proto\x1a#rpc_update_scheduled_transfer.proto\x1a#rpc_cancel_scheduled_transfer.proto\x1a&rpc_list_scheduled_transfer_runs.proto\x1a\x19rpc_list_currencies.proto\x1a\x17rpc_list_sessions.proto\x1a\x18rpc_revoke_session.proto\x1a\x1drpc_revoke_all_sessions.proto\x1a\x15rpc_logout_user.proto\x1a\x12rpc_get_user.proto\x1a\x1arpc_verify_login_mfa.proto\x1a\x15rpc_enroll_totp.proto\x1a\x16rpc_confirm_totp.proto\x1a\x16rpc_disable_totp.proto\x1a\x15rpc_unlock_user.proto\x1a$rpc_request_email_verification.proto\x1a\x16rpc_verify_email.proto\x1a rpc_request_password_reset.proto\x1a\x18rpc_reset_password.proto\x1a\x18rpc_create_api_key.proto\x1a\x17rpc_list_api_keys.proto\x1a\x18rpc_revoke_api_key.proto\x1a\x1frpc_delegate_access_token.proto2\xd5#\n" +
 	"\n" +
-	"SimpleBank\x12=\n" +
+	"SimpleBank\x12Q\n" +
 	"\n" +
-	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"\x00\x12:\n" +
-	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"\x00B)Z'github.com/akshay237/backend-with-go/pbb\x06proto3"
+	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"\x14\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/users\x12S\n" +
+	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/user/login\x12f\n" +
+	"\x0eVerifyLoginMFA\x12\x19.pb.VerifyLoginMFARequest\x1a\x1a.pb.VerifyLoginMFAResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/user/login/mfa\x12\x8d\x01\n" +
+	"\x18RequestEmailVerification\x12#.pb.RequestEmailVerificationRequest\x1a$.pb.RequestEmailVerificationResponse\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/v1/user/email/verification\x12`\n" +
+	"\vVerifyEmail\x12\x16.pb.VerifyEmailRequest\x1a\x17.pb.VerifyEmailResponse\" \x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/v1/user/email/verify\x12\x85\x01\n" +
+	"\x14RequestPasswordReset\x12\x1f.pb.RequestPasswordResetRequest\x1a .pb.RequestPasswordResetResponse\"*\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/v1/user/password/reset_request\x12h\n" +
+	"\rResetPassword\x12\x18.pb.ResetPasswordRequest\x1a\x19.pb.ResetPasswordResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/user/password/reset\x12Y\n" +
+	"\n" +
+	"EnrollTOTP\x12\x15.pb.EnrollTOTPRequest\x1a\x16.pb.EnrollTOTPResponse\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/v1/user/mfa/totp\x12d\n" +
+	"\vConfirmTOTP\x12\x16.pb.ConfirmTOTPRequest\x1a\x17.pb.ConfirmTOTPResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/v1/user/mfa/totp/confirm\x12d\n" +
+	"\vDisableTOTP\x12\x16.pb.DisableTOTPRequest\x1a\x17.pb.DisableTOTPResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/v1/user/mfa/totp/disable\x12p\n" +
+	"\x10RenewAccessToken\x12\x1b.pb.RenewAccessTokenRequest\x1a\x1c.pb.RenewAccessTokenResponse\"!\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/token/renew_access\x12u\n" +
+	"\x13DelegateAccessToken\x12\x1e.pb.DelegateAccessTokenRequest\x1a\x1f.pb.DelegateAccessTokenResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/token/delegate\x12W\n" +
+	"\n" +
+	"LogoutUser\x12\x15.pb.LogoutUserRequest\x1a\x16.pb.LogoutUserResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/user/logout\x12P\n" +
+	"\aGetUser\x12\x12.pb.GetUserRequest\x1a\x13.pb.GetUserResponse\"\x1c\x82\xd3\xe4\x93\x02\x16\x12\x14/v1/users/{username}\x12c\n" +
+	"\n" +
+	"UnlockUser\x12\x15.pb.UnlockUserRequest\x1a\x16.pb.UnlockUserResponse\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/v1/users/{username}/unlock\x12W\n" +
+	"\fListSessions\x12\x17.pb.ListSessionsRequest\x1a\x18.pb.ListSessionsResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/sessions\x12_\n" +
+	"\rRevokeSession\x12\x18.pb.RevokeSessionRequest\x1a\x19.pb.RevokeSessionResponse\"\x19\x82\xd3\xe4\x93\x02\x13*\x11/v1/sessions/{id}\x12f\n" +
+	"\x11RevokeAllSessions\x12\x1c.pb.RevokeAllSessionsRequest\x1a\x1d.pb.RevokeAllSessionsResponse\"\x14\x82\xd3\xe4\x93\x02\x0e*\f/v1/sessions\x12Z\n" +
+	"\fCreateApiKey\x12\x17.pb.CreateApiKeyRequest\x1a\x18.pb.CreateApiKeyResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/api_keys\x12T\n" +
+	"\vListApiKeys\x12\x16.pb.ListApiKeysRequest\x1a\x17.pb.ListApiKeysResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/api_keys\x12\\\n" +
+	"\fRevokeApiKey\x12\x17.pb.RevokeApiKeyRequest\x1a\x18.pb.RevokeApiKeyResponse\"\x19\x82\xd3\xe4\x93\x02\x13*\x11/v1/api_keys/{id}\x12]\n" +
+	"\rCreateAccount\x12\x18.pb.CreateAccountRequest\x1a\x19.pb.CreateAccountResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/accounts\x12V\n" +
+	"\n" +
+	"GetAccount\x12\x15.pb.GetAccountRequest\x1a\x16.pb.GetAccountResponse\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/accounts/{id}\x12W\n" +
+	"\fListAccounts\x12\x17.pb.ListAccountsRequest\x1a\x18.pb.ListAccountsResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/accounts\x12{\n" +
+	"\x13GetAccountStatement\x12\x1e.pb.GetAccountStatementRequest\x1a\x1f.pb.GetAccountStatementResponse\"#\x82\xd3\xe4\x93\x02\x1d\x12\x1b/v1/accounts/{id}/statement\x12]\n" +
+	"\rUpdateAccount\x12\x18.pb.UpdateAccountRequest\x1a\x19.pb.UpdateAccountResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\x1a\f/v1/accounts\x12\x87\x01\n" +
+	"\x14UpdateOverdraftLimit\x12\x1f.pb.UpdateOverdraftLimitRequest\x1a .pb.UpdateOverdraftLimitResponse\",\x82\xd3\xe4\x93\x02&:\x01*\x1a!/v1/accounts/{id}/overdraft_limit\x12_\n" +
+	"\rDeleteAccount\x12\x18.pb.DeleteAccountRequest\x1a\x19.pb.DeleteAccountResponse\"\x19\x82\xd3\xe4\x93\x02\x13*\x11/v1/accounts/{id}\x12a\n" +
+	"\x0eCreateTransfer\x12\x19.pb.CreateTransferRequest\x1a\x1a.pb.CreateTransferResponse\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/transfers\x12w\n" +
+	"\x13CreateTransferQuote\x12\x1e.pb.CreateTransferQuoteRequest\x1a\x1f.pb.CreateTransferQuoteResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/v1/transfers/quotes\x12Z\n" +
+	"\vGetTransfer\x12\x16.pb.GetTransferRequest\x1a\x17.pb.GetTransferResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/v1/transfers/{id}\x12s\n" +
+	"\x0fReverseTransfer\x12\x1a.pb.ReverseTransferRequest\x1a\x1b.pb.ReverseTransferResponse\"'\x82\xd3\xe4\x93\x02!:\x01*\"\x1c/v1/transfers/{id}/reversals\x12Z\n" +
+	"\rAuthorizeHold\x12\x18.pb.AuthorizeHoldRequest\x1a\x19.pb.AuthorizeHoldResponse\"\x14\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/holds\x12J\n" +
+	"\aGetHold\x12\x12.pb.GetHoldRequest\x1a\x13.pb.GetHoldResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/holds/{id}\x12a\n" +
+	"\vCaptureHold\x12\x16.pb.CaptureHoldRequest\x1a\x17.pb.CaptureHoldResponse\"!\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/holds/{id}/capture\x12U\n" +
+	"\bVoidHold\x12\x13.pb.VoidHoldRequest\x1a\x14.pb.VoidHoldResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/holds/{id}/void\x12\x86\x01\n" +
+	"\x17CreateScheduledTransfer\x12\".pb.CreateScheduledTransferRequest\x1a#.pb.CreateScheduledTransferResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/scheduled_transfers\x12\x7f\n" +
+	"\x14GetScheduledTransfer\x12\x1f.pb.GetScheduledTransferRequest\x1a .pb.GetScheduledTransferResponse\"$\x82\xd3\xe4\x93\x02\x1e\x12\x1c/v1/scheduled_transfers/{id}\x12\x80\x01\n" +
+	"\x16ListScheduledTransfers\x12!.pb.ListScheduledTransfersRequest\x1a\".pb.ListScheduledTransfersResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/v1/scheduled_transfers\x12\x8b\x01\n" +
+	"\x17UpdateScheduledTransfer\x12\".pb.UpdateScheduledTransferRequest\x1a#.pb.UpdateScheduledTransferResponse\"'\x82\xd3\xe4\x93\x02!:\x01*2\x1c/v1/scheduled_transfers/{id}\x12\x88\x01\n" +
+	"\x17CancelScheduledTransfer\x12\".pb.CancelScheduledTransferRequest\x1a#.pb.CancelScheduledTransferResponse\"$\x82\xd3\xe4\x93\x02\x1e*\x1c/v1/scheduled_transfers/{id}\x12\x93\x01\n" +
+	"\x19ListScheduledTransferRuns\x12$.pb.ListScheduledTransferRunsRequest\x1a%.pb.ListScheduledTransferRunsResponse\")\x82\xd3\xe4\x93\x02#\x12!/v1/scheduled_transfers/{id}/runs\x12_\n" +
+	"\x0eListCurrencies\x12\x19.pb.ListCurrenciesRequest\x1a\x1a.pb.ListCurrenciesResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/currenciesB)Z'github.com/akshay237/backend-with-go/pbb\x06proto3"
 
 var file_service_simple_bank_proto_goTypes = []any{
-	(*CreateUserRequest)(nil),  // 0: pb.CreateUserRequest
-	(*LoginUserRequest)(nil),   // 1: pb.LoginUserRequest
-	(*CreateUserResponse)(nil), // 2: pb.CreateUserResponse
-	(*LoginUserResponse)(nil),  // 3: pb.LoginUserResponse
+	(*CreateUserRequest)(nil),                 // 0: pb.CreateUserRequest
+	(*LoginUserRequest)(nil),                  // 1: pb.LoginUserRequest
+	(*VerifyLoginMFARequest)(nil),             // 2: pb.VerifyLoginMFARequest
+	(*RequestEmailVerificationRequest)(nil),   // 3: pb.RequestEmailVerificationRequest
+	(*VerifyEmailRequest)(nil),                // 4: pb.VerifyEmailRequest
+	(*RequestPasswordResetRequest)(nil),       // 5: pb.RequestPasswordResetRequest
+	(*ResetPasswordRequest)(nil),              // 6: pb.ResetPasswordRequest
+	(*EnrollTOTPRequest)(nil),                 // 7: pb.EnrollTOTPRequest
+	(*ConfirmTOTPRequest)(nil),                // 8: pb.ConfirmTOTPRequest
+	(*DisableTOTPRequest)(nil),                // 9: pb.DisableTOTPRequest
+	(*RenewAccessTokenRequest)(nil),           // 10: pb.RenewAccessTokenRequest
+	(*DelegateAccessTokenRequest)(nil),        // 11: pb.DelegateAccessTokenRequest
+	(*LogoutUserRequest)(nil),                 // 12: pb.LogoutUserRequest
+	(*GetUserRequest)(nil),                    // 13: pb.GetUserRequest
+	(*UnlockUserRequest)(nil),                 // 14: pb.UnlockUserRequest
+	(*ListSessionsRequest)(nil),               // 15: pb.ListSessionsRequest
+	(*RevokeSessionRequest)(nil),              // 16: pb.RevokeSessionRequest
+	(*RevokeAllSessionsRequest)(nil),          // 17: pb.RevokeAllSessionsRequest
+	(*CreateApiKeyRequest)(nil),               // 18: pb.CreateApiKeyRequest
+	(*ListApiKeysRequest)(nil),                // 19: pb.ListApiKeysRequest
+	(*RevokeApiKeyRequest)(nil),               // 20: pb.RevokeApiKeyRequest
+	(*CreateAccountRequest)(nil),              // 21: pb.CreateAccountRequest
+	(*GetAccountRequest)(nil),                 // 22: pb.GetAccountRequest
+	(*ListAccountsRequest)(nil),               // 23: pb.ListAccountsRequest
+	(*GetAccountStatementRequest)(nil),        // 24: pb.GetAccountStatementRequest
+	(*UpdateAccountRequest)(nil),              // 25: pb.UpdateAccountRequest
+	(*UpdateOverdraftLimitRequest)(nil),       // 26: pb.UpdateOverdraftLimitRequest
+	(*DeleteAccountRequest)(nil),              // 27: pb.DeleteAccountRequest
+	(*CreateTransferRequest)(nil),             // 28: pb.CreateTransferRequest
+	(*CreateTransferQuoteRequest)(nil),        // 29: pb.CreateTransferQuoteRequest
+	(*GetTransferRequest)(nil),                // 30: pb.GetTransferRequest
+	(*ReverseTransferRequest)(nil),            // 31: pb.ReverseTransferRequest
+	(*AuthorizeHoldRequest)(nil),              // 32: pb.AuthorizeHoldRequest
+	(*GetHoldRequest)(nil),                    // 33: pb.GetHoldRequest
+	(*CaptureHoldRequest)(nil),                // 34: pb.CaptureHoldRequest
+	(*VoidHoldRequest)(nil),                   // 35: pb.VoidHoldRequest
+	(*CreateScheduledTransferRequest)(nil),    // 36: pb.CreateScheduledTransferRequest
+	(*GetScheduledTransferRequest)(nil),       // 37: pb.GetScheduledTransferRequest
+	(*ListScheduledTransfersRequest)(nil),     // 38: pb.ListScheduledTransfersRequest
+	(*UpdateScheduledTransferRequest)(nil),    // 39: pb.UpdateScheduledTransferRequest
+	(*CancelScheduledTransferRequest)(nil),    // 40: pb.CancelScheduledTransferRequest
+	(*ListScheduledTransferRunsRequest)(nil),  // 41: pb.ListScheduledTransferRunsRequest
+	(*ListCurrenciesRequest)(nil),             // 42: pb.ListCurrenciesRequest
+	(*CreateUserResponse)(nil),                // 43: pb.CreateUserResponse
+	(*LoginUserResponse)(nil),                 // 44: pb.LoginUserResponse
+	(*VerifyLoginMFAResponse)(nil),            // 45: pb.VerifyLoginMFAResponse
+	(*RequestEmailVerificationResponse)(nil),  // 46: pb.RequestEmailVerificationResponse
+	(*VerifyEmailResponse)(nil),               // 47: pb.VerifyEmailResponse
+	(*RequestPasswordResetResponse)(nil),      // 48: pb.RequestPasswordResetResponse
+	(*ResetPasswordResponse)(nil),             // 49: pb.ResetPasswordResponse
+	(*EnrollTOTPResponse)(nil),                // 50: pb.EnrollTOTPResponse
+	(*ConfirmTOTPResponse)(nil),               // 51: pb.ConfirmTOTPResponse
+	(*DisableTOTPResponse)(nil),               // 52: pb.DisableTOTPResponse
+	(*RenewAccessTokenResponse)(nil),          // 53: pb.RenewAccessTokenResponse
+	(*DelegateAccessTokenResponse)(nil),       // 54: pb.DelegateAccessTokenResponse
+	(*LogoutUserResponse)(nil),                // 55: pb.LogoutUserResponse
+	(*GetUserResponse)(nil),                   // 56: pb.GetUserResponse
+	(*UnlockUserResponse)(nil),                // 57: pb.UnlockUserResponse
+	(*ListSessionsResponse)(nil),              // 58: pb.ListSessionsResponse
+	(*RevokeSessionResponse)(nil),             // 59: pb.RevokeSessionResponse
+	(*RevokeAllSessionsResponse)(nil),         // 60: pb.RevokeAllSessionsResponse
+	(*CreateApiKeyResponse)(nil),              // 61: pb.CreateApiKeyResponse
+	(*ListApiKeysResponse)(nil),               // 62: pb.ListApiKeysResponse
+	(*RevokeApiKeyResponse)(nil),              // 63: pb.RevokeApiKeyResponse
+	(*CreateAccountResponse)(nil),             // 64: pb.CreateAccountResponse
+	(*GetAccountResponse)(nil),                // 65: pb.GetAccountResponse
+	(*ListAccountsResponse)(nil),              // 66: pb.ListAccountsResponse
+	(*GetAccountStatementResponse)(nil),       // 67: pb.GetAccountStatementResponse
+	(*UpdateAccountResponse)(nil),             // 68: pb.UpdateAccountResponse
+	(*UpdateOverdraftLimitResponse)(nil),      // 69: pb.UpdateOverdraftLimitResponse
+	(*DeleteAccountResponse)(nil),             // 70: pb.DeleteAccountResponse
+	(*CreateTransferResponse)(nil),            // 71: pb.CreateTransferResponse
+	(*CreateTransferQuoteResponse)(nil),       // 72: pb.CreateTransferQuoteResponse
+	(*GetTransferResponse)(nil),               // 73: pb.GetTransferResponse
+	(*ReverseTransferResponse)(nil),           // 74: pb.ReverseTransferResponse
+	(*AuthorizeHoldResponse)(nil),             // 75: pb.AuthorizeHoldResponse
+	(*GetHoldResponse)(nil),                   // 76: pb.GetHoldResponse
+	(*CaptureHoldResponse)(nil),               // 77: pb.CaptureHoldResponse
+	(*VoidHoldResponse)(nil),                  // 78: pb.VoidHoldResponse
+	(*CreateScheduledTransferResponse)(nil),   // 79: pb.CreateScheduledTransferResponse
+	(*GetScheduledTransferResponse)(nil),      // 80: pb.GetScheduledTransferResponse
+	(*ListScheduledTransfersResponse)(nil),    // 81: pb.ListScheduledTransfersResponse
+	(*UpdateScheduledTransferResponse)(nil),   // 82: pb.UpdateScheduledTransferResponse
+	(*CancelScheduledTransferResponse)(nil),   // 83: pb.CancelScheduledTransferResponse
+	(*ListScheduledTransferRunsResponse)(nil), // 84: pb.ListScheduledTransferRunsResponse
+	(*ListCurrenciesResponse)(nil),            // 85: pb.ListCurrenciesResponse
 }
 var file_service_simple_bank_proto_depIdxs = []int32{
-	0, // 0: pb.SimpleBank.CreateUser:input_type -> pb.CreateUserRequest
-	1, // 1: pb.SimpleBank.LoginUser:input_type -> pb.LoginUserRequest
-	2, // 2: pb.SimpleBank.CreateUser:output_type -> pb.CreateUserResponse
-	3, // 3: pb.SimpleBank.LoginUser:output_type -> pb.LoginUserResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: pb.SimpleBank.CreateUser:input_type -> pb.CreateUserRequest
+	1,  // 1: pb.SimpleBank.LoginUser:input_type -> pb.LoginUserRequest
+	2,  // 2: pb.SimpleBank.VerifyLoginMFA:input_type -> pb.VerifyLoginMFARequest
+	3,  // 3: pb.SimpleBank.RequestEmailVerification:input_type -> pb.RequestEmailVerificationRequest
+	4,  // 4: pb.SimpleBank.VerifyEmail:input_type -> pb.VerifyEmailRequest
+	5,  // 5: pb.SimpleBank.RequestPasswordReset:input_type -> pb.RequestPasswordResetRequest
+	6,  // 6: pb.SimpleBank.ResetPassword:input_type -> pb.ResetPasswordRequest
+	7,  // 7: pb.SimpleBank.EnrollTOTP:input_type -> pb.EnrollTOTPRequest
+	8,  // 8: pb.SimpleBank.ConfirmTOTP:input_type -> pb.ConfirmTOTPRequest
+	9,  // 9: pb.SimpleBank.DisableTOTP:input_type -> pb.DisableTOTPRequest
+	10, // 10: pb.SimpleBank.RenewAccessToken:input_type -> pb.RenewAccessTokenRequest
+	11, // 11: pb.SimpleBank.DelegateAccessToken:input_type -> pb.DelegateAccessTokenRequest
+	12, // 12: pb.SimpleBank.LogoutUser:input_type -> pb.LogoutUserRequest
+	13, // 13: pb.SimpleBank.GetUser:input_type -> pb.GetUserRequest
+	14, // 14: pb.SimpleBank.UnlockUser:input_type -> pb.UnlockUserRequest
+	15, // 15: pb.SimpleBank.ListSessions:input_type -> pb.ListSessionsRequest
+	16, // 16: pb.SimpleBank.RevokeSession:input_type -> pb.RevokeSessionRequest
+	17, // 17: pb.SimpleBank.RevokeAllSessions:input_type -> pb.RevokeAllSessionsRequest
+	18, // 18: pb.SimpleBank.CreateApiKey:input_type -> pb.CreateApiKeyRequest
+	19, // 19: pb.SimpleBank.ListApiKeys:input_type -> pb.ListApiKeysRequest
+	20, // 20: pb.SimpleBank.RevokeApiKey:input_type -> pb.RevokeApiKeyRequest
+	21, // 21: pb.SimpleBank.CreateAccount:input_type -> pb.CreateAccountRequest
+	22, // 22: pb.SimpleBank.GetAccount:input_type -> pb.GetAccountRequest
+	23, // 23: pb.SimpleBank.ListAccounts:input_type -> pb.ListAccountsRequest
+	24, // 24: pb.SimpleBank.GetAccountStatement:input_type -> pb.GetAccountStatementRequest
+	25, // 25: pb.SimpleBank.UpdateAccount:input_type -> pb.UpdateAccountRequest
+	26, // 26: pb.SimpleBank.UpdateOverdraftLimit:input_type -> pb.UpdateOverdraftLimitRequest
+	27, // 27: pb.SimpleBank.DeleteAccount:input_type -> pb.DeleteAccountRequest
+	28, // 28: pb.SimpleBank.CreateTransfer:input_type -> pb.CreateTransferRequest
+	29, // 29: pb.SimpleBank.CreateTransferQuote:input_type -> pb.CreateTransferQuoteRequest
+	30, // 30: pb.SimpleBank.GetTransfer:input_type -> pb.GetTransferRequest
+	31, // 31: pb.SimpleBank.ReverseTransfer:input_type -> pb.ReverseTransferRequest
+	32, // 32: pb.SimpleBank.AuthorizeHold:input_type -> pb.AuthorizeHoldRequest
+	33, // 33: pb.SimpleBank.GetHold:input_type -> pb.GetHoldRequest
+	34, // 34: pb.SimpleBank.CaptureHold:input_type -> pb.CaptureHoldRequest
+	35, // 35: pb.SimpleBank.VoidHold:input_type -> pb.VoidHoldRequest
+	36, // 36: pb.SimpleBank.CreateScheduledTransfer:input_type -> pb.CreateScheduledTransferRequest
+	37, // 37: pb.SimpleBank.GetScheduledTransfer:input_type -> pb.GetScheduledTransferRequest
+	38, // 38: pb.SimpleBank.ListScheduledTransfers:input_type -> pb.ListScheduledTransfersRequest
+	39, // 39: pb.SimpleBank.UpdateScheduledTransfer:input_type -> pb.UpdateScheduledTransferRequest
+	40, // 40: pb.SimpleBank.CancelScheduledTransfer:input_type -> pb.CancelScheduledTransferRequest
+	41, // 41: pb.SimpleBank.ListScheduledTransferRuns:input_type -> pb.ListScheduledTransferRunsRequest
+	42, // 42: pb.SimpleBank.ListCurrencies:input_type -> pb.ListCurrenciesRequest
+	43, // 43: pb.SimpleBank.CreateUser:output_type -> pb.CreateUserResponse
+	44, // 44: pb.SimpleBank.LoginUser:output_type -> pb.LoginUserResponse
+	45, // 45: pb.SimpleBank.VerifyLoginMFA:output_type -> pb.VerifyLoginMFAResponse
+	46, // 46: pb.SimpleBank.RequestEmailVerification:output_type -> pb.RequestEmailVerificationResponse
+	47, // 47: pb.SimpleBank.VerifyEmail:output_type -> pb.VerifyEmailResponse
+	48, // 48: pb.SimpleBank.RequestPasswordReset:output_type -> pb.RequestPasswordResetResponse
+	49, // 49: pb.SimpleBank.ResetPassword:output_type -> pb.ResetPasswordResponse
+	50, // 50: pb.SimpleBank.EnrollTOTP:output_type -> pb.EnrollTOTPResponse
+	51, // 51: pb.SimpleBank.ConfirmTOTP:output_type -> pb.ConfirmTOTPResponse
+	52, // 52: pb.SimpleBank.DisableTOTP:output_type -> pb.DisableTOTPResponse
+	53, // 53: pb.SimpleBank.RenewAccessToken:output_type -> pb.RenewAccessTokenResponse
+	54, // 54: pb.SimpleBank.DelegateAccessToken:output_type -> pb.DelegateAccessTokenResponse
+	55, // 55: pb.SimpleBank.LogoutUser:output_type -> pb.LogoutUserResponse
+	56, // 56: pb.SimpleBank.GetUser:output_type -> pb.GetUserResponse
+	57, // 57: pb.SimpleBank.UnlockUser:output_type -> pb.UnlockUserResponse
+	58, // 58: pb.SimpleBank.ListSessions:output_type -> pb.ListSessionsResponse
+	59, // 59: pb.SimpleBank.RevokeSession:output_type -> pb.RevokeSessionResponse
+	60, // 60: pb.SimpleBank.RevokeAllSessions:output_type -> pb.RevokeAllSessionsResponse
+	61, // 61: pb.SimpleBank.CreateApiKey:output_type -> pb.CreateApiKeyResponse
+	62, // 62: pb.SimpleBank.ListApiKeys:output_type -> pb.ListApiKeysResponse
+	63, // 63: pb.SimpleBank.RevokeApiKey:output_type -> pb.RevokeApiKeyResponse
+	64, // 64: pb.SimpleBank.CreateAccount:output_type -> pb.CreateAccountResponse
+	65, // 65: pb.SimpleBank.GetAccount:output_type -> pb.GetAccountResponse
+	66, // 66: pb.SimpleBank.ListAccounts:output_type -> pb.ListAccountsResponse
+	67, // 67: pb.SimpleBank.GetAccountStatement:output_type -> pb.GetAccountStatementResponse
+	68, // 68: pb.SimpleBank.UpdateAccount:output_type -> pb.UpdateAccountResponse
+	69, // 69: pb.SimpleBank.UpdateOverdraftLimit:output_type -> pb.UpdateOverdraftLimitResponse
+	70, // 70: pb.SimpleBank.DeleteAccount:output_type -> pb.DeleteAccountResponse
+	71, // 71: pb.SimpleBank.CreateTransfer:output_type -> pb.CreateTransferResponse
+	72, // 72: pb.SimpleBank.CreateTransferQuote:output_type -> pb.CreateTransferQuoteResponse
+	73, // 73: pb.SimpleBank.GetTransfer:output_type -> pb.GetTransferResponse
+	74, // 74: pb.SimpleBank.ReverseTransfer:output_type -> pb.ReverseTransferResponse
+	75, // 75: pb.SimpleBank.AuthorizeHold:output_type -> pb.AuthorizeHoldResponse
+	76, // 76: pb.SimpleBank.GetHold:output_type -> pb.GetHoldResponse
+	77, // 77: pb.SimpleBank.CaptureHold:output_type -> pb.CaptureHoldResponse
+	78, // 78: pb.SimpleBank.VoidHold:output_type -> pb.VoidHoldResponse
+	79, // 79: pb.SimpleBank.CreateScheduledTransfer:output_type -> pb.CreateScheduledTransferResponse
+	80, // 80: pb.SimpleBank.GetScheduledTransfer:output_type -> pb.GetScheduledTransferResponse
+	81, // 81: pb.SimpleBank.ListScheduledTransfers:output_type -> pb.ListScheduledTransfersResponse
+	82, // 82: pb.SimpleBank.UpdateScheduledTransfer:output_type -> pb.UpdateScheduledTransferResponse
+	83, // 83: pb.SimpleBank.CancelScheduledTransfer:output_type -> pb.CancelScheduledTransferResponse
+	84, // 84: pb.SimpleBank.ListScheduledTransferRuns:output_type -> pb.ListScheduledTransferRunsResponse
+	85, // 85: pb.SimpleBank.ListCurrencies:output_type -> pb.ListCurrenciesResponse
+	43, // [43:86] is the sub-list for method output_type
+	0,  // [0:43] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_service_simple_bank_proto_init() }
@@ -56,6 +266,47 @@ func file_service_simple_bank_proto_init() {
 	}
 	file_rpc_create_user_proto_init()
 	file_rpc_login_user_proto_init()
+	file_rpc_renew_access_token_proto_init()
+	file_rpc_create_account_proto_init()
+	file_rpc_get_account_proto_init()
+	file_rpc_list_accounts_proto_init()
+	file_rpc_get_account_statement_proto_init()
+	file_rpc_update_account_proto_init()
+	file_rpc_update_overdraft_limit_proto_init()
+	file_rpc_delete_account_proto_init()
+	file_rpc_create_transfer_proto_init()
+	file_rpc_create_transfer_quote_proto_init()
+	file_rpc_get_transfer_proto_init()
+	file_rpc_reverse_transfer_proto_init()
+	file_rpc_authorize_hold_proto_init()
+	file_rpc_get_hold_proto_init()
+	file_rpc_capture_hold_proto_init()
+	file_rpc_void_hold_proto_init()
+	file_rpc_create_scheduled_transfer_proto_init()
+	file_rpc_get_scheduled_transfer_proto_init()
+	file_rpc_list_scheduled_transfers_proto_init()
+	file_rpc_update_scheduled_transfer_proto_init()
+	file_rpc_cancel_scheduled_transfer_proto_init()
+	file_rpc_list_scheduled_transfer_runs_proto_init()
+	file_rpc_list_currencies_proto_init()
+	file_rpc_list_sessions_proto_init()
+	file_rpc_revoke_session_proto_init()
+	file_rpc_revoke_all_sessions_proto_init()
+	file_rpc_logout_user_proto_init()
+	file_rpc_get_user_proto_init()
+	file_rpc_verify_login_mfa_proto_init()
+	file_rpc_enroll_totp_proto_init()
+	file_rpc_confirm_totp_proto_init()
+	file_rpc_disable_totp_proto_init()
+	file_rpc_unlock_user_proto_init()
+	file_rpc_request_email_verification_proto_init()
+	file_rpc_verify_email_proto_init()
+	file_rpc_request_password_reset_proto_init()
+	file_rpc_reset_password_proto_init()
+	file_rpc_create_api_key_proto_init()
+	file_rpc_list_api_keys_proto_init()
+	file_rpc_revoke_api_key_proto_init()
+	file_rpc_delegate_access_token_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
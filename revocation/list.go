@@ -0,0 +1,297 @@
+package revocation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/google/uuid"
+)
+
+// ErrUnboundToken is returned when a token is delegated from a token that has no session or api key
+var ErrUnboundToken = errors.New("token has no session to delegate from")
+
+// Store is the part of the database store used to keep the revoked access tokens.
+type Store interface {
+	RevokeToken(ctx context.Context, arg db.RevokeTokenParams) error
+	ListRevokedTokens(ctx context.Context) ([]db.RevokedToken, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	ListPasswordChanges(ctx context.Context, changedAfter time.Time) ([]db.ListPasswordChangesRow, error)
+	CreateAccessToken(ctx context.Context, arg db.CreateAccessTokenParams) (db.AccessToken, error)
+	GetAccessToken(ctx context.Context, id uuid.UUID) (db.AccessToken, error)
+	GetSessionByAccessToken(ctx context.Context, accessTokenID uuid.NullUUID) (db.Session, error)
+	GetAPIKey(ctx context.Context, id uuid.UUID) (db.ApiKey, error)
+	ListSessionAccessTokens(ctx context.Context, sessionIds []uuid.UUID) ([]db.AccessToken, error)
+	ListAPIKeyAccessTokens(ctx context.Context, apiKeyID uuid.NullUUID) ([]db.AccessToken, error)
+	ListClientAccessTokens(ctx context.Context, clientID sql.NullString) ([]db.AccessToken, error)
+	DeleteExpiredAccessTokens(ctx context.Context) (int64, error)
+}
+
+// List is the revocation list of access tokens keyed by the token id.
+// Revoked tokens are stored in Postgres and cached in memory. A token revoked by this process
+// is rejected immediately, other processes reject it after their next refresh.
+// An entry is dropped once the token expires, as an expired token is rejected anyway.
+// The list also keeps the recent password changes, a token issued before the password of its
+// user changed is rejected whether or not it belongs to a session.
+type List struct {
+	store Store
+	// passwordChangeWindow is how far back the password changes are kept. It is as long as the
+	// longest lived token, so a token issued before an older change has expired anyway.
+	passwordChangeWindow time.Duration
+
+	mu                sync.RWMutex
+	revoked           map[uuid.UUID]time.Time
+	passwordChangedAt map[string]time.Time
+}
+
+// NewList creates an empty revocation list
+func NewList(store Store, config util.Config) *List {
+	return &List{
+		store:                store,
+		passwordChangeWindow: max(config.AccessTokenDuration, config.RefreshTokenDuration),
+		revoked:              make(map[uuid.UUID]time.Time),
+		passwordChangedAt:    make(map[string]time.Time),
+	}
+}
+
+// Revoke revokes the access token until it expires
+func (l *List) Revoke(ctx context.Context, tokenID uuid.UUID, username string, expiresAt time.Time) error {
+	if !time.Now().Before(expiresAt) {
+		return nil
+	}
+
+	err := l.store.RevokeToken(ctx, db.RevokeTokenParams{
+		ID:        tokenID,
+		Username:  username,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.revoked[tokenID] = expiresAt
+	l.mu.Unlock()
+	return nil
+}
+
+// RevokeSessions revokes the access tokens issued along with the sessions and the tokens delegated under them
+func (l *List) RevokeSessions(ctx context.Context, sessions ...db.Session) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	sessionIDs := make([]uuid.UUID, 0, len(sessions))
+	for _, session := range sessions {
+		sessionIDs = append(sessionIDs, session.ID)
+		if !session.AccessTokenID.Valid || !session.AccessTokenExpiresAt.Valid {
+			continue
+		}
+
+		err := l.Revoke(ctx, session.AccessTokenID.UUID, session.Username, session.AccessTokenExpiresAt.Time)
+		if err != nil {
+			return err
+		}
+	}
+
+	delegated, err := l.store.ListSessionAccessTokens(ctx, sessionIDs)
+	if err != nil {
+		return err
+	}
+	return l.revokeAccessTokens(ctx, delegated)
+}
+
+// RevokeAPIKey revokes the tokens delegated under the api key
+func (l *List) RevokeAPIKey(ctx context.Context, apiKeyID uuid.UUID) error {
+	delegated, err := l.store.ListAPIKeyAccessTokens(ctx, uuid.NullUUID{UUID: apiKeyID, Valid: true})
+	if err != nil {
+		return err
+	}
+	return l.revokeAccessTokens(ctx, delegated)
+}
+
+// RevokeClient revokes the access tokens issued to the oauth client without a session
+func (l *List) RevokeClient(ctx context.Context, clientID string) error {
+	issued, err := l.store.ListClientAccessTokens(ctx, sql.NullString{String: clientID, Valid: true})
+	if err != nil {
+		return err
+	}
+	return l.revokeAccessTokens(ctx, issued)
+}
+
+func (l *List) revokeAccessTokens(ctx context.Context, accessTokens []db.AccessToken) error {
+	for _, accessToken := range accessTokens {
+		err := l.Revoke(ctx, accessToken.ID, accessToken.Username, accessToken.ExpiresAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IssueToClient records an access token issued to the oauth client without a refresh token,
+// so revoking the client revokes it
+func (l *List) IssueToClient(ctx context.Context, payload *token.Payload, clientID string) error {
+	_, err := l.store.CreateAccessToken(ctx, db.CreateAccessTokenParams{
+		ID:        payload.ID,
+		Username:  payload.Username,
+		ClientID:  sql.NullString{String: clientID, Valid: true},
+		ExpiresAt: payload.ExpiredAT,
+	})
+	return err
+}
+
+// Delegate records the token delegated from the token of the caller under the same session or api key,
+// so logging out or revoking the key revokes it too. A token delegated from a token without a session
+// is bound to what that token is bound to. It returns ErrUnboundToken if the caller is bound to nothing.
+func (l *List) Delegate(ctx context.Context, caller *token.Payload, delegated *token.Payload) error {
+	arg := db.CreateAccessTokenParams{
+		ID:        delegated.ID,
+		Username:  delegated.Username,
+		ExpiresAt: delegated.ExpiredAT,
+	}
+
+	parent, err := l.store.GetAccessToken(ctx, caller.ID)
+	switch {
+	case err == nil:
+		arg.SessionID = parent.SessionID
+		arg.ApiKeyID = parent.ApiKeyID
+		arg.ClientID = parent.ClientID
+	case errors.Is(err, sql.ErrNoRows):
+		arg.SessionID, arg.ApiKeyID, err = l.callerBinding(ctx, caller)
+		if err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	_, err = l.store.CreateAccessToken(ctx, arg)
+	return err
+}
+
+// callerBinding returns the session the caller logged in with or the api key the caller authenticated with
+func (l *List) callerBinding(ctx context.Context, caller *token.Payload) (uuid.NullUUID, uuid.NullUUID, error) {
+	session, err := l.store.GetSessionByAccessToken(ctx, uuid.NullUUID{UUID: caller.ID, Valid: true})
+	if err == nil {
+		return uuid.NullUUID{UUID: session.ID, Valid: true}, uuid.NullUUID{}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return uuid.NullUUID{}, uuid.NullUUID{}, err
+	}
+
+	// the payload of an api key carries the id of the key
+	apiKey, err := l.store.GetAPIKey(ctx, caller.ID)
+	if err == nil && apiKey.Username == caller.Username {
+		return uuid.NullUUID{}, uuid.NullUUID{UUID: apiKey.ID, Valid: true}, nil
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return uuid.NullUUID{}, uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{}, uuid.NullUUID{}, ErrUnboundToken
+}
+
+// IsRevoked returns true if the access token is revoked and not yet expired
+func (l *List) IsRevoked(tokenID uuid.UUID) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	expiresAt, ok := l.revoked[tokenID]
+	return ok && time.Now().Before(expiresAt)
+}
+
+// PasswordChanged rejects the tokens of the user issued before changedAt. The change is already
+// stored with the user, so only the cache of this process is updated.
+func (l *List) PasswordChanged(username string, changedAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if changedAt.After(l.passwordChangedAt[username]) {
+		l.passwordChangedAt[username] = changedAt
+	}
+}
+
+// IssuedBeforePasswordChange returns true if the token was issued before the password of its user changed
+func (l *List) IssuedBeforePasswordChange(payload *token.Payload) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	changedAt, ok := l.passwordChangedAt[payload.Username]
+	// the issued at claim of a JWT has second precision
+	return ok && payload.IssuedAt.Before(changedAt.Truncate(time.Second))
+}
+
+// Refresh loads the unexpired revoked tokens and the recent password changes from the database.
+// They are merged into the cached entries, so a token revoked by this process while the entries
+// were loaded is kept. The expired entries are dropped.
+func (l *List) Refresh(ctx context.Context) error {
+	rows, err := l.store.ListRevokedTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	changedAfter := time.Now().Add(-l.passwordChangeWindow)
+	changes, err := l.store.ListPasswordChanges(ctx, changedAfter)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, row := range rows {
+		l.revoked[row.ID] = row.ExpiresAt
+	}
+	now := time.Now()
+	for tokenID, expiresAt := range l.revoked {
+		if !now.Before(expiresAt) {
+			delete(l.revoked, tokenID)
+		}
+	}
+
+	for _, change := range changes {
+		if change.PasswordChangedAt.After(l.passwordChangedAt[change.Username]) {
+			l.passwordChangedAt[change.Username] = change.PasswordChangedAt
+		}
+	}
+	for username, changedAt := range l.passwordChangedAt {
+		if changedAt.Before(changedAfter) {
+			delete(l.passwordChangedAt, username)
+		}
+	}
+	return nil
+}
+
+// Run refreshes the list every interval and deletes the expired entries until the context is done
+func (l *List) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := l.store.DeleteExpiredRevokedTokens(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Println("failed to delete the expired revoked tokens:", err)
+			}
+
+			_, err = l.store.DeleteExpiredAccessTokens(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Println("failed to delete the expired access tokens:", err)
+			}
+
+			err = l.Refresh(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Println("failed to refresh the token revocation list:", err)
+			}
+		}
+	}
+}
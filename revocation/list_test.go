@@ -0,0 +1,168 @@
+package revocation
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/token"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = util.Config{
+	AccessTokenDuration:  time.Minute,
+	RefreshTokenDuration: time.Hour,
+}
+
+func TestListRevoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	list := NewList(store, testConfig)
+
+	tokenID := uuid.New()
+	expiresAt := time.Now().Add(time.Minute)
+	require.False(t, list.IsRevoked(tokenID))
+
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Eq(db.RevokeTokenParams{
+		ID:        tokenID,
+		Username:  "user",
+		ExpiresAt: expiresAt,
+	})).Times(1).Return(nil)
+	require.NoError(t, list.Revoke(context.Background(), tokenID, "user", expiresAt))
+	require.True(t, list.IsRevoked(tokenID))
+
+	// an expired token is rejected by the token maker, so it is not stored
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(0)
+	require.NoError(t, list.Revoke(context.Background(), uuid.New(), "user", time.Now().Add(-time.Minute)))
+
+	// sessions without an access token are skipped, the tokens delegated under the sessions are revoked
+	session := db.Session{
+		ID:                   uuid.New(),
+		Username:             "user",
+		AccessTokenID:        uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		AccessTokenExpiresAt: sql.NullTime{Time: expiresAt, Valid: true},
+	}
+	noAccessToken := db.Session{ID: uuid.New(), Username: "user"}
+	delegated := db.AccessToken{
+		ID:        uuid.New(),
+		Username:  "user",
+		SessionID: uuid.NullUUID{UUID: noAccessToken.ID, Valid: true},
+		ExpiresAt: expiresAt,
+	}
+
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(2).Return(nil)
+	store.EXPECT().ListSessionAccessTokens(gomock.Any(), gomock.Eq([]uuid.UUID{session.ID, noAccessToken.ID})).
+		Times(1).Return([]db.AccessToken{delegated}, nil)
+	require.NoError(t, list.RevokeSessions(context.Background(), session, noAccessToken))
+	require.True(t, list.IsRevoked(session.AccessTokenID.UUID))
+	require.True(t, list.IsRevoked(delegated.ID))
+
+	// no sessions, nothing to look up
+	require.NoError(t, list.RevokeSessions(context.Background()))
+}
+
+func TestListDelegate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	list := NewList(store, testConfig)
+
+	caller, err := token.NewPayload("user", "depositor", nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	delegated, err := token.NewPayload("user", "depositor", nil, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	apiKey := db.ApiKey{ID: caller.ID, Username: "user"}
+
+	// 1. a token delegated with an api key is bound to the key and revoked along with it
+	store.EXPECT().GetAccessToken(gomock.Any(), gomock.Eq(caller.ID)).Times(1).Return(db.AccessToken{}, sql.ErrNoRows)
+	store.EXPECT().GetSessionByAccessToken(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+	store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(caller.ID)).Times(1).Return(apiKey, nil)
+	store.EXPECT().CreateAccessToken(gomock.Any(), gomock.Eq(db.CreateAccessTokenParams{
+		ID:        delegated.ID,
+		Username:  "user",
+		ApiKeyID:  uuid.NullUUID{UUID: apiKey.ID, Valid: true},
+		ExpiresAt: delegated.ExpiredAT,
+	})).Times(1).Return(db.AccessToken{}, nil)
+	require.NoError(t, list.Delegate(context.Background(), caller, delegated))
+
+	store.EXPECT().ListAPIKeyAccessTokens(gomock.Any(), gomock.Eq(uuid.NullUUID{UUID: apiKey.ID, Valid: true})).Times(1).
+		Return([]db.AccessToken{{ID: delegated.ID, Username: "user", ExpiresAt: delegated.ExpiredAT}}, nil)
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+	require.NoError(t, list.RevokeAPIKey(context.Background(), apiKey.ID))
+	require.True(t, list.IsRevoked(delegated.ID))
+
+	// 2. an api key of another user doesn't bind the token
+	store.EXPECT().GetAccessToken(gomock.Any(), gomock.Any()).Times(1).Return(db.AccessToken{}, sql.ErrNoRows)
+	store.EXPECT().GetSessionByAccessToken(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+	store.EXPECT().GetAPIKey(gomock.Any(), gomock.Any()).Times(1).Return(db.ApiKey{ID: caller.ID, Username: "other"}, nil)
+	require.ErrorIs(t, list.Delegate(context.Background(), caller, delegated), ErrUnboundToken)
+}
+
+func TestListRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	list := NewList(store, testConfig)
+
+	revoked := db.RevokedToken{ID: uuid.New(), Username: "user", ExpiresAt: time.Now().Add(time.Minute)}
+	expired := db.RevokedToken{ID: uuid.New(), Username: "user", ExpiresAt: time.Now().Add(-time.Second)}
+
+	change := db.ListPasswordChangesRow{Username: "user", PasswordChangedAt: time.Now()}
+
+	store.EXPECT().ListRevokedTokens(gomock.Any()).Times(1).Return([]db.RevokedToken{revoked, expired}, nil)
+	store.EXPECT().ListPasswordChanges(gomock.Any(), gomock.Any()).Times(1).Return([]db.ListPasswordChangesRow{change}, nil)
+	require.NoError(t, list.Refresh(context.Background()))
+
+	require.True(t, list.IsRevoked(revoked.ID))
+	require.False(t, list.IsRevoked(expired.ID))
+	require.True(t, list.IssuedBeforePasswordChange(&token.Payload{Username: "user", IssuedAt: time.Now().Add(-time.Minute)}))
+
+	// a token revoked by this process while the entries were loaded is kept, the expired entries are dropped
+	local := uuid.New()
+	store.EXPECT().RevokeToken(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+	store.EXPECT().ListRevokedTokens(gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context) ([]db.RevokedToken, error) {
+			require.NoError(t, list.Revoke(context.Background(), local, "user", time.Now().Add(time.Minute)))
+			return []db.RevokedToken{revoked}, nil
+		})
+	store.EXPECT().ListPasswordChanges(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, changedAfter time.Time) ([]db.ListPasswordChangesRow, error) {
+			// the changes are kept as long as the refresh token lives
+			require.WithinDuration(t, time.Now().Add(-testConfig.RefreshTokenDuration), changedAfter, time.Second)
+			return nil, nil
+		})
+	list.PasswordChanged("other", time.Now().Add(-2*testConfig.RefreshTokenDuration))
+	require.NoError(t, list.Refresh(context.Background()))
+
+	require.True(t, list.IsRevoked(local))
+	require.True(t, list.IsRevoked(revoked.ID))
+	require.NotContains(t, list.revoked, expired.ID)
+	require.True(t, list.IssuedBeforePasswordChange(&token.Payload{Username: "user", IssuedAt: time.Now().Add(-time.Minute)}))
+	require.NotContains(t, list.passwordChangedAt, "other")
+}
+
+func TestListPasswordChanged(t *testing.T) {
+	list := NewList(nil, testConfig)
+	changedAt := time.Now()
+
+	list.PasswordChanged("user", changedAt)
+	// an older change loaded later doesn't move the cutoff back
+	list.PasswordChanged("user", changedAt.Add(-time.Hour))
+
+	require.True(t, list.IssuedBeforePasswordChange(&token.Payload{Username: "user", IssuedAt: changedAt.Add(-time.Minute)}))
+	require.False(t, list.IssuedBeforePasswordChange(&token.Payload{Username: "user", IssuedAt: changedAt.Add(time.Minute)}))
+	require.False(t, list.IssuedBeforePasswordChange(&token.Payload{Username: "other", IssuedAt: changedAt.Add(-time.Minute)}))
+
+	// the issued at claim of a JWT is truncated to the second, a token of the same second is accepted
+	require.False(t, list.IssuedBeforePasswordChange(&token.Payload{Username: "user", IssuedAt: changedAt.Truncate(time.Second)}))
+}
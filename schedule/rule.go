@@ -0,0 +1,251 @@
+package schedule
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+)
+
+// startGrace is how far in the past a schedule can start, so clients can send the current time as its start
+const startGrace = time.Minute
+
+var (
+	ErrInvalidFrequency  = errors.New("frequency must be once, daily, weekly or monthly")
+	ErrInvalidInterval   = errors.New("interval must be at least 1")
+	ErrInvalidDayOfMonth = errors.New("day of month must be between 1 and 31 and is only used by the monthly transfers")
+	ErrInvalidEnd        = errors.New("end date must be after the first occurrence")
+	ErrInvalidCount      = errors.New("max occurrences must be more than the occurrences already run")
+	ErrInvalidStatus     = errors.New("status must be active or paused")
+	ErrStartInPast       = errors.New("start date must not be in the past")
+	ErrNotEditable       = errors.New("scheduled transfer is already completed or cancelled")
+)
+
+// Rule is the recurrence of a scheduled transfer. It is the part of the iCalendar RRULE the transfers need:
+// FREQ with its INTERVAL, BYMONTHDAY for the monthly transfers, and an UNTIL date or a COUNT to end it.
+// The dates are computed in UTC.
+type Rule struct {
+	Frequency string
+	// Interval is the number of days, weeks or months b/w two occurrences
+	Interval int32
+	// DayOfMonth is the day a monthly transfer runs on, the shorter months use their last day
+	DayOfMonth int32
+	// Until is the last date an occurrence can run on, zero when the schedule doesn't end on a date
+	Until time.Time
+	// Count is the number of occurrences, zero when the schedule doesn't end after a count
+	Count int32
+}
+
+// RuleOf returns the recurrence rule of a scheduled transfer
+func RuleOf(schedule db.ScheduledTransfer) Rule {
+	return Rule{
+		Frequency:  schedule.Frequency,
+		Interval:   schedule.Interval,
+		DayOfMonth: schedule.DayOfMonth.Int32,
+		Until:      schedule.EndAt.Time,
+		Count:      schedule.MaxOccurrences.Int32,
+	}
+}
+
+// Validate checks the rule of a schedule which starts on start
+func (r Rule) Validate(start time.Time) error {
+	switch r.Frequency {
+	case db.FrequencyOnce, db.FrequencyDaily, db.FrequencyWeekly, db.FrequencyMonthly:
+	default:
+		return ErrInvalidFrequency
+	}
+	if r.Interval < 1 {
+		return ErrInvalidInterval
+	}
+	if r.DayOfMonth < 0 || r.DayOfMonth > 31 || (r.DayOfMonth != 0 && r.Frequency != db.FrequencyMonthly) {
+		return ErrInvalidDayOfMonth
+	}
+	if r.Count < 0 {
+		return ErrInvalidCount
+	}
+	if !r.Until.IsZero() && r.Until.Before(r.First(start)) {
+		return ErrInvalidEnd
+	}
+	return nil
+}
+
+// First returns the first occurrence of a schedule which starts on start
+func (r Rule) First(start time.Time) time.Time {
+	start = start.UTC()
+	if r.Frequency != db.FrequencyMonthly {
+		return start
+	}
+
+	first := monthDay(start, 0, r.dayOfMonth(start))
+	if first.Before(start) {
+		first = monthDay(start, 1, r.dayOfMonth(start))
+	}
+	return first
+}
+
+// Next returns the occurrence which follows occurrence, ok is false when the rule has no further occurrence
+func (r Rule) Next(occurrence time.Time) (next time.Time, ok bool) {
+	occurrence = occurrence.UTC()
+	interval := int(r.Interval)
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch r.Frequency {
+	case db.FrequencyDaily:
+		return occurrence.AddDate(0, 0, interval), true
+	case db.FrequencyWeekly:
+		return occurrence.AddDate(0, 0, 7*interval), true
+	case db.FrequencyMonthly:
+		return monthDay(occurrence, interval, r.dayOfMonth(occurrence)), true
+	}
+	return time.Time{}, false
+}
+
+// Ends reports whether next is past the end of the rule once occurrences occurrences were run
+func (r Rule) Ends(next time.Time, occurrences int32) bool {
+	if r.Count > 0 && occurrences >= r.Count {
+		return true
+	}
+	return !r.Until.IsZero() && next.After(r.Until)
+}
+
+// String returns the rule in the RRULE syntax, like FREQ=MONTHLY;INTERVAL=1;BYMONTHDAY=1;COUNT=12
+func (r Rule) String() string {
+	if r.Frequency == db.FrequencyOnce {
+		return "FREQ=DAILY;COUNT=1"
+	}
+
+	rule := fmt.Sprintf("FREQ=%s;INTERVAL=%d", frequencyNames[r.Frequency], r.Interval)
+	if r.DayOfMonth != 0 {
+		rule = fmt.Sprintf("%s;BYMONTHDAY=%d", rule, r.DayOfMonth)
+	}
+	if !r.Until.IsZero() {
+		rule = fmt.Sprintf("%s;UNTIL=%s", rule, r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		rule = fmt.Sprintf("%s;COUNT=%d", rule, r.Count)
+	}
+	return rule
+}
+
+var frequencyNames = map[string]string{
+	db.FrequencyDaily:   "DAILY",
+	db.FrequencyWeekly:  "WEEKLY",
+	db.FrequencyMonthly: "MONTHLY",
+}
+
+// dayOfMonth returns the day of a monthly occurrence, the day of t when the rule doesn't set one
+func (r Rule) dayOfMonth(t time.Time) int {
+	if r.DayOfMonth != 0 {
+		return int(r.DayOfMonth)
+	}
+	return t.Day()
+}
+
+// monthDay returns the day of the month which is months after the month of t, at the time of t.
+// The day is moved to the last day of the month when the month is shorter.
+func monthDay(t time.Time, months int, day int) time.Time {
+	year, month, _ := t.Date()
+	hour, minute, second := t.Clock()
+	first := time.Date(year, month+time.Month(months), 1, hour, minute, second, t.Nanosecond(), time.UTC)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// CreateParams contains a new scheduled transfer
+type CreateParams struct {
+	Owner         string
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	Currency      string
+	Rule          Rule
+	Start         time.Time
+}
+
+// NewScheduledTransfer validates a new schedule and returns the params to create it, the schedule first runs
+// on the first occurrence of its rule. The interval defaults to 1 and a monthly schedule without a day of the month
+// runs on the day it starts.
+func NewScheduledTransfer(arg CreateParams) (db.CreateScheduledTransferParams, error) {
+	rule := arg.Rule
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+
+	if arg.Start.Before(time.Now().Add(-startGrace)) {
+		return db.CreateScheduledTransferParams{}, ErrStartInPast
+	}
+	if err := rule.Validate(arg.Start); err != nil {
+		return db.CreateScheduledTransferParams{}, err
+	}
+
+	if rule.Frequency == db.FrequencyMonthly && rule.DayOfMonth == 0 {
+		rule.DayOfMonth = int32(arg.Start.UTC().Day())
+	}
+
+	return db.CreateScheduledTransferParams{
+		Owner:          arg.Owner,
+		FromAccountID:  arg.FromAccountID,
+		ToAccountID:    arg.ToAccountID,
+		Amount:         arg.Amount,
+		Currency:       arg.Currency,
+		Frequency:      rule.Frequency,
+		Interval:       rule.Interval,
+		DayOfMonth:     sql.NullInt32{Int32: rule.DayOfMonth, Valid: rule.DayOfMonth != 0},
+		StartAt:        arg.Start,
+		EndAt:          sql.NullTime{Time: rule.Until, Valid: !rule.Until.IsZero()},
+		MaxOccurrences: sql.NullInt32{Int32: rule.Count, Valid: rule.Count > 0},
+		OccurrenceAt:   rule.First(arg.Start),
+	}, nil
+}
+
+// UpdateParams contains the changes to a scheduled transfer, the zero fields are left unchanged
+type UpdateParams struct {
+	Amount         int64
+	EndAt          time.Time
+	MaxOccurrences int32
+	Status         string
+}
+
+// UpdateScheduledTransfer validates the changes to a schedule and returns the params to update it.
+// A schedule can be paused and resumed, a resumed schedule runs its pending occurrence right away if it is due.
+func UpdateScheduledTransfer(schedule db.ScheduledTransfer, arg UpdateParams) (db.UpdateScheduledTransferParams, error) {
+	if !schedule.IsEditable() {
+		return db.UpdateScheduledTransferParams{}, ErrNotEditable
+	}
+
+	update := db.UpdateScheduledTransferParams{
+		ID:             schedule.ID,
+		Amount:         schedule.Amount,
+		EndAt:          schedule.EndAt,
+		MaxOccurrences: schedule.MaxOccurrences,
+		Status:         schedule.Status,
+	}
+	if arg.Amount > 0 {
+		update.Amount = arg.Amount
+	}
+	if !arg.EndAt.IsZero() {
+		if arg.EndAt.Before(schedule.OccurrenceAt) {
+			return update, ErrInvalidEnd
+		}
+		update.EndAt = sql.NullTime{Time: arg.EndAt, Valid: true}
+	}
+	if arg.MaxOccurrences != 0 {
+		if arg.MaxOccurrences <= schedule.Occurrences {
+			return update, ErrInvalidCount
+		}
+		update.MaxOccurrences = sql.NullInt32{Int32: arg.MaxOccurrences, Valid: true}
+	}
+	if arg.Status != "" {
+		if arg.Status != db.ScheduleStatusActive && arg.Status != db.ScheduleStatusPaused {
+			return update, ErrInvalidStatus
+		}
+		update.Status = arg.Status
+	}
+	return update, nil
+}
@@ -0,0 +1,209 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+}
+
+func TestRuleNext(t *testing.T) {
+	testCases := []struct {
+		name string
+		rule Rule
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "Daily",
+			rule: Rule{Frequency: db.FrequencyDaily, Interval: 2},
+			from: date(2024, time.February, 27),
+			want: []time.Time{date(2024, time.February, 29), date(2024, time.March, 2)},
+		},
+		{
+			name: "Weekly",
+			rule: Rule{Frequency: db.FrequencyWeekly, Interval: 1},
+			from: date(2024, time.December, 25),
+			want: []time.Time{date(2025, time.January, 1), date(2025, time.January, 8)},
+		},
+		{
+			// the day of the month is kept after the shorter months
+			name: "MonthlyOnLastDays",
+			rule: Rule{Frequency: db.FrequencyMonthly, Interval: 1, DayOfMonth: 31},
+			from: date(2024, time.January, 31),
+			want: []time.Time{date(2024, time.February, 29), date(2024, time.March, 31), date(2024, time.April, 30)},
+		},
+		{
+			name: "EveryThreeMonths",
+			rule: Rule{Frequency: db.FrequencyMonthly, Interval: 3, DayOfMonth: 30},
+			from: date(2024, time.November, 30),
+			want: []time.Time{date(2025, time.February, 28), date(2025, time.May, 30)},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			occurrence := tc.from
+			for _, want := range tc.want {
+				next, ok := tc.rule.Next(occurrence)
+				require.True(t, ok)
+				require.Equal(t, want, next)
+				occurrence = next
+			}
+		})
+	}
+
+	_, ok := Rule{Frequency: db.FrequencyOnce, Interval: 1}.Next(date(2024, time.January, 1))
+	require.False(t, ok)
+}
+
+func TestRuleFirst(t *testing.T) {
+	// a monthly schedule starts on this month's day, or next month's when the day is already past
+	rule := Rule{Frequency: db.FrequencyMonthly, Interval: 1, DayOfMonth: 15}
+	require.Equal(t, date(2024, time.January, 15), rule.First(date(2024, time.January, 10)))
+	require.Equal(t, date(2024, time.February, 15), rule.First(date(2024, time.January, 20)))
+
+	rule.DayOfMonth = 31
+	require.Equal(t, date(2024, time.February, 29), rule.First(date(2024, time.February, 2)))
+
+	rule = Rule{Frequency: db.FrequencyWeekly, Interval: 1}
+	require.Equal(t, date(2024, time.January, 20), rule.First(date(2024, time.January, 20)))
+}
+
+func TestRuleEnds(t *testing.T) {
+	rule := Rule{Frequency: db.FrequencyDaily, Interval: 1, Count: 3}
+	require.False(t, rule.Ends(date(2024, time.January, 3), 2))
+	require.True(t, rule.Ends(date(2024, time.January, 4), 3))
+
+	rule = Rule{Frequency: db.FrequencyDaily, Interval: 1, Until: date(2024, time.January, 3)}
+	require.False(t, rule.Ends(date(2024, time.January, 3), 100))
+	require.True(t, rule.Ends(date(2024, time.January, 4), 1))
+}
+
+func TestRuleString(t *testing.T) {
+	rule := Rule{Frequency: db.FrequencyMonthly, Interval: 1, DayOfMonth: 1, Count: 12}
+	require.Equal(t, "FREQ=MONTHLY;INTERVAL=1;BYMONTHDAY=1;COUNT=12", rule.String())
+
+	rule = Rule{Frequency: db.FrequencyWeekly, Interval: 2, Until: date(2024, time.June, 30)}
+	require.Equal(t, "FREQ=WEEKLY;INTERVAL=2;UNTIL=20240630T093000Z", rule.String())
+
+	require.Equal(t, "FREQ=DAILY;COUNT=1", Rule{Frequency: db.FrequencyOnce, Interval: 1}.String())
+}
+
+func TestNewScheduledTransfer(t *testing.T) {
+	start := time.Now().Add(time.Hour).UTC()
+	arg := CreateParams{
+		Owner:         "owner",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        10,
+		Currency:      "USD",
+		Rule:          Rule{Frequency: db.FrequencyMonthly},
+		Start:         start,
+	}
+
+	// the interval and the day of the month default to the start
+	params, err := NewScheduledTransfer(arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), params.Interval)
+	require.Equal(t, int32(start.Day()), params.DayOfMonth.Int32)
+	require.Equal(t, start, params.OccurrenceAt)
+	require.False(t, params.EndAt.Valid)
+	require.False(t, params.MaxOccurrences.Valid)
+
+	testCases := []struct {
+		name   string
+		update func(arg *CreateParams)
+		err    error
+	}{
+		{
+			name:   "InvalidFrequency",
+			update: func(arg *CreateParams) { arg.Rule.Frequency = "yearly" },
+			err:    ErrInvalidFrequency,
+		},
+		{
+			name:   "InvalidInterval",
+			update: func(arg *CreateParams) { arg.Rule.Interval = -1 },
+			err:    ErrInvalidInterval,
+		},
+		{
+			name: "DayOfMonthNotMonthly",
+			update: func(arg *CreateParams) {
+				arg.Rule.Frequency = db.FrequencyWeekly
+				arg.Rule.DayOfMonth = 3
+			},
+			err: ErrInvalidDayOfMonth,
+		},
+		{
+			name:   "InvalidDayOfMonth",
+			update: func(arg *CreateParams) { arg.Rule.DayOfMonth = 32 },
+			err:    ErrInvalidDayOfMonth,
+		},
+		{
+			name:   "EndBeforeStart",
+			update: func(arg *CreateParams) { arg.Rule.Until = start.Add(-time.Minute) },
+			err:    ErrInvalidEnd,
+		},
+		{
+			name:   "StartInPast",
+			update: func(arg *CreateParams) { arg.Start = time.Now().Add(-time.Hour) },
+			err:    ErrStartInPast,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			invalid := arg
+			tc.update(&invalid)
+
+			_, err := NewScheduledTransfer(invalid)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestUpdateScheduledTransfer(t *testing.T) {
+	schedule := db.ScheduledTransfer{
+		ID:           1,
+		Amount:       10,
+		Frequency:    db.FrequencyDaily,
+		Interval:     1,
+		Occurrences:  2,
+		OccurrenceAt: date(2024, time.January, 3),
+		Status:       db.ScheduleStatusActive,
+	}
+
+	// the zero fields are left unchanged
+	params, err := UpdateScheduledTransfer(schedule, UpdateParams{Status: db.ScheduleStatusPaused})
+	require.NoError(t, err)
+	require.Equal(t, schedule.Amount, params.Amount)
+	require.Equal(t, db.ScheduleStatusPaused, params.Status)
+
+	params, err = UpdateScheduledTransfer(schedule, UpdateParams{Amount: 20, MaxOccurrences: 5})
+	require.NoError(t, err)
+	require.Equal(t, int64(20), params.Amount)
+	require.Equal(t, int32(5), params.MaxOccurrences.Int32)
+	require.Equal(t, db.ScheduleStatusActive, params.Status)
+
+	_, err = UpdateScheduledTransfer(schedule, UpdateParams{MaxOccurrences: 2})
+	require.ErrorIs(t, err, ErrInvalidCount)
+
+	_, err = UpdateScheduledTransfer(schedule, UpdateParams{EndAt: date(2024, time.January, 2)})
+	require.ErrorIs(t, err, ErrInvalidEnd)
+
+	_, err = UpdateScheduledTransfer(schedule, UpdateParams{Status: db.ScheduleStatusCompleted})
+	require.ErrorIs(t, err, ErrInvalidStatus)
+
+	schedule.Status = db.ScheduleStatusCancelled
+	_, err = UpdateScheduledTransfer(schedule, UpdateParams{Amount: 20})
+	require.ErrorIs(t, err, ErrNotEditable)
+}
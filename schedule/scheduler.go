@@ -0,0 +1,249 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/lib/pq"
+)
+
+// ErrAccountUnavailable is recorded when an account of a schedule is gone or no longer uses its currency
+var ErrAccountUnavailable = errors.New("account of the scheduled transfer doesn't exist or has another currency")
+
+// Defaults used when the scheduler is not configured
+const (
+	DefaultInterval     = time.Minute
+	DefaultMaxAttempts  = 3
+	DefaultRetryBackoff = 10 * time.Minute
+)
+
+const (
+	// claimDuration is how long the claimed schedules are skipped by the other schedulers, it covers a whole batch
+	claimDuration = 5 * time.Minute
+	// batchSize bounds the schedules claimed at once, the rest is left to the next run
+	batchSize = 100
+	// maxRetryBackoff bounds the wait before a retry, the doubling backoff of many attempts would overflow
+	maxRetryBackoff = 24 * time.Hour
+)
+
+// Store is the part of the database store used to run the scheduled transfers
+type Store interface {
+	ClaimDueScheduledTransfers(ctx context.Context, arg db.ClaimDueScheduledTransfersParams) ([]db.ScheduledTransfer, error)
+	GetAccount(ctx context.Context, id int64) (db.Account, error)
+	TransferTx(ctx context.Context, arg db.TransferTxParams) (db.TransferTxResult, error)
+	RecordScheduledTransferRunTx(ctx context.Context, arg db.RecordScheduledTransferRunTxParams) (db.RecordScheduledTransferRunTxResult, error)
+}
+
+// Scheduler runs the due scheduled transfers. Every replica can run a scheduler, the due schedules are claimed
+// with FOR UPDATE SKIP LOCKED so one occurrence is run by one of them. An occurrence which fails is retried
+// with a growing backoff, like one without the funds yet, and given up after the last attempt so the next
+// occurrence still runs. An occurrence which can't succeed on a retry, like one whose account is gone,
+// is given up right away.
+type Scheduler struct {
+	store        Store
+	maxAttempts  int32
+	retryBackoff time.Duration
+}
+
+// NewScheduler creates the scheduler with the retry policy of the config
+func NewScheduler(store Store, config util.Config) *Scheduler {
+	maxAttempts := int32(config.ScheduledTransferMaxAttempts)
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	retryBackoff := config.ScheduledTransferRetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = DefaultRetryBackoff
+	}
+
+	return &Scheduler{
+		store:        store,
+		maxAttempts:  maxAttempts,
+		retryBackoff: retryBackoff,
+	}
+}
+
+// RunDue claims the due schedules, runs them and returns how many runs were recorded. Every schedule is run
+// on its own, an error is returned after the whole batch along with the errors of the other schedules.
+// A schedule whose run couldn't be recorded stays claimed until its claim expires and is run again then,
+// the idempotency key of its occurrence keeps it from transferring twice.
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	schedules, err := s.store.ClaimDueScheduledTransfers(ctx, db.ClaimDueScheduledTransfersParams{
+		ClaimedUntil: time.Now().Add(claimDuration),
+		BatchSize:    batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	runs := 0
+	var errs []error
+	for _, schedule := range schedules {
+		if err := s.run(ctx, schedule); err != nil {
+			errs = append(errs, fmt.Errorf("scheduled transfer %d: %w", schedule.ID, err))
+			continue
+		}
+		runs++
+	}
+	return runs, errors.Join(errs...)
+}
+
+// run transfers the amount of the claimed occurrence and records the run. The idempotency key of the occurrence
+// makes sure a run which is repeated after a crash, before it was recorded, doesn't transfer twice.
+func (s *Scheduler) run(ctx context.Context, schedule db.ScheduledTransfer) error {
+	attempt := schedule.Attempts + 1
+	arg := db.RecordScheduledTransferRunTxParams{
+		Run: db.CreateScheduledTransferRunParams{
+			ScheduledTransferID: schedule.ID,
+			OccurrenceAt:        schedule.OccurrenceAt,
+			Attempt:             attempt,
+		},
+	}
+
+	var result db.TransferTxResult
+	err := s.checkAccounts(ctx, schedule)
+	if err == nil {
+		result, err = s.store.TransferTx(ctx, db.TransferTxParams{
+			FromAccountId:  schedule.FromAccountID,
+			ToAccountId:    schedule.ToAccountID,
+			Amount:         occurrenceAmount(schedule),
+			IdempotencyKey: idempotencyKey(schedule),
+		})
+	}
+	switch {
+	case err == nil:
+		arg.Run.Status = db.RunStatusSucceeded
+		arg.Run.TransferID = sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+		arg.Schedule = advance(schedule, time.Now())
+	case ctx.Err() != nil:
+		// the scheduler is stopping, the claim expires and the occurrence is run again
+		return err
+	case attempt < s.maxAttempts && !isPermanent(err):
+		arg.Run.Status = db.RunStatusRetrying
+		arg.Run.Error = err.Error()
+		arg.Schedule = db.AdvanceScheduledTransferParams{
+			ID:           schedule.ID,
+			Occurrences:  schedule.Occurrences,
+			OccurrenceAt: schedule.OccurrenceAt,
+			NextRunAt:    time.Now().Add(s.backoff(attempt)),
+			Attempts:     attempt,
+			Status:       db.ScheduleStatusActive,
+		}
+	default:
+		arg.Run.Status = db.RunStatusFailed
+		arg.Run.Error = err.Error()
+		arg.Schedule = advance(schedule, time.Now())
+	}
+
+	_, err = s.store.RecordScheduledTransferRunTx(ctx, arg)
+	return err
+}
+
+// checkAccounts checks that both accounts still exist and use the currency of the schedule
+func (s *Scheduler) checkAccounts(ctx context.Context, schedule db.ScheduledTransfer) error {
+	for _, id := range []int64{schedule.FromAccountID, schedule.ToAccountID} {
+		account, err := s.store.GetAccount(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrAccountUnavailable
+			}
+			return err
+		}
+		if account.Currency != schedule.Currency {
+			return ErrAccountUnavailable
+		}
+	}
+	return nil
+}
+
+// isPermanent reports whether a failed occurrence fails the same way on a retry
+func isPermanent(err error) bool {
+	if errors.Is(err, ErrAccountUnavailable) || errors.Is(err, db.ErrIdempotencyKeyConflict) {
+		return true
+	}
+
+	// a single transfer over the limit is rejected whenever it runs, the daily and hourly limits reset
+	var velocityErr *db.VelocityError
+	if errors.As(err, &velocityErr) {
+		return velocityErr.Reason == db.VelocityReasonMaxAmount
+	}
+
+	// a violated constraint, like an account which was deleted
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Class() == "23"
+}
+
+// occurrenceAmount is the amount frozen for the occurrence when it was claimed, so changing the amount
+// of the schedule while an occurrence is retried applies from the next occurrence
+func occurrenceAmount(schedule db.ScheduledTransfer) int64 {
+	if schedule.OccurrenceAmount.Valid {
+		return schedule.OccurrenceAmount.Int64
+	}
+	return schedule.Amount
+}
+
+// backoff returns the wait before the next attempt, it doubles after every failed attempt up to maxRetryBackoff
+func (s *Scheduler) backoff(attempt int32) time.Duration {
+	wait := s.retryBackoff
+	for i := int32(1); i < attempt && wait < maxRetryBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxRetryBackoff)
+}
+
+// advance moves the schedule to its first occurrence after now, or completes it when its rule ends.
+// Occurrences missed while the schedule was paused or no scheduler was running are skipped,
+// so a schedule never runs several transfers at once to catch up.
+func advance(schedule db.ScheduledTransfer, now time.Time) db.AdvanceScheduledTransferParams {
+	arg := db.AdvanceScheduledTransferParams{
+		ID:           schedule.ID,
+		Occurrences:  schedule.Occurrences + 1,
+		OccurrenceAt: schedule.OccurrenceAt,
+		NextRunAt:    schedule.OccurrenceAt,
+		Status:       db.ScheduleStatusCompleted,
+	}
+
+	rule := RuleOf(schedule)
+	next, ok := rule.Next(schedule.OccurrenceAt)
+	for ok && !next.After(now) {
+		next, ok = rule.Next(next)
+	}
+	if !ok || rule.Ends(next, arg.Occurrences) {
+		return arg
+	}
+
+	arg.OccurrenceAt = next
+	arg.NextRunAt = next
+	arg.Status = db.ScheduleStatusActive
+	return arg
+}
+
+// idempotencyKey identifies the transfer of one occurrence of a schedule, it is reserved so no client
+// of the owner can send it
+func idempotencyKey(schedule db.ScheduledTransfer) string {
+	return fmt.Sprintf("%sscheduled-transfer-%d-%d", db.ReservedIdempotencyKeyPrefix, schedule.ID, schedule.OccurrenceAt.Unix())
+}
+
+// Run runs the due scheduled transfers on every interval until the context is done
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := s.RunDue(ctx)
+			if err != nil {
+				log.Println("failed to run the scheduled transfers:", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomSchedule(occurrenceAt time.Time) db.ScheduledTransfer {
+	return db.ScheduledTransfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: util.RandomInt(1, 1000),
+		ToAccountID:   util.RandomInt(1001, 2000),
+		Amount:        util.RandomBalance(),
+		Currency:      util.USD,
+		Frequency:     db.FrequencyDaily,
+		Interval:      1,
+		OccurrenceAt:  occurrenceAt,
+		NextRunAt:     occurrenceAt,
+		Status:        db.ScheduleStatusActive,
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	now := time.Now().UTC()
+	schedule := randomSchedule(now.Add(-time.Minute))
+
+	arg := advance(schedule, now)
+	require.Equal(t, db.ScheduleStatusActive, arg.Status)
+	require.Equal(t, int32(1), arg.Occurrences)
+	require.Equal(t, schedule.OccurrenceAt.AddDate(0, 0, 1), arg.OccurrenceAt)
+	require.Equal(t, arg.OccurrenceAt, arg.NextRunAt)
+	require.Zero(t, arg.Attempts)
+
+	// the occurrences missed while no scheduler ran are skipped
+	schedule.OccurrenceAt = now.AddDate(0, 0, -3).Add(-time.Minute)
+	arg = advance(schedule, now)
+	require.Equal(t, schedule.OccurrenceAt.AddDate(0, 0, 4), arg.OccurrenceAt)
+	require.True(t, arg.NextRunAt.After(now))
+
+	// the schedule is completed after its last occurrence
+	schedule.MaxOccurrences = sql.NullInt32{Int32: 1, Valid: true}
+	arg = advance(schedule, now)
+	require.Equal(t, db.ScheduleStatusCompleted, arg.Status)
+
+	schedule = randomSchedule(now.Add(-time.Minute))
+	schedule.Frequency = db.FrequencyOnce
+	arg = advance(schedule, now)
+	require.Equal(t, db.ScheduleStatusCompleted, arg.Status)
+	require.Equal(t, int32(1), arg.Occurrences)
+}
+
+func TestRunDue(t *testing.T) {
+	now := time.Now().UTC()
+	config := util.Config{ScheduledTransferMaxAttempts: 2, ScheduledTransferRetryBackoff: time.Minute}
+	errConnection := errors.New("connection reset by peer")
+
+	testCases := []struct {
+		name        string
+		attempts    int32
+		toCurrency  string
+		transferErr error
+		noTransfer  bool
+		check       func(t *testing.T, arg db.RecordScheduledTransferRunTxParams)
+	}{
+		{
+			name: "Succeeded",
+			check: func(t *testing.T, arg db.RecordScheduledTransferRunTxParams) {
+				require.Equal(t, db.RunStatusSucceeded, arg.Run.Status)
+				require.True(t, arg.Run.TransferID.Valid)
+				require.Equal(t, int32(1), arg.Run.Attempt)
+				require.Equal(t, int32(1), arg.Schedule.Occurrences)
+				require.True(t, arg.Schedule.NextRunAt.After(now))
+			},
+		},
+		{
+			name:        "Retrying",
+			transferErr: errConnection,
+			check: func(t *testing.T, arg db.RecordScheduledTransferRunTxParams) {
+				require.Equal(t, db.RunStatusRetrying, arg.Run.Status)
+				require.Equal(t, errConnection.Error(), arg.Run.Error)
+				require.False(t, arg.Run.TransferID.Valid)
+				require.Zero(t, arg.Schedule.Occurrences)
+				require.Equal(t, int32(1), arg.Schedule.Attempts)
+				require.Equal(t, arg.Run.OccurrenceAt, arg.Schedule.OccurrenceAt)
+				require.WithinDuration(t, time.Now().Add(time.Minute), arg.Schedule.NextRunAt, time.Second)
+			},
+		},
+		{
+			// the occurrence is given up after the last attempt and the next one is scheduled
+			name:        "Failed",
+			attempts:    1,
+			transferErr: errConnection,
+			check: func(t *testing.T, arg db.RecordScheduledTransferRunTxParams) {
+				require.Equal(t, db.RunStatusFailed, arg.Run.Status)
+				require.Equal(t, int32(2), arg.Run.Attempt)
+				require.Equal(t, int32(1), arg.Schedule.Occurrences)
+				require.Zero(t, arg.Schedule.Attempts)
+				require.Equal(t, db.ScheduleStatusActive, arg.Schedule.Status)
+				require.True(t, arg.Schedule.NextRunAt.After(now))
+			},
+		},
+		{
+			// the account may be funded before the next attempt
+			name:        "InsufficientFunds",
+			transferErr: db.ErrInsufficientFunds,
+			check: func(t *testing.T, arg db.RecordScheduledTransferRunTxParams) {
+				require.Equal(t, db.RunStatusRetrying, arg.Run.Status)
+				require.Equal(t, db.ErrInsufficientFunds.Error(), arg.Run.Error)
+				require.Equal(t, int32(1), arg.Run.Attempt)
+				require.Zero(t, arg.Schedule.Occurrences)
+				require.WithinDuration(t, time.Now().Add(time.Minute), arg.Schedule.NextRunAt, time.Second)
+			},
+		},
+		{
+			// a failure a retry can't fix gives the occurrence up on the first attempt
+			name:        "IdempotencyKeyConflict",
+			transferErr: db.ErrIdempotencyKeyConflict,
+			check: func(t *testing.T, arg db.RecordScheduledTransferRunTxParams) {
+				require.Equal(t, db.RunStatusFailed, arg.Run.Status)
+				require.Equal(t, int32(1), arg.Run.Attempt)
+				require.Equal(t, int32(1), arg.Schedule.Occurrences)
+				require.True(t, arg.Schedule.NextRunAt.After(now))
+			},
+		},
+		{
+			name:        "MaxAmountExceeded",
+			transferErr: &db.VelocityError{Reason: db.VelocityReasonMaxAmount},
+			check: func(t *testing.T, arg db.RecordScheduledTransferRunTxParams) {
+				require.Equal(t, db.RunStatusFailed, arg.Run.Status)
+				require.Equal(t, int32(1), arg.Schedule.Occurrences)
+			},
+		},
+		{
+			name:        "DailyAmountExceeded",
+			transferErr: &db.VelocityError{Reason: db.VelocityReasonMaxDailyAmount},
+			check: func(t *testing.T, arg db.RecordScheduledTransferRunTxParams) {
+				require.Equal(t, db.RunStatusRetrying, arg.Run.Status)
+				require.Zero(t, arg.Schedule.Occurrences)
+			},
+		},
+		{
+			name:       "AccountCurrencyChanged",
+			toCurrency: util.EUR,
+			noTransfer: true,
+			check: func(t *testing.T, arg db.RecordScheduledTransferRunTxParams) {
+				require.Equal(t, db.RunStatusFailed, arg.Run.Status)
+				require.Equal(t, ErrAccountUnavailable.Error(), arg.Run.Error)
+				require.Equal(t, int32(1), arg.Schedule.Occurrences)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			schedule := randomSchedule(now.Add(-time.Minute))
+			schedule.Attempts = tc.attempts
+			toCurrency := schedule.Currency
+			if tc.toCurrency != "" {
+				toCurrency = tc.toCurrency
+			}
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return([]db.ScheduledTransfer{schedule}, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(schedule.FromAccountID)).
+				Times(1).
+				Return(db.Account{ID: schedule.FromAccountID, Currency: schedule.Currency}, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(schedule.ToAccountID)).
+				Times(1).
+				Return(db.Account{ID: schedule.ToAccountID, Currency: toCurrency}, nil)
+
+			transfers := 1
+			if tc.noTransfer {
+				transfers = 0
+			}
+			store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+					FromAccountId:  schedule.FromAccountID,
+					ToAccountId:    schedule.ToAccountID,
+					Amount:         schedule.Amount,
+					IdempotencyKey: idempotencyKey(schedule),
+				})).
+				Times(transfers).
+				Return(db.TransferTxResult{Transfer: db.Transfer{ID: 1}}, tc.transferErr)
+			store.EXPECT().
+				RecordScheduledTransferRunTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.RecordScheduledTransferRunTxParams) (db.RecordScheduledTransferRunTxResult, error) {
+					require.Equal(t, schedule.ID, arg.Run.ScheduledTransferID)
+					require.Equal(t, schedule.ID, arg.Schedule.ID)
+					tc.check(t, arg)
+					return db.RecordScheduledTransferRunTxResult{}, nil
+				})
+
+			runs, err := NewScheduler(store, config).RunDue(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, runs)
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	scheduler := NewScheduler(nil, util.Config{ScheduledTransferRetryBackoff: time.Minute})
+
+	// the wait doubles after every attempt and is capped, a large attempt count doesn't overflow it
+	require.Equal(t, time.Minute, scheduler.backoff(1))
+	require.Equal(t, 2*time.Minute, scheduler.backoff(2))
+	require.Equal(t, 8*time.Minute, scheduler.backoff(4))
+	require.Equal(t, maxRetryBackoff, scheduler.backoff(20))
+	require.Equal(t, maxRetryBackoff, scheduler.backoff(math.MaxInt32))
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	schedule := randomSchedule(time.Now())
+	require.True(t, strings.HasPrefix(idempotencyKey(schedule), db.ReservedIdempotencyKeyPrefix))
+}
+
+func TestRunDueBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().UTC()
+	failing := randomSchedule(now.Add(-time.Minute))
+	deleted := randomSchedule(now.Add(-time.Minute))
+	// the amount was changed after the occurrence was first claimed
+	frozen := randomSchedule(now.Add(-time.Minute))
+	frozen.OccurrenceAmount = sql.NullInt64{Int64: frozen.Amount + 1, Valid: true}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ScheduledTransfer{failing, deleted, frozen}, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(deleted.FromAccountID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).AnyTimes().Return(db.Account{Currency: util.USD}, nil)
+
+	// 1. the run of the first schedule can't be recorded, the others still run
+	store.EXPECT().
+		TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+			FromAccountId:  failing.FromAccountID,
+			ToAccountId:    failing.ToAccountID,
+			Amount:         failing.Amount,
+			IdempotencyKey: idempotencyKey(failing),
+		})).
+		Times(1).
+		Return(db.TransferTxResult{Transfer: db.Transfer{ID: 1}}, nil)
+
+	// 2. the occurrence transfers the amount frozen when it was claimed
+	store.EXPECT().
+		TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+			FromAccountId:  frozen.FromAccountID,
+			ToAccountId:    frozen.ToAccountID,
+			Amount:         frozen.OccurrenceAmount.Int64,
+			IdempotencyKey: idempotencyKey(frozen),
+		})).
+		Times(1).
+		Return(db.TransferTxResult{Transfer: db.Transfer{ID: 2}}, nil)
+
+	errRecord := errors.New("connection reset by peer")
+	store.EXPECT().
+		RecordScheduledTransferRunTx(gomock.Any(), gomock.Any()).
+		Times(3).
+		DoAndReturn(func(_ context.Context, arg db.RecordScheduledTransferRunTxParams) (db.RecordScheduledTransferRunTxResult, error) {
+			switch arg.Run.ScheduledTransferID {
+			case failing.ID:
+				return db.RecordScheduledTransferRunTxResult{}, errRecord
+			case deleted.ID:
+				// 3. a deleted account fails the occurrence for good
+				require.Equal(t, db.RunStatusFailed, arg.Run.Status)
+				require.Equal(t, ErrAccountUnavailable.Error(), arg.Run.Error)
+			}
+			return db.RecordScheduledTransferRunTxResult{}, nil
+		})
+
+	runs, err := NewScheduler(store, util.Config{}).RunDue(context.Background())
+	require.ErrorIs(t, err, errRecord)
+	require.Equal(t, 2, runs)
+}
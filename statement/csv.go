@@ -0,0 +1,40 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{"date", "description", "counterparty_account_id", "transfer_id", "amount", "balance", "currency"}
+
+// writeCSV writes the statement as CSV, the lines are framed by a row of the opening and of the closing balance
+func (s Statement) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	rows := make([][]string, 0, len(s.Lines)+3)
+	rows = append(rows, csvHeader)
+	rows = append(rows, []string{s.From.Format(DateLayout), "Opening balance", "", "", "", s.Format(s.OpeningBalance), s.Currency.Code})
+	for _, line := range s.Lines {
+		rows = append(rows, []string{
+			line.CreatedAt.UTC().Format(time.RFC3339),
+			line.description(),
+			nullInt64(line.CounterpartyAccountID.Int64, line.CounterpartyAccountID.Valid),
+			nullInt64(line.TransferID.Int64, line.TransferID.Valid),
+			s.Format(line.Amount),
+			s.Format(line.RunningBalance),
+			s.Currency.Code,
+		})
+	}
+	rows = append(rows, []string{s.To.Format(DateLayout), "Closing balance", "", "", "", s.Format(s.ClosingBalance), s.Currency.Code})
+
+	return writer.WriteAll(rows)
+}
+
+func nullInt64(value int64, valid bool) string {
+	if !valid {
+		return ""
+	}
+	return strconv.FormatInt(value, 10)
+}
@@ -0,0 +1,124 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ofxTimeLayout is the datetime format of OFX, always written in UTC
+const ofxTimeLayout = "20060102150405.000[0:GMT]"
+
+type ofxDocument struct {
+	XMLName xml.Name        `xml:"OFX"`
+	SignOn  ofxSignOn       `xml:"SIGNONMSGSRSV1>SONRS"`
+	Bank    ofxStatementSet `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignOn struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DTServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+type ofxStatementSet struct {
+	TrnUID    string       `xml:"TRNUID"`
+	Status    ofxStatus    `xml:"STATUS"`
+	Statement ofxStatement `xml:"STMTRS"`
+}
+
+type ofxStatement struct {
+	Currency     string           `xml:"CURDEF"`
+	Account      ofxAccount       `xml:"BANKACCTFROM"`
+	Transactions ofxTransactions  `xml:"BANKTRANLIST"`
+	LedgerBal    ofxLedgerBalance `xml:"LEDGERBAL"`
+}
+
+type ofxAccount struct {
+	BankID    string `xml:"BANKID"`
+	AccountID string `xml:"ACCTID"`
+	Type      string `xml:"ACCTTYPE"`
+}
+
+type ofxTransactions struct {
+	Start        string           `xml:"DTSTART"`
+	End          string           `xml:"DTEND"`
+	Transactions []ofxTransaction `xml:"STMTTRN"`
+}
+
+type ofxTransaction struct {
+	Type   string `xml:"TRNTYPE"`
+	Posted string `xml:"DTPOSTED"`
+	Amount string `xml:"TRNAMT"`
+	FitID  string `xml:"FITID"`
+	Name   string `xml:"NAME"`
+}
+
+type ofxLedgerBalance struct {
+	Amount string `xml:"BALAMT"`
+	AsOf   string `xml:"DTASOF"`
+}
+
+// writeOFX writes the statement as an OFX 2 bank statement, the FITID of a transaction is the id of its entry
+func (s Statement) writeOFX(w io.Writer) error {
+	transactions := make([]ofxTransaction, 0, len(s.Lines))
+	for _, line := range s.Lines {
+		trnType := "CREDIT"
+		if line.Amount < 0 {
+			trnType = "DEBIT"
+		}
+		transactions = append(transactions, ofxTransaction{
+			Type:   trnType,
+			Posted: ofxTime(line.CreatedAt),
+			Amount: s.Format(line.Amount),
+			FitID:  strconv.FormatInt(line.ID, 10),
+			Name:   line.description(),
+		})
+	}
+
+	document := ofxDocument{
+		SignOn: ofxSignOn{
+			Status:   ofxStatus{Code: 0, Severity: "INFO"},
+			DTServer: ofxTime(time.Now()),
+			Language: "ENG",
+		},
+		Bank: ofxStatementSet{
+			TrnUID: "0",
+			Status: ofxStatus{Code: 0, Severity: "INFO"},
+			Statement: ofxStatement{
+				Currency: s.Currency.Code,
+				Account: ofxAccount{
+					BankID:    "backend-with-go",
+					AccountID: strconv.FormatInt(s.Account.ID, 10),
+					Type:      "CHECKING",
+				},
+				Transactions: ofxTransactions{
+					Start:        ofxTime(s.From),
+					End:          ofxTime(s.end()),
+					Transactions: transactions,
+				},
+				LedgerBal: ofxLedgerBalance{
+					Amount: s.Format(s.ClosingBalance),
+					AsOf:   ofxTime(s.end()),
+				},
+			},
+		},
+	}
+
+	if _, err := io.WriteString(w, xml.Header+`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>`+"\n"); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(document)
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format(ofxTimeLayout)
+}
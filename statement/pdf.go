@@ -0,0 +1,121 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The statement is printed in a monospaced font on A4 pages, so the columns line up without measuring the text
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 40
+	pdfFontSize     = 9
+	pdfLeading      = 12
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+	pdfRowFormat    = "%-20s %-42s %14s %14s"
+)
+
+// writePDF writes the statement as a PDF document, a page is added whenever the lines don't fit
+func (s Statement) writePDF(w io.Writer) error {
+	text := []string{
+		"Account statement",
+		"",
+		fmt.Sprintf("Account: %d", s.Account.ID),
+		fmt.Sprintf("Owner: %s", s.Account.Owner),
+		fmt.Sprintf("Currency: %s", s.Currency.Code),
+		fmt.Sprintf("Period: %s to %s", s.From.Format(DateLayout), s.To.Format(DateLayout)),
+		"",
+		fmt.Sprintf(pdfRowFormat, "Date", "Description", "Amount", "Balance"),
+		fmt.Sprintf(pdfRowFormat, s.From.Format(DateLayout), "Opening balance", "", s.Format(s.OpeningBalance)),
+	}
+	for _, line := range s.Lines {
+		text = append(text, fmt.Sprintf(pdfRowFormat,
+			line.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+			truncate(line.description(), 42),
+			s.Format(line.Amount),
+			s.Format(line.RunningBalance),
+		))
+	}
+	text = append(text, fmt.Sprintf(pdfRowFormat, s.To.Format(DateLayout), "Closing balance", "", s.Format(s.ClosingBalance)))
+
+	var pages []string
+	for start := 0; start < len(text); start += pdfLinesPerPage {
+		end := min(start+pdfLinesPerPage, len(text))
+		pages = append(pages, pdfPageContent(text[start:end]))
+	}
+	return writePDFDocument(w, pages)
+}
+
+// pdfPageContent returns the content stream which prints the lines from the top of a page
+func pdfPageContent(lines []string) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) '\n", pdfEscape(line))
+	}
+	content.WriteString("ET\n")
+	return content.String()
+}
+
+// writePDFDocument writes the objects of the document and the cross reference table which locates them.
+// Objects 1 to 3 are the catalog, the page tree and the font, then each page is followed by its content stream.
+func writePDFDocument(w io.Writer, pages []string) error {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// pdfEscape escapes a string literal of PDF, the characters outside of ASCII are replaced as the font can't print them
+func pdfEscape(s string) string {
+	var escaped strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r < ' ' || r > '~':
+			escaped.WriteByte('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length-3] + "..."
+}
@@ -0,0 +1,171 @@
+package statement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/akshay237/backend-with-go/currency"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+)
+
+const (
+	// DateLayout is the layout of the dates which bound a statement
+	DateLayout = "2006-01-02"
+	// MaxPeriod is the longest period a statement can cover
+	MaxPeriod = 366 * 24 * time.Hour
+)
+
+// Formats a statement can be exported to
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatOFX  = "ofx"
+	FormatPDF  = "pdf"
+)
+
+var (
+	ErrInvalidPeriod = fmt.Errorf("from and to must be dates like 2024-01-31, from not after to, at most %d days apart", int(MaxPeriod.Hours()/24))
+	ErrInvalidFormat = errors.New("format must be json, csv, ofx or pdf")
+)
+
+// Store is the part of the database store used to build the statements
+type Store interface {
+	GetAccountBalanceAt(ctx context.Context, arg db.GetAccountBalanceAtParams) (int64, error)
+	ListStatementEntries(ctx context.Context, arg db.ListStatementEntriesParams) ([]db.ListStatementEntriesRow, error)
+}
+
+// Line is an entry of the statement with the balance of the account once it was posted
+type Line struct {
+	db.ListStatementEntriesRow
+	RunningBalance int64 `json:"running_balance"`
+}
+
+// Statement is the history of an account over a period, from the start of the From day to the end of the To day in UTC.
+// The balances are the sums of the entries, so they always match the lines.
+type Statement struct {
+	Account        db.Account
+	Currency       currency.Currency
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	Lines          []Line
+}
+
+// ParsePeriod parses the dates which bound a statement, the to date is included
+func ParsePeriod(from string, to string) (time.Time, time.Time, error) {
+	fromDate, err := time.Parse(DateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+	toDate, err := time.Parse(DateLayout, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+	if toDate.Before(fromDate) || toDate.Sub(fromDate) >= MaxPeriod {
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+	return fromDate, toDate, nil
+}
+
+// New builds the statement of the account from the from date to the to date
+func New(ctx context.Context, store Store, account db.Account, cur currency.Currency, from time.Time, to time.Time) (Statement, error) {
+	statement := Statement{
+		Account:  account,
+		Currency: cur,
+		From:     from,
+		To:       to,
+	}
+
+	// 1. the opening balance is the sum of the entries posted before the period
+	var err error
+	statement.OpeningBalance, err = store.GetAccountBalanceAt(ctx, db.GetAccountBalanceAtParams{
+		AccountID: account.ID,
+		At:        from,
+	})
+	if err != nil {
+		return statement, err
+	}
+
+	// 2. the entries of the period with the balance after each of them
+	entries, err := store.ListStatementEntries(ctx, db.ListStatementEntriesParams{
+		AccountID: account.ID,
+		FromTime:  from,
+		ToTime:    statement.end(),
+	})
+	if err != nil {
+		return statement, err
+	}
+
+	balance := statement.OpeningBalance
+	statement.Lines = make([]Line, 0, len(entries))
+	for _, entry := range entries {
+		balance += entry.Amount
+		statement.Lines = append(statement.Lines, Line{ListStatementEntriesRow: entry, RunningBalance: balance})
+	}
+	statement.ClosingBalance = balance
+	return statement, nil
+}
+
+// end returns the end of the period, the start of the day after the to date
+func (s Statement) end() time.Time {
+	return s.To.AddDate(0, 0, 1)
+}
+
+// Format formats an amount in the currency of the statement, without its symbol
+func (s Statement) Format(amount int64) string {
+	return s.Currency.Format(amount)
+}
+
+// Export writes the statement in a downloadable format
+func (s Statement) Export(w io.Writer, format string) error {
+	switch format {
+	case FormatCSV:
+		return s.writeCSV(w)
+	case FormatOFX:
+		return s.writeOFX(w)
+	case FormatPDF:
+		return s.writePDF(w)
+	}
+	return ErrInvalidFormat
+}
+
+// ContentType returns the media type of a format a statement can be exported to
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/json"
+}
+
+// Filename returns the name the statement is downloaded with
+func (s Statement) Filename(format string) string {
+	return fmt.Sprintf("statement-%d-%s-%s.%s", s.Account.ID, s.From.Format(DateLayout), s.To.Format(DateLayout), format)
+}
+
+// description returns what the line is about, the counterparty of a transfer
+func (l Line) description() string {
+	switch {
+	case !l.TransferID.Valid:
+		return "Adjustment"
+	case l.ReversalOf.Valid && l.Amount >= 0:
+		return fmt.Sprintf("Refund from %s", l.counterparty())
+	case l.ReversalOf.Valid:
+		return fmt.Sprintf("Refund to %s", l.counterparty())
+	case l.Amount >= 0:
+		return fmt.Sprintf("Transfer from %s", l.counterparty())
+	}
+	return fmt.Sprintf("Transfer to %s", l.counterparty())
+}
+
+func (l Line) counterparty() string {
+	return fmt.Sprintf("%s (account %d)", l.CounterpartyOwner.String, l.CounterpartyAccountID.Int64)
+}
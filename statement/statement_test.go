@@ -0,0 +1,205 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/currency"
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var usd = currency.Currency{Code: util.USD, NumericCode: 840, Exponent: 2, Symbol: "$"}
+
+func randomStatement(t *testing.T) Statement {
+	from, to, err := ParsePeriod("2024-01-01", "2024-01-31")
+	require.NoError(t, err)
+
+	account := db.Account{ID: util.RandomInt(1, 1000), Owner: util.RandomOwner(), Currency: util.USD}
+	counterparty := sql.NullInt64{Int64: util.RandomInt(1001, 2000), Valid: true}
+	owner := sql.NullString{String: "o(w)ner", Valid: true}
+
+	return Statement{
+		Account:        account,
+		Currency:       usd,
+		From:           from,
+		To:             to,
+		OpeningBalance: 10000,
+		ClosingBalance: 7550,
+		Lines: []Line{
+			{
+				ListStatementEntriesRow: db.ListStatementEntriesRow{
+					ID:                    1,
+					Amount:                -2500,
+					TransferID:            sql.NullInt64{Int64: 10, Valid: true},
+					CreatedAt:             from.Add(time.Hour),
+					CounterpartyAccountID: counterparty,
+					CounterpartyOwner:     owner,
+				},
+				RunningBalance: 7500,
+			},
+			{
+				ListStatementEntriesRow: db.ListStatementEntriesRow{
+					ID:                    2,
+					Amount:                50,
+					TransferID:            sql.NullInt64{Int64: 11, Valid: true},
+					CreatedAt:             from.Add(2 * time.Hour),
+					CounterpartyAccountID: counterparty,
+					CounterpartyOwner:     owner,
+					ReversalOf:            sql.NullInt64{Int64: 10, Valid: true},
+				},
+				RunningBalance: 7550,
+			},
+		},
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	from, to, err := ParsePeriod("2024-01-01", "2024-01-01")
+	require.NoError(t, err)
+	require.Equal(t, from, to)
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), from)
+
+	_, _, err = ParsePeriod("2024-01-01", "2024-12-31")
+	require.NoError(t, err)
+
+	for _, period := range [][2]string{
+		{"2024-02-01", "2024-01-31"},
+		{"2024-01-01", "2025-01-01"},
+		{"01/01/2024", "2024-01-31"},
+		{"2024-01-01", ""},
+	} {
+		_, _, err := ParsePeriod(period[0], period[1])
+		require.ErrorIs(t, err, ErrInvalidPeriod, period)
+	}
+}
+
+func TestNew(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	expected := randomStatement(t)
+	entries := make([]db.ListStatementEntriesRow, 0, len(expected.Lines))
+	for _, line := range expected.Lines {
+		entries = append(entries, line.ListStatementEntriesRow)
+	}
+
+	// the period ends at the start of the day after the to date
+	store.EXPECT().
+		GetAccountBalanceAt(gomock.Any(), gomock.Eq(db.GetAccountBalanceAtParams{AccountID: expected.Account.ID, At: expected.From})).
+		Times(1).
+		Return(expected.OpeningBalance, nil)
+	store.EXPECT().
+		ListStatementEntries(gomock.Any(), gomock.Eq(db.ListStatementEntriesParams{
+			AccountID: expected.Account.ID,
+			FromTime:  expected.From,
+			ToTime:    expected.To.AddDate(0, 0, 1),
+		})).
+		Times(1).
+		Return(entries, nil)
+
+	st, err := New(context.Background(), store, expected.Account, usd, expected.From, expected.To)
+	require.NoError(t, err)
+	require.Equal(t, expected, st)
+}
+
+func TestNewWithoutEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetAccountBalanceAt(gomock.Any(), gomock.Any()).Times(1).Return(int64(-300), nil)
+	store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+
+	from, to, err := ParsePeriod("2024-01-01", "2024-01-31")
+	require.NoError(t, err)
+
+	st, err := New(context.Background(), store, db.Account{ID: 1}, usd, from, to)
+	require.NoError(t, err)
+	require.Empty(t, st.Lines)
+	require.Equal(t, int64(-300), st.OpeningBalance)
+	require.Equal(t, st.OpeningBalance, st.ClosingBalance)
+}
+
+func TestExportCSV(t *testing.T) {
+	st := randomStatement(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, st.Export(&buf, FormatCSV))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, len(st.Lines)+3)
+	require.Equal(t, csvHeader, rows[0])
+	require.Equal(t, []string{"2024-01-01", "Opening balance", "", "", "", "100.00", "USD"}, rows[1])
+	require.Equal(t, "Transfer to o(w)ner", strings.Split(rows[2][1], " (")[0])
+	require.Equal(t, []string{"-25.00", "75.00"}, rows[2][4:6])
+	require.True(t, strings.HasPrefix(rows[3][1], "Refund from"))
+	require.Equal(t, []string{"2024-01-31", "Closing balance", "", "", "", "75.50", "USD"}, rows[4])
+}
+
+func TestExportOFX(t *testing.T) {
+	st := randomStatement(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, st.Export(&buf, FormatOFX))
+	require.Contains(t, buf.String(), `<?OFX OFXHEADER="200"`)
+
+	var document ofxDocument
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &document))
+
+	statement := document.Bank.Statement
+	require.Equal(t, util.USD, statement.Currency)
+	require.Equal(t, "20240201000000.000[0:GMT]", statement.Transactions.End)
+	require.Len(t, statement.Transactions.Transactions, 2)
+	require.Equal(t, "DEBIT", statement.Transactions.Transactions[0].Type)
+	require.Equal(t, "-25.00", statement.Transactions.Transactions[0].Amount)
+	require.Equal(t, "CREDIT", statement.Transactions.Transactions[1].Type)
+	require.Equal(t, "75.50", statement.LedgerBal.Amount)
+}
+
+func TestExportPDF(t *testing.T) {
+	st := randomStatement(t)
+
+	// the lines of a long statement are split across pages
+	for i := 0; i < 2*pdfLinesPerPage; i++ {
+		st.Lines = append(st.Lines, st.Lines[0])
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, st.Export(&buf, FormatPDF))
+
+	document := buf.String()
+	require.True(t, strings.HasPrefix(document, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(document, "%%EOF\n"))
+	require.Contains(t, document, "/Count 3")
+	require.Contains(t, document, `Transfer to o\(w\)ner`)
+
+	// every object is where the cross reference table says it is
+	xref := strings.Index(document, "xref\n")
+	require.Positive(t, xref)
+	for i, entry := range strings.Split(document[xref:], "\n")[3:] {
+		if !strings.HasSuffix(entry, " n ") {
+			break
+		}
+		offset, err := strconv.Atoi(entry[:10])
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(document[offset:], strconv.Itoa(i+1)+" 0 obj"))
+	}
+}
+
+func TestExportInvalidFormat(t *testing.T) {
+	st := randomStatement(t)
+	require.ErrorIs(t, st.Export(&bytes.Buffer{}, FormatJSON), ErrInvalidFormat)
+	require.Equal(t, "application/pdf", ContentType(FormatPDF))
+	require.Equal(t, "statement-1-2024-01-01-2024-01-31.csv", Statement{Account: db.Account{ID: 1}, From: st.From, To: st.To}.Filename(FormatCSV))
+}
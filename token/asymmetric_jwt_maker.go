@@ -0,0 +1,109 @@
+package token
+
+import (
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AsymmetricJWTMaker is a JSON Web Token maker signing with the Ed25519 or P-256 keys of a key set.
+// The kid header tells the verifier which public key to use.
+type AsymmetricJWTMaker struct {
+	keys *KeySet
+}
+
+// NewAsymmetricJWTMaker creates a new AsymmetricJWTMaker
+func NewAsymmetricJWTMaker(keys *KeySet) (Maker, error) {
+	return &AsymmetricJWTMaker{keys: keys}, nil
+}
+
+// CreateToken creates a new token of a type for a specific username, role, scopes and duration
+func (m *AsymmetricJWTMaker) CreateToken(username string, role string, scopes []string, tokenType string, duration time.Duration) (string, *Payload, error) {
+
+	// 1. Create the token payload
+	payload, err := NewPayload(username, role, scopes, tokenType, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	// 2. create the claims and the jwt token signed by the active key
+	claims := &UserClaims{
+		ID:       payload.ID,
+		Username: payload.Username,
+		Role:     payload.Role,
+		Scopes:   payload.Scopes,
+		Type:     payload.Type,
+		RegisteredClaims: &jwt.RegisteredClaims{
+			Issuer:    "Simple Bank",
+			IssuedAt:  jwt.NewNumericDate(payload.IssuedAt),
+			ExpiresAt: jwt.NewNumericDate(payload.ExpiredAT),
+		},
+	}
+	key := m.keys.Active()
+	jwtToken := jwt.NewWithClaims(jwtSigningMethod(key.Algorithm), claims)
+	jwtToken.Header["kid"] = key.ID
+
+	// 3. return the signed token
+	token, err := jwtToken.SignedString(key.PrivateKey)
+	return token, payload, err
+}
+
+// VerifyToken checks if the token is valid or not
+func (m *AsymmetricJWTMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		key, err := m.keys.Key(kid)
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+
+		// the algorithm must match the key, otherwise a token could pick a weaker algorithm
+		if token.Method.Alg() != jwtSigningMethod(key.Algorithm).Alg() {
+			return nil, ErrInvalidToken
+		}
+		return key.Public(), nil
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &UserClaims{}, keyFunc)
+	if err != nil {
+		if strings.Contains(err.Error(), ErrExpiredToken.Error()) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := jwtToken.Claims.(*UserClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	if time.Now().After(claims.ExpiresAt.Time) {
+		return nil, ErrExpiredToken
+	}
+	return &Payload{
+		ID:        claims.ID,
+		Username:  claims.Username,
+		Role:      claims.Role,
+		Scopes:    claims.Scopes,
+		Type:      claims.Type,
+		IssuedAt:  claims.IssuedAt.Time,
+		ExpiredAT: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// JWKS returns the public keys which verify the tokens of this maker
+func (m *AsymmetricJWTMaker) JWKS() JWKS {
+	return m.keys.JWKS()
+}
+
+func jwtSigningMethod(algorithm string) jwt.SigningMethod {
+	if algorithm == AlgorithmES256 {
+		return jwt.SigningMethodES256
+	}
+	return jwt.SigningMethodEdDSA
+}
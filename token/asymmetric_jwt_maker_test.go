@@ -0,0 +1,120 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeySet(t *testing.T, algorithm string, kids ...string) *KeySet {
+	keys := make([]*SigningKey, 0, len(kids))
+	for _, kid := range kids {
+		key, err := GenerateSigningKey(kid, algorithm)
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+
+	set, err := NewKeySet(kids[0], keys...)
+	require.NoError(t, err)
+	return set
+}
+
+func TestAsymmetricJWTMaker(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmEdDSA, AlgorithmES256} {
+		t.Run(algorithm, func(t *testing.T) {
+			maker, err := NewAsymmetricJWTMaker(newTestKeySet(t, algorithm, "key-1"))
+			require.NoError(t, err)
+
+			username := util.RandomOwner()
+			role := util.DepositorRole
+			duration := time.Minute
+
+			issuedAt := time.Now()
+			expiredAt := issuedAt.Add(duration)
+
+			token, payload, err := maker.CreateToken(username, role, nil, TokenTypeAccess, duration)
+			require.NoError(t, err)
+			require.NotEmpty(t, token)
+			require.NotEmpty(t, payload)
+
+			payload, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.NotEmpty(t, payload)
+			require.NotZero(t, payload.ID)
+			require.Equal(t, username, payload.Username)
+			require.Equal(t, role, payload.Role)
+			require.Equal(t, TokenTypeAccess, payload.Type)
+			require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+			require.WithinDuration(t, expiredAt, payload.ExpiredAT, time.Second)
+		})
+	}
+}
+
+func TestExpiredAsymmetricJWTToken(t *testing.T) {
+	maker, err := NewAsymmetricJWTMaker(newTestKeySet(t, AlgorithmEdDSA, "key-1"))
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, nil, TokenTypeAccess, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Empty(t, payload)
+}
+
+func TestAsymmetricJWTUnknownKey(t *testing.T) {
+	maker, err := NewAsymmetricJWTMaker(newTestKeySet(t, AlgorithmEdDSA, "key-1"))
+	require.NoError(t, err)
+
+	// a token signed by a key of another key set with the same kid is rejected
+	otherMaker, err := NewAsymmetricJWTMaker(newTestKeySet(t, AlgorithmEdDSA, "key-1"))
+	require.NoError(t, err)
+	token, _, err := otherMaker.CreateToken(util.RandomOwner(), util.DepositorRole, nil, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	// a token with an unknown kid is rejected
+	otherMaker, err = NewAsymmetricJWTMaker(newTestKeySet(t, AlgorithmEdDSA, "key-2"))
+	require.NoError(t, err)
+	token, _, err = otherMaker.CreateToken(util.RandomOwner(), util.DepositorRole, nil, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestAsymmetricJWTAlgorithmMismatch(t *testing.T) {
+	keys := newTestKeySet(t, AlgorithmEdDSA, "key-1")
+	maker, err := NewAsymmetricJWTMaker(keys)
+	require.NoError(t, err)
+
+	payload, err := NewPayload(util.RandomOwner(), util.AdminRole, nil, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	// an HMAC token signed with the public key must not be accepted for the Ed25519 key
+	claims := &UserClaims{
+		ID:       payload.ID,
+		Username: payload.Username,
+		Role:     payload.Role,
+		RegisteredClaims: &jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(payload.IssuedAt),
+			ExpiresAt: jwt.NewNumericDate(payload.ExpiredAT),
+		},
+	}
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	jwtToken.Header["kid"] = "key-1"
+	token, err := jwtToken.SignedString([]byte(keys.Active().Public().(ed25519.PublicKey)))
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
@@ -0,0 +1,85 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+)
+
+// JWK is the public part of a signing key as described in RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWKS is the JSON Web Key Set served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeyPublisher is implemented by the makers whose tokens can be verified with public keys
+type KeyPublisher interface {
+	JWKS() JWKS
+}
+
+// JWKS returns the public keys of the set, it includes the keys which only verify tokens
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range s.Keys() {
+		jwk := JWK{
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: key.Algorithm,
+		}
+
+		switch public := key.Public().(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		case *ecdsa.PublicKey:
+			ecdhKey, err := public.ECDH()
+			if err != nil {
+				continue
+			}
+			// the uncompressed point is 0x04 || x || y
+			point := ecdhKey.Bytes()
+			size := (len(point) - 1) / 2
+			jwk.KeyType = "EC"
+			jwk.Curve = "P-256"
+			jwk.X = base64.RawURLEncoding.EncodeToString(point[1 : 1+size])
+			jwk.Y = base64.RawURLEncoding.EncodeToString(point[1+size:])
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+// NewJWKSHandler serves the public keys of the maker, the key set is empty for the symmetric makers
+func NewJWKSHandler(maker Maker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		jwks := JWKS{Keys: []JWK{}}
+		if publisher, ok := maker.(KeyPublisher); ok {
+			jwks = publisher.JWKS()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		json.NewEncoder(w).Encode(jwks)
+	})
+}
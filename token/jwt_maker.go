@@ -14,6 +14,9 @@ const minSecretKeySize = 32
 type UserClaims struct {
 	ID       uuid.UUID `json:"id"`
 	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	Scopes   []string  `json:"scopes,omitempty"`
+	Type     string    `json:"type"`
 	*jwt.RegisteredClaims
 }
 
@@ -31,11 +34,11 @@ func NewJWTMaker(secretKey string) (Maker, error) {
 	return &JWTMaker{secretKey: secretKey}, nil
 }
 
-// CreateToken creates a new token for a specific username and duration
-func (m *JWTMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+// CreateToken creates a new token of a type for a specific username, role, scopes and duration
+func (m *JWTMaker) CreateToken(username string, role string, scopes []string, tokenType string, duration time.Duration) (string, *Payload, error) {
 
 	// 1. Create the token payload
-	payload, err := NewPayload(username, duration)
+	payload, err := NewPayload(username, role, scopes, tokenType, duration)
 	if err != nil {
 		return "", payload, err
 	}
@@ -44,6 +47,9 @@ func (m *JWTMaker) CreateToken(username string, duration time.Duration) (string,
 	claims := &UserClaims{
 		ID:       payload.ID,
 		Username: payload.Username,
+		Role:     payload.Role,
+		Scopes:   payload.Scopes,
+		Type:     payload.Type,
 		RegisteredClaims: &jwt.RegisteredClaims{
 			Issuer:    "Simple Bank",
 			IssuedAt:  jwt.NewNumericDate(payload.IssuedAt),
@@ -86,6 +92,9 @@ func (m *JWTMaker) VerifyToken(token string) (*Payload, error) {
 	return &Payload{
 		ID:        claims.ID,
 		Username:  claims.Username,
+		Role:      claims.Role,
+		Scopes:    claims.Scopes,
+		Type:      claims.Type,
 		IssuedAt:  claims.IssuedAt.Time,
 		ExpiredAT: claims.ExpiresAt.Time,
 	}, nil
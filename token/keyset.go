@@ -0,0 +1,184 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Supported signing key algorithms
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmES256 = "ES256"
+)
+
+const keyFileExtension = ".pem"
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// SigningKey is a private key with its key id, the kid is sent with every token signed by it
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+}
+
+// Public returns the public key used to verify the tokens signed by this key
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.PrivateKey.Public()
+}
+
+// KeySet holds the keys of the asymmetric token makers. Only the active key signs new tokens,
+// every key in the set verifies tokens, so tokens signed by a previous key stay valid until they expire.
+//
+// Rotation procedure:
+//  1. generate the new key into the keys directory with `token keygen KID ALGORITHM` and deploy it,
+//     the new key is published in the JWKS but doesn't sign anything yet.
+//  2. set TOKEN_ACTIVE_KEY_ID to the new kid and deploy, new tokens are signed by the new key.
+//  3. once the refresh token duration has passed, remove the old key file and deploy.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// NewKeySet creates a key set from the keys, the active key must be one of them
+func NewKeySet(activeKeyID string, keys ...*SigningKey) (*KeySet, error) {
+	set := &KeySet{
+		keys: make(map[string]*SigningKey, len(keys)),
+	}
+	for _, key := range keys {
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate signing key %q", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+
+	active, ok := set.keys[activeKeyID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %q is not in the key set", activeKeyID)
+	}
+	set.active = active
+	return set, nil
+}
+
+// LoadKeySet loads every <kid>.pem file of the directory, they must hold PKCS #8 encoded Ed25519 or P-256 private keys
+func LoadKeySet(dir string, activeKeyID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*SigningKey, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(path), keyFileExtension)
+		key, err := ParseSigningKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signing key %s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(activeKeyID, keys...)
+}
+
+// ParseSigningKey parses a PEM encoded PKCS #8 private key
+func ParseSigningKey(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := privateKey.(type) {
+	case ed25519.PrivateKey:
+		return &SigningKey{ID: kid, Algorithm: AlgorithmEdDSA, PrivateKey: key}, nil
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported curve %s", key.Curve.Params().Name)
+		}
+		return &SigningKey{ID: kid, Algorithm: AlgorithmES256, PrivateKey: key}, nil
+	}
+	return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+}
+
+// GenerateSigningKey creates a new random key for the algorithm
+func GenerateSigningKey(kid string, algorithm string) (*SigningKey, error) {
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return &SigningKey{ID: kid, Algorithm: algorithm, PrivateKey: key}, nil
+	case AlgorithmES256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return &SigningKey{ID: kid, Algorithm: algorithm, PrivateKey: key}, nil
+	}
+	return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+}
+
+// WriteSigningKey stores the key as <kid>.pem in the directory, an existing key file is never overwritten
+func WriteSigningKey(dir string, key *SigningKey) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, key.ID+keyFileExtension)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	err = pem.Encode(file, &pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	return path, err
+}
+
+// Active returns the key which signs the new tokens
+func (s *KeySet) Active() *SigningKey {
+	return s.active
+}
+
+// Key returns the key with the kid
+func (s *KeySet) Key(kid string) (*SigningKey, error) {
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// Keys returns all the keys of the set sorted by kid
+func (s *KeySet) Keys() []*SigningKey {
+	keys := make([]*SigningKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
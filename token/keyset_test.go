@@ -0,0 +1,159 @@
+package token
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+
+	for kid, algorithm := range map[string]string{"key-1": AlgorithmEdDSA, "key-2": AlgorithmES256} {
+		key, err := GenerateSigningKey(kid, algorithm)
+		require.NoError(t, err)
+		_, err = WriteSigningKey(dir, key)
+		require.NoError(t, err)
+	}
+
+	keys, err := LoadKeySet(dir, "key-2")
+	require.NoError(t, err)
+	require.Equal(t, "key-2", keys.Active().ID)
+	require.Equal(t, AlgorithmES256, keys.Active().Algorithm)
+	require.Len(t, keys.Keys(), 2)
+
+	key, err := keys.Key("key-1")
+	require.NoError(t, err)
+	require.Equal(t, AlgorithmEdDSA, key.Algorithm)
+
+	_, err = keys.Key("key-3")
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	// the active key must be in the directory
+	_, err = LoadKeySet(dir, "key-3")
+	require.Error(t, err)
+
+	// an existing key is never overwritten
+	key, err = GenerateSigningKey("key-1", AlgorithmEdDSA)
+	require.NoError(t, err)
+	_, err = WriteSigningKey(dir, key)
+	require.Error(t, err)
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, err := GenerateSigningKey("key-1", AlgorithmEdDSA)
+	require.NoError(t, err)
+	newKey, err := GenerateSigningKey("key-2", AlgorithmEdDSA)
+	require.NoError(t, err)
+
+	// 1. tokens are signed by the old key
+	keys, err := NewKeySet(oldKey.ID, oldKey)
+	require.NoError(t, err)
+	maker, err := NewAsymmetricJWTMaker(keys)
+	require.NoError(t, err)
+
+	oldToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, nil, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	// 2. the new key becomes active, the old token stays valid
+	keys, err = NewKeySet(newKey.ID, oldKey, newKey)
+	require.NoError(t, err)
+	maker, err = NewAsymmetricJWTMaker(keys)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	newToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, nil, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(newToken)
+	require.NoError(t, err)
+
+	// 3. the old key is retired, its tokens are rejected
+	keys, err = NewKeySet(newKey.ID, newKey)
+	require.NoError(t, err)
+	maker, err = NewAsymmetricJWTMaker(keys)
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	_, err = maker.VerifyToken(newToken)
+	require.NoError(t, err)
+}
+
+func TestJWKSHandler(t *testing.T) {
+	edKey, err := GenerateSigningKey("key-1", AlgorithmEdDSA)
+	require.NoError(t, err)
+	ecKey, err := GenerateSigningKey("key-2", AlgorithmES256)
+	require.NoError(t, err)
+
+	keys, err := NewKeySet(edKey.ID, edKey, ecKey)
+	require.NoError(t, err)
+	maker, err := NewAsymmetricJWTMaker(keys)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	NewJWKSHandler(maker).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var jwks JWKS
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &jwks))
+	require.Len(t, jwks.Keys, 2)
+
+	require.Equal(t, "key-1", jwks.Keys[0].KeyID)
+	require.Equal(t, "OKP", jwks.Keys[0].KeyType)
+	require.Equal(t, "Ed25519", jwks.Keys[0].Curve)
+	require.Equal(t, AlgorithmEdDSA, jwks.Keys[0].Algorithm)
+	require.NotEmpty(t, jwks.Keys[0].X)
+
+	require.Equal(t, "key-2", jwks.Keys[1].KeyID)
+	require.Equal(t, "EC", jwks.Keys[1].KeyType)
+	require.Equal(t, "P-256", jwks.Keys[1].Curve)
+	require.Equal(t, AlgorithmES256, jwks.Keys[1].Algorithm)
+	require.Len(t, jwks.Keys[1].X, 43)
+	require.Len(t, jwks.Keys[1].Y, 43)
+
+	// the symmetric makers have no public keys
+	symmetricMaker, err := NewPasteoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	recorder = httptest.NewRecorder()
+	NewJWKSHandler(symmetricMaker).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"keys":[]}`, recorder.Body.String())
+}
+
+func TestNewMaker(t *testing.T) {
+	dir := t.TempDir()
+	key, err := GenerateSigningKey("key-1", AlgorithmEdDSA)
+	require.NoError(t, err)
+	_, err = WriteSigningKey(dir, key)
+	require.NoError(t, err)
+
+	config := util.Config{
+		TokenSymmetricKey: util.RandomString(32),
+		TokenKeysDir:      dir,
+		TokenActiveKeyID:  "key-1",
+	}
+
+	for _, name := range []string{"", MakerPasteoLocal, MakerPasteoPublic, MakerJWT, MakerJWTHS256} {
+		config.TokenMaker = name
+		maker, err := NewMaker(config)
+		require.NoError(t, err, name)
+
+		token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, nil, TokenTypeAccess, time.Minute)
+		require.NoError(t, err)
+		_, err = maker.VerifyToken(token)
+		require.NoError(t, err)
+	}
+
+	config.TokenMaker = "unknown"
+	_, err = NewMaker(config)
+	require.Error(t, err)
+}
@@ -1,12 +1,46 @@
 package token
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+)
 
 // Maker is an interface for managing tokens
 type Maker interface {
-	// CreateToken creates a new token for a specific username and duration
-	CreateToken(username string, duration time.Duration) (string, *Payload, error)
+	// CreateToken creates a new token of a type for a specific username, role, scopes and duration
+	CreateToken(username string, role string, scopes []string, tokenType string, duration time.Duration) (string, *Payload, error)
 
 	// VerifyToken checks if the token is valid or not
 	VerifyToken(token string) (*Payload, error)
 }
+
+// Supported token makers
+const (
+	MakerPasteoLocal  = "pasteo-local"
+	MakerPasteoPublic = "pasteo-public"
+	MakerJWT          = "jwt"
+	MakerJWTHS256     = "jwt-hs256"
+)
+
+// NewMaker creates the token maker selected in the config, PASTEO v2.local is used by default.
+// The asymmetric makers sign with the active key of the keys directory.
+func NewMaker(config util.Config) (Maker, error) {
+	switch config.TokenMaker {
+	case "", MakerPasteoLocal:
+		return NewPasteoMaker(config.TokenSymmetricKey)
+	case MakerJWTHS256:
+		return NewJWTMaker(config.TokenSymmetricKey)
+	case MakerJWT, MakerPasteoPublic:
+		keys, err := LoadKeySet(config.TokenKeysDir, config.TokenActiveKeyID)
+		if err != nil {
+			return nil, err
+		}
+		if config.TokenMaker == MakerJWT {
+			return NewAsymmetricJWTMaker(keys)
+		}
+		return NewPasteoPublicMaker(keys)
+	}
+	return nil, fmt.Errorf("unsupported token maker %q", config.TokenMaker)
+}
@@ -26,11 +26,11 @@ func NewPasteoMaker(symmetricKey string) (Maker, error) {
 	}, nil
 }
 
-// CreateToken creates a new token for a specific username and duration
-func (pt *PasteoMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+// CreateToken creates a new token of a type for a specific username, role, scopes and duration
+func (pt *PasteoMaker) CreateToken(username string, role string, scopes []string, tokenType string, duration time.Duration) (string, *Payload, error) {
 
 	// 1. create the new payload
-	payload, err := NewPayload(username, duration)
+	payload, err := NewPayload(username, role, scopes, tokenType, duration)
 	if err != nil {
 		return "", payload, err
 	}
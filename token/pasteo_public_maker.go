@@ -0,0 +1,83 @@
+package token
+
+import (
+	"fmt"
+	"time"
+
+	pasteo "github.com/o1egl/paseto"
+)
+
+// keyFooter is the unencrypted footer of the public PASTEO tokens, it names the key which signed the token
+type keyFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// PasteoPublicMaker is a PASTEO v2.public token maker, the tokens are signed with the Ed25519 keys of a key set.
+type PasteoPublicMaker struct {
+	pasteo *pasteo.V2
+	keys   *KeySet
+}
+
+// NewPasteoPublicMaker creates a new PasteoPublicMaker, v2.public supports only Ed25519 keys
+func NewPasteoPublicMaker(keys *KeySet) (Maker, error) {
+	for _, key := range keys.Keys() {
+		if key.Algorithm != AlgorithmEdDSA {
+			return nil, fmt.Errorf("invalid signing key %q: PASTEO v2.public requires an Ed25519 key", key.ID)
+		}
+	}
+	return &PasteoPublicMaker{
+		pasteo: pasteo.NewV2(),
+		keys:   keys,
+	}, nil
+}
+
+// CreateToken creates a new token of a type for a specific username, role, scopes and duration
+func (pt *PasteoPublicMaker) CreateToken(username string, role string, scopes []string, tokenType string, duration time.Duration) (string, *Payload, error) {
+
+	// 1. create the new payload
+	payload, err := NewPayload(username, role, scopes, tokenType, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	// 2. sign the token with the active key
+	key := pt.keys.Active()
+	token, err := pt.pasteo.Sign(key.PrivateKey, payload, keyFooter{KeyID: key.ID})
+	return token, payload, err
+}
+
+// VerifyToken checks if the token is valid or not
+func (pt *PasteoPublicMaker) VerifyToken(token string) (*Payload, error) {
+
+	// 1. find the key which signed the token
+	var footer keyFooter
+	err := pasteo.ParseFooter(token, &footer)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := pt.keys.Key(footer.KeyID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	// 2. verify the signature and get the payload
+	payload := &Payload{}
+	err = pt.pasteo.Verify(token, key.Public(), payload, nil)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	// 3. check if the payload is valid or not
+	err = payload.Valid()
+	if err != nil {
+		return nil, ErrExpiredToken
+	}
+
+	return payload, nil
+}
+
+// JWKS returns the public keys which verify the tokens of this maker
+func (pt *PasteoPublicMaker) JWKS() JWKS {
+	return pt.keys.JWKS()
+}
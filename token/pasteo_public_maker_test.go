@@ -0,0 +1,56 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasteoPublicMaker(t *testing.T) {
+	maker, err := NewPasteoPublicMaker(newTestKeySet(t, AlgorithmEdDSA, "key-1"))
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	role := util.DepositorRole
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, role, nil, TokenTypeAccess, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, TokenTypeAccess, payload.Type)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAT, time.Second)
+}
+
+func TestExpiredPasteoPublicToken(t *testing.T) {
+	maker, err := NewPasteoPublicMaker(newTestKeySet(t, AlgorithmEdDSA, "key-1"))
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, nil, TokenTypeAccess, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Empty(t, payload)
+}
+
+func TestPasteoPublicMakerRequiresEd25519(t *testing.T) {
+	_, err := NewPasteoPublicMaker(newTestKeySet(t, AlgorithmES256, "key-1"))
+	require.Error(t, err)
+}
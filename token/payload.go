@@ -9,20 +9,30 @@ import (
 )
 
 var (
-	ErrInvalidToken = errors.New("token is invalid")
-	ErrExpiredToken = fmt.Errorf("token is expired")
+	ErrInvalidToken   = errors.New("token is invalid")
+	ErrExpiredToken   = fmt.Errorf("token is expired")
+	ErrWrongTokenType = errors.New("token is of the wrong type")
+)
+
+// Types of token, only an access token authenticates a request and only a refresh token renews a session
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
 )
 
 // Contains the payload data of token.
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	Type      string    `json:"type"`
 	IssuedAt  time.Time `json:"issuedAt"`
 	ExpiredAT time.Time `json:"expiredAT"`
 }
 
-// NewPayload creates a new token payload with a specific username and duration
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// NewPayload creates a new token payload of a type with a specific username, role, scopes and duration
+func NewPayload(username string, role string, scopes []string, tokenType string, duration time.Duration) (*Payload, error) {
 	tokenId, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -31,6 +41,9 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenId,
 		Username:  username,
+		Role:      role,
+		Scopes:    scopes,
+		Type:      tokenType,
 		IssuedAt:  time.Now(),
 		ExpiredAT: time.Now().Add(duration),
 	}
@@ -44,3 +57,11 @@ func (payload *Payload) Valid() error {
 	}
 	return nil
 }
+
+// CheckType checks the token is of the expected type, a token issued without a type is of no type
+func (payload *Payload) CheckType(tokenType string) error {
+	if payload.Type != tokenType {
+		return ErrWrongTokenType
+	}
+	return nil
+}
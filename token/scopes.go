@@ -0,0 +1,71 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Scopes of the access tokens. Every protected route and method requires one of them,
+// a token without scopes is not limited so the tokens issued before the scopes keep working.
+const (
+	ScopeAccountsRead   = "accounts:read"
+	ScopeAccountsWrite  = "accounts:write"
+	ScopeTransfersRead  = "transfers:read"
+	ScopeTransfersWrite = "transfers:write"
+	ScopeUsersRead      = "users:read"
+	ScopeUsersWrite     = "users:write"
+	// ScopeSecurityRead and ScopeSecurityWrite cover the sessions, the api keys and the second factor of the user
+	ScopeSecurityRead  = "security:read"
+	ScopeSecurityWrite = "security:write"
+)
+
+var (
+	ErrUnknownScope    = errors.New("unknown scope")
+	ErrScopeNotGranted = errors.New("scope is not granted")
+)
+
+// supportedScopes is the list of all the scopes
+var supportedScopes = []string{
+	ScopeAccountsRead,
+	ScopeAccountsWrite,
+	ScopeTransfersRead,
+	ScopeTransfersWrite,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeSecurityRead,
+	ScopeSecurityWrite,
+}
+
+// IsSupportedScope reports whether the scope is known
+func IsSupportedScope(scope string) bool {
+	return slices.Contains(supportedScopes, scope)
+}
+
+// HasScope reports whether the token can be used for the scope
+func (payload *Payload) HasScope(scope string) bool {
+	return len(payload.Scopes) == 0 || slices.Contains(payload.Scopes, scope)
+}
+
+// NarrowScopes returns the scopes of a token derived from a token with the granted scopes.
+// No requested scopes keep the granted ones, otherwise every requested scope must be known
+// and granted, so a derived token never has more rights than the token it comes from.
+func NarrowScopes(granted []string, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return granted, nil
+	}
+
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		if !IsSupportedScope(scope) {
+			return nil, fmt.Errorf("%w %q", ErrUnknownScope, scope)
+		}
+		if len(granted) > 0 && !slices.Contains(granted, scope) {
+			return nil, fmt.Errorf("%w: %q", ErrScopeNotGranted, scope)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
@@ -0,0 +1,58 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopesRoundTrip(t *testing.T) {
+	jwtMaker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+	pasteoMaker, err := NewPasteoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	scopes := []string{ScopeAccountsRead, ScopeTransfersRead}
+	for _, maker := range []Maker{jwtMaker, pasteoMaker} {
+		token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, scopes, TokenTypeAccess, time.Minute)
+		require.NoError(t, err)
+
+		payload, err := maker.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, scopes, payload.Scopes)
+		require.True(t, payload.HasScope(ScopeAccountsRead))
+		require.False(t, payload.HasScope(ScopeTransfersWrite))
+	}
+}
+
+func TestHasScopeWithoutScopes(t *testing.T) {
+	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, nil, TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	require.True(t, payload.HasScope(ScopeTransfersWrite))
+}
+
+func TestNarrowScopes(t *testing.T) {
+	// 1. no requested scopes keep the granted ones
+	scopes, err := NarrowScopes([]string{ScopeAccountsRead}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{ScopeAccountsRead}, scopes)
+
+	scopes, err = NarrowScopes(nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, scopes)
+
+	// 2. an unlimited token can request any known scope, duplicates are dropped
+	scopes, err = NarrowScopes(nil, []string{ScopeAccountsRead, ScopeTransfersWrite, ScopeAccountsRead})
+	require.NoError(t, err)
+	require.Equal(t, []string{ScopeAccountsRead, ScopeTransfersWrite}, scopes)
+
+	// 3. a limited token can't request more than it has
+	_, err = NarrowScopes([]string{ScopeAccountsRead}, []string{ScopeTransfersWrite})
+	require.ErrorIs(t, err, ErrScopeNotGranted)
+
+	// 4. unknown scopes are rejected
+	_, err = NarrowScopes(nil, []string{"accounts:*"})
+	require.ErrorIs(t, err, ErrUnknownScope)
+}
@@ -0,0 +1,191 @@
+package usertoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/mail"
+	"github.com/akshay237/backend-with-go/util"
+)
+
+// Defaults used when the token durations are not configured
+const (
+	DefaultEmailVerificationDuration = 24 * time.Hour
+	DefaultPasswordResetDuration     = 30 * time.Minute
+)
+
+var (
+	ErrInvalidToken    = errors.New("token is invalid, expired or already used")
+	ErrAlreadyVerified = errors.New("email is already verified")
+)
+
+// Store is the part of the database store used for the tokens mailed to the users
+type Store interface {
+	GetUserByEmail(ctx context.Context, email string) (db.User, error)
+	CreateUserToken(ctx context.Context, arg db.CreateUserTokenParams) (db.UserToken, error)
+	GetUsableUserToken(ctx context.Context, arg db.GetUsableUserTokenParams) (db.UserToken, error)
+	VerifyEmailTx(ctx context.Context, tokenHash string) (db.User, error)
+	ResetPasswordTx(ctx context.Context, arg db.ResetPasswordTxParams) (db.ResetPasswordTxResult, error)
+}
+
+// Service mails the email verification and password reset tokens and redeems them.
+// The tokens are random and single use, only their hashes are stored.
+type Service struct {
+	store                     Store
+	mailer                    mail.Mailer
+	emailVerificationDuration time.Duration
+	passwordResetDuration     time.Duration
+}
+
+// NewService creates the service with the token durations of the config
+func NewService(store Store, mailer mail.Mailer, config util.Config) *Service {
+	emailVerificationDuration := config.EmailTokenDuration
+	if emailVerificationDuration <= 0 {
+		emailVerificationDuration = DefaultEmailVerificationDuration
+	}
+	passwordResetDuration := config.ResetTokenDuration
+	if passwordResetDuration <= 0 {
+		passwordResetDuration = DefaultPasswordResetDuration
+	}
+
+	return &Service{
+		store:                     store,
+		mailer:                    mailer,
+		emailVerificationDuration: emailVerificationDuration,
+		passwordResetDuration:     passwordResetDuration,
+	}
+}
+
+// SendEmailVerification mails a token which verifies the current email address of the user
+func (s *Service) SendEmailVerification(ctx context.Context, user db.User) error {
+	if user.IsEmailVerified {
+		return ErrAlreadyVerified
+	}
+
+	token, err := s.createToken(ctx, user, db.UserTokenEmailVerification, s.emailVerificationDuration)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nuse this token to verify your email address:\n\n%s\n\nThe token expires in %s.\n",
+			user.FullName, token, s.emailVerificationDuration),
+	})
+}
+
+// VerifyEmail redeems an email verification token and returns the verified user
+func (s *Service) VerifyEmail(ctx context.Context, token string) (db.User, error) {
+	user, err := s.store.VerifyEmailTx(ctx, HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.User{}, ErrInvalidToken
+		}
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+// SendPasswordReset mails a password reset token to the user with the email address.
+// An unknown address is not reported, so the endpoint can't be used to find out who has an account.
+func (s *Service) SendPasswordReset(ctx context.Context, email string) error {
+	user, err := s.store.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("password reset requested for an unknown email")
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.createToken(ctx, user, db.UserTokenPasswordReset, s.passwordResetDuration)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nuse this token to reset your password:\n\n%s\n\n"+
+			"The token expires in %s. If you didn't ask for a password reset, you can ignore this email.\n",
+			user.FullName, token, s.passwordResetDuration),
+	})
+}
+
+// LookupPasswordReset returns the password reset token without using it, so the new password
+// can be checked against the policy of the user before it is hashed
+func (s *Service) LookupPasswordReset(ctx context.Context, token string) (db.UserToken, error) {
+	userToken, err := s.store.GetUsableUserToken(ctx, db.GetUsableUserTokenParams{
+		TokenHash: HashToken(token),
+		Purpose:   db.UserTokenPasswordReset,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.UserToken{}, ErrInvalidToken
+		}
+		return db.UserToken{}, err
+	}
+	return userToken, nil
+}
+
+// ResetPassword redeems a password reset token. The new password must already be hashed,
+// the result contains the sessions blocked by the reset whose access tokens must be revoked.
+func (s *Service) ResetPassword(ctx context.Context, token string, hashedPassword string) (db.ResetPasswordTxResult, error) {
+	result, err := s.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		TokenHash:      HashToken(token),
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.ResetPasswordTxResult{}, ErrInvalidToken
+		}
+		return db.ResetPasswordTxResult{}, err
+	}
+	return result, nil
+}
+
+// createToken stores the hash of a new token for the user and returns the token
+func (s *Service) createToken(ctx context.Context, user db.User, purpose string, duration time.Duration) (string, error) {
+	token, err := GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.store.CreateUserToken(ctx, db.CreateUserTokenParams{
+		TokenHash: HashToken(token),
+		Username:  user.Username,
+		Purpose:   purpose,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(duration),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// GenerateToken returns a new random token
+func GenerateToken() (string, error) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// HashToken returns the hash stored for the token. The tokens are random, so a fast hash is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package usertoken
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	mockdb "github.com/akshay237/backend-with-go/database/mock"
+	db "github.com/akshay237/backend-with-go/database/sqlc"
+	"github.com/akshay237/backend-with-go/mail"
+	"github.com/akshay237/backend-with-go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var mailedTokenPattern = regexp.MustCompile(`(?m)^[A-Za-z0-9_-]{43}$`)
+
+func randomUser() db.User {
+	return db.User{
+		Username: util.RandomOwner(),
+		FullName: util.RandomOwner(),
+		Email:    util.RandomEmail(),
+	}
+}
+
+// mailedToken returns the token in the last message sent to the address
+func mailedToken(t *testing.T, mailer *mail.MemoryMailer, to string) string {
+	msg, ok := mailer.Last(to)
+	require.True(t, ok)
+
+	token := mailedTokenPattern.FindString(msg.Body)
+	require.NotEmpty(t, token)
+	return token
+}
+
+func TestSendEmailVerification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	mailer := mail.NewMemoryMailer()
+	service := NewService(store, mailer, util.Config{})
+	user := randomUser()
+
+	// 1. only the hash of the mailed token is stored
+	var stored db.CreateUserTokenParams
+	store.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateUserTokenParams) (db.UserToken, error) {
+			stored = arg
+			return db.UserToken{}, nil
+		})
+
+	require.NoError(t, service.SendEmailVerification(context.Background(), user))
+	token := mailedToken(t, mailer, user.Email)
+	require.Equal(t, HashToken(token), stored.TokenHash)
+	require.NotEqual(t, token, stored.TokenHash)
+	require.Equal(t, db.UserTokenEmailVerification, stored.Purpose)
+	require.Equal(t, user.Email, stored.Email)
+	require.WithinDuration(t, time.Now().Add(DefaultEmailVerificationDuration), stored.ExpiresAt, time.Second)
+
+	// 2. the token verifies the email
+	verified := user
+	verified.IsEmailVerified = true
+	store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Eq(HashToken(token))).Times(1).Return(verified, nil)
+
+	result, err := service.VerifyEmail(context.Background(), token)
+	require.NoError(t, err)
+	require.True(t, result.IsEmailVerified)
+
+	// 3. a verified email is not verified again
+	err = service.SendEmailVerification(context.Background(), verified)
+	require.ErrorIs(t, err, ErrAlreadyVerified)
+}
+
+func TestSendPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	mailer := mail.NewMemoryMailer()
+	service := NewService(store, mailer, util.Config{ResetTokenDuration: time.Minute})
+	user := randomUser()
+
+	// 1. an unknown email gets no mail and no error
+	store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq("unknown@example.com")).Times(1).Return(db.User{}, sql.ErrNoRows)
+	require.NoError(t, service.SendPasswordReset(context.Background(), "unknown@example.com"))
+	require.Empty(t, mailer.Messages())
+
+	// 2. the user gets a reset token
+	store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).Times(1).Return(user, nil)
+	store.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateUserTokenParams) (db.UserToken, error) {
+			require.Equal(t, db.UserTokenPasswordReset, arg.Purpose)
+			require.WithinDuration(t, time.Now().Add(time.Minute), arg.ExpiresAt, time.Second)
+			return db.UserToken{}, nil
+		})
+	require.NoError(t, service.SendPasswordReset(context.Background(), user.Email))
+	token := mailedToken(t, mailer, user.Email)
+
+	// 3. the token resets the password once
+	store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Eq(db.ResetPasswordTxParams{
+		TokenHash:      HashToken(token),
+		HashedPassword: "hashed",
+	})).Times(1).Return(db.ResetPasswordTxResult{User: user}, nil)
+	result, err := service.ResetPassword(context.Background(), token, "hashed")
+	require.NoError(t, err)
+	require.Equal(t, user.Username, result.User.Username)
+
+	store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ResetPasswordTxResult{}, sql.ErrNoRows)
+	_, err = service.ResetPassword(context.Background(), token, "hashed")
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// DefaultTrustedProxies are trusted when no proxies are configured, the gateway calls the gRPC server over loopback
+var DefaultTrustedProxies = []string{"127.0.0.0/8", "::1/128"}
+
+// ParseTrustedProxies parses the addresses and networks of the proxies in front of the servers,
+// the loopback addresses are trusted when none are configured
+func ParseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	if len(proxies) == 0 {
+		proxies = DefaultTrustedProxies
+	}
+
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// ClientIP returns the address of the client of a request received from remoteAddr with the X-Forwarded-For values.
+// The forwarded addresses are only read when the request came from a trusted proxy: the right most address which
+// is not a trusted proxy is the client, the addresses left of it are sent by the client and can't be trusted.
+func ClientIP(remoteAddr string, forwardedFor []string, trustedProxies []netip.Prefix) string {
+	ip := hostIP(remoteAddr)
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+
+	var hops []string
+	for _, value := range forwardedFor {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hostIP(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// a malformed hop was not added by a trusted proxy, the last trusted hop is the client
+			break
+		}
+		ip = hop
+		if !isTrustedProxy(hop, trustedProxies) {
+			break
+		}
+	}
+	return ip
+}
+
+// hostIP returns the address without the port
+func hostIP(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// isTrustedProxy reports whether the address is one of the trusted proxies
+func isTrustedProxy(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		clientIP     string
+	}{
+		{
+			name:       "Direct",
+			remoteAddr: "203.0.113.7:51234",
+			clientIP:   "203.0.113.7",
+		},
+		{
+			name:         "UntrustedPeerForwarding",
+			remoteAddr:   "203.0.113.7:51234",
+			forwardedFor: []string{"198.51.100.1"},
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "TrustedProxy",
+			remoteAddr:   "10.0.0.2:51234",
+			forwardedFor: []string{"203.0.113.7"},
+			clientIP:     "203.0.113.7",
+		},
+		{
+			// the client prepends an allowed address, the proxies append the address they received from
+			name:         "SpoofedHop",
+			remoteAddr:   "10.0.0.2:51234",
+			forwardedFor: []string{"198.51.100.1, 203.0.113.7", "192.0.2.1"},
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "MalformedHop",
+			remoteAddr:   "10.0.0.2:51234",
+			forwardedFor: []string{"203.0.113.7, not-an-ip"},
+			clientIP:     "10.0.0.2",
+		},
+		{
+			name:         "AllTrusted",
+			remoteAddr:   "10.0.0.2:51234",
+			forwardedFor: []string{"10.0.0.3"},
+			clientIP:     "10.0.0.3",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.clientIP, ClientIP(tc.remoteAddr, tc.forwardedFor, trusted))
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	// the loopback addresses of the gateway are trusted by default
+	trusted, err := ParseTrustedProxies(nil)
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.7", ClientIP("127.0.0.1:51234", []string{"203.0.113.7"}, trusted))
+
+	_, err = ParseTrustedProxies([]string{"not-a-proxy"})
+	require.Error(t, err)
+}
@@ -8,15 +8,51 @@ import (
 
 // config struct to hold the configurations params
 type Config struct {
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
-	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
-	StopFilePath         string        `mapstructure:"STOP_FILE_PATH"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESh_TOKEN_DURATION"`
-	RunGRPC              bool          `mapstructure:"RUN_GRPC"`
+	DBDriver                      string        `mapstructure:"DB_DRIVER"`
+	DBSource                      string        `mapstructure:"DB_SOURCE"`
+	HTTPServerAddress             string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	GRPCServerAddress             string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	TrustedProxies                []string      `mapstructure:"TRUSTED_PROXIES"`
+	StopFilePath                  string        `mapstructure:"STOP_FILE_PATH"`
+	TokenSymmetricKey             string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenMaker                    string        `mapstructure:"TOKEN_MAKER"`
+	TokenKeysDir                  string        `mapstructure:"TOKEN_KEYS_DIR"`
+	TokenActiveKeyID              string        `mapstructure:"TOKEN_ACTIVE_KEY_ID"`
+	AccessTokenDuration           time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration          time.Duration `mapstructure:"REFRESh_TOKEN_DURATION"`
+	ServeGinRouter                bool          `mapstructure:"SERVE_GIN_ROUTER"`
+	RateProvider                  string        `mapstructure:"RATE_PROVIDER"`
+	RatesFilePath                 string        `mapstructure:"RATES_FILE_PATH"`
+	QuoteLockDuration             time.Duration `mapstructure:"QUOTE_LOCK_DURATION"`
+	CurrencyRefresh               time.Duration `mapstructure:"CURRENCY_REFRESH_INTERVAL"`
+	RevocationRefresh             time.Duration `mapstructure:"REVOCATION_REFRESH_INTERVAL"`
+	TOTPEncryptionKey             string        `mapstructure:"TOTP_ENCRYPTION_KEY"`
+	MFAChallengeDuration          time.Duration `mapstructure:"MFA_CHALLENGE_DURATION"`
+	LoginMaxAttempts              int           `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LoginIPMaxAttempts            int           `mapstructure:"LOGIN_IP_MAX_ATTEMPTS"`
+	LoginLockoutDuration          time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	LoginBackoff                  time.Duration `mapstructure:"LOGIN_BACKOFF"`
+	Mailer                        string        `mapstructure:"MAILER"`
+	MailSender                    string        `mapstructure:"MAIL_SENDER"`
+	MailFilePath                  string        `mapstructure:"MAIL_FILE_PATH"`
+	SMTPAddress                   string        `mapstructure:"SMTP_ADDRESS"`
+	SMTPUsername                  string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword                  string        `mapstructure:"SMTP_PASSWORD"`
+	EmailTokenDuration            time.Duration `mapstructure:"EMAIL_VERIFICATION_TOKEN_DURATION"`
+	ResetTokenDuration            time.Duration `mapstructure:"PASSWORD_RESET_TOKEN_DURATION"`
+	PasswordHashAlgorithm         string        `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	PasswordBcryptCost            int           `mapstructure:"PASSWORD_BCRYPT_COST"`
+	PasswordArgon2Time            int           `mapstructure:"PASSWORD_ARGON2_TIME"`
+	PasswordArgon2Memory          int           `mapstructure:"PASSWORD_ARGON2_MEMORY"`
+	PasswordArgon2Threads         int           `mapstructure:"PASSWORD_ARGON2_THREADS"`
+	PasswordMinLength             int           `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordBreachedList          string        `mapstructure:"PASSWORD_BREACHED_LIST"`
+	OAuthCodeDuration             time.Duration `mapstructure:"OAUTH_CODE_DURATION"`
+	HoldDuration                  time.Duration `mapstructure:"HOLD_DURATION"`
+	HoldSweepInterval             time.Duration `mapstructure:"HOLD_SWEEP_INTERVAL"`
+	ScheduledTransferInterval     time.Duration `mapstructure:"SCHEDULED_TRANSFER_INTERVAL"`
+	ScheduledTransferMaxAttempts  int           `mapstructure:"SCHEDULED_TRANSFER_MAX_ATTEMPTS"`
+	ScheduledTransferRetryBackoff time.Duration `mapstructure:"SCHEDULED_TRANSFER_RETRY_BACKOFF"`
 }
 
 // loads the config from the application env
@@ -1,17 +1,9 @@
 package util
 
-// Constants for all supported currencies.
+// Constants for the built in currencies.
+// More currencies are enabled at runtime in the currencies table.
 const (
 	USD = "USD"
 	EUR = "EUR"
 	INR = "INR"
 )
-
-// IsSupportedCurrency returns true is the currency is supported
-func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case USD, EUR, INR:
-		return true
-	}
-	return false
-}
@@ -1,21 +1,212 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword returns the bcrypt hash of the password
+// Supported password hash algorithms
+const (
+	PasswordBcrypt   = "bcrypt"
+	PasswordArgon2id = "argon2id"
+)
+
+// Default password hash parameters, the argon2id ones are the second recommended option of RFC 9106
+const (
+	DefaultBcryptCost    = bcrypt.DefaultCost
+	DefaultArgon2Time    = 3
+	DefaultArgon2Memory  = 64 * 1024
+	DefaultArgon2Threads = 4
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	argon2idPrefix   = "$argon2id$"
+)
+
+var (
+	ErrMismatchedPassword = errors.New("password is incorrect")
+	ErrUnsupportedHash    = errors.New("unsupported password hash")
+)
+
+var (
+	argon2HashEncoding = base64.RawStdEncoding
+	bcryptPrefixes     = []string{"$2a$", "$2b$", "$2y$"}
+
+	defaultPasswordHasher = &PasswordHasher{
+		algorithm:  PasswordArgon2id,
+		bcryptCost: DefaultBcryptCost,
+		argon2:     argon2Params{time: DefaultArgon2Time, memory: DefaultArgon2Memory, threads: DefaultArgon2Threads},
+	}
+)
+
+// argon2Params are the cost parameters stored in an argon2id hash
+type argon2Params struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+}
+
+// PasswordHasher hashes the passwords with the configured algorithm. The hashes carry their algorithm
+// and parameters, so hashes of older settings are still checked and reported as outdated.
+//
+// The formats are the bcrypt modular crypt format and the PHC string format of argon2id:
+//
+//	$2a$10$<salt and hash>
+//	$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+type PasswordHasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2     argon2Params
+}
+
+// NewPasswordHasher creates the hasher with the algorithm and parameters of the config, argon2id is used by default
+func NewPasswordHasher(config Config) (*PasswordHasher, error) {
+	hasher := &PasswordHasher{
+		algorithm:  config.PasswordHashAlgorithm,
+		bcryptCost: config.PasswordBcryptCost,
+		argon2: argon2Params{
+			time:    uint32(config.PasswordArgon2Time),
+			memory:  uint32(config.PasswordArgon2Memory),
+			threads: uint8(config.PasswordArgon2Threads),
+		},
+	}
+
+	if hasher.algorithm == "" {
+		hasher.algorithm = PasswordArgon2id
+	}
+	if hasher.bcryptCost == 0 {
+		hasher.bcryptCost = DefaultBcryptCost
+	}
+	if hasher.argon2.time == 0 {
+		hasher.argon2.time = DefaultArgon2Time
+	}
+	if hasher.argon2.memory == 0 {
+		hasher.argon2.memory = DefaultArgon2Memory
+	}
+	if hasher.argon2.threads == 0 {
+		hasher.argon2.threads = DefaultArgon2Threads
+	}
+
+	switch hasher.algorithm {
+	case PasswordBcrypt:
+		if hasher.bcryptCost < bcrypt.MinCost || hasher.bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("invalid bcrypt cost %d", hasher.bcryptCost)
+		}
+	case PasswordArgon2id:
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", hasher.algorithm)
+	}
+	return hasher, nil
+}
+
+// HashPassword returns the hash of the password with the default argon2id parameters
 func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return defaultPasswordHasher.HashPassword(password)
+}
+
+// CheckPassword checks if the provided password is correct or not, the hash can have any supported format
+func CheckPassword(password string, hashedPassword string) error {
+	_, err := defaultPasswordHasher.CheckPassword(password, hashedPassword)
+	return err
+}
+
+// HashPassword returns the hash of the password with the configured algorithm
+func (h *PasswordHasher) HashPassword(password string) (string, error) {
+	if h.algorithm == PasswordBcrypt {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash password: %v", err)
+		}
+		return string(hashedPassword), nil
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	_, err := rand.Read(salt)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %v", err)
 	}
-	return string(hashedPassword), nil
+	key := argon2.IDKey([]byte(password), salt, h.argon2.time, h.argon2.memory, h.argon2.threads, argon2KeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		h.argon2.memory, h.argon2.time, h.argon2.threads,
+		argon2HashEncoding.EncodeToString(salt), argon2HashEncoding.EncodeToString(key)), nil
 }
 
-// CheckPassword checks if the provided password is correct or not
-func CheckPassword(password string, hashedPassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+// CheckPassword checks the password against the hash. If the password is correct, outdated reports
+// whether the hash was made with another algorithm or weaker parameters and should be replaced.
+func (h *PasswordHasher) CheckPassword(password string, hashedPassword string) (outdated bool, err error) {
+	if strings.HasPrefix(hashedPassword, argon2idPrefix) {
+		params, salt, key, err := parseArgon2Hash(hashedPassword)
+		if err != nil {
+			return false, err
+		}
+
+		other := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(key, other) != 1 {
+			return false, ErrMismatchedPassword
+		}
+		return h.algorithm != PasswordArgon2id || params != h.argon2, nil
+	}
+
+	for _, prefix := range bcryptPrefixes {
+		if !strings.HasPrefix(hashedPassword, prefix) {
+			continue
+		}
+
+		err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+		if err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, ErrMismatchedPassword
+			}
+			return false, err
+		}
+
+		cost, err := bcrypt.Cost([]byte(hashedPassword))
+		if err != nil {
+			return false, err
+		}
+		return h.algorithm != PasswordBcrypt || cost < h.bcryptCost, nil
+	}
+
+	return false, ErrUnsupportedHash
+}
+
+// parseArgon2Hash reads the parameters, salt and key of an argon2id hash in the PHC string format
+func parseArgon2Hash(hashedPassword string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads)
+	if err != nil || params.time == 0 || params.threads == 0 {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	salt, err := argon2HashEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+	key, err := argon2HashEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+	return params, salt, key, nil
 }
@@ -0,0 +1,107 @@
+package util
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultPasswordMinLength is used when the minimum password length is not configured
+const DefaultPasswordMinLength = 8
+
+// breachedPrefixLength is the length of the hash prefix the breached passwords are grouped by,
+// it is the prefix of the k-anonymity range API of Have I Been Pwned
+const breachedPrefixLength = 5
+
+var (
+	ErrPasswordTooShort   = errors.New("password is too short")
+	ErrPasswordBreached   = errors.New("password appeared in a data breach, choose another one")
+	ErrPasswordIsUsername = errors.New("password must not be the username")
+)
+
+// PasswordPolicy is checked for every new password
+type PasswordPolicy struct {
+	minLength int
+	// breached maps the prefix of the SHA-1 hashes of the breached passwords to the suffixes
+	breached map[string]map[string]struct{}
+}
+
+// NewPasswordPolicy creates the policy of the config, the breached passwords list is optional
+func NewPasswordPolicy(config Config) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{
+		minLength: config.PasswordMinLength,
+		breached:  make(map[string]map[string]struct{}),
+	}
+	if policy.minLength <= 0 {
+		policy.minLength = DefaultPasswordMinLength
+	}
+
+	if config.PasswordBreachedList != "" {
+		err := policy.loadBreachedList(config.PasswordBreachedList)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return policy, nil
+}
+
+// Validate returns an error wrapping one of the ErrPassword errors if the password is not allowed
+func (p *PasswordPolicy) Validate(username string, password string) error {
+	if utf8.RuneCountInString(password) < p.minLength {
+		return fmt.Errorf("%w: it must have at least %d characters", ErrPasswordTooShort, p.minLength)
+	}
+	if strings.EqualFold(password, username) {
+		return ErrPasswordIsUsername
+	}
+	if p.isBreached(password) {
+		return ErrPasswordBreached
+	}
+	return nil
+}
+
+// isBreached looks up the suffixes of the hash prefix of the password, the same way as the range API
+func (p *PasswordPolicy) isBreached(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	_, ok := p.breached[hash[:breachedPrefixLength]][hash[breachedPrefixLength:]]
+	return ok
+}
+
+// loadBreachedList reads the SHA-1 hashes of the breached passwords. Each line has a hash in hex,
+// optionally followed by the breach count as in the Have I Been Pwned downloads:
+//
+//	5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:10434004
+func (p *PasswordPolicy) loadBreachedList(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot read breached passwords list: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(text, ":")
+		hash = strings.ToUpper(hash)
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != 2*sha1.Size {
+			return fmt.Errorf("invalid SHA-1 hash on line %d of the breached passwords list", line)
+		}
+
+		prefix, suffix := hash[:breachedPrefixLength], hash[breachedPrefixLength:]
+		if p.breached[prefix] == nil {
+			p.breached[prefix] = make(map[string]struct{})
+		}
+		p.breached[prefix][suffix] = struct{}{}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	// the SHA-1 hash of "password1", listed the way the Have I Been Pwned downloads are
+	breachedList := filepath.Join(t.TempDir(), "breached.txt")
+	err := os.WriteFile(breachedList, []byte("# breached passwords\n\ne38ad214943daad1d64c102faec29de4afe9da3d:2413945\n"), 0o600)
+	require.NoError(t, err)
+
+	policy, err := NewPasswordPolicy(Config{PasswordBreachedList: breachedList})
+	require.NoError(t, err)
+
+	require.NoError(t, policy.Validate("alice", "correct horse"))
+	require.ErrorIs(t, policy.Validate("alice", "short"), ErrPasswordTooShort)
+	// the length is counted in characters, not bytes
+	require.ErrorIs(t, policy.Validate("alice", "äöüäöüä"), ErrPasswordTooShort)
+	require.ErrorIs(t, policy.Validate("alice_smith", "Alice_Smith"), ErrPasswordIsUsername)
+	require.ErrorIs(t, policy.Validate("alice", "password1"), ErrPasswordBreached)
+
+	policy, err = NewPasswordPolicy(Config{PasswordMinLength: 4})
+	require.NoError(t, err)
+	require.NoError(t, policy.Validate("alice", "pass"))
+	require.NoError(t, policy.Validate("alice", "password1"))
+}
+
+func TestPasswordPolicyInvalidBreachedList(t *testing.T) {
+	_, err := NewPasswordPolicy(Config{PasswordBreachedList: filepath.Join(t.TempDir(), "missing.txt")})
+	require.Error(t, err)
+
+	breachedList := filepath.Join(t.TempDir(), "breached.txt")
+	err = os.WriteFile(breachedList, []byte("not-a-hash\n"), 0o600)
+	require.NoError(t, err)
+	_, err = NewPasswordPolicy(Config{PasswordBreachedList: breachedList})
+	require.Error(t, err)
+}
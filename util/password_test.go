@@ -1,6 +1,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -13,11 +14,81 @@ func TestPassword(t *testing.T) {
 	hashedPassword, err := HashPassword(password)
 	require.NoError(t, err)
 	require.NotEmpty(t, hashedPassword)
+	require.True(t, strings.HasPrefix(hashedPassword, "$argon2id$v=19$m=65536,t=3,p=4$"))
 
 	err = CheckPassword(password, hashedPassword)
 	require.NoError(t, err)
 
 	wrongPassword := RandomString(6)
 	err = CheckPassword(wrongPassword, hashedPassword)
-	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+	require.ErrorIs(t, err, ErrMismatchedPassword)
+
+	// the same password gets a new salt every time
+	otherHashedPassword, err := HashPassword(password)
+	require.NoError(t, err)
+	require.NotEqual(t, hashedPassword, otherHashedPassword)
+}
+
+func TestPasswordHasher(t *testing.T) {
+	password := RandomString(8)
+
+	bcryptHasher, err := NewPasswordHasher(Config{PasswordHashAlgorithm: PasswordBcrypt, PasswordBcryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+	argon2Hasher, err := NewPasswordHasher(Config{PasswordArgon2Time: 1, PasswordArgon2Memory: 1024, PasswordArgon2Threads: 1})
+	require.NoError(t, err)
+
+	bcryptHash, err := bcryptHasher.HashPassword(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(bcryptHash, "$2a$04$"))
+	argon2Hash, err := argon2Hasher.HashPassword(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(argon2Hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	// 1. a hash of the configured algorithm and parameters is up to date
+	outdated, err := bcryptHasher.CheckPassword(password, bcryptHash)
+	require.NoError(t, err)
+	require.False(t, outdated)
+	outdated, err = argon2Hasher.CheckPassword(password, argon2Hash)
+	require.NoError(t, err)
+	require.False(t, outdated)
+
+	// 2. a hash of the other algorithm is checked and reported as outdated
+	outdated, err = argon2Hasher.CheckPassword(password, bcryptHash)
+	require.NoError(t, err)
+	require.True(t, outdated)
+	outdated, err = bcryptHasher.CheckPassword(password, argon2Hash)
+	require.NoError(t, err)
+	require.True(t, outdated)
+
+	// 3. weaker parameters are outdated
+	outdated, err = defaultPasswordHasher.CheckPassword(password, argon2Hash)
+	require.NoError(t, err)
+	require.True(t, outdated)
+	strongerBcrypt, err := NewPasswordHasher(Config{PasswordHashAlgorithm: PasswordBcrypt, PasswordBcryptCost: bcrypt.MinCost + 1})
+	require.NoError(t, err)
+	outdated, err = strongerBcrypt.CheckPassword(password, bcryptHash)
+	require.NoError(t, err)
+	require.True(t, outdated)
+
+	// 4. a wrong password is never outdated
+	outdated, err = argon2Hasher.CheckPassword("wrong-password", bcryptHash)
+	require.ErrorIs(t, err, ErrMismatchedPassword)
+	require.False(t, outdated)
+	outdated, err = bcryptHasher.CheckPassword("wrong-password", argon2Hash)
+	require.ErrorIs(t, err, ErrMismatchedPassword)
+	require.False(t, outdated)
+
+	// 5. unknown and malformed hashes are rejected
+	for _, hash := range []string{"", "plain-text", "$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5", "$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$a2V5", "$argon2id$v=19$broken"} {
+		_, err = argon2Hasher.CheckPassword(password, hash)
+		require.ErrorIs(t, err, ErrUnsupportedHash, hash)
+	}
+}
+
+func TestNewPasswordHasherInvalidConfig(t *testing.T) {
+	_, err := NewPasswordHasher(Config{PasswordHashAlgorithm: "md5"})
+	require.Error(t, err)
+
+	_, err = NewPasswordHasher(Config{PasswordHashAlgorithm: PasswordBcrypt, PasswordBcryptCost: bcrypt.MaxCost + 1})
+	require.Error(t, err)
 }
@@ -0,0 +1,18 @@
+package util
+
+// Constants for the user roles.
+const (
+	DepositorRole = "depositor"
+	AdminRole     = "admin"
+	// ServiceRole is the role of the service accounts, they authenticate with API keys only
+	ServiceRole = "service"
+)
+
+// IsSupportedRole returns true if the role is supported
+func IsSupportedRole(role string) bool {
+	switch role {
+	case DepositorRole, AdminRole, ServiceRole:
+		return true
+	}
+	return false
+}